@@ -42,6 +42,7 @@ func initAPP() *cli.App {
 		cmd.AssetCommand,
 		cmd.FileCommand,
 		cmd.UserspaceCommand,
+		cmd.ShareCommand,
 		cmd.NodeCommand,
 		cmd.SectorCommand,
 		cmd.DnsCommand,
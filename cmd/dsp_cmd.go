@@ -41,6 +41,9 @@ var FileCommand = cli.Command{
 				flags.DspFileUrlFlag,
 				flags.DspUploadShareFlag,
 				flags.DspUploadStoreTypeFlag,
+				flags.DspUploadCheckDuplicateFlag,
+				flags.DspUploadRejectDuplicateFlag,
+				flags.DspUploadEncryptTypeFlag,
 				flags.TestFlag,
 				flags.DspUploadFileTestCountSize,
 				flags.DspSizeFlag,
@@ -59,6 +62,8 @@ var FileCommand = cli.Command{
 				flags.DspDecryptPwdFlag,
 				flags.DspMaxPeerCntFlag,
 				flags.DspSetFileNameFlag,
+				flags.DspUploadEncryptTypeFlag,
+				flags.DspShareLinkFlag,
 			},
 			Description: "Download file",
 		},
@@ -163,6 +168,33 @@ var FileCommand = cli.Command{
 			},
 			Description: "Get uploaded file prove detail",
 		},
+		{
+			Name:  "history",
+			Usage: "Export or import transfer history",
+			Subcommands: []cli.Command{
+				{
+					Action:    exportTransferHistory,
+					Name:      "export",
+					Usage:     "Export transfer history",
+					ArgsUsage: "[arguments...]",
+					Flags: []cli.Flag{
+						flags.DspHistoryPathFlag,
+					},
+					Description: "Write every task, its progress info and file metadata to a JSON Lines archive",
+				},
+				{
+					Action:    importTransferHistory,
+					Name:      "import",
+					Usage:     "Import transfer history",
+					ArgsUsage: "[arguments...]",
+					Flags: []cli.Flag{
+						flags.DspHistoryPathFlag,
+					},
+					Description: "Merge the tasks of a JSON Lines archive into transfer history, existing task ids are skipped",
+				},
+			},
+			Description: "Export or import transfer history",
+		},
 	},
 	Description: `./edge file --help command to view help information.`,
 }
@@ -217,7 +249,10 @@ func fileDownload(ctx *cli.Context) error {
 	decryptPwd := ctx.String(flags.GetFlagName(flags.DspDecryptPwdFlag))
 	maxPeerNum := ctx.Uint64(flags.GetFlagName(flags.DspMaxPeerCntFlag))
 	setFileName := ctx.Bool(flags.GetFlagName(flags.DspSetFileNameFlag))
-	_, err = utils.DownloadFile(fileHash, url, link, decryptPwd, maxPeerNum, setFileName, pwdHash)
+	encryptType := ctx.String(flags.GetFlagName(flags.DspUploadEncryptTypeFlag))
+	shareLink := ctx.String(flags.GetFlagName(flags.DspShareLinkFlag))
+	_, err = utils.DownloadFile(fileHash, url, link, decryptPwd, maxPeerNum, setFileName, pwdHash, encryptType,
+		shareLink)
 	if err != nil {
 		PrintErrorMsg("download file err %s", err)
 		return err
@@ -250,6 +285,9 @@ func fileUpload(ctx *cli.Context) error {
 	uploadUrl := ctx.String(flags.GetFlagName(flags.DspFileUrlFlag))
 	share := ctx.Bool(flags.GetFlagName(flags.DspUploadShareFlag))
 	storeType := ctx.Int64(flags.GetFlagName(flags.DspUploadStoreTypeFlag))
+	checkDuplicate := ctx.Bool(flags.GetFlagName(flags.DspUploadCheckDuplicateFlag))
+	rejectDuplicate := ctx.Bool(flags.GetFlagName(flags.DspUploadRejectDuplicateFlag))
+	encryptType := ctx.String(flags.GetFlagName(flags.DspUploadEncryptTypeFlag))
 
 	realFileSize := uint64(0)
 	if ctx.IsSet(flags.GetFlagName(flags.DspSizeFlag)) {
@@ -268,7 +306,8 @@ func fileUpload(ctx *cli.Context) error {
 	testCount := ctx.Int64(flags.GetFlagName(flags.DspUploadFileTestCountSize))
 	if !test {
 		_, err = utils.UploadFile(fileName, pwdHash, fileDesc, nil, encryptPwd, encryptNodeAddr,
-			uploadUrl, share, duration, proveLevel, uploadPrivilege, copyNum, storeType, realFileSize)
+			uploadUrl, share, duration, proveLevel, uploadPrivilege, copyNum, storeType, realFileSize, rejectDuplicate, encryptType,
+			checkDuplicate)
 		if err != nil {
 			PrintErrorMsg("upload file err %s", err)
 			return err
@@ -291,7 +330,7 @@ func fileUpload(ctx *cli.Context) error {
 		fileName = filepath.Join(config.FsFileRootPath(), "/", baseName)
 		ioutil.WriteFile(fileName, data, 0666)
 		PrintInfoMsg("filemd5 is %s", hex.EncodeToString(md5Ret[:]))
-		_, err = utils.UploadFile(fileName, pwdHash, fileDesc, nil, encryptPwd, encryptNodeAddr, uploadUrl, share, duration, proveLevel, uploadPrivilege, copyNum, storeType, realFileSize)
+		_, err = utils.UploadFile(fileName, pwdHash, fileDesc, nil, encryptPwd, encryptNodeAddr, uploadUrl, share, duration, proveLevel, uploadPrivilege, copyNum, storeType, realFileSize, rejectDuplicate, encryptType, checkDuplicate)
 		if err != nil {
 			PrintErrorMsg("upload file err %s", err)
 			return err
@@ -363,6 +402,28 @@ func getTransferList(ctx *cli.Context) error {
 	return nil
 }
 
+func exportTransferHistory(ctx *cli.Context) error {
+	path := ctx.String(flags.GetFlagName(flags.DspHistoryPathFlag))
+	ret, err := utils.ExportTransferHistory(path)
+	if err != nil {
+		PrintErrorMsg("export transfer history err %s", err)
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func importTransferHistory(ctx *cli.Context) error {
+	path := ctx.String(flags.GetFlagName(flags.DspHistoryPathFlag))
+	ret, err := utils.ImportTransferHistory(path)
+	if err != nil {
+		PrintErrorMsg("import transfer history err %s", err)
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
 func getUserSpace(ctx *cli.Context) error {
 	// if !ctx.IsSet(flags.GetFlagName(flags.DspWalletAddrFlag)) {
 	// 	PrintErrorMsg("Missing wallet address.")
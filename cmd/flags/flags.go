@@ -107,6 +107,26 @@ var (
 		Name:  "serviceTime",
 		Usage: "DSP storage node service time. [string]",
 	}
+	DspServicePeriodFlag = cli.StringFlag{
+		Name:  "servicePeriod",
+		Usage: "DSP storage node service period in seconds from now. [string]",
+	}
+	DspNodeSetupCheckFlag = cli.BoolFlag{
+		Name:  "check",
+		Usage: "Only check the machine and show the setup plan",
+	}
+	DspNodeSetupYesFlag = cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Setup the node with the plan without confirming",
+	}
+	DspDataDirsFlag = cli.StringFlag{
+		Name:  "dataDirs",
+		Usage: "New data directories separated by comma to grow the volume onto, not supported yet. [string]",
+	}
+	DspVolumeResizeCheckFlag = cli.BoolFlag{
+		Name:  "check",
+		Usage: "Only check the new volume",
+	}
 	DspWalletAddrFlag = cli.StringFlag{
 		Name:  "walletAddr",
 		Usage: "Account wallet address. [string]",
@@ -121,6 +141,10 @@ var (
 		Name:  "link",
 		Usage: "`<link>` of file. [string]",
 	}
+	DspShareLinkFlag = cli.StringFlag{
+		Name:  "shareLink",
+		Usage: "Signed share `<link>` of file, if the account isn't allowed by the file privilege. [string]",
+	}
 	DspInorderFlag = cli.BoolFlag{
 		Name:  "inorder",
 		Usage: "Download file in order. [bool]",
@@ -218,6 +242,31 @@ var (
 		Usage: "File list size limit. [string]",
 		Value: "0",
 	}
+	DspShareBeginFlag = cli.StringFlag{
+		Name:  "begin",
+		Usage: "Share records created after the unix timestamp. [string]",
+		Value: "0",
+	}
+	DspShareEndFlag = cli.StringFlag{
+		Name:  "end",
+		Usage: "Share records created before the unix timestamp, 0 is now. [string]",
+		Value: "0",
+	}
+	DspSharePeriodFlag = cli.StringFlag{
+		Name:  "period",
+		Usage: "Share stats period. day, week or month. [string]",
+		Value: "day",
+	}
+	DspShareTopFlag = cli.StringFlag{
+		Name:  "top",
+		Usage: "Number of top files by profit. [string]",
+		Value: "10",
+	}
+	DspHistoryPathFlag = cli.StringFlag{
+		Name:  "path",
+		Usage: "Relative `<path>` of the transfer history archive in JSON Lines, under the history directory of data dir. [string]",
+		Value: "transfer_history.jsonl",
+	}
 	DspFileTransferTypeFlag = cli.StringFlag{
 		Name:  "transferType",
 		Usage: "File transfer type. [string]",
@@ -227,6 +276,18 @@ var (
 		Name:  "storeType",
 		Usage: "Store file type. 0, space mode, 1 advance mode.[int64]",
 	}
+	DspUploadCheckDuplicateFlag = cli.BoolFlag{
+		Name:  "checkDuplicate",
+		Usage: "Show the stored file with the same content in the result of upload. [bool]",
+	}
+	DspUploadRejectDuplicateFlag = cli.BoolFlag{
+		Name:  "rejectDuplicate",
+		Usage: "Don't upload file if the same content has been stored. [bool]",
+	}
+	DspUploadEncryptTypeFlag = cli.StringFlag{
+		Name:  "encryptType",
+		Usage: "Encrypt type of the file, \"gcm\" for chunked aes-gcm stream. [string]",
+	}
 	DspFileNameFlag = cli.StringFlag{
 		Name:  "fileName",
 		Usage: "File name. [string]",
@@ -410,6 +471,10 @@ var (
 		Name:  "isPlot",
 		Usage: "Set if the sector is used to store plot files",
 	}
+	DspSectorRebalancePlanFlag = cli.BoolFlag{
+		Name:  "plan",
+		Usage: "Only show the plan of moving files out of the sector",
+	}
 
 	////////////////Dsp Plot Setting///////////////////
 	PlotSystemFlag = cli.StringFlag{
@@ -447,6 +512,33 @@ var (
 		Name:  "taskId",
 		Usage: "Target taskId for plot task",
 	}
+	PlotJobsFlag = cli.Uint64Flag{
+		Name:  "jobs",
+		Usage: "Max plot jobs running in parallel",
+		Value: 1,
+	}
+	PlotThreadsFlag = cli.Uint64Flag{
+		Name:  "threads",
+		Usage: "Max cpu threads of a plot job, 0 for all",
+	}
+	PlotMinFreeSpaceFlag = cli.Uint64Flag{
+		Name:  "minFreeSpace",
+		Usage: "Disk space(MB) kept free after plotting",
+		Value: 1024,
+	}
+	PlotCapacityFlag = cli.Uint64Flag{
+		Name:  "capacity",
+		Usage: "Total size(MB) of plot files to plan, 0 for all capacity of plot directories",
+	}
+	PlotStartPlanFlag = cli.BoolFlag{
+		Name:  "start",
+		Usage: "Start plot jobs of the plan, the plotted files are added to mine",
+	}
+	PlotVerifySamplesFlag = cli.Uint64Flag{
+		Name:  "samples",
+		Usage: "Nonces sampled of each plot file to check its scoops, 0 for only checking file sizes",
+		Value: 2,
+	}
 
 	CreateSectorFlag = cli.BoolFlag{
 		Name:  "create-sector",
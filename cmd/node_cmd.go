@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/saveio/edge/cmd/flags"
 	"github.com/saveio/edge/cmd/utils"
+	eUtils "github.com/saveio/edge/utils"
 	chainCom "github.com/saveio/themis/common"
+	"github.com/saveio/themis/common/password"
 	"github.com/urfave/cli"
 )
 
@@ -64,6 +70,41 @@ var NodeCommand = cli.Command{
 				flags.DspWalletAddrFlag,
 			},
 		},
+		{
+			Action:    setupNode,
+			Name:      "setup",
+			Usage:     "Setup storage node step by step",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.DspNodeAddrFlag,
+				flags.DspVolumeFlag,
+				flags.DspServicePeriodFlag,
+				flags.DspSectorSizeFlag,
+				flags.DspSectorProveLevelFlag,
+				flags.TotalDepositFlag,
+				flags.DspNodeSetupCheckFlag,
+				flags.DspNodeSetupYesFlag,
+				flags.WalletPasswordFlag,
+			},
+			Description: "Check free disk and reachability of the node, suggest volume and sectors, " +
+				"then register node, create sectors and open channel in order. " +
+				"The finished steps are rolled back if a step failed. " +
+				"Use --yes and --password to setup without prompts.",
+		},
+		{
+			Action:    resizeVolume,
+			Name:      "resize",
+			Usage:     "Resize volume of node",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.DspVolumeFlag,
+				flags.DspDataDirsFlag,
+				flags.DspVolumeResizeCheckFlag,
+			},
+			Description: "Check the new volume with used space, sectors, free disk of FsRepoRoot and FsMaxStorage, " +
+				"then update the volume while the node keeps running. " +
+				"Growing onto new data directories is rejected, the fs store only writes to FsRepoRoot.",
+		},
 	},
 	Description: `./dsp node --help command to view help information.`,
 }
@@ -154,3 +195,104 @@ func withDraw(ctx *cli.Context) error {
 	PrintJsonData(ret)
 	return nil
 }
+
+func setupNode(ctx *cli.Context) error {
+	nodeAddr := ctx.String(flags.GetFlagName(flags.DspNodeAddrFlag))
+	volume := ctx.String(flags.GetFlagName(flags.DspVolumeFlag))
+	servicePeriod := ctx.String(flags.GetFlagName(flags.DspServicePeriodFlag))
+	sectorSize := fmt.Sprintf("%d", ctx.Uint64(flags.GetFlagName(flags.DspSectorSizeFlag)))
+	proveLevel := fmt.Sprintf("%d", ctx.Uint64(flags.GetFlagName(flags.DspSectorProveLevelFlag)))
+	channelAmount := fmt.Sprintf("%d", ctx.Uint64(flags.GetFlagName(flags.TotalDepositFlag)))
+	ret, err := utils.CheckNodeSetup(nodeAddr, volume, servicePeriod, sectorSize, proveLevel, channelAmount)
+	if err != nil {
+		PrintErrorMsg("check node setup err:%s\n", err)
+		return err
+	}
+	PrintJsonData(ret)
+	plan := struct {
+		Volume   uint64
+		Errors   []string
+		Warnings []string
+	}{}
+	if err := json.Unmarshal(ret, &plan); err != nil {
+		return err
+	}
+	if len(plan.Errors) > 0 {
+		PrintErrorMsg("node can't be setup: %s", strings.Join(plan.Errors, ", "))
+		return nil
+	}
+	if ctx.Bool(flags.GetFlagName(flags.DspNodeSetupCheckFlag)) {
+		return nil
+	}
+	for _, warning := range plan.Warnings {
+		PrintWarnMsg("%s", warning)
+	}
+	if !ctx.Bool(flags.GetFlagName(flags.DspNodeSetupYesFlag)) {
+		fmt.Print("Setup the node with the plan above? [y/N]: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			PrintInfoMsg("Node setup is cancelled")
+			return nil
+		}
+	}
+	pwd := []byte(ctx.String(flags.GetFlagName(flags.WalletPasswordFlag)))
+	if len(pwd) == 0 {
+		if pwd, err = password.GetPassword(); err != nil {
+			return err
+		}
+	}
+	pwdHash := eUtils.Sha256HexStr(string(pwd))
+	// setup with the planned volume, it may be suggested
+	ret, err = utils.SetupNode(nodeAddr, fmt.Sprintf("%d", plan.Volume), servicePeriod, sectorSize, proveLevel,
+		channelAmount, pwdHash)
+	if err != nil {
+		PrintErrorMsg("setup node err:%s\n", err)
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func resizeVolume(ctx *cli.Context) error {
+	if !ctx.IsSet(flags.GetFlagName(flags.DspVolumeFlag)) {
+		PrintErrorMsg("Missing argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	volume := ctx.String(flags.GetFlagName(flags.DspVolumeFlag))
+	dataDirs := ctx.String(flags.GetFlagName(flags.DspDataDirsFlag))
+	ret, err := utils.CheckVolumeResize(volume, dataDirs)
+	if err != nil {
+		PrintErrorMsg("check volume resize err:%s\n", err)
+		return err
+	}
+	PrintJsonData(ret)
+	plan := struct {
+		Errors   []string
+		Warnings []string
+	}{}
+	if err := json.Unmarshal(ret, &plan); err != nil {
+		return err
+	}
+	if len(plan.Errors) > 0 {
+		PrintErrorMsg("volume can't be resized: %s", strings.Join(plan.Errors, ", "))
+		return nil
+	}
+	if ctx.Bool(flags.GetFlagName(flags.DspVolumeResizeCheckFlag)) {
+		return nil
+	}
+	for _, warning := range plan.Warnings {
+		PrintWarnMsg("%s", warning)
+	}
+	pwd, err := password.GetPassword()
+	if err != nil {
+		return err
+	}
+	ret, err = utils.ResizeVolume(volume, eUtils.Sha256HexStr(string(pwd)), dataDirs)
+	if err != nil {
+		PrintErrorMsg("resize volume err:%s\n", err)
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
@@ -74,6 +74,61 @@ var PlotCommand = cli.Command{
 			Flags:       []cli.Flag{},
 			Description: "List all plot tasks",
 		},
+		{
+			Action:      getPlotJobs,
+			Name:        "jobs",
+			Usage:       "List plot jobs with progress",
+			ArgsUsage:   "[arguments...]",
+			Flags:       []cli.Flag{},
+			Description: "List plot jobs with progress and the limits of jobs",
+		},
+		{
+			Action:    cancelPlotJob,
+			Name:      "cancel",
+			Usage:     "Cancel a plot job",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.PlotTaskIdFlag,
+			},
+			Description: "Cancel a pending or running plot job, remove its partial plot file and task",
+		},
+		{
+			Action:    setPlotJobLimits,
+			Name:      "limits",
+			Usage:     "Set limits of plot jobs",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.PlotJobsFlag,
+				flags.PlotThreadsFlag,
+				flags.PlotMinFreeSpaceFlag,
+			},
+			Description: "Set max parallel plot jobs, cpu threads of a job and disk space kept free",
+		},
+		{
+			Action:    planPlotFiles,
+			Name:      "plan",
+			Usage:     "Plan plot files in plot directories",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.PlotCapacityFlag,
+				flags.PlotSizeFlag,
+				flags.PlotStartPlanFlag,
+				flags.CreateSectorFlag,
+			},
+			Description: "Plan plot files with continuous nonces in plot directories by their capacity, " +
+				"start plot jobs of the plan with --start",
+		},
+		{
+			Action:    verifyPlotFiles,
+			Name:      "verify",
+			Usage:     "Verify plot files",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.PlotPathFlag,
+				flags.PlotVerifySamplesFlag,
+			},
+			Description: "Check sizes and sampled scoops of plot files, find overlapping or missing nonce ranges",
+		},
 	},
 	Description: `./dsp plot --help command to view help information.`,
 }
@@ -234,3 +289,73 @@ func getAllPlotTasks(ctx *cli.Context) error {
 	PrintJsonData(ret)
 	return nil
 }
+
+func getPlotJobs(ctx *cli.Context) error {
+	ret, err := utils.GetPlotJobs()
+	if err != nil {
+		PrintErrorMsg(err.Error())
+		return nil
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func cancelPlotJob(ctx *cli.Context) error {
+	taskId := ctx.String(flags.GetFlagName(flags.PlotTaskIdFlag))
+	if len(taskId) == 0 {
+		PrintErrorMsg("Missing argument --taskId.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	ret, err := utils.CancelPlotJob(taskId)
+	if err != nil {
+		PrintErrorMsg(err.Error())
+		return nil
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func setPlotJobLimits(ctx *cli.Context) error {
+	jobs := ctx.Uint64(flags.GetFlagName(flags.PlotJobsFlag))
+	threads := ctx.Uint64(flags.GetFlagName(flags.PlotThreadsFlag))
+	minFreeSpace := ctx.Uint64(flags.GetFlagName(flags.PlotMinFreeSpaceFlag))
+	ret, err := utils.SetPlotJobLimits(jobs, threads, minFreeSpace)
+	if err != nil {
+		PrintErrorMsg(err.Error())
+		return nil
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func verifyPlotFiles(ctx *cli.Context) error {
+	path := ctx.String(flags.GetFlagName(flags.PlotPathFlag))
+	samples := ctx.Uint64(flags.GetFlagName(flags.PlotVerifySamplesFlag))
+	ret, err := utils.VerifyPlotFiles(path, fmt.Sprintf("%d", samples))
+	if err != nil {
+		PrintErrorMsg(err.Error())
+		return nil
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func planPlotFiles(ctx *cli.Context) error {
+	capacity := ctx.Uint64(flags.GetFlagName(flags.PlotCapacityFlag))
+	fileSize := ctx.Uint64(flags.GetFlagName(flags.PlotSizeFlag))
+	var ret []byte
+	var err error
+	if ctx.Bool(flags.GetFlagName(flags.PlotStartPlanFlag)) {
+		createSector := ctx.Bool(flags.GetFlagName(flags.CreateSectorFlag))
+		ret, err = utils.StartPlotPlan(capacity, fileSize, createSector)
+	} else {
+		ret, err = utils.PlanPlotFiles(capacity, fileSize)
+	}
+	if err != nil {
+		PrintErrorMsg(err.Error())
+		return nil
+	}
+	PrintJsonData(ret)
+	return nil
+}
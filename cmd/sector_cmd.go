@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/saveio/edge/cmd/flags"
 	"github.com/saveio/edge/cmd/utils"
+	eUtils "github.com/saveio/edge/utils"
+	"github.com/saveio/themis/common/password"
 	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
 	"github.com/urfave/cli"
 )
@@ -57,6 +60,27 @@ var SectorCommand = cli.Command{
 			},
 			Description: "Get all sector info for a node",
 		},
+		{
+			Action:      getSectorsSummary,
+			Name:        "list",
+			Usage:       "List sectors of the node with usage and health",
+			ArgsUsage:   "[arguments...]",
+			Flags:       []cli.Flag{},
+			Description: "List sectors of the node with used and free size, files and plots, prove level and last proof",
+		},
+		{
+			Action:    rebalanceSector,
+			Name:      "rebalance",
+			Usage:     "Move files out of a sector and delete it",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.DspSectorIdFlag,
+				flags.CreateSectorFlag,
+				flags.DspSectorRebalancePlanFlag,
+				flags.GasLimitFlag,
+			},
+			Description: "Move plot files out of a sector and delete the sector, the planned target sectors are advisory",
+		},
 	},
 	Description: `./dsp sector --help command to view help information.`,
 }
@@ -145,6 +169,46 @@ func getSectorInfosForNode(ctx *cli.Context) error {
 	return nil
 }
 
+func getSectorsSummary(ctx *cli.Context) error {
+	ret, err := utils.GetSectorsSummary()
+	if err != nil {
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
+func rebalanceSector(ctx *cli.Context) error {
+	if !ctx.IsSet(flags.GetFlagName(flags.DspSectorIdFlag)) {
+		PrintErrorMsg("Missing argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+
+	sectorId := ctx.String(flags.GetFlagName(flags.DspSectorIdFlag))
+	createSector := ctx.Bool(flags.GetFlagName(flags.CreateSectorFlag))
+	if ctx.Bool(flags.GetFlagName(flags.DspSectorRebalancePlanFlag)) {
+		ret, err := utils.PlanSectorRebalance(sectorId, createSector)
+		if err != nil {
+			return err
+		}
+		PrintJsonData(ret)
+		return nil
+	}
+	pwd, err := password.GetPassword()
+	if err != nil {
+		return err
+	}
+	pwdHash := eUtils.Sha256HexStr(string(pwd))
+	gasLimit := ctx.Uint64(flags.GetFlagName(flags.GasLimitFlag))
+	ret, err := utils.RebalanceSector(sectorId, createSector, pwdHash, fmt.Sprintf("%d", gasLimit))
+	if err != nil {
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
+
 type SectorInfo struct {
 	NodeAddr         string
 	SectorID         uint64
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/saveio/edge/cmd/flags"
+	"github.com/saveio/edge/cmd/utils"
+	"github.com/urfave/cli"
+)
+
+var ShareCommand = cli.Command{
+	Name:  "share",
+	Usage: "Show revenue of shared files",
+	Subcommands: []cli.Command{
+		{
+			Action:    getShareStats,
+			Name:      "stats",
+			Usage:     "Show share revenue stats",
+			ArgsUsage: "[arguments...]",
+			Flags: []cli.Flag{
+				flags.DspShareBeginFlag,
+				flags.DspShareEndFlag,
+				flags.DspSharePeriodFlag,
+				flags.DspShareTopFlag,
+			},
+			Description: "Show share revenue per file, per downloader and per period, with top files by profit",
+		},
+	},
+	Description: `./edge share --help command to view help information.`,
+}
+
+func getShareStats(ctx *cli.Context) error {
+	begin := ctx.String(flags.GetFlagName(flags.DspShareBeginFlag))
+	end := ctx.String(flags.GetFlagName(flags.DspShareEndFlag))
+	period := ctx.String(flags.GetFlagName(flags.DspSharePeriodFlag))
+	top := ctx.String(flags.GetFlagName(flags.DspShareTopFlag))
+	ret, err := utils.GetShareStats(begin, end, period, top)
+	if err != nil {
+		PrintErrorMsg("get share stats err %s", err)
+		return err
+	}
+	PrintJsonData(ret)
+	return nil
+}
@@ -8,7 +8,8 @@ import (
 )
 
 func UploadFile(path, password, desc string, WhiteList []string, encryptPassword, encryptNodeAddr, url string, share bool,
-	duration, proveLevel string, privilege uint64, copyNum string, storeType int64, realFileSize uint64) ([]byte, error) {
+	duration, proveLevel string, privilege uint64, copyNum string, storeType int64, realFileSize uint64, rejectDuplicate bool,
+	encryptType string, checkDuplicate bool) ([]byte, error) {
 	var err error
 	var durationVal, proveLevelVal, copyNumVal interface{}
 	var durationF float64
@@ -32,7 +33,8 @@ func UploadFile(path, password, desc string, WhiteList []string, encryptPassword
 		}
 	}
 	ret, dErr := sendRpcRequest("uploadfile", []interface{}{path, password, desc, WhiteList, encryptPassword,
-		encryptNodeAddr, url, share, durationVal, proveLevelVal, float64(privilege), copyNumVal, storeType, realFileSize})
+		encryptNodeAddr, url, share, durationVal, proveLevelVal, float64(privilege), copyNumVal, storeType, realFileSize, rejectDuplicate,
+		encryptType, checkDuplicate})
 	if dErr != nil {
 		fmt.Printf("dErr %v\n", dErr)
 		return nil, dErr.Error
@@ -132,6 +134,27 @@ func GetFileShareRevenue() ([]byte, error) {
 	}
 	return ret, nil
 }
+func GetShareStats(begin, end, period, top string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("getsharestats", []interface{}{begin, end, period, top})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+func ExportTransferHistory(path string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("exporttransferhistory", []interface{}{path})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+func ImportTransferHistory(path string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("importtransferhistory", []interface{}{path})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
 func WhiteListOperate(fileHash string, operation uint64, list []map[string]interface{}) ([]byte, error) {
 	ret, dErr := sendRpcRequest("whitelistoperate", []interface{}{fileHash, operation, list})
 	if dErr != nil {
@@ -39,3 +39,38 @@ func NodeUpdate(nodeAddr, volume, serviceTime string) ([]byte, error) {
 	}
 	return ret, nil
 }
+
+func CheckVolumeResize(volume, dataDirs string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("checkvolumeresize", []interface{}{volume, dataDirs})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func ResizeVolume(volume, pwdHash, dataDirs string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("resizevolume", []interface{}{volume, pwdHash, dataDirs})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func CheckNodeSetup(nodeAddr, volume, servicePeriod, sectorSize, proveLevel, channelAmount string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("checknodesetup", []interface{}{nodeAddr, volume, servicePeriod, sectorSize,
+		proveLevel, channelAmount})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func SetupNode(nodeAddr, volume, servicePeriod, sectorSize, proveLevel, channelAmount, pwdHash string) ([]byte,
+	error) {
+	ret, dErr := sendRpcRequest("setupnode", []interface{}{nodeAddr, volume, servicePeriod, sectorSize,
+		proveLevel, channelAmount, pwdHash})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
@@ -50,3 +50,51 @@ func GetAllPlotTasks() ([]byte, error) {
 	}
 	return ret, nil
 }
+
+func GetPlotJobs() ([]byte, error) {
+	ret, dErr := sendRpcRequest("getplotjobs", []interface{}{})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func CancelPlotJob(taskId string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("cancelplotjob", []interface{}{taskId})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func SetPlotJobLimits(jobs, threads, minFreeSpace uint64) ([]byte, error) {
+	ret, dErr := sendRpcRequest("setplotjoblimits", []interface{}{jobs, threads, minFreeSpace})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func VerifyPlotFiles(path, samples string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("verifyplotfiles", []interface{}{path, samples})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func PlanPlotFiles(capacity, fileSize uint64) ([]byte, error) {
+	ret, dErr := sendRpcRequest("planplotfiles", []interface{}{capacity, fileSize})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func StartPlotPlan(capacity, fileSize uint64, createSector bool) ([]byte, error) {
+	ret, dErr := sendRpcRequest("startplotplan", []interface{}{capacity, fileSize, createSector})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
@@ -31,3 +31,27 @@ func GetSectorInfosForNode(addr string) ([]byte, error) {
 	}
 	return ret, nil
 }
+
+func GetSectorsSummary() ([]byte, error) {
+	ret, dErr := sendRpcRequest("getsectorssummary", []interface{}{})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func PlanSectorRebalance(sectorId string, createSector bool) ([]byte, error) {
+	ret, dErr := sendRpcRequest("plansectorrebalance", []interface{}{sectorId, createSector})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
+
+func RebalanceSector(sectorId string, createSector bool, pwdHash, gasLimit string) ([]byte, error) {
+	ret, dErr := sendRpcRequest("rebalancesector", []interface{}{sectorId, createSector, pwdHash, gasLimit})
+	if dErr != nil {
+		return nil, dErr.Error
+	}
+	return ret, nil
+}
@@ -69,6 +69,19 @@ type BaseConfig struct {
 
 	WalletDir string `json:"WalletDir"`
 	PlotPath  string `json:"PlotPath"`
+
+	PlotDirs []PlotDir `json:"PlotDirs"` // directories to store plot files besides PlotPath
+
+	PlotJobs         int    `json:"PlotJobs"`         // max plot jobs running in parallel
+	PlotThreads      uint64 `json:"PlotThreads"`      // max cpu threads of a plot job, 0 for all
+	PlotMinFreeSpace uint64 `json:"PlotMinFreeSpace"` // MB kept free on the disk of plot path
+	PlotNative       bool   `json:"PlotNative"`       // generate plot files in go instead of the bundled plot tool
+}
+
+// PlotDir. a directory to store plot files
+type PlotDir struct {
+	Path     string `json:"Path"`
+	Capacity uint64 `json:"Capacity"` // max MB of plot files in the directory, 0 for no limit
 }
 
 type FsConfig struct {
@@ -105,6 +118,8 @@ type DspConfig struct {
 	AllowLocalNode  bool   `json:"AllowLocalNode"`
 
 	Mode string `json:"Mode"`
+
+	TrashGracePeriod int `json:"TrashGracePeriod"` // seconds, zero or negative value disables trash and deletes files immediately
 }
 
 type BootstrapConfig struct {
@@ -194,6 +209,7 @@ func TestConfig() *EdgeConfig {
 			DNSWalletAddrs:       nil,
 			HealthCheckDNS:       true,
 			SeedInterval:         600,
+			TrashGracePeriod:     common.DEFAULT_TRASH_GRACE_PERIOD,
 			Trackers:             nil,
 			MaxUploadTask:        1000,
 			MaxDownloadTask:      1000,
@@ -257,6 +273,7 @@ func LocalConfig() *EdgeConfig {
 			DNSWalletAddrs:       nil,
 			HealthCheckDNS:       true,
 			SeedInterval:         600,
+			TrashGracePeriod:     common.DEFAULT_TRASH_GRACE_PERIOD,
 			Trackers:             nil,
 			MaxUploadTask:        1000,
 			MaxDownloadTask:      1000,
@@ -390,6 +407,12 @@ func SetDefaultFieldForConfig(cfg *EdgeConfig) {
 	if len(cfg.BaseConfig.PlotPath) == 0 {
 		cfg.BaseConfig.PlotPath = common.DEFAULT_PLOT_PATH
 	}
+	if cfg.BaseConfig.PlotJobs == 0 {
+		cfg.BaseConfig.PlotJobs = common.DEFAULT_PLOT_JOBS
+	}
+	if cfg.BaseConfig.PlotMinFreeSpace == 0 {
+		cfg.BaseConfig.PlotMinFreeSpace = common.DEFAULT_PLOT_MIN_FREE_SPACE
+	}
 }
 
 func GetConfigFromFile(cfgFileName string) *EdgeConfig {
@@ -485,6 +508,11 @@ func FsFileRootPath() string {
 	return filepath.Join(BaseDataDirPath(), Parameters.FsConfig.FsFileRoot, curUsrWalAddr)
 }
 
+// TransferHistoryDirPath. directory of transfer history archives, the archives can't be read or written out of it
+func TransferHistoryDirPath() string {
+	return filepath.Join(BaseDataDirPath(), common.DEFAULT_TRANSFER_HISTORY_DIR, curUsrWalAddr)
+}
+
 func WsEnabled() bool {
 	if Parameters.BaseConfig.WsPortOffset == 0 {
 		return false
@@ -503,6 +531,33 @@ func PlotPath() string {
 	return filepath.Join(BaseDataDirPath(), Parameters.BaseConfig.PlotPath, addr)
 }
 
+// PlotDirs. all directories to store plot files, PlotPath is the first one.
+// The capacity of PlotPath is set by the item with the same path in PlotDirs.
+func PlotDirs() []PlotDir {
+	dirs := []PlotDir{{Path: PlotPath()}}
+	for _, dir := range Parameters.BaseConfig.PlotDirs {
+		if len(dir.Path) == 0 {
+			continue
+		}
+		if filepath.Clean(dir.Path) == filepath.Clean(dirs[0].Path) {
+			dirs[0].Capacity = dir.Capacity
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// PlotDirPaths. paths of all directories to store plot files
+func PlotDirPaths() []string {
+	dirs := PlotDirs()
+	paths := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		paths = append(paths, dir.Path)
+	}
+	return paths
+}
+
 func GetDefaultAddressFromWallet(walletDir string) string {
 	wallet, err := wallet.OpenWallet(walletDir)
 	if err != nil {
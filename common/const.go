@@ -15,6 +15,29 @@ const (
 	BLOCK_CONFIRM                   = 1    // block confirm
 	BLOCK_DELAY                     = 3    // block delay
 	MAX_CACHE_SIZE                  = 1000 // max cache size
+	MAX_UPLOAD_RESUME_COUNT         = 3    // max auto resume times of a interrupted upload task
+	MAX_WHITELIST_BATCH_SIZE        = 20   // max whitelist txs sent before waiting for them confirmed
+	SHARE_STATS_PAGE_SIZE           = 100  // share records loaded in one page when aggregating stats
+	FILE_INDEX_SYNC_INTERVAL        = 60   // interval of syncing the metadata index with the transfer tasks
+	SEEDING_PROFIT_CHECK_INTERVAL   = 600  // interval of checking seeding files profitability
+	SEED_STATE_SAVE_INTERVAL        = 10   // interval of saving changed seeding states to db
+	TRASH_CHECK_INTERVAL            = 60   // interval of checking trashed files to delete from chain
+	MAX_TRASH_DELETE_BATCH_SIZE     = 50   // max files deleted from chain in one tx when emptying trash
+	MAX_TRASH_PURGE_RECORDS         = 100  // max purge records of trash kept in db
+	TRANSFER_HISTORY_PAGE_SIZE      = 100  // task ids loaded in one page when exporting transfer history
+	TRANSFER_HISTORY_VERSION        = 1    // version of transfer history archive record
+	COST_FORECAST_DEFAULT_MONTHS    = 3    // default horizon of cost forecast
+	COST_FORECAST_MAX_MONTHS        = 36   // max horizon of cost forecast
+	SPACE_GUARD_CHECK_INTERVAL      = 600  // interval of checking userspace with guardian policy
+	MAX_SPACE_GUARD_ACTION_LOGS     = 100  // max action logs of userspace guardian kept in db
+	NODE_MONITOR_CHECK_INTERVAL     = 600  // interval of sampling node info and checking proofs
+	MAX_NODE_MONITOR_SAMPLES        = 1008 // max samples of node monitor kept in db, 7 days by default interval
+	MAX_NODE_MONITOR_ACTION_LOGS    = 100  // max action logs of node monitor kept in db
+	NODE_MONITOR_DEFAULT_DAYS       = 7    // default days of computing node earnings
+	NODE_EXIT_CHECK_INTERVAL        = 600  // interval of checking files handoff of node exit
+	SHARE_PRIVILEGE_CACHE_TIME      = 60   // seconds of caching file info for checking the privilege of share requests
+	SHARE_LINK_PRESENT_TIME         = 3600 // seconds of serving the p2p asks of a requester after it presents a share link
+	SHARE_LINK_PRESENT_TIMEOUT      = 10   // seconds of presenting a share link to a node
 )
 
 // default config
@@ -30,6 +53,14 @@ const (
 	DEFAULT_TRACKER_PORT_OFFSET   = 337             // tracker port offset
 	DEFAULT_WS_PORT_OFFSET        = 339             // tracker port offset
 	DEFAULT_PLOT_PATH             = "./plots"       // default plot path
+	DEFAULT_TRANSFER_HISTORY_DIR  = "history"       // directory of transfer history archives in data dir
+	DEFAULT_TRASH_GRACE_PERIOD    = 0               // seconds before trashed files deleted from chain, trash is disabled by default
+	DEFAULT_FORECAST_SPACE_PERIOD = 30 * 24 * 3600  // seconds of userspace extended by one renewal in forecast
+	DEFAULT_EXIT_STALL_PERIOD     = 7 * 24 * 3600   // seconds a file of exiting node gains no replica before stalled
+	DEFAULT_PLOT_JOBS             = 1               // max plot jobs running in parallel
+	DEFAULT_PLOT_MIN_FREE_SPACE   = 1024            // MB kept free on the disk of plot path
+	DEFAULT_PLOT_FILE_SIZE        = 31457280        // KB of each plot file planned
+	DEFAULT_NODE_SERVICE_PERIOD   = 365 * 24 * 3600 // seconds of service time of node setup
 )
 
 // network common
@@ -7,6 +7,10 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/saveio/dsp-go-sdk/utils/async"
 	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/utils/nodemonitor"
+	"github.com/saveio/edge/utils/plotjob"
+	"github.com/saveio/edge/utils/seedpolicy"
+	"github.com/saveio/edge/utils/spaceguard"
 	sdkCom "github.com/saveio/themis-go-sdk/common"
 )
 
@@ -75,6 +79,36 @@ type NotifyModuleState struct {
 	Response chan *NotifyResp
 }
 
+type NotifySeedingDecision struct {
+	Decision *seedpolicy.Decision
+	Response chan *NotifyResp
+}
+
+type NotifyUserspaceAlert struct {
+	Alert    *spaceguard.Alert
+	Response chan *NotifyResp
+}
+
+type NotifyUserspaceAction struct {
+	Action   *spaceguard.ActionLog
+	Response chan *NotifyResp
+}
+
+type NotifyPlotJob struct {
+	Job      *plotjob.Job
+	Response chan *NotifyResp
+}
+
+type NotifyNodeAlert struct {
+	Alert    *nodemonitor.Alert
+	Response chan *NotifyResp
+}
+
+type NotifyNodeAction struct {
+	Action   *nodemonitor.ActionLog
+	Response chan *NotifyResp
+}
+
 type NotifyResp struct {
 	Error error
 }
@@ -333,3 +367,117 @@ func EventNotifyNewTask(taskType int, id string) error {
 	})
 	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
 }
+
+func EventNotifySeedingDecision(decision *seedpolicy.Decision) error {
+	if EventServerPid == nil {
+		return fmt.Errorf("event server has not instance")
+	}
+	req := &NotifySeedingDecision{
+		Decision: decision,
+		Response: make(chan *NotifyResp, 1),
+	}
+	f := async.TimeoutFunc(func() error {
+		EventServerPid.Tell(req)
+		resp := <-req.Response
+		if resp != nil {
+			return resp.Error
+		}
+		return nil
+	})
+	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
+}
+
+func EventNotifyUserspaceAlert(alert *spaceguard.Alert) error {
+	if EventServerPid == nil {
+		return fmt.Errorf("event server has not instance")
+	}
+	req := &NotifyUserspaceAlert{
+		Alert:    alert,
+		Response: make(chan *NotifyResp, 1),
+	}
+	f := async.TimeoutFunc(func() error {
+		EventServerPid.Tell(req)
+		resp := <-req.Response
+		if resp != nil {
+			return resp.Error
+		}
+		return nil
+	})
+	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
+}
+
+func EventNotifyUserspaceAction(action *spaceguard.ActionLog) error {
+	if EventServerPid == nil {
+		return fmt.Errorf("event server has not instance")
+	}
+	req := &NotifyUserspaceAction{
+		Action:   action,
+		Response: make(chan *NotifyResp, 1),
+	}
+	f := async.TimeoutFunc(func() error {
+		EventServerPid.Tell(req)
+		resp := <-req.Response
+		if resp != nil {
+			return resp.Error
+		}
+		return nil
+	})
+	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
+}
+
+func EventNotifyPlotJob(job *plotjob.Job) error {
+	if EventServerPid == nil {
+		return fmt.Errorf("event server has not instance")
+	}
+	req := &NotifyPlotJob{
+		Job:      job,
+		Response: make(chan *NotifyResp, 1),
+	}
+	f := async.TimeoutFunc(func() error {
+		EventServerPid.Tell(req)
+		resp := <-req.Response
+		if resp != nil {
+			return resp.Error
+		}
+		return nil
+	})
+	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
+}
+
+func EventNotifyNodeAlert(alert *nodemonitor.Alert) error {
+	if EventServerPid == nil {
+		return fmt.Errorf("event server has not instance")
+	}
+	req := &NotifyNodeAlert{
+		Alert:    alert,
+		Response: make(chan *NotifyResp, 1),
+	}
+	f := async.TimeoutFunc(func() error {
+		EventServerPid.Tell(req)
+		resp := <-req.Response
+		if resp != nil {
+			return resp.Error
+		}
+		return nil
+	})
+	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
+}
+
+func EventNotifyNodeAction(action *nodemonitor.ActionLog) error {
+	if EventServerPid == nil {
+		return fmt.Errorf("event server has not instance")
+	}
+	req := &NotifyNodeAction{
+		Action:   action,
+		Response: make(chan *NotifyResp, 1),
+	}
+	f := async.TimeoutFunc(func() error {
+		EventServerPid.Tell(req)
+		resp := <-req.Response
+		if resp != nil {
+			return resp.Error
+		}
+		return nil
+	})
+	return async.DoWithTimeout(f, time.Duration(common.EVENT_ACTOR_TIMEOUT)*time.Second)
+}
@@ -33,10 +33,13 @@ import (
 	"github.com/saveio/edge/common"
 	"github.com/saveio/edge/common/config"
 	"github.com/saveio/edge/dsp/cache"
+	"github.com/saveio/edge/dsp/db"
 	"github.com/saveio/edge/p2p/actor/req"
 	p2p_actor "github.com/saveio/edge/p2p/actor/server"
 	"github.com/saveio/edge/p2p/network"
 	edgeUtils "github.com/saveio/edge/utils"
+	"github.com/saveio/edge/utils/plotjob"
+	"github.com/saveio/edge/utils/seedpolicy"
 	"github.com/saveio/max/max"
 	"github.com/saveio/pylons"
 	"github.com/saveio/pylons/actor/msg_opcode"
@@ -70,18 +73,38 @@ type Endpoint struct {
 	eventHub          *EventHub
 	state             *LifeCycle
 	cache             *cache.EdgeCache
+	db                *db.EdgeDB
 	uploadFileLock    *sync.Mutex
+	streamDecryptPwds sync.Map // download task id => password of gcm stream
+	erasureUploads    sync.Map // erasure manifest id => *erasureUploadParams, to upload a shard again
+	sharePresents     sync.Map // file hash + requester => unix time until its presented share link expires
+	servedLock        sync.Mutex
+	servedBlocks      map[string]*db.ServedBlocks // blocks sent to downloaders not saved to db, guarded by servedLock
+	seedStateLock     sync.Mutex
+	seedPolicy        *seedpolicy.Policy               // cached seeding policy, guarded by seedStateLock
+	seedStates        map[string]*seedpolicy.FileState // seeding states in memory, guarded by seedStateLock
+	seedStatesDirty   map[string]bool                  // file hashes of seeding states not saved to db
+	trashLock         sync.Mutex
+	fileIndexLock     sync.Mutex
+	plotJobs          *plotjob.Manager
+	plotPlanLock      sync.Mutex
+	nodeSetupLock     sync.Mutex
+	nodeExitLock      sync.Mutex
 }
 
 func Init(walletDir, pwd string) (*Endpoint, error) {
 	e := &Endpoint{
-		closeCh:        make(chan struct{}, 1),
-		eventHub:       NewEventHub(),
-		state:          NewLifeCycle(),
-		dspAccLock:     new(sync.Mutex),
-		cache:          cache.NewEdgeCache(),
-		uploadFileLock: new(sync.Mutex),
-	}
+		closeCh:         make(chan struct{}, 1),
+		eventHub:        NewEventHub(),
+		state:           NewLifeCycle(),
+		dspAccLock:      new(sync.Mutex),
+		cache:           cache.NewEdgeCache(),
+		uploadFileLock:  new(sync.Mutex),
+		seedStates:      make(map[string]*seedpolicy.FileState),
+		seedStatesDirty: make(map[string]bool),
+		servedBlocks:    make(map[string]*db.ServedBlocks),
+	}
+	e.plotJobs = e.newPlotJobManager()
 	DspService = e
 	log.Debugf("walletDir: %s, %d", walletDir, len(walletDir))
 	if len(walletDir) == 0 {
@@ -171,9 +194,16 @@ func StartDspNode(endpoint *Endpoint, startListen, startShare, startChannel bool
 	if err := dspOS.CreateDirIfNeed(config.ClientSqliteDBPath()); err != nil {
 		return err
 	}
-	if err := dspOS.CreateDirIfNeed(config.PlotPath()); err != nil {
+	edgeDB, err := db.NewEdgeDB(config.ClientSqliteDBPath())
+	if err != nil {
 		return err
 	}
+	endpoint.db = edgeDB
+	for _, path := range config.PlotDirPaths() {
+		if err := dspOS.CreateDirIfNeed(path); err != nil {
+			return err
+		}
+	}
 	// Skip init fs if Dsp doesn't start listen
 	if !startListen {
 		dspConfig.FsRepoRoot = ""
@@ -194,6 +224,7 @@ func StartDspNode(endpoint *Endpoint, startListen, startShare, startChannel bool
 		}
 		return hostAddr
 	})
+	p2pActor.SetDspSendHook(endpoint.countServedBlocks)
 	endpoint.p2pActor = p2pActor
 	dspSrv := dsp.NewDsp(dspConfig, endpoint.GetDspAccount(), p2pActor.GetLocalPID(), dspConfig.Mode)
 	log.Debugf("new dsp svr %v", dspSrv)
@@ -231,6 +262,15 @@ func StartDspNode(endpoint *Endpoint, startListen, startShare, startChannel bool
 		go endpoint.setupDNSNodeBackground()
 		go endpoint.RegisterProgressCh()
 		go endpoint.RegisterShareNotificationCh()
+		go endpoint.resumeUploadCheckpoints()
+		go endpoint.cleanSealedUploads()
+		go endpoint.trashService()
+		go endpoint.spaceGuardService()
+		go endpoint.nodeMonitorService()
+		go endpoint.nodeExitService()
+		go endpoint.erasureUploadService()
+		go endpoint.seedStateService()
+		go endpoint.fileIndexService()
 	}
 	go endpoint.stateChangeService()
 	version, _ := endpoint.GetNodeVersion()
@@ -279,6 +319,11 @@ func (this *Endpoint) Stop() error {
 	}
 	log.Debugf("stop edge with closing channel success")
 	this.ResetChannelProgress()
+	if this.db != nil {
+		if err := this.db.Close(); err != nil {
+			log.Errorf("close edge db err %s", err)
+		}
+	}
 	return this.getDsp().Stop()
 }
 
@@ -356,7 +401,7 @@ func (this *Endpoint) startDspP2P(hostAddr *common.HostAddr, acc *account.Accoun
 	codes[dspNetCom.MSG_OP_CODE] = &pb.Message{}
 	opts := []network.NetworkOption{
 		network.WithKeys(networkKey),
-		network.WithMsgHandler(this.getDsp().Receive),
+		network.WithMsgHandler(this.seedingMsgHandler),
 		network.WithNetworkId(config.Parameters.BaseConfig.NetworkId),
 		network.WithWalletAddrFromPeerId(crypto.AddressFromPubkeyHex),
 		network.WithOpcodes(codes),
@@ -471,6 +516,7 @@ func (this *Endpoint) startShareService() {
 	if err == nil {
 		this.getDsp().PushFilesToTrackers(files)
 	}
+	go this.seedingProfitService()
 }
 
 // SetupDNSNodeBackground. setup a dns node background when received first payments.
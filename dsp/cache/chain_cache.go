@@ -1,11 +1,21 @@
 package cache
 
-import lru "github.com/hashicorp/golang-lru"
+import (
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
 
 type ChainCache struct {
 	c *lru.ARCCache
 }
 
+type fileInfoEntry struct {
+	info     *fs.FileInfo
+	cachedAt time.Time
+}
+
 func (this *ChainCache) SetTxHashToHeight(txHash string, height uint32) {
 	this.c.Add(TxHashHeightKey(txHash), height)
 }
@@ -37,3 +47,20 @@ func (this *ChainCache) TimestampFromHeight(height uint32) uint32 {
 	}
 	return ts
 }
+
+func (this *ChainCache) SetFileInfo(fileHash string, info *fs.FileInfo) {
+	this.c.Add(FileInfoKey(fileHash), &fileInfoEntry{info: info, cachedAt: time.Now()})
+}
+
+// FileInfo. get the cached file info, return nil if it's not cached or older than maxAge
+func (this *ChainCache) FileInfo(fileHash string, maxAge time.Duration) *fs.FileInfo {
+	value, ok := this.c.Get(FileInfoKey(fileHash))
+	if !ok {
+		return nil
+	}
+	entry, ok := value.(*fileInfoEntry)
+	if !ok || time.Since(entry.cachedAt) > maxAge {
+		return nil
+	}
+	return entry.info
+}
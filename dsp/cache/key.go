@@ -11,3 +11,7 @@ func TxHashHeightKey(txHash string) string {
 func BlockHeightTimestampKey(blockHeight uint32) string {
 	return fmt.Sprintf("BLOCK_HEIGHT_TS: %d", blockHeight)
 }
+
+func FileInfoKey(fileHash string) string {
+	return fmt.Sprintf("CACHE_FILE_INFO: %s", fileHash)
+}
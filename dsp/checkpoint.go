@@ -0,0 +1,226 @@
+package dsp
+
+import (
+	"os"
+	"time"
+
+	"github.com/saveio/dsp-go-sdk/store"
+	dspTypes "github.com/saveio/dsp-go-sdk/task/types"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/uploadckpt"
+	"github.com/saveio/themis/common/log"
+)
+
+type ResumableUpload struct {
+	TaskId          string
+	FileHash        string
+	FileName        string
+	FilePath        string
+	TotalBlockCount uint64
+	NodeAckedBlocks map[string]uploadckpt.Blocks // blocks acknowledged by each node
+	ResumeFrom      map[string]uint64            // first block not acknowledged of each node
+	LastState       store.TaskState
+	CurrentState    store.TaskState
+	ResumeCount     uint32
+	LastError       string
+	UpdatedAt       uint64
+	WillResume      bool
+}
+
+// GetResumableUploads. list all upload checkpoints and whether they will be resumed on startup
+func (this *Endpoint) GetResumableUploads() ([]*ResumableUpload, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	checkpoints, err := this.db.GetAllUploadCheckpoints()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	resps := make([]*ResumableUpload, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		resp := &ResumableUpload{
+			TaskId:          checkpoint.TaskId,
+			FileHash:        checkpoint.FileHash,
+			FileName:        checkpoint.FileName,
+			FilePath:        checkpoint.FilePath,
+			TotalBlockCount: checkpoint.TotalBlockCount,
+			NodeAckedBlocks: checkpointAcked(checkpoint).Nodes,
+			ResumeFrom:      checkpointAcked(checkpoint).ResumeFrom(),
+			LastState:       checkpoint.State,
+			CurrentState:    checkpoint.State,
+			ResumeCount:     checkpoint.ResumeCount,
+			LastError:       checkpoint.LastError,
+			UpdatedAt:       checkpoint.UpdatedAt,
+		}
+		if dsp.IsTaskExist(checkpoint.TaskId) {
+			if state, err := dsp.GetTaskState(checkpoint.TaskId); err == nil {
+				resp.CurrentState = state
+			}
+			resp.WillResume = this.canResumeUpload(checkpoint)
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+// saveUploadCheckpoint. save the state and the blocks acknowledged by each node of the upload task,
+// it's deleted when the task is done or canceled
+func (this *Endpoint) saveUploadCheckpoint(info *dspTypes.ProgressInfo) {
+	dsp := this.getDsp()
+	if dsp == nil || this.db == nil || info == nil || info.Type != store.TaskTypeUpload {
+		return
+	}
+	if info.TaskState == store.TaskStateDone || info.TaskState == store.TaskStateCancel {
+		if err := this.db.DeleteUploadCheckpoint(info.TaskId); err != nil {
+			log.Errorf("delete upload checkpoint of %s err %s", info.TaskId, err)
+		}
+		return
+	}
+	checkpoint, err := this.db.GetUploadCheckpoint(info.TaskId)
+	if err != nil {
+		log.Errorf("get upload checkpoint of %s err %s", info.TaskId, err)
+		return
+	}
+	now := uint64(time.Now().Unix())
+	if checkpoint == nil {
+		checkpoint = &db.UploadCheckpoint{
+			TaskId:    info.TaskId,
+			CreatedAt: now,
+		}
+		if taskInfo := dsp.GetTaskInfo(info.TaskId); taskInfo != nil {
+			checkpoint.FilePath = taskInfo.FilePath
+		}
+	}
+	if len(info.FileHash) > 0 {
+		checkpoint.FileHash = info.FileHash
+	}
+	if len(info.FileName) > 0 {
+		checkpoint.FileName = info.FileName
+	}
+	checkpoint.TotalBlockCount = uint64(info.Total)
+	checkpoint.State = info.TaskState
+	acked := checkpointAcked(checkpoint)
+	acked.Total = checkpoint.TotalBlockCount
+	counts := make(map[string]uint64, len(info.Progress))
+	for node, cnt := range info.Progress {
+		counts[node] = uint64(cnt.Progress)
+	}
+	if nodes := acked.Regressed(counts); len(nodes) > 0 {
+		log.Warnf("upload task %s sends blocks acknowledged before to nodes %v again, resume from %v",
+			info.TaskId, nodes, acked.ResumeFrom())
+	}
+	for node, count := range counts {
+		acked.Ack(node, count)
+	}
+	checkpoint.UpdatedAt = now
+	if err := this.db.PutUploadCheckpoint(checkpoint); err != nil {
+		log.Errorf("save upload checkpoint of %s err %s", info.TaskId, err)
+	}
+}
+
+// resumeUploadCheckpoints. resume the upload tasks which were interrupted by the last shutdown.
+// A paused task is resumed, a task left running by the shutdown is paused and resumed to restart its sending,
+// and a failed task is resumed if all its blocks were acknowledged, otherwise retried. The sdk sends each node
+// the blocks after those recorded in its task store, the checkpoint keeps the acknowledged blocks to check it.
+func (this *Endpoint) resumeUploadCheckpoints() {
+	dsp := this.getDsp()
+	if dsp == nil || this.db == nil {
+		return
+	}
+	for !dsp.Running() {
+		select {
+		case <-time.After(time.Second):
+		case <-this.closeCh:
+			return
+		}
+	}
+	checkpoints, err := this.db.GetAllUploadCheckpoints()
+	if err != nil {
+		log.Errorf("get upload checkpoints err %s", err)
+		return
+	}
+	for _, checkpoint := range checkpoints {
+		if !dsp.IsTaskExist(checkpoint.TaskId) {
+			this.db.DeleteUploadCheckpoint(checkpoint.TaskId)
+			continue
+		}
+		state, err := dsp.GetTaskState(checkpoint.TaskId)
+		if err != nil {
+			continue
+		}
+		if state == store.TaskStateDone || state == store.TaskStateCancel {
+			this.db.DeleteUploadCheckpoint(checkpoint.TaskId)
+			continue
+		}
+		if !this.canResumeUpload(checkpoint) {
+			continue
+		}
+		var resp *FileTaskResp
+		var derr *DspErr
+		acked := checkpointAcked(checkpoint)
+		switch state {
+		case store.TaskStatePause:
+			resp, derr = this.ResumeUploadFile([]string{checkpoint.TaskId})
+		case store.TaskStateFailed:
+			if acked.Sent() {
+				resp, derr = this.ResumeUploadFile([]string{checkpoint.TaskId})
+			} else {
+				resp, derr = this.RetryUploadFile([]string{checkpoint.TaskId})
+			}
+		default:
+			// the task was running when the node stopped, nothing is sending its blocks now
+			if err := dsp.PauseUpload(checkpoint.TaskId); err != nil {
+				log.Errorf("pause interrupted upload task %s err %s", checkpoint.TaskId, err)
+			}
+			resp, derr = this.ResumeUploadFile([]string{checkpoint.TaskId})
+		}
+		checkpoint.ResumeCount++
+		checkpoint.LastError = ""
+		if derr != nil {
+			checkpoint.LastError = derr.Error.Error()
+		} else if resp != nil && len(resp.Tasks) > 0 && len(resp.Tasks[0].Error) > 0 {
+			checkpoint.LastError = resp.Tasks[0].Error
+		}
+		checkpoint.UpdatedAt = uint64(time.Now().Unix())
+		log.Infof("resume upload task %s from checkpoint, file %s, blocks %v, err: %s",
+			checkpoint.TaskId, checkpoint.FilePath, acked.ResumeFrom(), checkpoint.LastError)
+		if err := this.db.PutUploadCheckpoint(checkpoint); err != nil {
+			log.Errorf("save upload checkpoint of %s err %s", checkpoint.TaskId, err)
+		}
+	}
+}
+
+// canResumeUpload. only the task which was uploading when the node stopped can be resumed,
+// the task paused or failed before is left to the user.
+func (this *Endpoint) canResumeUpload(checkpoint *db.UploadCheckpoint) bool {
+	switch checkpoint.State {
+	case store.TaskStatePause, store.TaskStateFailed, store.TaskStateDone, store.TaskStateCancel:
+		return false
+	}
+	if checkpoint.ResumeCount >= common.MAX_UPLOAD_RESUME_COUNT {
+		return false
+	}
+	if len(checkpoint.FilePath) == 0 {
+		return false
+	}
+	if _, err := os.Stat(checkpoint.FilePath); err != nil {
+		return false
+	}
+	return true
+}
+
+// checkpointAcked. the acknowledged blocks of checkpoint, created if not recorded
+func checkpointAcked(checkpoint *db.UploadCheckpoint) *uploadckpt.Checkpoint {
+	if checkpoint.Acked == nil {
+		checkpoint.Acked = &uploadckpt.Checkpoint{
+			Total: checkpoint.TotalBlockCount,
+			Nodes: make(map[string]uploadckpt.Blocks),
+		}
+	}
+	return checkpoint.Acked
+}
@@ -0,0 +1,479 @@
+package dsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/fileindex"
+	"github.com/saveio/themis/common/log"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+// actions applied to all files of a collection
+const (
+	COLLECTION_ACTION_EXTEND    = "extend"
+	COLLECTION_ACTION_DELETE    = "delete"
+	COLLECTION_ACTION_WHITELIST = "whitelist"
+	COLLECTION_ACTION_SHARE     = "share"
+)
+
+type TagResp struct {
+	Name  string
+	Count int
+}
+
+type TagFilesResp struct {
+	Tag    string
+	Files  []string
+	Failed []string
+}
+
+// CollectionActionParams. the parameters used by collection actions, only the ones of the action are required
+type CollectionActionParams struct {
+	Duration       uint64 // seconds, extend: keep the files stored for it from now, share: link expired duration
+	GasLimit       uint64 // delete
+	WhitelistGroup string // whitelist
+	Operation      string // whitelist: add, remove or replace
+	Recipient      string // share
+	DownloadLimit  uint32 // share
+}
+
+type CollectionActionResult struct {
+	FileHash string
+	Tx       string `json:",omitempty"`
+	Link     string `json:",omitempty"`
+	Code     int64
+	Error    string `json:",omitempty"`
+}
+
+type CollectionActionResp struct {
+	Name    string
+	Action  string
+	Success int
+	Failed  int
+	Results []*CollectionActionResult
+}
+
+// GetTags. get all tags of uploaded files with the count of tagged files, sorted by name
+func (this *Endpoint) GetTags() ([]*TagResp, *DspErr) {
+	entries, derr := this.SyncFileIndex()
+	if derr != nil {
+		return nil, derr
+	}
+	counts := make(map[string]*TagResp)
+	for _, e := range entries {
+		if e.Kind != fileindex.KIND_UPLOAD {
+			continue
+		}
+		for _, t := range e.Tags {
+			key := strings.ToLower(t)
+			if _, ok := counts[key]; !ok {
+				counts[key] = &TagResp{Name: t}
+			}
+			counts[key].Count++
+		}
+	}
+	tags := make([]*TagResp, 0, len(counts))
+	for _, t := range counts {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name)
+	})
+	return tags, nil
+}
+
+// TagFiles. add the tags to the uploaded files
+func (this *Endpoint) TagFiles(fileHashes, tags []string) (*TagFilesResp, *DspErr) {
+	tags = normalizeTags(tags)
+	if len(fileHashes) == 0 || len(tags) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	return this.updateUploadFileTags(fileHashes, strings.Join(tags, ","), func(old []string) []string {
+		return normalizeTags(append(old, tags...))
+	})
+}
+
+// UntagFiles. remove the tags from the uploaded files
+func (this *Endpoint) UntagFiles(fileHashes, tags []string) (*TagFilesResp, *DspErr) {
+	tags = normalizeTags(tags)
+	if len(fileHashes) == 0 || len(tags) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	return this.updateUploadFileTags(fileHashes, strings.Join(tags, ","), func(old []string) []string {
+		return removeTags(old, tags)
+	})
+}
+
+// RenameTag. rename the tag of all uploaded files, it's merged if the new tag exists
+func (this *Endpoint) RenameTag(name, newName string) (*TagFilesResp, *DspErr) {
+	newName = strings.TrimSpace(newName)
+	if len(strings.TrimSpace(name)) == 0 || len(newName) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	return this.updateUploadFileTags(nil, newName, func(old []string) []string {
+		if len(removeTags(old, []string{name})) == len(old) {
+			return old
+		}
+		tags := make([]string, 0, len(old))
+		for _, t := range old {
+			if strings.EqualFold(t, strings.TrimSpace(name)) {
+				t = newName
+			}
+			tags = append(tags, t)
+		}
+		return normalizeTags(tags)
+	})
+}
+
+// DeleteTag. remove the tag from all uploaded files
+func (this *Endpoint) DeleteTag(name string) (*TagFilesResp, *DspErr) {
+	if len(strings.TrimSpace(name)) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	return this.updateUploadFileTags(nil, name, func(old []string) []string {
+		return removeTags(old, []string{name})
+	})
+}
+
+// updateUploadFileTags. update tags of the uploaded files with update func, all uploaded files are checked if
+// fileHashes is empty. The files not uploaded are returned as failed.
+func (this *Endpoint) updateUploadFileTags(fileHashes []string, tag string, update func([]string) []string) (
+	*TagFilesResp, *DspErr) {
+	entries, derr := this.SyncFileIndex()
+	if derr != nil {
+		return nil, derr
+	}
+	uploads := make(map[string]*fileindex.Entry)
+	for _, e := range entries {
+		if e.Kind == fileindex.KIND_UPLOAD {
+			uploads[e.FileHash] = e
+		}
+	}
+	if len(fileHashes) == 0 {
+		for hash := range uploads {
+			fileHashes = append(fileHashes, hash)
+		}
+		sort.Strings(fileHashes)
+	}
+	resp := &TagFilesResp{Tag: tag, Files: make([]string, 0), Failed: make([]string, 0)}
+	for _, hash := range fileHashes {
+		e, ok := uploads[hash]
+		if !ok {
+			resp.Failed = append(resp.Failed, hash)
+			continue
+		}
+		tags := update(e.Tags)
+		if strings.Join(tags, ",") == strings.Join(e.Tags, ",") {
+			continue
+		}
+		e.Tags = tags
+		e.UpdatedAt = uint64(time.Now().Unix())
+		if err := this.db.PutFileIndexEntry(e); err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		resp.Files = append(resp.Files, hash)
+	}
+	return resp, nil
+}
+
+// SetCollection. create or update a collection, the files of existing collection are kept if fileHashes is nil
+func (this *Endpoint) SetCollection(name, description string, fileHashes []string) (*db.Collection, *DspErr) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	old, err := this.db.GetCollection(name)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	collection := &db.Collection{
+		Name:        name,
+		Description: description,
+		FileHashes:  make([]string, 0),
+		UpdatedAt:   uint64(time.Now().Unix()),
+	}
+	collection.CreatedAt = collection.UpdatedAt
+	if old != nil {
+		collection.CreatedAt = old.CreatedAt
+		collection.FileHashes = old.FileHashes
+	}
+	if fileHashes != nil {
+		collection.FileHashes = mergeFileHashes(nil, fileHashes)
+	}
+	if err := this.db.PutCollection(collection); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return collection, nil
+}
+
+// GetCollections. get all collections sorted by name
+func (this *Endpoint) GetCollections() ([]*db.Collection, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	collections, err := this.db.GetAllCollections()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+	return collections, nil
+}
+
+func (this *Endpoint) GetCollection(name string) (*db.Collection, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	collection, err := this.db.GetCollection(name)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if collection == nil {
+		return nil, &DspErr{Code: DSP_COLLECTION_NOT_FOUND, Error: ErrMaps[DSP_COLLECTION_NOT_FOUND]}
+	}
+	return collection, nil
+}
+
+// DeleteCollection. delete the collection, the files of collection are not affected
+func (this *Endpoint) DeleteCollection(name string) *DspErr {
+	if _, derr := this.GetCollection(name); derr != nil {
+		return derr
+	}
+	if err := this.db.DeleteCollection(name); err != nil {
+		return &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return nil
+}
+
+// AddCollectionFiles. add the files to collection
+func (this *Endpoint) AddCollectionFiles(name string, fileHashes []string) (*db.Collection, *DspErr) {
+	if len(fileHashes) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	collection, derr := this.GetCollection(name)
+	if derr != nil {
+		return nil, derr
+	}
+	collection.FileHashes = mergeFileHashes(collection.FileHashes, fileHashes)
+	return this.putCollection(collection)
+}
+
+// RemoveCollectionFiles. remove the files from collection
+func (this *Endpoint) RemoveCollectionFiles(name string, fileHashes []string) (*db.Collection, *DspErr) {
+	if len(fileHashes) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	collection, derr := this.GetCollection(name)
+	if derr != nil {
+		return nil, derr
+	}
+	removed := make(map[string]struct{}, len(fileHashes))
+	for _, hash := range fileHashes {
+		removed[hash] = struct{}{}
+	}
+	hashes := make([]string, 0, len(collection.FileHashes))
+	for _, hash := range collection.FileHashes {
+		if _, ok := removed[hash]; !ok {
+			hashes = append(hashes, hash)
+		}
+	}
+	collection.FileHashes = hashes
+	return this.putCollection(collection)
+}
+
+// CollectionAction. apply the action to all files of collection in one call.
+// The files stored in user space expire with the user space, they can't be extended one by one.
+// Extending keeps them stored for the duration from now, so the user space is only extended by the time
+// the earliest expired file of collection lacks, with one tx. Nothing is sent if all files are kept long enough.
+// Deleting moves the files into trash instead if trash is enabled by TrashGracePeriod.
+// The other actions report the result of each file.
+func (this *Endpoint) CollectionAction(name, action string, params *CollectionActionParams) (
+	*CollectionActionResp, *DspErr) {
+	if params == nil {
+		params = &CollectionActionParams{}
+	}
+	collection, derr := this.GetCollection(name)
+	if derr != nil {
+		return nil, derr
+	}
+	if len(collection.FileHashes) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: fmt.Errorf("collection %s is empty", name)}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	resp := &CollectionActionResp{
+		Name:    name,
+		Action:  action,
+		Results: make([]*CollectionActionResult, 0, len(collection.FileHashes)),
+	}
+	switch action {
+	case COLLECTION_ACTION_EXTEND:
+		if params.Duration == 0 {
+			return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+		}
+		height, err := dsp.GetCurrentBlockHeight()
+		if err != nil {
+			return nil, &DspErr{Code: CHAIN_GET_HEIGHT_FAILED, Error: err}
+		}
+		keepUntil := uint64(time.Now().Unix()) + params.Duration
+		lack := uint64(0)
+		extendable := make([]*CollectionActionResult, 0, len(collection.FileHashes))
+		for _, hash := range collection.FileHashes {
+			info, err := dsp.GetFileInfo(hash)
+			if err != nil || info == nil {
+				resp.Results = append(resp.Results, &CollectionActionResult{FileHash: hash,
+					Code: DSP_FILE_INFO_NOT_FOUND, Error: ErrMaps[DSP_FILE_INFO_NOT_FOUND].Error()})
+				continue
+			}
+			if fs.FileStoreType(info.StorageType) != fs.FileStoreTypeNormal {
+				resp.Results = append(resp.Results, &CollectionActionResult{FileHash: hash,
+					Code: INVALID_PARAMS, Error: "file is not stored in user space, can't be extended"})
+				continue
+			}
+			extendable = append(extendable, &CollectionActionResult{FileHash: hash})
+			if expiredAt := blockHeightToTimestamp(uint64(height), info.ExpiredHeight); expiredAt < keepUntil &&
+				keepUntil-expiredAt > lack {
+				lack = keepUntil - expiredAt
+			}
+		}
+		if lack == 0 {
+			log.Debugf("files of collection %s are kept until %d, no need to extend", name, keepUntil)
+			resp.Results = append(resp.Results, extendable...)
+		} else if len(extendable) > 0 {
+			tx, derr := this.SetUserSpace("", 0, uint64(fs.UserSpaceNone), lack, uint64(fs.UserSpaceAdd))
+			for _, r := range extendable {
+				if derr != nil {
+					r.Code, r.Error = derr.Code, derr.Error.Error()
+				} else {
+					r.Tx = tx
+				}
+			}
+			resp.Results = append(resp.Results, extendable...)
+		}
+	case COLLECTION_ACTION_DELETE:
+		deleted, derr := this.DeleteUploadFiles(collection.FileHashes, params.GasLimit)
+		if derr != nil {
+			return nil, derr
+		}
+		txs := make(map[string]string, len(deleted))
+		for _, d := range deleted {
+			txs[d.FileHash] = d.Tx
+		}
+		hashes := make([]string, 0)
+		for _, hash := range collection.FileHashes {
+			tx, ok := txs[hash]
+			if !ok {
+				hashes = append(hashes, hash)
+				resp.Results = append(resp.Results, &CollectionActionResult{FileHash: hash,
+					Code: DSP_DELETE_FILE_FAILED, Error: ErrMaps[DSP_DELETE_FILE_FAILED].Error()})
+				continue
+			}
+			resp.Results = append(resp.Results, &CollectionActionResult{FileHash: hash, Tx: tx})
+		}
+		collection.FileHashes = hashes
+		if _, derr := this.putCollection(collection); derr != nil {
+			log.Errorf("update collection %s after delete err %s", name, derr.Error)
+		}
+	case COLLECTION_ACTION_WHITELIST:
+		if len(params.WhitelistGroup) == 0 {
+			return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+		}
+		operation := params.Operation
+		if len(operation) == 0 {
+			operation = WHITELIST_GROUP_ADD
+		}
+		applied, derr := this.ApplyWhitelistGroup(params.WhitelistGroup, collection.FileHashes, operation)
+		if derr != nil {
+			return nil, derr
+		}
+		for _, r := range applied.Results {
+			resp.Results = append(resp.Results, &CollectionActionResult{
+				FileHash: r.FileHash,
+				Tx:       r.Tx,
+				Code:     r.Code,
+				Error:    r.Error,
+			})
+		}
+	case COLLECTION_ACTION_SHARE:
+		blocks := uint64(0)
+		if params.Duration > 0 {
+			// share links expire by block height, round up to one block at least
+			blocks = (params.Duration + config.BlockTime() - 1) / config.BlockTime()
+		}
+		for _, hash := range collection.FileHashes {
+			link, derr := this.CreateShareLink(hash, params.Recipient, blocks, params.DownloadLimit)
+			if derr != nil {
+				resp.Results = append(resp.Results, &CollectionActionResult{FileHash: hash,
+					Code: derr.Code, Error: derr.Error.Error()})
+				continue
+			}
+			resp.Results = append(resp.Results, &CollectionActionResult{FileHash: hash, Link: link.Link})
+		}
+	default:
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: fmt.Errorf("invalid action %s", action)}
+	}
+	for _, r := range resp.Results {
+		if r.Code == 0 {
+			resp.Success++
+		} else {
+			resp.Failed++
+		}
+	}
+	log.Debugf("collection %s action %s, success %d, failed %d", name, action, resp.Success, resp.Failed)
+	return resp, nil
+}
+
+func (this *Endpoint) putCollection(collection *db.Collection) (*db.Collection, *DspErr) {
+	collection.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutCollection(collection); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return collection, nil
+}
+
+// mergeFileHashes. append the new file hashes to list without duplicates, the order is kept
+func mergeFileHashes(list, fileHashes []string) []string {
+	exist := make(map[string]struct{}, len(list)+len(fileHashes))
+	merged := make([]string, 0, len(list)+len(fileHashes))
+	for _, hash := range append(append([]string{}, list...), fileHashes...) {
+		hash = strings.TrimSpace(hash)
+		if len(hash) == 0 {
+			continue
+		}
+		if _, ok := exist[hash]; ok {
+			continue
+		}
+		exist[hash] = struct{}{}
+		merged = append(merged, hash)
+	}
+	return merged
+}
+
+// removeTags. remove the tags case insensitively
+func removeTags(tags, removed []string) []string {
+	list := make([]string, 0, len(tags))
+	for _, t := range tags {
+		found := false
+		for _, r := range removed {
+			if strings.EqualFold(t, strings.TrimSpace(r)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, t)
+		}
+	}
+	return list
+}
@@ -16,6 +16,7 @@ type ConfigResponse struct {
 	BlockConfirm int
 	SeedInterval int
 	PlotPath     string
+	TrashPeriod  int
 }
 
 func (this *Endpoint) GetConfigs() *ConfigResponse {
@@ -28,6 +29,7 @@ func (this *Endpoint) GetConfigs() *ConfigResponse {
 		BlockConfirm: int(config.Parameters.DspConfig.BlockConfirm),
 		SeedInterval: config.Parameters.DspConfig.SeedInterval,
 		PlotPath:     config.PlotPath(),
+		TrashPeriod:  config.Parameters.DspConfig.TrashGracePeriod,
 	}
 	return newResp
 }
@@ -41,6 +43,7 @@ func (this *Endpoint) SetConfigs(fields map[string]interface{}) (*ConfigResponse
 		LogMaxSize:   int(config.Parameters.BaseConfig.LogMaxSize),
 		BlockConfirm: int(config.Parameters.DspConfig.BlockConfirm),
 		SeedInterval: config.Parameters.DspConfig.SeedInterval,
+		TrashPeriod:  config.Parameters.DspConfig.TrashGracePeriod,
 	}
 	for key, value := range fields {
 		switch key {
@@ -85,6 +88,15 @@ func (this *Endpoint) SetConfigs(fields map[string]interface{}) (*ConfigResponse
 			}
 			config.Parameters.DspConfig.SeedInterval = int(newInterval)
 			newResp.SeedInterval = int(newInterval)
+		case "TrashPeriod":
+			newPeriod, ok := value.(float64)
+			if !ok {
+				log.Debugf("unsupport type %s %T", key, value)
+				continue
+			}
+			// the trashed files keep their purge time, the new period applies to the later deleted files
+			config.Parameters.DspConfig.TrashGracePeriod = int(newPeriod)
+			newResp.TrashPeriod = int(newPeriod)
 		case "DownloadPath":
 			newPath, ok := value.(string)
 			if !ok {
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"github.com/saveio/dsp-go-sdk/store"
+	"github.com/saveio/edge/utils/uploadckpt"
+)
+
+// UploadCheckpoint. the state and the blocks acknowledged by each node of an upload task,
+// used to resume the task after the node restarted
+type UploadCheckpoint struct {
+	TaskId          string
+	FileHash        string
+	FileName        string
+	FilePath        string
+	TotalBlockCount uint64
+	Acked           *uploadckpt.Checkpoint // blocks acknowledged by each storage node
+	State           store.TaskState
+	ResumeCount     uint32
+	LastError       string
+	CreatedAt       uint64
+	UpdatedAt       uint64
+}
+
+func (this *EdgeDB) PutUploadCheckpoint(checkpoint *UploadCheckpoint) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(UploadCheckpointKey(checkpoint.TaskId), checkpoint)
+}
+
+// GetUploadCheckpoint. get checkpoint of the task, return nil if not found
+func (this *EdgeDB) GetUploadCheckpoint(taskId string) (*UploadCheckpoint, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	checkpoint := &UploadCheckpoint{}
+	exist, err := this.getData(UploadCheckpointKey(taskId), checkpoint)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (this *EdgeDB) GetAllUploadCheckpoints() ([]*UploadCheckpoint, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(UPLOAD_CHECKPOINT_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := make([]*UploadCheckpoint, 0, len(keys))
+	for _, key := range keys {
+		checkpoint := &UploadCheckpoint{}
+		exist, err := this.getData(key, checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, nil
+}
+
+func (this *EdgeDB) DeleteUploadCheckpoint(taskId string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(UploadCheckpointKey(taskId))
+}
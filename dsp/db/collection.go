@@ -0,0 +1,56 @@
+package db
+
+// Collection. a named set of uploaded files, like a album or folder
+type Collection struct {
+	Name        string
+	Description string
+	FileHashes  []string
+	CreatedAt   uint64
+	UpdatedAt   uint64
+}
+
+func (this *EdgeDB) PutCollection(collection *Collection) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(CollectionKey(collection.Name), collection)
+}
+
+// GetCollection. get collection by name, return nil if not found
+func (this *EdgeDB) GetCollection(name string) (*Collection, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	collection := &Collection{}
+	exist, err := this.getData(CollectionKey(name), collection)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (this *EdgeDB) GetAllCollections() ([]*Collection, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(COLLECTION_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	collections := make([]*Collection, 0, len(keys))
+	for _, key := range keys {
+		collection := &Collection{}
+		exist, err := this.getData(key, collection)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
+func (this *EdgeDB) DeleteCollection(name string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(CollectionKey(name))
+}
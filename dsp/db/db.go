@@ -0,0 +1,69 @@
+package db
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/saveio/dsp-go-sdk/store"
+)
+
+// EdgeDB. local database of edge, used to save the data which is not stored by dsp
+type EdgeDB struct {
+	db   *store.LevelDBStore
+	lock *sync.RWMutex
+}
+
+func NewEdgeDB(path string) (*EdgeDB, error) {
+	db, err := store.NewLevelDBStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return &EdgeDB{
+		db:   db,
+		lock: new(sync.RWMutex),
+	}, nil
+}
+
+func (this *EdgeDB) Close() error {
+	return this.db.Close()
+}
+
+// putData. marshal value to json and save it with the key
+func (this *EdgeDB) putData(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return this.db.Put([]byte(key), data)
+}
+
+// getData. unmarshal the saved value of the key, return false if the key not found
+func (this *EdgeDB) getData(key string, value interface{}) (bool, error) {
+	data, err := this.db.Get([]byte(key))
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if len(data) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (this *EdgeDB) deleteData(key string) error {
+	return this.db.Delete([]byte(key))
+}
+
+func (this *EdgeDB) getKeysByPrefix(prefix string) ([]string, error) {
+	return this.db.QueryStringKeysByPrefix([]byte(prefix))
+}
+
+func isNotFound(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,87 @@
+package db
+
+// UploadedContent. a uploaded file indexed by the hash of its content
+type UploadedContent struct {
+	ContentHash string
+	FileHash    string
+	FileName    string
+	FilePath    string
+	Size        uint64
+	CreatedAt   uint64
+}
+
+// ContentHashCache. the content hash of a local file, it's valid while the size and modify time are not changed
+type ContentHashCache struct {
+	Path        string
+	Size        int64
+	ModTime     int64
+	ContentHash string
+}
+
+// AddUploadedContent. add a uploaded file to the content hash index
+func (this *EdgeDB) AddUploadedContent(content *UploadedContent) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	contents := make([]*UploadedContent, 0)
+	if _, err := this.getData(UploadContentKey(content.ContentHash), &contents); err != nil {
+		return err
+	}
+	for _, c := range contents {
+		if c.FileHash == content.FileHash {
+			return nil
+		}
+	}
+	contents = append(contents, content)
+	return this.putData(UploadContentKey(content.ContentHash), contents)
+}
+
+// GetUploadedContents. get all uploaded files with the content hash
+func (this *EdgeDB) GetUploadedContents(contentHash string) ([]*UploadedContent, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	contents := make([]*UploadedContent, 0)
+	if _, err := this.getData(UploadContentKey(contentHash), &contents); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// RemoveUploadedContent. remove a file hash from the content hash index
+func (this *EdgeDB) RemoveUploadedContent(contentHash, fileHash string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	contents := make([]*UploadedContent, 0)
+	exist, err := this.getData(UploadContentKey(contentHash), &contents)
+	if err != nil || !exist {
+		return err
+	}
+	left := make([]*UploadedContent, 0, len(contents))
+	for _, c := range contents {
+		if c.FileHash == fileHash {
+			continue
+		}
+		left = append(left, c)
+	}
+	if len(left) == 0 {
+		return this.deleteData(UploadContentKey(contentHash))
+	}
+	return this.putData(UploadContentKey(contentHash), left)
+}
+
+func (this *EdgeDB) PutContentHashCache(cache *ContentHashCache) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(ContentHashCacheKey(cache.Path), cache)
+}
+
+// GetContentHashCache. get the cached content hash of file path, return nil if not cached
+func (this *EdgeDB) GetContentHashCache(path string) (*ContentHashCache, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	cache := &ContentHashCache{}
+	exist, err := this.getData(ContentHashCacheKey(path), cache)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return cache, nil
+}
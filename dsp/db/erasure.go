@@ -0,0 +1,105 @@
+package db
+
+// ErasureShard. one data or parity shard of a erasure coded file, uploaded as a separate task
+type ErasureShard struct {
+	Index          int
+	Parity         bool
+	ShardPath      string
+	Size           uint64
+	Checksum       string // sha256 of the shard content
+	TaskId         string
+	FileHash       string
+	Nodes          []string
+	Error          string   // why the upload of shard failed
+	RelocatedTasks []string // upload tasks deleted because their nodes store other shards
+	DownloadTaskId string
+	DownloadPath   string
+}
+
+// ErasureManifest. the shard layout of a erasure coded file
+type ErasureManifest struct {
+	Id            string
+	FileName      string
+	FilePath      string
+	FileSize      uint64
+	ContentHash   string
+	DataShards    int
+	ParityShards  int
+	StripeLen     int
+	Shards        []*ErasureShard
+	UploadState   string
+	UploadError   string
+	ShardsRemoved bool // the local shard files have been removed after uploading
+	DownloadState string
+	DownloadPath  string
+	DownloadError string
+	CreatedAt     uint64
+	UpdatedAt     uint64
+}
+
+// Relocated. the upload task has been deleted because its nodes store other shards
+func (this *ErasureShard) Relocated(taskId string) bool {
+	for _, id := range this.RelocatedTasks {
+		if id == taskId {
+			return true
+		}
+	}
+	return false
+}
+
+// UploadedShards. count of shards which have been stored
+func (this *ErasureManifest) UploadedShards() int {
+	cnt := 0
+	for _, s := range this.Shards {
+		if len(s.FileHash) > 0 {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (this *EdgeDB) PutErasureManifest(manifest *ErasureManifest) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(ErasureManifestKey(manifest.Id), manifest)
+}
+
+// GetErasureManifest. get manifest by id, return nil if not found
+func (this *EdgeDB) GetErasureManifest(id string) (*ErasureManifest, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	manifest := &ErasureManifest{}
+	exist, err := this.getData(ErasureManifestKey(id), manifest)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (this *EdgeDB) GetAllErasureManifests() ([]*ErasureManifest, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(ERASURE_MANIFEST_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]*ErasureManifest, 0, len(keys))
+	for _, key := range keys {
+		manifest := &ErasureManifest{}
+		exist, err := this.getData(key, manifest)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+func (this *EdgeDB) DeleteErasureManifest(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(ErasureManifestKey(id))
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"github.com/saveio/edge/utils/fileindex"
+)
+
+func (this *EdgeDB) PutFileIndexEntry(entry *fileindex.Entry) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(FileIndexKey(entry.Kind, entry.FileHash), entry)
+}
+
+// GetFileIndexEntry. get the index entry of file, return nil if not found
+func (this *EdgeDB) GetFileIndexEntry(kind, fileHash string) (*fileindex.Entry, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	entry := &fileindex.Entry{}
+	exist, err := this.getData(FileIndexKey(kind, fileHash), entry)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (this *EdgeDB) GetAllFileIndexEntries() ([]*fileindex.Entry, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(FILE_INDEX_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*fileindex.Entry, 0, len(keys))
+	for _, key := range keys {
+		entry := &fileindex.Entry{}
+		exist, err := this.getData(key, entry)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (this *EdgeDB) DeleteFileIndexEntry(kind, fileHash string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(FileIndexKey(kind, fileHash))
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"github.com/saveio/dsp-go-sdk/store"
+	dspTypes "github.com/saveio/dsp-go-sdk/task/types"
+	"github.com/saveio/edge/utils/fileindex"
+)
+
+// TransferHistory. a task record of transfer history archive, also saved when it's imported from other node
+type TransferHistory struct {
+	Version    int
+	TaskId     string
+	Task       *store.TaskInfo
+	Progress   *dspTypes.ProgressInfo `json:",omitempty"`
+	File       *fileindex.Entry       `json:",omitempty"`
+	ExportedAt uint64
+	ImportedAt uint64 `json:",omitempty"`
+}
+
+func (this *EdgeDB) PutTransferHistory(history *TransferHistory) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(TransferHistoryKey(history.TaskId), history)
+}
+
+// GetTransferHistory. get imported task record by id, return nil if not found
+func (this *EdgeDB) GetTransferHistory(taskId string) (*TransferHistory, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	history := &TransferHistory{}
+	exist, err := this.getData(TransferHistoryKey(taskId), history)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (this *EdgeDB) GetAllTransferHistories() ([]*TransferHistory, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(TRANSFER_HISTORY_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	histories := make([]*TransferHistory, 0, len(keys))
+	for _, key := range keys {
+		history := &TransferHistory{}
+		exist, err := this.getData(key, history)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		histories = append(histories, history)
+	}
+	return histories, nil
+}
+
+func (this *EdgeDB) DeleteTransferHistory(taskId string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(TransferHistoryKey(taskId))
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"fmt"
+)
+
+const (
+	FILE_VERSION_PREFIX      = "FILE_VERSION: "
+	UPLOAD_CHECKPOINT_PREFIX = "UPLOAD_CHECKPOINT: "
+	ERASURE_MANIFEST_PREFIX  = "ERASURE_MANIFEST: "
+	SHARE_LINK_PREFIX        = "SHARE_LINK: "
+	WHITELIST_GROUP_PREFIX   = "WHITELIST_GROUP: "
+	FILE_WHITELIST_PREFIX    = "FILE_WHITELIST_GROUPS: "
+	SEED_STATE_PREFIX        = "SEED_STATE: "
+	FILE_INDEX_PREFIX        = "FILE_INDEX: "
+	COLLECTION_PREFIX        = "COLLECTION: "
+	TRASH_ITEM_PREFIX        = "TRASH_ITEM: "
+	TRASH_PURGE_PREFIX       = "TRASH_PURGE: "
+	TRANSFER_HISTORY_PREFIX  = "TRANSFER_HISTORY: "
+	SPACE_ACTION_PREFIX      = "SPACE_GUARD_ACTION: "
+	NODE_SAMPLE_PREFIX       = "NODE_MONITOR_SAMPLE: "
+	NODE_ACTION_PREFIX       = "NODE_MONITOR_ACTION: "
+	SHARE_SERVED_PREFIX      = "SHARE_SERVED: "
+)
+
+func FileVersionKey(name string) string {
+	return fmt.Sprintf("%s%s", FILE_VERSION_PREFIX, name)
+}
+
+func UploadContentKey(contentHash string) string {
+	return fmt.Sprintf("UPLOAD_CONTENT_HASH: %s", contentHash)
+}
+
+func ContentHashCacheKey(path string) string {
+	return fmt.Sprintf("UPLOAD_CONTENT_CACHE: %s", path)
+}
+
+func UploadCheckpointKey(taskId string) string {
+	return fmt.Sprintf("%s%s", UPLOAD_CHECKPOINT_PREFIX, taskId)
+}
+
+func ErasureManifestKey(id string) string {
+	return fmt.Sprintf("%s%s", ERASURE_MANIFEST_PREFIX, id)
+}
+
+func ShareLinkKey(id string) string {
+	return fmt.Sprintf("%s%s", SHARE_LINK_PREFIX, id)
+}
+
+func ShareLinkUsageKey(id string) string {
+	return fmt.Sprintf("SHARE_LINK_USAGE: %s", id)
+}
+
+func WhitelistGroupKey(name string) string {
+	return fmt.Sprintf("%s%s", WHITELIST_GROUP_PREFIX, name)
+}
+
+func FileWhitelistGroupsKey(fileHash string) string {
+	return fmt.Sprintf("%s%s", FILE_WHITELIST_PREFIX, fileHash)
+}
+
+func SeedingPolicyKey() string {
+	return "SEEDING_POLICY"
+}
+
+func SeedStateKey(fileHash string) string {
+	return fmt.Sprintf("%s%s", SEED_STATE_PREFIX, fileHash)
+}
+
+func FileIndexKey(kind, fileHash string) string {
+	return fmt.Sprintf("%s%s:%s", FILE_INDEX_PREFIX, kind, fileHash)
+}
+
+func CollectionKey(name string) string {
+	return fmt.Sprintf("%s%s", COLLECTION_PREFIX, name)
+}
+
+func TrashItemKey(fileHash string) string {
+	return fmt.Sprintf("%s%s", TRASH_ITEM_PREFIX, fileHash)
+}
+
+func TrashPurgeKey(id string) string {
+	return fmt.Sprintf("%s%s", TRASH_PURGE_PREFIX, id)
+}
+
+func TransferHistoryKey(taskId string) string {
+	return fmt.Sprintf("%s%s", TRANSFER_HISTORY_PREFIX, taskId)
+}
+
+func SpaceGuardPolicyKey() string {
+	return "SPACE_GUARD_POLICY"
+}
+
+func SpaceGuardStateKey() string {
+	return "SPACE_GUARD_STATE"
+}
+
+func SpaceGuardActionKey(id string) string {
+	return fmt.Sprintf("%s%s", SPACE_ACTION_PREFIX, id)
+}
+
+func NodeMonitorPolicyKey() string {
+	return "NODE_MONITOR_POLICY"
+}
+
+func NodeMonitorStateKey() string {
+	return "NODE_MONITOR_STATE"
+}
+
+func NodeMonitorSampleKey(createdAt uint64) string {
+	return fmt.Sprintf("%s%d", NODE_SAMPLE_PREFIX, createdAt)
+}
+
+func NodeMonitorActionKey(id string) string {
+	return fmt.Sprintf("%s%s", NODE_ACTION_PREFIX, id)
+}
+
+func NodeExitKey() string {
+	return "NODE_EXIT"
+}
+
+func ShareServedKey(hour uint64, fileHash, downloader string) string {
+	return fmt.Sprintf("%s%d:%s:%s", SHARE_SERVED_PREFIX, hour, fileHash, downloader)
+}
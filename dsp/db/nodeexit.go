@@ -0,0 +1,26 @@
+package db
+
+import (
+	"github.com/saveio/edge/utils/nodeexit"
+)
+
+func (this *EdgeDB) PutNodeExit(exit *nodeexit.Exit) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(NodeExitKey(), exit)
+}
+
+// GetNodeExit. get the planned exit of node, return nil if not started
+func (this *EdgeDB) GetNodeExit() (*nodeexit.Exit, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	exit := &nodeexit.Exit{}
+	exist, err := this.getData(NodeExitKey(), exit)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, nil
+	}
+	return exit, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"github.com/saveio/edge/utils/nodemonitor"
+)
+
+func (this *EdgeDB) PutNodeMonitorPolicy(policy *nodemonitor.Policy) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(NodeMonitorPolicyKey(), policy)
+}
+
+// GetNodeMonitorPolicy. get the node monitor policy, return a empty policy if not set
+func (this *EdgeDB) GetNodeMonitorPolicy() (*nodemonitor.Policy, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	policy := &nodemonitor.Policy{}
+	if _, err := this.getData(NodeMonitorPolicyKey(), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (this *EdgeDB) PutNodeMonitorState(state *nodemonitor.State) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(NodeMonitorStateKey(), state)
+}
+
+// GetNodeMonitorState. get the node monitor state, return a empty state if not set
+func (this *EdgeDB) GetNodeMonitorState() (*nodemonitor.State, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	state := &nodemonitor.State{}
+	if _, err := this.getData(NodeMonitorStateKey(), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (this *EdgeDB) PutNodeMonitorSample(sample *nodemonitor.Sample) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(NodeMonitorSampleKey(sample.CreatedAt), sample)
+}
+
+func (this *EdgeDB) GetAllNodeMonitorSamples() ([]*nodemonitor.Sample, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(NODE_SAMPLE_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]*nodemonitor.Sample, 0, len(keys))
+	for _, key := range keys {
+		sample := &nodemonitor.Sample{}
+		exist, err := this.getData(key, sample)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+func (this *EdgeDB) DeleteNodeMonitorSample(createdAt uint64) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(NodeMonitorSampleKey(createdAt))
+}
+
+func (this *EdgeDB) PutNodeMonitorAction(action *nodemonitor.ActionLog) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(NodeMonitorActionKey(action.Id), action)
+}
+
+func (this *EdgeDB) GetAllNodeMonitorActions() ([]*nodemonitor.ActionLog, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(NODE_ACTION_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]*nodemonitor.ActionLog, 0, len(keys))
+	for _, key := range keys {
+		action := &nodemonitor.ActionLog{}
+		exist, err := this.getData(key, action)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (this *EdgeDB) DeleteNodeMonitorAction(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(NodeMonitorActionKey(id))
+}
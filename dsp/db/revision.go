@@ -0,0 +1,47 @@
+package db
+
+func (this *EdgeDB) PutVersionedFile(file *VersionedFile) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(FileVersionKey(file.Name), file)
+}
+
+// GetVersionedFile. get versioned file by name, return nil if not found
+func (this *EdgeDB) GetVersionedFile(name string) (*VersionedFile, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	file := &VersionedFile{}
+	exist, err := this.getData(FileVersionKey(name), file)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (this *EdgeDB) GetAllVersionedFiles() ([]*VersionedFile, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(FILE_VERSION_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*VersionedFile, 0, len(keys))
+	for _, key := range keys {
+		file := &VersionedFile{}
+		exist, err := this.getData(key, file)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (this *EdgeDB) DeleteVersionedFile(name string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(FileVersionKey(name))
+}
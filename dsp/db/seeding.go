@@ -0,0 +1,62 @@
+package db
+
+import (
+	"github.com/saveio/edge/utils/seedpolicy"
+)
+
+func (this *EdgeDB) PutSeedingPolicy(policy *seedpolicy.Policy) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(SeedingPolicyKey(), policy)
+}
+
+// GetSeedingPolicy. get the seeding policy, return a empty policy if not set
+func (this *EdgeDB) GetSeedingPolicy() (*seedpolicy.Policy, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	policy := &seedpolicy.Policy{}
+	if _, err := this.getData(SeedingPolicyKey(), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (this *EdgeDB) PutSeedState(state *seedpolicy.FileState) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(SeedStateKey(state.FileHash), state)
+}
+
+// GetSeedState. get seeding state of file, return nil if not found
+func (this *EdgeDB) GetSeedState(fileHash string) (*seedpolicy.FileState, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	state := &seedpolicy.FileState{}
+	exist, err := this.getData(SeedStateKey(fileHash), state)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (this *EdgeDB) GetAllSeedStates() ([]*seedpolicy.FileState, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(SEED_STATE_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*seedpolicy.FileState, 0, len(keys))
+	for _, key := range keys {
+		state := &seedpolicy.FileState{}
+		exist, err := this.getData(key, state)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
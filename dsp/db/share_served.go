@@ -0,0 +1,57 @@
+package db
+
+// ServedBlocks. blocks of a file sent to a downloader in one hour, counted by the sending node
+type ServedBlocks struct {
+	FileHash   string
+	Downloader string
+	Hour       uint64 // unix seconds of the hour start
+	Blocks     uint64
+	Bytes      uint64
+}
+
+// AddServedBlocks. add the counts to the stored ones of the same hour, file and downloader
+func (this *EdgeDB) AddServedBlocks(list []*ServedBlocks) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for _, served := range list {
+		key := ShareServedKey(served.Hour, served.FileHash, served.Downloader)
+		stored := &ServedBlocks{}
+		exist, err := this.getData(key, stored)
+		if err != nil {
+			return err
+		}
+		if exist {
+			stored.Blocks += served.Blocks
+			stored.Bytes += served.Bytes
+		} else {
+			*stored = *served
+		}
+		if err := this.putData(key, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetServedBlocks. get the served blocks of the hours overlapping the time between start and end
+func (this *EdgeDB) GetServedBlocks(start, end uint64) ([]*ServedBlocks, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(SHARE_SERVED_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*ServedBlocks, 0)
+	for _, key := range keys {
+		served := &ServedBlocks{}
+		exist, err := this.getData(key, served)
+		if err != nil {
+			return nil, err
+		}
+		if !exist || served.Hour+3600 <= start || served.Hour > end {
+			continue
+		}
+		list = append(list, served)
+	}
+	return list, nil
+}
@@ -0,0 +1,102 @@
+package db
+
+// ShareLink. a share link issued by local account
+type ShareLink struct {
+	Id            string
+	FileHash      string
+	FileName      string
+	Recipient     string
+	ExpiredHeight uint64
+	DownloadLimit uint32
+	Link          string
+	Revoked       bool
+	Revocation    string // signed revocation to be accepted by serving nodes
+	CreatedAt     uint64
+	RevokedAt     uint64
+}
+
+// ShareLinkUsage. the usage of a share link on the serving node
+type ShareLinkUsage struct {
+	Id            string
+	FileHash      string
+	DownloadCount uint32
+	Revoked       bool
+	UpdatedAt     uint64
+}
+
+func (this *EdgeDB) PutShareLink(link *ShareLink) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(ShareLinkKey(link.Id), link)
+}
+
+// GetShareLink. get issued share link by id, return nil if not found
+func (this *EdgeDB) GetShareLink(id string) (*ShareLink, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	link := &ShareLink{}
+	exist, err := this.getData(ShareLinkKey(id), link)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (this *EdgeDB) GetAllShareLinks() ([]*ShareLink, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(SHARE_LINK_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	links := make([]*ShareLink, 0, len(keys))
+	for _, key := range keys {
+		link := &ShareLink{}
+		exist, err := this.getData(key, link)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// GetShareLinkUsage. get usage of a share link, return a empty usage if not found
+func (this *EdgeDB) GetShareLinkUsage(id string) (*ShareLinkUsage, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	usage := &ShareLinkUsage{}
+	exist, err := this.getData(ShareLinkUsageKey(id), usage)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return &ShareLinkUsage{Id: id}, nil
+	}
+	return usage, nil
+}
+
+// UpdateShareLinkUsage. update the usage atomically by the update function,
+// the usage is not saved if the function returns error
+func (this *EdgeDB) UpdateShareLinkUsage(id string, update func(*ShareLinkUsage) error) (*ShareLinkUsage, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	usage := &ShareLinkUsage{}
+	exist, err := this.getData(ShareLinkUsageKey(id), usage)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		usage = &ShareLinkUsage{Id: id}
+	}
+	if err := update(usage); err != nil {
+		return nil, err
+	}
+	if err := this.putData(ShareLinkUsageKey(id), usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
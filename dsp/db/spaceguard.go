@@ -0,0 +1,73 @@
+package db
+
+import (
+	"github.com/saveio/edge/utils/spaceguard"
+)
+
+func (this *EdgeDB) PutSpaceGuardPolicy(policy *spaceguard.Policy) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(SpaceGuardPolicyKey(), policy)
+}
+
+// GetSpaceGuardPolicy. get the userspace guardian policy, return a empty policy if not set
+func (this *EdgeDB) GetSpaceGuardPolicy() (*spaceguard.Policy, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	policy := &spaceguard.Policy{}
+	if _, err := this.getData(SpaceGuardPolicyKey(), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (this *EdgeDB) PutSpaceGuardState(state *spaceguard.State) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(SpaceGuardStateKey(), state)
+}
+
+// GetSpaceGuardState. get the userspace guardian state, return a empty state if not set
+func (this *EdgeDB) GetSpaceGuardState() (*spaceguard.State, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	state := &spaceguard.State{}
+	if _, err := this.getData(SpaceGuardStateKey(), state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (this *EdgeDB) PutSpaceGuardAction(action *spaceguard.ActionLog) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(SpaceGuardActionKey(action.Id), action)
+}
+
+func (this *EdgeDB) GetAllSpaceGuardActions() ([]*spaceguard.ActionLog, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(SPACE_ACTION_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	actions := make([]*spaceguard.ActionLog, 0, len(keys))
+	for _, key := range keys {
+		action := &spaceguard.ActionLog{}
+		exist, err := this.getData(key, action)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (this *EdgeDB) DeleteSpaceGuardAction(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(SpaceGuardActionKey(id))
+}
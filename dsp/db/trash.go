@@ -0,0 +1,103 @@
+package db
+
+// TrashItem. a soft deleted upload file waiting to be deleted from chain
+type TrashItem struct {
+	FileHash      string
+	FileName      string
+	Size          uint64
+	GasLimit      uint64
+	ExpiredHeight uint64
+	DeletedAt     uint64
+	PurgeAt       uint64 // the time to delete file from chain, no later than the file expired
+}
+
+// TrashPurge. the record of trashed files deleted from chain in one batch
+type TrashPurge struct {
+	Id         string
+	FileHashes []string
+	Txs        []string
+	GasPrice   uint64
+	Fee        uint64 // the gas limit calculated before deleting
+	PurgedAt   uint64
+	Error      string
+}
+
+func (this *EdgeDB) PutTrashItem(item *TrashItem) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(TrashItemKey(item.FileHash), item)
+}
+
+// GetTrashItem. get trashed file by hash, return nil if not found
+func (this *EdgeDB) GetTrashItem(fileHash string) (*TrashItem, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	item := &TrashItem{}
+	exist, err := this.getData(TrashItemKey(fileHash), item)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (this *EdgeDB) GetAllTrashItems() ([]*TrashItem, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(TRASH_ITEM_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*TrashItem, 0, len(keys))
+	for _, key := range keys {
+		item := &TrashItem{}
+		exist, err := this.getData(key, item)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (this *EdgeDB) DeleteTrashItem(fileHash string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(TrashItemKey(fileHash))
+}
+
+func (this *EdgeDB) PutTrashPurge(purge *TrashPurge) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(TrashPurgeKey(purge.Id), purge)
+}
+
+func (this *EdgeDB) GetAllTrashPurges() ([]*TrashPurge, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(TRASH_PURGE_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	purges := make([]*TrashPurge, 0, len(keys))
+	for _, key := range keys {
+		purge := &TrashPurge{}
+		exist, err := this.getData(key, purge)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		purges = append(purges, purge)
+	}
+	return purges, nil
+}
+
+func (this *EdgeDB) DeleteTrashPurge(id string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(TrashPurgeKey(id))
+}
@@ -0,0 +1,101 @@
+package db
+
+// FileRevision. one uploaded revision of a versioned file
+type FileRevision struct {
+	Version   uint32
+	FileHash  string
+	FileName  string
+	Comment   string
+	CreatedAt uint64
+	UrlTx     string
+	InTrash   bool `json:",omitempty"` // pruned into trash, removed when it's deleted from chain
+}
+
+// VersionedFile. a logical file name which maps to an ordered revision list
+type VersionedFile struct {
+	Name        string
+	Url         string
+	Current     uint32
+	LastVersion uint32 // version of the newest revision ever added
+	Revisions   []*FileRevision
+	UpdatedAt   uint64
+}
+
+// GetRevision. get revision by version number
+func (this *VersionedFile) GetRevision(version uint32) *FileRevision {
+	for _, r := range this.Revisions {
+		if r.Version == version {
+			return r
+		}
+	}
+	return nil
+}
+
+// GetRevisionByHash. get revision by file hash
+func (this *VersionedFile) GetRevisionByHash(fileHash string) *FileRevision {
+	for _, r := range this.Revisions {
+		if r.FileHash == fileHash {
+			return r
+		}
+	}
+	return nil
+}
+
+// LatestRevision. get the newest revision
+func (this *VersionedFile) LatestRevision() *FileRevision {
+	if len(this.Revisions) == 0 {
+		return nil
+	}
+	return this.Revisions[len(this.Revisions)-1]
+}
+
+// NextVersion. version of the next revision, versions are never reused even if the latest one is pruned
+func (this *VersionedFile) NextVersion() uint32 {
+	last := this.LastVersion
+	for _, r := range this.Revisions {
+		if r.Version > last {
+			last = r.Version
+		}
+	}
+	return last + 1
+}
+
+// AddRevision. add the revision with the next version as the current one
+func (this *VersionedFile) AddRevision(revision *FileRevision) {
+	revision.Version = this.NextVersion()
+	this.Revisions = append(this.Revisions, revision)
+	this.Current = revision.Version
+	this.LastVersion = revision.Version
+}
+
+// PruneHashes. file hashes of the revisions older than the newest "keep" ones,
+// the current revision and the revisions already in trash are not included.
+func (this *VersionedFile) PruneHashes(keep uint32) []string {
+	hashes := make([]string, 0)
+	if len(this.Revisions) <= int(keep) {
+		return hashes
+	}
+	for _, r := range this.Revisions[:len(this.Revisions)-int(keep)] {
+		if r.Version == this.Current || r.InTrash {
+			continue
+		}
+		hashes = append(hashes, r.FileHash)
+	}
+	return hashes
+}
+
+// RemoveRevisions. remove the revisions of file hashes
+func (this *VersionedFile) RemoveRevisions(fileHashes []string) {
+	removed := make(map[string]struct{}, len(fileHashes))
+	for _, hash := range fileHashes {
+		removed[hash] = struct{}{}
+	}
+	revisions := make([]*FileRevision, 0, len(this.Revisions))
+	for _, r := range this.Revisions {
+		if _, ok := removed[r.FileHash]; ok {
+			continue
+		}
+		revisions = append(revisions, r)
+	}
+	this.Revisions = revisions
+}
@@ -0,0 +1,33 @@
+package db
+
+import "testing"
+
+func TestVersionedFile(t *testing.T) {
+	file := &VersionedFile{Name: "doc"}
+	if v := file.NextVersion(); v != 1 {
+		t.Fatalf("unexpected next version %d", v)
+	}
+	for _, hash := range []string{"a", "b", "c", "d"} {
+		file.AddRevision(&FileRevision{FileHash: hash})
+	}
+	if file.Current != 4 || file.LatestRevision().Version != 4 || file.GetRevisionByHash("b").Version != 2 {
+		t.Fatalf("unexpected revisions %+v", file.Revisions)
+	}
+
+	// the current revision and the revisions in trash are never pruned
+	file.Current = 1
+	file.GetRevision(2).InTrash = true
+	hashes := file.PruneHashes(1)
+	if len(hashes) != 1 || hashes[0] != "c" {
+		t.Fatalf("unexpected prune hashes %v", hashes)
+	}
+	if hashes := file.PruneHashes(4); len(hashes) != 0 {
+		t.Fatalf("unexpected prune hashes %v", hashes)
+	}
+
+	// versions are not reused after the latest revision is removed
+	file.RemoveRevisions([]string{"c", "d"})
+	if len(file.Revisions) != 2 || file.NextVersion() != 5 {
+		t.Fatalf("unexpected revisions %+v", file.Revisions)
+	}
+}
@@ -0,0 +1,128 @@
+package db
+
+// WhitelistMember. a address of whitelist group, zero height means using the default height of group
+type WhitelistMember struct {
+	Addr          string
+	StartHeight   uint64
+	ExpiredHeight uint64
+}
+
+// WhitelistGroup. a named address set which can be applied to many files
+type WhitelistGroup struct {
+	Name          string
+	Members       []*WhitelistMember
+	StartHeight   uint64 // default start height, zero means the current height when applied
+	ExpiredHeight uint64 // default expired height, zero means the expired height of file
+	CreatedAt     uint64
+	UpdatedAt     uint64
+}
+
+// FileWhitelistGroups. the whitelist groups applied to a file
+type FileWhitelistGroups struct {
+	FileHash  string
+	Groups    []string
+	UpdatedAt uint64
+}
+
+// HasGroup. check if the group is applied to the file
+func (this *FileWhitelistGroups) HasGroup(name string) bool {
+	for _, g := range this.Groups {
+		if g == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *EdgeDB) PutWhitelistGroup(group *WhitelistGroup) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.putData(WhitelistGroupKey(group.Name), group)
+}
+
+// GetWhitelistGroup. get whitelist group by name, return nil if not found
+func (this *EdgeDB) GetWhitelistGroup(name string) (*WhitelistGroup, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	group := &WhitelistGroup{}
+	exist, err := this.getData(WhitelistGroupKey(name), group)
+	if err != nil || !exist {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (this *EdgeDB) GetAllWhitelistGroups() ([]*WhitelistGroup, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(WHITELIST_GROUP_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]*WhitelistGroup, 0, len(keys))
+	for _, key := range keys {
+		group := &WhitelistGroup{}
+		exist, err := this.getData(key, group)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func (this *EdgeDB) DeleteWhitelistGroup(name string) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.deleteData(WhitelistGroupKey(name))
+}
+
+func (this *EdgeDB) PutFileWhitelistGroups(groups *FileWhitelistGroups) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if len(groups.Groups) == 0 {
+		return this.deleteData(FileWhitelistGroupsKey(groups.FileHash))
+	}
+	return this.putData(FileWhitelistGroupsKey(groups.FileHash), groups)
+}
+
+// GetFileWhitelistGroups. get the groups applied to the file, return a empty record if not found
+func (this *EdgeDB) GetFileWhitelistGroups(fileHash string) (*FileWhitelistGroups, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	groups := &FileWhitelistGroups{}
+	exist, err := this.getData(FileWhitelistGroupsKey(fileHash), groups)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return &FileWhitelistGroups{FileHash: fileHash, Groups: make([]string, 0)}, nil
+	}
+	return groups, nil
+}
+
+// GetAllFileWhitelistGroups. get the applied groups of all files, mapping from file hash
+func (this *EdgeDB) GetAllFileWhitelistGroups() (map[string]*FileWhitelistGroups, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	keys, err := this.getKeysByPrefix(FILE_WHITELIST_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]*FileWhitelistGroups, len(keys))
+	for _, key := range keys {
+		groups := &FileWhitelistGroups{}
+		exist, err := this.getData(key, groups)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			continue
+		}
+		m[groups.FileHash] = groups
+	}
+	return m, nil
+}
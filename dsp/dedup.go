@@ -0,0 +1,262 @@
+package dsp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/saveio/dsp-go-sdk/store"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/themis/common/log"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+const (
+	DUPLICATE_ACTION_RELINK = "relink"
+	DUPLICATE_ACTION_EXTEND = "extend"
+)
+
+const (
+	DUPLICATE_SOURCE_INDEX       = "index"
+	DUPLICATE_SOURCE_UPLOAD_LIST = "uploadlist"
+)
+
+// DuplicateUpload. a stored file which has the same content with the file to upload
+type DuplicateUpload struct {
+	FileHash      string
+	FileName      string
+	Url           string
+	StoreType     uint64
+	ExpiredHeight uint64
+	ExpiredAt     uint64
+	Source        string
+	Actions       []string
+}
+
+type ReuseDuplicateResp struct {
+	Action    string
+	Tx        string
+	Duplicate *DuplicateUpload
+}
+
+// FindDuplicateUpload. find a file which has been uploaded by current account with the same content.
+// The root hash on chain is computed with the upload prefix which is different for each task,
+// so it can't be computed before uploading. The content hash of file is used to match the local index
+// and the upload list instead, it's cached by path and only computed again when the file is changed.
+func (this *Endpoint) FindDuplicateUpload(path string) (*DuplicateUpload, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_FILEPATH_ERROR, Error: err}
+	}
+	if stat.IsDir() {
+		return nil, nil
+	}
+	contentHash, err := this.cachedContentHash(path)
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_FILEPATH_ERROR, Error: err}
+	}
+	contents, err := this.db.GetUploadedContents(contentHash)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	for i := len(contents) - 1; i >= 0; i-- {
+		dup := this.getStoredDuplicate(contents[i].FileHash, contents[i].FileName)
+		if dup == nil {
+			continue
+		}
+		dup.Source = DUPLICATE_SOURCE_INDEX
+		return dup, nil
+	}
+	// the file may be uploaded before the index is built, match it with the upload list
+	taskInfos, err := dsp.GetUploadTaskInfos()
+	if err != nil {
+		return nil, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND, Error: err}
+	}
+	for _, info := range taskInfos {
+		if info == nil || info.TaskState != store.TaskStateDone || len(info.FileHash) == 0 {
+			continue
+		}
+		taskStat, err := os.Stat(info.FilePath)
+		if err != nil || taskStat.IsDir() || taskStat.Size() != stat.Size() {
+			continue
+		}
+		taskContentHash, err := this.cachedContentHash(info.FilePath)
+		if err != nil || taskContentHash != contentHash {
+			continue
+		}
+		this.addUploadedContent(taskContentHash, info)
+		dup := this.getStoredDuplicate(info.FileHash, info.FileName)
+		if dup == nil {
+			continue
+		}
+		dup.Source = DUPLICATE_SOURCE_UPLOAD_LIST
+		return dup, nil
+	}
+	return nil, nil
+}
+
+// ReuseDuplicateUpload. reuse a stored file instead of uploading the same content again.
+// "relink" binds the url to the stored file. "extend" keeps the file stored for the duration (in second) from now,
+// the file stored in user space expires with the user space, so only the time the file lacks is added to the user space.
+func (this *Endpoint) ReuseDuplicateUpload(path, action, url string, duration uint64) (
+	*ReuseDuplicateResp, *DspErr) {
+	dup, derr := this.FindDuplicateUpload(path)
+	if derr != nil {
+		return nil, derr
+	}
+	if dup == nil {
+		return nil, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND, Error: ErrMaps[DSP_FILE_INFO_NOT_FOUND]}
+	}
+	resp := &ReuseDuplicateResp{
+		Action:    action,
+		Duplicate: dup,
+	}
+	switch action {
+	case DUPLICATE_ACTION_RELINK:
+		if len(url) == 0 {
+			return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+		}
+		tx, derr := this.UpdateFileUrlLink(url, dup.FileHash, dup.FileName, 0, 0)
+		if derr != nil {
+			return nil, derr
+		}
+		resp.Tx = tx
+		dup.Url = url
+	case DUPLICATE_ACTION_EXTEND:
+		if fs.FileStoreType(dup.StoreType) != fs.FileStoreTypeNormal {
+			return nil, &DspErr{Code: INVALID_PARAMS,
+				Error: fmt.Errorf("file %s is not stored in user space, can't be extended", dup.FileHash)}
+		}
+		if duration == 0 {
+			return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+		}
+		keepUntil := uint64(time.Now().Unix()) + duration
+		if dup.ExpiredAt >= keepUntil {
+			log.Debugf("duplicate file %s expires at %d, no need to extend", dup.FileHash, dup.ExpiredAt)
+			break
+		}
+		tx, derr := this.SetUserSpace("", 0, uint64(fs.UserSpaceNone), keepUntil-dup.ExpiredAt,
+			uint64(fs.UserSpaceAdd))
+		if derr != nil {
+			return nil, derr
+		}
+		resp.Tx = tx
+	default:
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: fmt.Errorf("invalid action %s", action)}
+	}
+	log.Debugf("reuse duplicate file %s of %s with action %s, tx %s", dup.FileHash, path, action, resp.Tx)
+	return resp, nil
+}
+
+// indexUploadedFile. add the file of a finished upload task to the content hash index
+func (this *Endpoint) indexUploadedFile(taskId string) {
+	dsp := this.getDsp()
+	if dsp == nil || this.db == nil {
+		return
+	}
+	info := dsp.GetTaskInfo(taskId)
+	if info == nil || len(info.FileHash) == 0 || len(info.FilePath) == 0 {
+		return
+	}
+	contentHash, err := this.cachedContentHash(info.FilePath)
+	if err != nil {
+		log.Errorf("compute content hash of %s err %s", info.FilePath, err)
+		return
+	}
+	this.addUploadedContent(contentHash, info)
+}
+
+func (this *Endpoint) addUploadedContent(contentHash string, info *store.TaskInfo) {
+	err := this.db.AddUploadedContent(&db.UploadedContent{
+		ContentHash: contentHash,
+		FileHash:    info.FileHash,
+		FileName:    info.FileName,
+		FilePath:    info.FilePath,
+		Size:        info.RealFileSize,
+		CreatedAt:   uint64(time.Now().Unix()),
+	})
+	if err != nil {
+		log.Errorf("add uploaded content %s of %s err %s", contentHash, info.FileHash, err)
+	}
+}
+
+// getStoredDuplicate. return the duplicate info if the file is still stored by current account
+func (this *Endpoint) getStoredDuplicate(fileHash, fileName string) *DuplicateUpload {
+	dsp := this.getDsp()
+	info, err := dsp.GetFileInfo(fileHash)
+	if info == nil || err != nil {
+		return nil
+	}
+	if info.FileOwner.ToBase58() != dsp.WalletAddress() {
+		return nil
+	}
+	now, err := dsp.GetCurrentBlockHeight()
+	if err != nil || info.ExpiredHeight <= uint64(now) {
+		return nil
+	}
+	dup := &DuplicateUpload{
+		FileHash:      fileHash,
+		FileName:      fileName,
+		StoreType:     info.StorageType,
+		ExpiredHeight: info.ExpiredHeight,
+		ExpiredAt:     blockHeightToTimestamp(uint64(now), info.ExpiredHeight),
+		Actions:       []string{DUPLICATE_ACTION_RELINK},
+	}
+	if len(dsp.GetLinkFromUrl(string(info.Url))) > 0 {
+		dup.Url = string(info.Url)
+	}
+	if fs.FileStoreType(info.StorageType) == fs.FileStoreTypeNormal {
+		dup.Actions = append(dup.Actions, DUPLICATE_ACTION_EXTEND)
+	}
+	return dup
+}
+
+// cachedContentHash. content hash of file, cached until the file is changed
+func (this *Endpoint) cachedContentHash(path string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if cache, err := this.db.GetContentHashCache(path); err == nil && cache != nil &&
+		cache.Size == stat.Size() && cache.ModTime == stat.ModTime().UnixNano() {
+		return cache.ContentHash, nil
+	}
+	contentHash, err := contentHashOfFile(path)
+	if err != nil {
+		return "", err
+	}
+	err = this.db.PutContentHashCache(&db.ContentHashCache{
+		Path:        path,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime().UnixNano(),
+		ContentHash: contentHash,
+	})
+	if err != nil {
+		log.Errorf("cache content hash of %s err %s", path, err)
+	}
+	return contentHash, nil
+}
+
+// contentHashOfFile. sha256 of the file content
+func contentHashOfFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
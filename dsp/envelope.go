@@ -0,0 +1,406 @@
+package dsp
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+	dspPrefix "github.com/saveio/dsp-go-sdk/types/prefix"
+	dspTask "github.com/saveio/dsp-go-sdk/utils/task"
+	"github.com/saveio/edge/utils/envelope"
+	"github.com/saveio/edge/utils/gcmstream"
+	"github.com/saveio/themis/common/log"
+	"github.com/saveio/themis/crypto/ec"
+	"github.com/saveio/themis/crypto/keypair"
+)
+
+const ENVELOPE_ENCRYPT_SUFFIX = ".eptm"
+
+type FileRecipient struct {
+	Address string
+	KeyId   string
+	AddedAt uint64
+}
+
+type FileRecipientsResp struct {
+	Path       string
+	Recipients []*FileRecipient
+}
+
+// EncryptFileForRecipients. encrypt the file once with a random content key to path.eptm,
+// and wrap the content key for each recipient address. The local account is always a recipient,
+// so recipients can be added later by the owner.
+// File layout: dsp encrypt prefix | envelope header | gcm stream
+func (this *Endpoint) EncryptFileForRecipients(path string, addresses []string) (*FileRecipientsResp, *DspErr) {
+	if len(addresses) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	if stat.IsDir() {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: fmt.Errorf("%s is a directory", path)}
+	}
+	owner, derr := this.localEnvelopeKey("")
+	if derr != nil {
+		return nil, derr
+	}
+	key, err := envelope.NewContentKey()
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	header := &envelope.Header{Version: envelope.VERSION, Capacity: envelope.DEFAULT_CAPACITY}
+	ownerEnvelope, err := newRecipient(this.getDspWalletAddress(), &owner.PublicKey, key)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	header.Recipients = append(header.Recipients, ownerEnvelope)
+	if derr := this.addRecipients(header, addresses, key); derr != nil {
+		return nil, derr
+	}
+	if err := growHeader(header); err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	headerBuf, err := header.Serialize()
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	prefix := dspPrefix.NewEncryptAPrefix(this.getDspWalletAddr(), uint64(stat.Size()),
+		false, dspPrefix.ENCRYPTTYPE_ECIES)
+	if prefix == nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: fmt.Errorf("prefix is nil")}
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	defer src.Close()
+	output := path + ENVELOPE_ENCRYPT_SUFFIX
+	out, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	err = writeStream(out, src, append(prefix.Serialize(), headerBuf...), hex.EncodeToString(key))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	log.Debugf("encrypted %s to %s for %d recipients", path, output, len(header.Recipients))
+	return newFileRecipientsResp(output, header), nil
+}
+
+// AddFileRecipients. wrap the content key for new recipients, only the header is rewritten.
+// The content is moved without re-encryption if the header capacity is not enough.
+func (this *Endpoint) AddFileRecipients(path string, addresses []string) (*FileRecipientsResp, *DspErr) {
+	if len(addresses) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	priv, derr := this.localEnvelopeKey("")
+	if derr != nil {
+		return nil, derr
+	}
+	_, prefixLen, header, f, derr := openEnvelopeFile(path)
+	if derr != nil {
+		return nil, derr
+	}
+	f.Close()
+	key, derr := unwrapContentKey(header, priv)
+	if derr != nil {
+		return nil, derr
+	}
+	oldSize := header.Size()
+	if derr := this.addRecipients(header, addresses, key); derr != nil {
+		return nil, derr
+	}
+	if err := rewriteEnvelopeHeader(path, prefixLen, header, oldSize); err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	log.Debugf("add %d recipients to %s", len(addresses), path)
+	return newFileRecipientsResp(path, header), nil
+}
+
+// GetFileRecipients. list the recipients of a multi-recipient encrypted file
+func (this *Endpoint) GetFileRecipients(path string) (*FileRecipientsResp, *DspErr) {
+	_, _, header, f, derr := openEnvelopeFile(path)
+	if derr != nil {
+		return nil, derr
+	}
+	f.Close()
+	return newFileRecipientsResp(path, header), nil
+}
+
+// IsEnvelopeEncrypted. check whether the file is encrypted for multi recipients
+func (this *Endpoint) IsEnvelopeEncrypted(path string) bool {
+	_, _, _, f, derr := openEnvelopeFile(path)
+	if derr != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+// decryptEnvelopeFile. decrypt the file with the envelope of the private key,
+// the local account key is used if the private key is empty.
+func (this *Endpoint) decryptEnvelopeFile(path, fileName, privKey string) (string, *DspErr) {
+	priv, derr := this.localEnvelopeKey(privKey)
+	if derr != nil {
+		return "", derr
+	}
+	filePrefix, _, header, f, derr := openEnvelopeFile(path)
+	if derr != nil {
+		return "", derr
+	}
+	defer f.Close()
+	key, derr := unwrapContentKey(header, priv)
+	if derr != nil {
+		return "", derr
+	}
+	r, err := gcmstream.NewReader(f, []byte(hex.EncodeToString(key)))
+	if err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	if len(fileName) == 0 {
+		fileName = filePrefix.FileName
+	}
+	if len(fileName) == 0 {
+		fileName = strings.TrimSuffix(filepath.Base(path), ENVELOPE_ENCRYPT_SUFFIX)
+	}
+	outPath := dspTask.GetDecryptedFilePath(path, fileName)
+	if err := writeDecrypted(outPath, r); err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	log.Debugf("decrypted multi-recipient file %s to %s", path, outPath)
+	return outPath, nil
+}
+
+// addRecipients. wrap the key for the addresses, the existing recipients are skipped
+func (this *Endpoint) addRecipients(header *envelope.Header, addresses []string, key []byte) *DspErr {
+	for _, address := range addresses {
+		pub, derr := this.recipientPublicKey(address)
+		if derr != nil {
+			return derr
+		}
+		if header.GetRecipient(envelope.KeyId(pub)) != nil {
+			continue
+		}
+		r, err := newRecipient(address, pub, key)
+		if err != nil {
+			return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+		}
+		header.Recipients = append(header.Recipients, r)
+	}
+	return nil
+}
+
+// recipientPublicKey. get the public key of address from dns
+func (this *Endpoint) recipientPublicKey(address string) (*ecdsa.PublicKey, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	pubKey, err := dsp.DNS.GetNodePubKey(address)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	pub, err := parseEnvelopePublicKey(pubKey)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: fmt.Errorf("public key of %s: %s", address, err)}
+	}
+	return pub, nil
+}
+
+// localEnvelopeKey. parse the hex private key, use the key of local account if it's empty.
+// The password of local account should be checked by the caller before its key is used.
+func (this *Endpoint) localEnvelopeKey(privKey string) (*ecdsa.PrivateKey, *DspErr) {
+	if len(privKey) == 0 {
+		acc := this.GetDspAccount()
+		if acc == nil {
+			return nil, &DspErr{Code: ACCOUNT_NOT_LOGIN, Error: ErrMaps[ACCOUNT_NOT_LOGIN]}
+		}
+		privKey = fmt.Sprintf("%x", acc.GetEthPrivateKey())
+	}
+	priv, err := ethCrypto.HexToECDSA(strings.TrimPrefix(privKey, "0x"))
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	return priv, nil
+}
+
+func newRecipient(address string, pub *ecdsa.PublicKey, key []byte) (*envelope.Recipient, error) {
+	wrapped, err := envelope.WrapKey(pub, key)
+	if err != nil {
+		return nil, err
+	}
+	return &envelope.Recipient{
+		Address:    address,
+		KeyId:      envelope.KeyId(pub),
+		WrappedKey: wrapped,
+		AddedAt:    uint64(time.Now().Unix()),
+	}, nil
+}
+
+// unwrapContentKey. find the envelope of the key, all envelopes are tried if no key id matched
+func unwrapContentKey(header *envelope.Header, priv *ecdsa.PrivateKey) ([]byte, *DspErr) {
+	if r := header.GetRecipient(envelope.KeyId(&priv.PublicKey)); r != nil {
+		key, err := envelope.UnwrapKey(priv, r.WrappedKey)
+		if err != nil {
+			return nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+		}
+		return key, nil
+	}
+	for _, r := range header.Recipients {
+		if key, err := envelope.UnwrapKey(priv, r.WrappedKey); err == nil {
+			return key, nil
+		}
+	}
+	return nil, &DspErr{Code: DSP_FILE_NOT_RECIPIENT, Error: ErrMaps[DSP_FILE_NOT_RECIPIENT]}
+}
+
+// parseEnvelopePublicKey. the public key may be a raw secp256k1 key or a serialized account key
+func parseEnvelopePublicKey(v interface{}) (*ecdsa.PublicKey, error) {
+	var buf []byte
+	switch key := v.(type) {
+	case *ecdsa.PublicKey:
+		return key, nil
+	case *ec.PublicKey:
+		return key.PublicKey, nil
+	case []byte:
+		buf = key
+	case string:
+		b, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		buf = b
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", v)
+	}
+	if pub, err := ethCrypto.UnmarshalPubkey(buf); err == nil {
+		return pub, nil
+	}
+	if pub, err := ethCrypto.DecompressPubkey(buf); err == nil {
+		return pub, nil
+	}
+	pk, err := keypair.DeserializePublicKey(buf)
+	if err != nil {
+		return nil, err
+	}
+	ecPub, ok := pk.(*ec.PublicKey)
+	if !ok {
+		return nil, envelope.ErrUnsupportedKey
+	}
+	return ecPub.PublicKey, nil
+}
+
+// openEnvelopeFile. parse the prefix and envelope header, the returned file is positioned at the gcm stream
+func openEnvelopeFile(path string) (*dspPrefix.FilePrefix, int64, *envelope.Header, *os.File, *DspErr) {
+	filePrefix, prefix, err := dspPrefix.GetPrefixFromFile(path)
+	if err != nil || filePrefix == nil {
+		return nil, 0, nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: fmt.Errorf("get prefix of %s failed", path)}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	if _, err := f.Seek(int64(len(prefix)), io.SeekStart); err != nil {
+		f.Close()
+		return nil, 0, nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	header, err := envelope.ReadHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, 0, nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: fmt.Errorf("%s: %s", path, err)}
+	}
+	return filePrefix, int64(len(prefix)), header, f, nil
+}
+
+// growHeader. double the capacity until the recipients fit
+func growHeader(header *envelope.Header) error {
+	required, err := header.RequiredCapacity()
+	if err != nil {
+		return err
+	}
+	for header.Capacity < required {
+		if header.Capacity >= envelope.MAX_CAPACITY {
+			return envelope.ErrHeaderFull
+		}
+		header.Capacity *= 2
+	}
+	return nil
+}
+
+// rewriteEnvelopeHeader. write the header in place if it fits the old capacity,
+// otherwise copy the prefix, new header and encrypted content to a new file and replace the old one.
+func rewriteEnvelopeHeader(path string, prefixLen int64, header *envelope.Header, oldSize int64) error {
+	if err := growHeader(header); err != nil {
+		return err
+	}
+	buf, err := header.Serialize()
+	if err != nil {
+		return err
+	}
+	if header.Size() == oldSize {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0666)
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteAt(buf, prefixLen)
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := path + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, io.NewSectionReader(src, 0, prefixLen)); err == nil {
+		if _, err = out.Write(buf); err == nil {
+			if _, err = src.Seek(prefixLen+oldSize, io.SeekStart); err == nil {
+				_, err = io.Copy(out, src)
+			}
+		}
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func newFileRecipientsResp(path string, header *envelope.Header) *FileRecipientsResp {
+	resp := &FileRecipientsResp{Path: path, Recipients: make([]*FileRecipient, 0, len(header.Recipients))}
+	for _, r := range header.Recipients {
+		resp.Recipients = append(resp.Recipients, &FileRecipient{
+			Address: r.Address,
+			KeyId:   r.KeyId,
+			AddedAt: r.AddedAt,
+		})
+	}
+	return resp
+}
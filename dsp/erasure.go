@@ -0,0 +1,675 @@
+package dsp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/saveio/dsp-go-sdk/store"
+	dspOS "github.com/saveio/dsp-go-sdk/utils/os"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/erasure"
+	"github.com/saveio/themis/cmd/utils"
+	chainCfg "github.com/saveio/themis/common/config"
+	"github.com/saveio/themis/common/log"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+const (
+	ERASURE_UPLOAD_DOING  = "doing"
+	ERASURE_UPLOAD_DONE   = "done"
+	ERASURE_UPLOAD_FAILED = "failed"
+)
+
+const (
+	ERASURE_DOWNLOAD_DOING  = "doing"
+	ERASURE_DOWNLOAD_DONE   = "done"
+	ERASURE_DOWNLOAD_FAILED = "failed"
+)
+
+const (
+	ERASURE_DOWNLOAD_CHECK_INTERVAL = 3  // seconds
+	ERASURE_UPLOAD_CHECK_INTERVAL   = 30 // seconds
+	ERASURE_SHARD_MAX_RELOCATIONS   = 3  // times a shard is uploaded again if its node stores another shard
+)
+
+// ErasureFee. fee of uploading a file with erasure coding, compared with full replication
+type ErasureFee struct {
+	DataShards          int
+	ParityShards        int
+	ShardSize           uint64 // KiB
+	ShardCopyNum        uint64 // copy number of each shard, DefaultCopyNum for user space files
+	ShardFee            *CalculateResp
+	Fee                 *CalculateResp
+	StorageOverhead     float64 // stored bytes / file bytes of erasure coding
+	ReplicationOverhead float64 // stored bytes / file bytes of replication
+	Saving              int64   // total replication fee - total erasure fee
+	SavingFormat        string
+}
+
+func (this *CalculateResp) total() uint64 {
+	return this.TxFee + this.StorageFee + this.ValidFee
+}
+
+// CalculateErasureUploadFee. calculate the fee of uploading the file as k data shards and m parity shards.
+// Each shard is uploaded as a single copy file, or with DefaultCopyNum copies for user space files
+// the same as UploadFile does, so the fee is the sum of k+m shard fees.
+func (this *Endpoint) CalculateErasureUploadFee(filePath string, replication *CalculateResp, durationVal,
+	proveLevelVal, copynumVal, whitelistVal, storeType interface{}, dataShards, parityShards int) (
+	*ErasureFee, *DspErr) {
+	if _, err := erasure.NewCodec(dataShards, parityShards); err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_GET_FILESIZE_FAILED, Error: err}
+	}
+	shardSize := uint64(erasure.ShardFileSize(stat.Size(), dataShards, erasure.DEFAULT_STRIPE_LEN) / 1024)
+	if shardSize == 0 {
+		shardSize = 1
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	shardCopyNum := uint64(0)
+	if sType, _ := ToUint64(storeType); fs.FileStoreType(sType) == fs.FileStoreTypeNormal {
+		fsSetting, err := dsp.GetFsSetting()
+		if err != nil {
+			return nil, &DspErr{Code: FS_GET_SETTING_FAILED, Error: err}
+		}
+		proveLevelVal = 1
+		shardCopyNum = fsSetting.DefaultCopyNum
+	}
+	shardFee, derr := this.calculateUploadFeeOfSize(shardSize, durationVal, proveLevelVal, shardCopyNum, whitelistVal,
+		storeType)
+	if derr != nil {
+		return nil, derr
+	}
+	total := uint64(dataShards + parityShards)
+	fee := &CalculateResp{
+		TxFee:      shardFee.TxFee * total,
+		StorageFee: shardFee.StorageFee * total,
+		ValidFee:   shardFee.ValidFee * total,
+	}
+	fee.TxFeeFormat = utils.FormatUsdt(fee.TxFee)
+	fee.StorageFeeFormat = utils.FormatUsdt(fee.StorageFee)
+	fee.ValidFeeFormat = utils.FormatUsdt(fee.ValidFee)
+	copyNum, _ := ToUint64(copynumVal)
+	resp := &ErasureFee{
+		DataShards:          dataShards,
+		ParityShards:        parityShards,
+		ShardSize:           shardSize,
+		ShardCopyNum:        shardCopyNum,
+		ShardFee:            shardFee,
+		Fee:                 fee,
+		StorageOverhead:     float64(total*(shardCopyNum+1)) / float64(dataShards),
+		ReplicationOverhead: float64(copyNum + 1),
+	}
+	if replication != nil {
+		resp.Saving = int64(replication.total()) - int64(fee.total())
+		if resp.Saving >= 0 {
+			resp.SavingFormat = utils.FormatUsdt(uint64(resp.Saving))
+		} else {
+			resp.SavingFormat = "-" + utils.FormatUsdt(uint64(-resp.Saving))
+		}
+	}
+	return resp, nil
+}
+
+// UploadFileErasure. split the file into data and parity shards with reed-solomon coding,
+// and upload each shard as a separate single copy task. The file can be rebuilt from any k shards.
+// Storage nodes are selected by the sdk for each task, so the upload is refused if there are not enough nodes
+// for every shard. If a shard is stored on the node of another shard, erasureUploadService deletes it
+// and uploads it again, up to ERASURE_SHARD_MAX_RELOCATIONS times.
+func (this *Endpoint) UploadFileErasure(path, desc string, dataShards, parityShards int, durationVal,
+	proveLevelVal, privilegeVal, storageTypeVal interface{}, encryptPwd string, whitelist []string) (
+	*db.ErasureManifest, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	codec, err := erasure.NewCodec(dataShards, parityShards)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_FILEPATH_ERROR, Error: err}
+	}
+	if stat.IsDir() {
+		return nil, &DspErr{Code: FS_UPLOAD_FILEPATH_ERROR, Error: fmt.Errorf("%s is a directory", path)}
+	}
+	if len(desc) == 0 {
+		desc = filepath.Base(path)
+	}
+	nodeList, err := dsp.GetNodeList()
+	if err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	if nodeList.NodeNum < uint64(codec.TotalShards()) {
+		return nil, &DspErr{Code: DSP_ERASURE_UPLOAD_FAILED,
+			Error: fmt.Errorf("only %d storage nodes, need %d for distinct shards", nodeList.NodeNum, codec.TotalShards())}
+	}
+	contentHash, err := contentHashOfFile(path)
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_FILEPATH_ERROR, Error: err}
+	}
+	manifest := &db.ErasureManifest{
+		Id:           uuid.NewUUID().String(),
+		FileName:     desc,
+		FilePath:     path,
+		ContentHash:  contentHash,
+		DataShards:   dataShards,
+		ParityShards: parityShards,
+		StripeLen:    erasure.DEFAULT_STRIPE_LEN,
+		Shards:       make([]*db.ErasureShard, 0, codec.TotalShards()),
+		UploadState:  ERASURE_UPLOAD_DOING,
+		CreatedAt:    uint64(time.Now().Unix()),
+	}
+	shardDir := erasureShardDir(manifest.Id)
+	if err := dspOS.CreateDirIfNeed(shardDir); err != nil {
+		return nil, &DspErr{Code: DSP_ERASURE_ENCODE_FAILED, Error: err}
+	}
+	shardPaths := make([]string, codec.TotalShards())
+	for i := range shardPaths {
+		shardPaths[i] = filepath.Join(shardDir, fmt.Sprintf("%s.shard%d", filepath.Base(path), i))
+	}
+	fileSize, err := codec.EncodeFile(path, shardPaths, manifest.StripeLen)
+	if err != nil {
+		os.RemoveAll(shardDir)
+		return nil, &DspErr{Code: DSP_ERASURE_ENCODE_FAILED, Error: err}
+	}
+	manifest.FileSize = uint64(fileSize)
+	for i, shardPath := range shardPaths {
+		checksum, err := contentHashOfFile(shardPath)
+		if err != nil {
+			os.RemoveAll(shardDir)
+			return nil, &DspErr{Code: DSP_ERASURE_ENCODE_FAILED, Error: err}
+		}
+		manifest.Shards = append(manifest.Shards, &db.ErasureShard{
+			Index:     i,
+			Parity:    i >= dataShards,
+			ShardPath: shardPath,
+			Size:      uint64(erasure.ShardFileSize(fileSize, dataShards, manifest.StripeLen)),
+			Checksum:  checksum,
+		})
+	}
+	if err := this.db.PutErasureManifest(manifest); err != nil {
+		os.RemoveAll(shardDir)
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	params := &erasureUploadParams{
+		desc:        desc,
+		duration:    durationVal,
+		proveLevel:  proveLevelVal,
+		privilege:   privilegeVal,
+		storageType: storageTypeVal,
+		encryptPwd:  encryptPwd,
+		whitelist:   whitelist,
+	}
+	this.erasureUploads.Store(manifest.Id, params)
+	started := 0
+	for i, shard := range manifest.Shards {
+		if derr := this.uploadErasureShard(shard, params); derr != nil {
+			log.Errorf("upload shard %d of %s failed, err %s", i, path, derr.Error)
+			shard.Error = derr.Error.Error()
+			continue
+		}
+		started++
+	}
+	if started == 0 {
+		// nothing uploaded, drop the manifest
+		this.erasureUploads.Delete(manifest.Id)
+		this.db.DeleteErasureManifest(manifest.Id)
+		os.RemoveAll(shardDir)
+		return nil, &DspErr{Code: DSP_ERASURE_UPLOAD_FAILED, Error: errors.New(shardErrors(manifest))}
+	}
+	if started < len(manifest.Shards) {
+		// the started shards keep uploading, their files are removed by erasureUploadService when they finish
+		manifest.UploadState = ERASURE_UPLOAD_FAILED
+		manifest.UploadError = shardErrors(manifest)
+		manifest.UpdatedAt = uint64(time.Now().Unix())
+		if err := this.db.PutErasureManifest(manifest); err != nil {
+			log.Errorf("save erasure manifest %s err %s", manifest.Id, err)
+		}
+		return nil, &DspErr{Code: DSP_ERASURE_UPLOAD_FAILED,
+			Error: fmt.Errorf("erasure file %s: %s", manifest.Id, manifest.UploadError)}
+	}
+	log.Debugf("upload erasure file %s with %d+%d shards, manifest %s", path, dataShards, parityShards, manifest.Id)
+	return manifest, nil
+}
+
+// erasureUploadParams. params of the shard uploads, kept in memory to upload a shard again
+type erasureUploadParams struct {
+	desc        string
+	duration    interface{}
+	proveLevel  interface{}
+	privilege   interface{}
+	storageType interface{}
+	encryptPwd  string
+	whitelist   []string
+}
+
+// uploadErasureShard. upload the shard as a single copy task
+func (this *Endpoint) uploadErasureShard(shard *db.ErasureShard, params *erasureUploadParams) *DspErr {
+	shardDesc := fmt.Sprintf("%s.shard%d", params.desc, shard.Index)
+	_, derr := this.UploadFile("", shard.ShardPath, shardDesc, params.duration, params.proveLevel, params.privilege,
+		0, params.storageType, nil, params.encryptPwd, "", "", params.whitelist, false)
+	return derr
+}
+
+// relocateErasureShards. delete the shards stored on the nodes of other shards and upload them again,
+// the sdk can't be told which nodes to use, so the new nodes are checked by the next refresh
+func (this *Endpoint) relocateErasureShards(manifest *db.ErasureManifest, shards []*db.ErasureShard) error {
+	value, ok := this.erasureUploads.Load(manifest.Id)
+	if !ok {
+		return fmt.Errorf("shards are stored on the same nodes %v, the upload params are lost after restart",
+			sharedShardNodes(manifest))
+	}
+	params := value.(*erasureUploadParams)
+	for _, shard := range shards {
+		if len(shard.RelocatedTasks) >= ERASURE_SHARD_MAX_RELOCATIONS {
+			return fmt.Errorf("shard %d is stored on the nodes %v of other shards after %d uploads",
+				shard.Index, shard.Nodes, len(shard.RelocatedTasks)+1)
+		}
+		if _, derr := this.deleteUploadFile(shard.FileHash, chainCfg.DEFAULT_GAS_LIMIT); derr != nil {
+			return fmt.Errorf("delete shard %d from nodes %v err %s", shard.Index, shard.Nodes, derr.Error)
+		}
+		log.Infof("shard %d of erasure file %s is stored on the nodes %v of other shards, upload it again",
+			shard.Index, manifest.Id, shard.Nodes)
+		shard.RelocatedTasks = append(shard.RelocatedTasks, shard.TaskId)
+		shard.TaskId, shard.FileHash, shard.Nodes = "", "", nil
+		if derr := this.uploadErasureShard(shard, params); derr != nil {
+			shard.Error = derr.Error.Error()
+			return fmt.Errorf("upload shard %d again err %s", shard.Index, derr.Error)
+		}
+	}
+	return nil
+}
+
+// GetErasureManifests. get all erasure coded files
+func (this *Endpoint) GetErasureManifests() ([]*db.ErasureManifest, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	manifests, err := this.db.GetAllErasureManifests()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	for _, manifest := range manifests {
+		this.refreshErasureManifest(manifest)
+	}
+	return manifests, nil
+}
+
+// GetErasureManifest. get the manifest with the upload state of each shard
+func (this *Endpoint) GetErasureManifest(id string) (*db.ErasureManifest, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	manifest, err := this.db.GetErasureManifest(id)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if manifest == nil {
+		return nil, &DspErr{Code: DSP_ERASURE_NOT_FOUND, Error: ErrMaps[DSP_ERASURE_NOT_FOUND]}
+	}
+	this.refreshErasureManifest(manifest)
+	return manifest, nil
+}
+
+// DownloadFileErasure. download all stored shards of the file,
+// and rebuild the file as soon as any k shards are downloaded and verified.
+func (this *Endpoint) DownloadFileErasure(id, password string) (*db.ErasureManifest, *DspErr) {
+	manifest, derr := this.GetErasureManifest(id)
+	if derr != nil {
+		return nil, derr
+	}
+	if manifest.DownloadState == ERASURE_DOWNLOAD_DOING {
+		return manifest, nil
+	}
+	if manifest.UploadedShards() < manifest.DataShards {
+		return nil, &DspErr{Code: DSP_ERASURE_DECODE_FAILED,
+			Error: fmt.Errorf("only %d shards stored, need %d", manifest.UploadedShards(), manifest.DataShards)}
+	}
+	started := 0
+	for _, shard := range manifest.Shards {
+		shard.DownloadTaskId, shard.DownloadPath = "", ""
+		if len(shard.FileHash) == 0 {
+			continue
+		}
+		taskId := uuid.NewUUID().String()
+		if derr := this.DownloadFile(taskId, shard.FileHash, "", "", password, 0, false, false, ""); derr != nil {
+			log.Errorf("download shard %d of %s failed, err %s", shard.Index, manifest.Id, derr.Error)
+			continue
+		}
+		shard.DownloadTaskId = taskId
+		started++
+	}
+	if started < manifest.DataShards {
+		this.removeErasureDownloads(manifest)
+		return nil, &DspErr{Code: DSP_ERASURE_DECODE_FAILED,
+			Error: fmt.Errorf("only %d shards can be downloaded, need %d", started, manifest.DataShards)}
+	}
+	manifest.DownloadState = ERASURE_DOWNLOAD_DOING
+	manifest.DownloadPath = ""
+	manifest.DownloadError = ""
+	manifest.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutErasureManifest(manifest); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	go this.reconstructErasureFile(manifest)
+	return manifest, nil
+}
+
+// reconstructErasureFile. wait for the shard download tasks and decode the file
+func (this *Endpoint) reconstructErasureFile(manifest *db.ErasureManifest) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	finish := func(state string, err error) {
+		this.removeErasureDownloads(manifest)
+		manifest.DownloadState = state
+		if err != nil {
+			manifest.DownloadError = err.Error()
+		}
+		manifest.UpdatedAt = uint64(time.Now().Unix())
+		if err := this.db.PutErasureManifest(manifest); err != nil {
+			log.Errorf("save erasure manifest %s err %s", manifest.Id, err)
+		}
+	}
+	for {
+		select {
+		case <-time.After(time.Duration(ERASURE_DOWNLOAD_CHECK_INTERVAL) * time.Second):
+		case <-this.closeCh:
+			return
+		}
+		ready, pending := 0, 0
+		for _, shard := range manifest.Shards {
+			if len(shard.DownloadTaskId) == 0 {
+				continue
+			}
+			if len(shard.DownloadPath) > 0 {
+				ready++
+				continue
+			}
+			state, err := dsp.GetTaskState(shard.DownloadTaskId)
+			if err != nil {
+				continue
+			}
+			switch state {
+			case store.TaskStateDone:
+				info := dsp.GetTaskInfo(shard.DownloadTaskId)
+				if info == nil {
+					continue
+				}
+				checksum, err := contentHashOfFile(info.FilePath)
+				if err != nil || checksum != shard.Checksum {
+					log.Warnf("shard %d of %s is corrupted, skip it", shard.Index, manifest.Id)
+					if err := dsp.DeleteDownloadedLocalFile(shard.FileHash); err != nil {
+						log.Errorf("remove corrupted shard %d of %s err %s", shard.Index, manifest.Id, err)
+					}
+					shard.DownloadTaskId = ""
+					continue
+				}
+				shard.DownloadPath = info.FilePath
+				ready++
+			case store.TaskStateFailed, store.TaskStateCancel:
+				shard.DownloadTaskId = ""
+			default:
+				pending++
+			}
+		}
+		if ready >= manifest.DataShards {
+			break
+		}
+		if ready+pending < manifest.DataShards {
+			finish(ERASURE_DOWNLOAD_FAILED,
+				fmt.Errorf("only %d shards available, need %d", ready+pending, manifest.DataShards))
+			return
+		}
+	}
+	codec, err := erasure.NewCodec(manifest.DataShards, manifest.ParityShards)
+	if err != nil {
+		finish(ERASURE_DOWNLOAD_FAILED, err)
+		return
+	}
+	shardPaths := make([]string, codec.TotalShards())
+	for _, shard := range manifest.Shards {
+		shardPaths[shard.Index] = shard.DownloadPath
+	}
+	outPath := filepath.Join(config.FsFileRootPath(), manifest.FileName)
+	if _, err := os.Stat(outPath); err == nil {
+		outPath = filepath.Join(config.FsFileRootPath(), fmt.Sprintf("%s_%s", manifest.Id, manifest.FileName))
+	}
+	if err := codec.DecodeFile(shardPaths, outPath, int64(manifest.FileSize), manifest.StripeLen); err != nil {
+		finish(ERASURE_DOWNLOAD_FAILED, err)
+		return
+	}
+	if contentHash, err := contentHashOfFile(outPath); err != nil || contentHash != manifest.ContentHash {
+		os.Remove(outPath)
+		finish(ERASURE_DOWNLOAD_FAILED, fmt.Errorf("content hash of rebuilt file mismatch"))
+		return
+	}
+	manifest.DownloadPath = outPath
+	log.Infof("rebuild erasure file %s to %s", manifest.Id, outPath)
+	finish(ERASURE_DOWNLOAD_DONE, nil)
+}
+
+// removeErasureDownloads. remove the downloaded shards and cancel the unfinished shard downloads,
+// the shards are not needed after the file is rebuilt or the rebuilding is failed
+func (this *Endpoint) removeErasureDownloads(manifest *db.ErasureManifest) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	for _, shard := range manifest.Shards {
+		if len(shard.DownloadTaskId) == 0 {
+			continue
+		}
+		if len(shard.DownloadPath) > 0 {
+			if err := dsp.DeleteDownloadedLocalFile(shard.FileHash); err != nil {
+				log.Errorf("remove downloaded shard %d of %s err %s", shard.Index, manifest.Id, err)
+			}
+		} else if err := dsp.CancelDownload(shard.DownloadTaskId); err != nil {
+			log.Errorf("cancel download of shard %d of %s err %s", shard.Index, manifest.Id, err)
+		}
+		if err := dsp.CleanTasks([]string{shard.DownloadTaskId}); err != nil {
+			log.Errorf("clean download task of shard %d of %s err %s", shard.Index, manifest.Id, err)
+		}
+		shard.DownloadTaskId, shard.DownloadPath = "", ""
+	}
+}
+
+// erasureUploadService. follow the shard uploads of erasure files until all of them are finished
+func (this *Endpoint) erasureUploadService() {
+	ti := time.NewTicker(time.Duration(ERASURE_UPLOAD_CHECK_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			if this.db == nil {
+				continue
+			}
+			manifests, err := this.db.GetAllErasureManifests()
+			if err != nil {
+				log.Errorf("get erasure manifests err %s", err)
+				continue
+			}
+			for _, manifest := range manifests {
+				this.refreshErasureManifest(manifest)
+			}
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+// refreshErasureManifest. fill the task id, file hash and storage nodes of shards from the upload list.
+// A shard stored on the node of another shard is uploaded again, the upload is failed if any shard is failed
+// or a shard can't be relocated, and the local shard files are removed once no shard is uploading.
+func (this *Endpoint) refreshErasureManifest(manifest *db.ErasureManifest) {
+	dsp := this.getDsp()
+	if dsp == nil || manifest.ShardsRemoved {
+		return
+	}
+	taskInfos, err := dsp.GetUploadTaskInfos()
+	if err != nil {
+		return
+	}
+	shardOfPath := make(map[string]*db.ErasureShard, len(manifest.Shards))
+	for _, shard := range manifest.Shards {
+		if len(shard.FileHash) > 0 || len(shard.Error) > 0 {
+			continue
+		}
+		shardOfPath[shard.ShardPath] = shard
+	}
+	updated := false
+	for _, info := range taskInfos {
+		if info == nil {
+			continue
+		}
+		shard, ok := shardOfPath[info.FilePath]
+		if !ok || shard.Relocated(info.Id) {
+			continue
+		}
+		delete(shardOfPath, info.FilePath)
+		shard.TaskId = info.Id
+		switch info.TaskState {
+		case store.TaskStateDone:
+			if len(info.FileHash) == 0 {
+				continue
+			}
+		case store.TaskStateFailed:
+			shard.Error = "upload task failed"
+			updated = true
+			continue
+		case store.TaskStateCancel:
+			shard.Error = "upload task is cancelled"
+			updated = true
+			continue
+		default:
+			continue
+		}
+		shard.FileHash = info.FileHash
+		if fileInfo, _ := dsp.GetFileInfo(info.FileHash); fileInfo != nil {
+			shard.Nodes = make([]string, 0, len(fileInfo.PrimaryNodes.AddrList))
+			for _, addr := range fileInfo.PrimaryNodes.AddrList {
+				shard.Nodes = append(shard.Nodes, addr.ToBase58())
+			}
+		}
+		updated = true
+	}
+	for _, shard := range shardOfPath {
+		if len(shard.TaskId) > 0 {
+			// the task was seen before and it's removed from the upload list
+			shard.Error = "upload task not found"
+			updated = true
+		}
+	}
+	if manifest.UploadState != ERASURE_UPLOAD_FAILED && manifest.UploadState != ERASURE_UPLOAD_DONE {
+		if errs := shardErrors(manifest); len(errs) > 0 {
+			manifest.UploadState, manifest.UploadError = ERASURE_UPLOAD_FAILED, errs
+			updated = true
+		} else if shards := collidingShards(manifest); len(shards) > 0 {
+			if err := this.relocateErasureShards(manifest, shards); err != nil {
+				manifest.UploadState, manifest.UploadError = ERASURE_UPLOAD_FAILED, err.Error()
+			}
+			updated = true
+		} else if manifest.UploadedShards() == len(manifest.Shards) {
+			manifest.UploadState = ERASURE_UPLOAD_DONE
+			updated = true
+		}
+		if manifest.UploadState == ERASURE_UPLOAD_FAILED {
+			log.Errorf("upload erasure file %s failed, err %s", manifest.Id, manifest.UploadError)
+		}
+		if manifest.UploadState != ERASURE_UPLOAD_DOING {
+			this.erasureUploads.Delete(manifest.Id)
+		}
+	}
+	uploading := false
+	for _, shard := range manifest.Shards {
+		if len(shard.FileHash) == 0 && len(shard.Error) == 0 {
+			uploading = true
+			break
+		}
+	}
+	if !uploading {
+		if err := os.RemoveAll(erasureShardDir(manifest.Id)); err != nil {
+			log.Errorf("remove shards of erasure file %s err %s", manifest.Id, err)
+		} else {
+			manifest.ShardsRemoved = true
+			updated = true
+		}
+	}
+	if !updated {
+		return
+	}
+	manifest.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutErasureManifest(manifest); err != nil {
+		log.Errorf("save erasure manifest %s err %s", manifest.Id, err)
+	}
+}
+
+// shardErrors. the upload errors of shards joined in one message
+func shardErrors(manifest *db.ErasureManifest) string {
+	errs := make([]string, 0)
+	for _, shard := range manifest.Shards {
+		if len(shard.Error) > 0 {
+			errs = append(errs, fmt.Sprintf("shard %d: %s", shard.Index, shard.Error))
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
+func erasureShardDir(id string) string {
+	return filepath.Join(config.FsFileRootPath(), "erasure", id)
+}
+
+// sharedShardNodes. nodes which store more than one shard of the file
+func sharedShardNodes(manifest *db.ErasureManifest) []string {
+	cnt := make(map[string]int)
+	nodes := make([]string, 0)
+	for _, shard := range manifest.Shards {
+		for _, node := range shard.Nodes {
+			cnt[node]++
+			if cnt[node] == 2 {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
+
+// collidingShards. shards stored on any node which also stores a shard with lower index
+func collidingShards(manifest *db.ErasureManifest) []*db.ErasureShard {
+	used := make(map[string]bool)
+	shards := make([]*db.ErasureShard, 0)
+	for _, shard := range manifest.Shards {
+		colliding := false
+		for _, node := range shard.Nodes {
+			if used[node] {
+				colliding = true
+				break
+			}
+		}
+		if colliding {
+			shards = append(shards, shard)
+			continue
+		}
+		for _, node := range shard.Nodes {
+			used[node] = true
+		}
+	}
+	return shards
+}
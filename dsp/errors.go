@@ -128,10 +128,39 @@ const (
 	DSP_DNS_QUERY_ALLPLUGININFOS_FAILED = 55067
 	DSP_EXIST_ACTIVE_DOWNLOAD_TASK      = 55068
 	DSP_USER_SPACE_PERIOD_NOT_ENOUGH    = 55069
+	DSP_UPLOAD_FILE_DUPLICATED          = 55070
 
-	DSP_FILE_INFO_NOT_FOUND      = 55100
-	DSP_FILE_NOT_EXISTS          = 55101
-	DSP_FILE_DECRYPTED_WRONG_PWD = 55102
+	DSP_FILE_INFO_NOT_FOUND       = 55100
+	DSP_FILE_NOT_EXISTS           = 55101
+	DSP_FILE_DECRYPTED_WRONG_PWD  = 55102
+	DSP_FILE_REVISION_NOT_FOUND   = 55103
+	DSP_FILE_REVISION_EXIST       = 55104
+	DSP_ERASURE_ENCODE_FAILED     = 55105
+	DSP_ERASURE_DECODE_FAILED     = 55106
+	DSP_ERASURE_NOT_FOUND         = 55107
+	DSP_FILE_NOT_RECIPIENT        = 55108
+	DSP_SHARE_LINK_INVALID        = 55109
+	DSP_SHARE_LINK_EXPIRED        = 55110
+	DSP_SHARE_LINK_REVOKED        = 55111
+	DSP_SHARE_LINK_EXHAUSTED      = 55112
+	DSP_SHARE_LINK_NOT_FOUND      = 55113
+	DSP_WHITELIST_GROUP_NOT_FOUND = 55114
+	DSP_SEEDING_POLICY_INVALID    = 55115
+	DSP_COLLECTION_NOT_FOUND      = 55116
+	DSP_SPACE_POLICY_INVALID      = 55117
+	DSP_NODE_MONITOR_INVALID      = 55118
+	DSP_NODE_SETUP_INVALID        = 55119
+	DSP_NODE_EXIT_INVALID         = 55120
+	DSP_NODE_RESIZE_INVALID       = 55121
+	DSP_ERASURE_UPLOAD_FAILED     = 55122
+
+	DSP_SECTOR_CREATE_FAILED    = 55200
+	DSP_SECTOR_DELETE_FAILED    = 55201
+	DSP_SECTOR_QUERY_FAILED     = 55202
+	DSP_SECTOR_NOT_FOUND        = 55203
+	DSP_SECTOR_NOT_EMPTY        = 55204
+	DSP_SECTOR_NO_SPACE         = 55205
+	DSP_SECTOR_REBALANCE_FAILED = 55206
 
 	DSP_CHANNEL_INTERNAL_ERROR           = 56000
 	DSP_CHANNEL_OPEN_FAILED              = 56001
@@ -157,6 +186,10 @@ const (
 	DSP_TASK_NOT_EXIST       = 58000
 	DSP_TASK_POC_ERROR       = 58010
 	DSP_TASK_POC_WRONG_NONCE = 58011
+	DSP_TASK_PLOT_NOT_FOUND  = 58012
+	DSP_TASK_PLOT_FINISHED   = 58013
+	DSP_TASK_PLOT_INVALID    = 58014
+	DSP_TASK_PLOT_NO_SPACE   = 58015
 
 	DB_FIND_SHARE_RECORDS_FAILED     = 59000
 	DB_SUM_SHARE_PROFIT_FAILED       = 59001
@@ -165,6 +198,7 @@ const (
 	DB_GET_FILEINFO_FAILED           = 59004
 	DB_USER_SPACE_HAVE_FILE_NOT_CASH = 59005
 	DB_USER_SPACE_NOT_BALANCE        = 59006
+	DB_LOCAL_STORE_FAILED            = 59007
 
 	NET_RECONNECT_PEER_FAILED = 59100
 	NET_PROXY_DISCONNECTED    = 59101
@@ -261,6 +295,26 @@ var ErrMaps = map[int64]error{
 	DSP_FILE_INFO_NOT_FOUND:              errors.New("dsp file info not found"),
 	DSP_FILE_NOT_EXISTS:                  errors.New("dsp file not exists"),
 	DSP_FILE_DECRYPTED_WRONG_PWD:         errors.New("dsp file decrypt password wrong"),
+	DSP_FILE_REVISION_NOT_FOUND:          errors.New("dsp file revision not found"),
+	DSP_FILE_REVISION_EXIST:              errors.New("dsp file revision exist"),
+	DSP_ERASURE_ENCODE_FAILED:            errors.New("dsp erasure encode file failed"),
+	DSP_ERASURE_DECODE_FAILED:            errors.New("dsp erasure decode file failed"),
+	DSP_ERASURE_NOT_FOUND:                errors.New("dsp erasure manifest not found"),
+	DSP_FILE_NOT_RECIPIENT:               errors.New("dsp file is not encrypted for the key"),
+	DSP_SHARE_LINK_INVALID:               errors.New("dsp share link is invalid"),
+	DSP_SHARE_LINK_EXPIRED:               errors.New("dsp share link is expired"),
+	DSP_SHARE_LINK_REVOKED:               errors.New("dsp share link is revoked"),
+	DSP_SHARE_LINK_EXHAUSTED:             errors.New("dsp share link download count exhausted"),
+	DSP_SHARE_LINK_NOT_FOUND:             errors.New("dsp share link not found"),
+	DSP_WHITELIST_GROUP_NOT_FOUND:        errors.New("dsp whitelist group not found"),
+	DSP_SEEDING_POLICY_INVALID:           errors.New("dsp seeding policy invalid"),
+	DSP_COLLECTION_NOT_FOUND:             errors.New("dsp collection not found"),
+	DSP_SPACE_POLICY_INVALID:             errors.New("dsp userspace guardian policy invalid"),
+	DSP_NODE_MONITOR_INVALID:             errors.New("dsp node monitor policy invalid"),
+	DSP_NODE_SETUP_INVALID:               errors.New("dsp node setup invalid"),
+	DSP_NODE_EXIT_INVALID:                errors.New("dsp node exit invalid"),
+	DSP_NODE_RESIZE_INVALID:              errors.New("dsp node volume resize invalid"),
+	DSP_ERASURE_UPLOAD_FAILED:            errors.New("dsp erasure upload file failed"),
 	DSP_CHANNEL_INTERNAL_ERROR:           errors.New("dsp channel internal error"),
 	DSP_CHANNEL_OPEN_FAILED:              errors.New("dsp channel open failed"),
 	DSP_CHANNEL_CLOSE_FAILED:             errors.New("dsp channel close failed"),
@@ -282,9 +336,23 @@ var ErrMaps = map[int64]error{
 	DSP_CHANNEL_DNS_OFFLINE:              errors.New("dsp channel dns offline"),
 	DSP_CHANNEL_NOT_EXIST:                errors.New("dsp channel not exist"),
 	DSP_EXIST_ACTIVE_DOWNLOAD_TASK:       errors.New("dsp exist active task"),
+	DSP_UPLOAD_FILE_DUPLICATED:           errors.New("dsp upload file has been stored"),
 
 	DSP_TASK_NOT_EXIST: errors.New("dsp task not exist"),
 
+	DSP_TASK_PLOT_NOT_FOUND: errors.New("dsp plot job not found"),
+	DSP_TASK_PLOT_FINISHED:  errors.New("dsp plot job has finished"),
+	DSP_TASK_PLOT_INVALID:   errors.New("dsp plot file is invalid"),
+	DSP_TASK_PLOT_NO_SPACE:  errors.New("dsp plot directories have no enough capacity"),
+
+	DSP_SECTOR_CREATE_FAILED:    errors.New("dsp create sector failed"),
+	DSP_SECTOR_DELETE_FAILED:    errors.New("dsp delete sector failed"),
+	DSP_SECTOR_QUERY_FAILED:     errors.New("dsp query sector failed"),
+	DSP_SECTOR_NOT_FOUND:        errors.New("dsp sector not found"),
+	DSP_SECTOR_NOT_EMPTY:        errors.New("dsp sector is not empty"),
+	DSP_SECTOR_NO_SPACE:         errors.New("dsp no enough space in other sectors"),
+	DSP_SECTOR_REBALANCE_FAILED: errors.New("dsp rebalance sector failed"),
+
 	DB_FIND_SHARE_RECORDS_FAILED:     errors.New("db find share records failed"),
 	DB_SUM_SHARE_PROFIT_FAILED:       errors.New("db sum share profit failed"),
 	DB_FIND_USER_SPACE_RECORD_FAILED: errors.New("db find user space record failed"),
@@ -293,6 +361,7 @@ var ErrMaps = map[int64]error{
 	FS_USER_SPACE_SECOND_INVALID:     errors.New("fs user space second invalid"),
 	DB_USER_SPACE_HAVE_FILE_NOT_CASH: errors.New("db user space have file not cash"),
 	DB_USER_SPACE_NOT_BALANCE:        errors.New("db user space not balance"),
+	DB_LOCAL_STORE_FAILED:            errors.New("db local store failed"),
 
 	NET_RECONNECT_PEER_FAILED:  errors.New("net reconnect peer failed"),
 	NET_PROXY_DISCONNECTED:     errors.New("proxy has disconnted"),
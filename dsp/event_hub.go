@@ -5,6 +5,7 @@ import (
 
 	"github.com/saveio/edge/common/config"
 	"github.com/saveio/edge/dsp/actor/client"
+	"github.com/saveio/edge/utils/nodemonitor"
 	"github.com/saveio/themis-go-sdk/usdt"
 	"github.com/saveio/themis/common/log"
 )
@@ -181,6 +182,20 @@ func (this *Endpoint) notifyNewNetworkState() {
 	client.EventNotifyChannels()
 }
 
+func (this *Endpoint) notifyNodeAlert(alert *nodemonitor.Alert) {
+	if !config.WsEnabled() {
+		return
+	}
+	client.EventNotifyNodeAlert(alert)
+}
+
+func (this *Endpoint) notifyNodeAction(action *nodemonitor.ActionLog) {
+	if !config.WsEnabled() {
+		return
+	}
+	client.EventNotifyNodeAction(action)
+}
+
 func (this *Endpoint) notifyNewTransferTask(transferType TransferType, id string) {
 	if !config.WsEnabled() {
 		return
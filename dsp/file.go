@@ -31,6 +31,9 @@ import (
 	"github.com/saveio/edge/common"
 	"github.com/saveio/edge/common/config"
 	"github.com/saveio/edge/dsp/actor/client"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/fileindex"
+	"github.com/saveio/edge/utils/transferhistory"
 	sdkcom "github.com/saveio/themis-go-sdk/common"
 	chainSdkFs "github.com/saveio/themis-go-sdk/fs"
 	"github.com/saveio/themis/cmd/utils"
@@ -51,7 +54,9 @@ const (
 
 type DeleteFileResp struct {
 	dspTypes.DeleteUploadFileResp
-	IsUploaded bool `json:"IsUploaded,omitempty"`
+	IsUploaded bool   `json:"IsUploaded,omitempty"`
+	InTrash    bool   `json:"InTrash,omitempty"`
+	PurgeAt    uint64 `json:"PurgeAt,omitempty"`
 }
 
 type TransferType int
@@ -152,6 +157,8 @@ type FileResp struct {
 	StoreType     fs.FileStoreType
 	RealFileSize  uint64
 	Nodes         []NodeProveDetail
+	Groups        []string `json:",omitempty"`
+	Tags          []string `json:",omitempty"`
 }
 
 type NodeProveDetail struct {
@@ -221,6 +228,8 @@ type CalculateResp struct {
 	StorageFeeFormat string
 	ValidFee         uint64
 	ValidFeeFormat   string
+	Duplicate        *DuplicateUpload `json:",omitempty"`
+	Erasure          *ErasureFee      `json:",omitempty"`
 }
 
 type UserspaceCostResp struct {
@@ -237,6 +246,8 @@ type FsContractSettingResp struct {
 	DefaultProvePeriod uint64
 	DefaultProveLevel  uint64
 	MinVolume          uint64
+	FsGasPrice         uint64
+	GasPerGBPerBlock   uint64
 }
 
 type FileTask struct {
@@ -694,7 +705,23 @@ func (this *Endpoint) CancelUploadFile(taskIds []string, gasLimit uint64) (*File
 	return resp, nil
 }
 
+// DeleteUploadFile. move the upload file into trash, or delete it from chain immediately if trash is disabled.
+// The resp without tx is returned if the file has been deleted from chain.
 func (this *Endpoint) DeleteUploadFile(fileHash string, gasLimit uint64) (*DeleteFileResp, *DspErr) {
+	if trashGracePeriod() == 0 {
+		return this.deleteUploadFile(fileHash, gasLimit)
+	}
+	resps, derr := this.TrashUploadFiles([]string{fileHash}, gasLimit)
+	if derr != nil {
+		return nil, derr
+	}
+	if len(resps) == 0 {
+		return deletedFileResp(fileHash), nil
+	}
+	return resps[0], nil
+}
+
+func (this *Endpoint) deleteUploadFile(fileHash string, gasLimit uint64) (*DeleteFileResp, *DspErr) {
 	dsp := this.getDsp()
 	if dsp == nil {
 		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
@@ -702,7 +729,7 @@ func (this *Endpoint) DeleteUploadFile(fileHash string, gasLimit uint64) (*Delet
 	fi, err := dsp.GetFileInfo(fileHash)
 	if fi == nil && dsp.IsFileInfoDeleted(err) {
 		log.Debugf("file info is deleted: %v, %s", fi, err)
-		return nil, nil
+		return deletedFileResp(fileHash), nil
 	}
 	if fi != nil && err == nil && fi.FileOwner.ToBase58() == dsp.WalletAddress() ||
 		ethCom.BytesToAddress(fi.FileOwner[:]) == dsp.CurrentAccount().EthAddress {
@@ -736,6 +763,13 @@ func (this *Endpoint) DeleteUploadFile(fileHash string, gasLimit uint64) (*Delet
 	return nil, &DspErr{Code: DSP_DELETE_FILE_FAILED, Error: err}
 }
 
+// deletedFileResp. the resp of a upload file which has been deleted from chain, no tx is sent
+func deletedFileResp(fileHash string) *DeleteFileResp {
+	resp := &DeleteFileResp{IsUploaded: true}
+	resp.FileHash = fileHash
+	return resp
+}
+
 func (this *Endpoint) DeleteDownloadFile(fileHash string) (*DeleteFileResp, *DspErr) {
 	dsp := this.getDsp()
 	if dsp == nil {
@@ -751,7 +785,15 @@ func (this *Endpoint) DeleteDownloadFile(fileHash string) (*DeleteFileResp, *Dsp
 	return nil, nil
 }
 
+// DeleteUploadFiles. move the upload files into trash, or delete them from chain immediately if trash is disabled
 func (this *Endpoint) DeleteUploadFiles(fileHashes []string, gasLimit uint64) ([]*DeleteFileResp, *DspErr) {
+	if trashGracePeriod() == 0 {
+		return this.deleteUploadFiles(fileHashes, gasLimit)
+	}
+	return this.TrashUploadFiles(fileHashes, gasLimit)
+}
+
+func (this *Endpoint) deleteUploadFiles(fileHashes []string, gasLimit uint64) ([]*DeleteFileResp, *DspErr) {
 	dsp := this.getDsp()
 	if dsp == nil {
 		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
@@ -835,6 +877,8 @@ func (this *Endpoint) GetFsConfig() (*FsContractSettingResp, *DspErr) {
 		DefaultProvePeriod: set.DefaultProvePeriod * config.BlockTime(),
 		DefaultProveLevel:  set.DefaultProveLevel,
 		MinVolume:          set.MinVolume,
+		FsGasPrice:         set.FsGasPrice,
+		GasPerGBPerBlock:   set.GasPerGBPerBlock,
 	}, nil
 }
 
@@ -862,8 +906,10 @@ func (this *Endpoint) getChannelMinBalance() (uint64, *DspErr) {
 	return minChannelBalance, nil
 }
 
+// DownloadFile. download the file by url, file hash or link.
+// The share link is presented to the nodes of the file if local account isn't allowed by the file privilege.
 func (this *Endpoint) DownloadFile(taskId, fileHash, url, linkStr, password string, max uint64,
-	setFileName, inOrder bool) *DspErr {
+	setFileName, inOrder bool, shareLink string) *DspErr {
 	dsp := this.getDsp()
 	if dsp == nil {
 		return &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
@@ -917,9 +963,8 @@ func (this *Endpoint) DownloadFile(taskId, fileHash, url, linkStr, password stri
 			return &DspErr{Code: INTERNAL_ERROR, Error: fmt.Errorf("file hash not found for url %s", url)}
 		}
 		info, _ := dsp.GetFileInfo(hash)
-		if info != nil && !dsp.CheckFilePrivilege(info, hash, dsp.WalletAddress()) {
-			return &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
-				Error: fmt.Errorf("user %s has no privilege to download this file", dsp.WalletAddress())}
+		if derr := this.checkDownloadPrivilege(info, hash, shareLink); derr != nil {
+			return derr
 		}
 
 		go func() {
@@ -946,9 +991,8 @@ func (this *Endpoint) DownloadFile(taskId, fileHash, url, linkStr, password stri
 			}
 		}
 		info, _ := dsp.GetFileInfo(fileHash)
-		if info != nil && !dsp.CheckFilePrivilege(info, fileHash, dsp.WalletAddress()) {
-			return &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
-				Error: fmt.Errorf("user %s has no privilege to download this file", dsp.WalletAddress())}
+		if derr := this.checkDownloadPrivilege(info, fileHash, shareLink); derr != nil {
+			return derr
 		}
 		if info != nil {
 			log.Debugf("fileBlockSize %v, minChannelBalance: %d", info.FileBlockNum*info.FileBlockSize, minChannelBalance)
@@ -980,9 +1024,8 @@ func (this *Endpoint) DownloadFile(taskId, fileHash, url, linkStr, password stri
 			return &DspErr{Code: INTERNAL_ERROR, Error: fmt.Errorf("file hash not found for url %s", hash)}
 		}
 		info, _ := dsp.GetFileInfo(hash)
-		if info != nil && !dsp.CheckFilePrivilege(info, hash, dsp.WalletAddress()) {
-			return &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
-				Error: fmt.Errorf("user %s has no privilege to download this file", dsp.WalletAddress())}
+		if derr := this.checkDownloadPrivilege(info, hash, shareLink); derr != nil {
+			return derr
 		}
 		if info != nil && info.FileBlockNum*info.FileBlockSize > minChannelBalance {
 			return &DspErr{Code: DSP_CHANNEL_BALANCE_DNS_NOT_ENOUGH, Error: ErrMaps[DSP_CHANNEL_BALANCE_DNS_NOT_ENOUGH]}
@@ -1003,6 +1046,19 @@ func (this *Endpoint) DownloadFile(taskId, fileHash, url, linkStr, password stri
 	return nil
 }
 
+// checkDownloadPrivilege. local account must be allowed by the file privilege, or present the share link
+func (this *Endpoint) checkDownloadPrivilege(info *fs.FileInfo, fileHash, shareLink string) *DspErr {
+	dsp := this.getDsp()
+	if info == nil || dsp.CheckFilePrivilege(info, fileHash, dsp.WalletAddress()) {
+		return nil
+	}
+	if len(shareLink) == 0 {
+		return &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
+			Error: fmt.Errorf("user %s has no privilege to download this file", dsp.WalletAddress())}
+	}
+	return this.presentShareLink(fileHash, shareLink)
+}
+
 func (this *Endpoint) PauseDownloadFile(taskIds []string) (*FileTaskResp, *DspErr) {
 	resp := &FileTaskResp{
 		Tasks: make([]*FileTask, 0, len(taskIds)),
@@ -1222,6 +1278,7 @@ func (this *Endpoint) RegisterProgressCh() {
 			switch v.Type {
 			case store.TaskTypeUpload:
 				go this.notifyUploadingTransferList()
+				this.saveUploadCheckpoint(v)
 			case store.TaskTypeDownload:
 				go this.notifyDownloadingTransferList()
 			default:
@@ -1243,8 +1300,20 @@ func (this *Endpoint) RegisterProgressCh() {
 				switch v.Type {
 				case store.TaskTypeUpload:
 					go this.notifyUploadingTransferList()
+					if v.TaskState == store.TaskStateDone {
+						go this.indexUploadedFile(v.TaskId)
+					}
+					if v.TaskState == store.TaskStateDone || v.TaskState == store.TaskStateCancel {
+						go this.removeSealedUpload(v.TaskId)
+					}
 				case store.TaskTypeDownload:
 					go this.notifyDownloadingTransferList()
+					switch v.TaskState {
+					case store.TaskStateDone:
+						go this.decryptDownloadedStream(v.TaskId)
+					case store.TaskStateFailed, store.TaskStateCancel:
+						this.streamDecryptPwds.Delete(v.TaskId)
+					}
 				default:
 				}
 			}
@@ -1268,7 +1337,13 @@ func (this *Endpoint) DeleteTransferRecord(taskIds []string) (*FileTaskResp, *Ds
 			Id:    id,
 			State: int(store.TaskStateCancel),
 		}
-		err := dsp.HideTaskIds([]string{id})
+		imported, err := this.deleteImportedTransfer(id)
+		if !imported {
+			if state, _ := dsp.GetTaskState(id); state == store.TaskStateFailed {
+				this.removeSealedUpload(id)
+			}
+			err = dsp.HideTaskIds([]string{id})
+		}
 		if err != nil {
 			taskResp.Code = DSP_CANCEL_TASK_FAILED
 			taskResp.Error = err.Error()
@@ -1302,8 +1377,22 @@ func (this *Endpoint) GetTransferList(pType TransferType, offset, limit uint32,
 		reverse = true
 		includeFailed = false
 	}
-	ids := dsp.GetTaskIdList(offset, limit, createdAt, createdAtEnd, updatedAt, updatedAtEnd, infoType,
+	// the imported history is listed after local tasks, so the local tasks are queried from the first one
+	imported := this.getImportedTransfers(pType, createdAt, createdAtEnd, updatedAt, updatedAtEnd)
+	idOffset, idLimit := offset, limit
+	if len(imported) > 0 {
+		idOffset = 0
+		if limit > 0 {
+			idLimit = offset + limit
+		}
+	}
+	ids := dsp.GetTaskIdList(idOffset, idLimit, createdAt, createdAtEnd, updatedAt, updatedAtEnd, infoType,
 		complete, reverse, includeFailed, ignoreHide)
+	if len(imported) > 0 {
+		page := transferhistory.MergePage(len(ids), len(imported), offset, limit)
+		ids = ids[page.LocalStart:page.LocalEnd]
+		imported = imported[page.ImportedStart:page.ImportedEnd]
+	}
 	infos := make([]*Transfer, 0, len(ids))
 	for idx, key := range ids {
 		info := dsp.GetProgressInfo(key)
@@ -1329,7 +1418,7 @@ func (this *Endpoint) GetTransferList(pType TransferType, offset, limit uint32,
 		}
 		infos = append(infos, pInfo)
 	}
-	resp.Transfers = infos
+	resp.Transfers = append(infos, imported...)
 	return resp, nil
 }
 
@@ -1344,6 +1433,11 @@ func (this *Endpoint) GetTransferDetail(pType TransferType, id string) (*Transfe
 	}
 	resp := &Transfer{}
 	info := dsp.GetProgressInfo(id)
+	if info == nil && this.db != nil {
+		if history, _ := this.db.GetTransferHistory(id); history != nil {
+			info = history.Progress
+		}
+	}
 	if info == nil {
 		return resp, nil
 	}
@@ -1413,6 +1507,25 @@ func (this *Endpoint) GetTransferDetailByUrl(pType TransferType, url string) (*T
 }
 
 func (this *Endpoint) CalculateUploadFee(filePath string, durationVal, proveLevelVal, timesVal, copynumVal,
+	whitelistVal, storeType interface{}) (*CalculateResp, *DspErr) {
+	fi, err := os.Open(filePath)
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_GET_FILESIZE_FAILED, Error: err}
+	}
+	defer fi.Close()
+	fileStat, err := fi.Stat()
+	if err != nil {
+		return nil, &DspErr{Code: FS_UPLOAD_GET_FILESIZE_FAILED, Error: err}
+	}
+	fileSize := uint64(fileStat.Size() / 1024)
+	if fileSize == 0 {
+		fileSize = 1
+	}
+	return this.calculateUploadFeeOfSize(fileSize, durationVal, proveLevelVal, copynumVal, whitelistVal, storeType)
+}
+
+// calculateUploadFeeOfSize. calculate upload fee of a file with size in KiB
+func (this *Endpoint) calculateUploadFeeOfSize(fileSize uint64, durationVal, proveLevelVal, copynumVal,
 	whitelistVal, storeType interface{}) (*CalculateResp, *DspErr) {
 	dsp := this.getDsp()
 	if dsp == nil {
@@ -1439,14 +1552,6 @@ func (this *Endpoint) CalculateUploadFee(filePath string, durationVal, proveLeve
 
 	sType, _ := ToUint64(storeType)
 
-	fi, err := os.Open(filePath)
-	if err != nil {
-		return nil, &DspErr{Code: FS_UPLOAD_GET_FILESIZE_FAILED, Error: err}
-	}
-	fileStat, err := fi.Stat()
-	if err != nil {
-		return nil, &DspErr{Code: FS_UPLOAD_GET_FILESIZE_FAILED, Error: err}
-	}
 	copyNum, err := ToUint64(copynumVal)
 	if err != nil {
 		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
@@ -1459,10 +1564,6 @@ func (this *Endpoint) CalculateUploadFee(filePath string, durationVal, proveLeve
 		Num:  uint64(wh),
 		List: make([]fs.Rule, uint64(wh)),
 	}
-	fileSize := uint64(fileStat.Size() / 1024)
-	if fileSize == 0 {
-		fileSize = 1
-	}
 	opt := &fs.UploadOption{
 		FileDesc:        []byte{},
 		FileSize:        fileSize,
@@ -1686,6 +1787,9 @@ func (this *Endpoint) EncryptFileInDir(path, password string) *DspErr {
 }
 
 func (this *Endpoint) DecryptFile(path, fileName, password string) (string, *DspErr) {
+	if this.IsStreamEncrypted(path) {
+		return this.DecryptFileStream(path, fileName, password)
+	}
 	filePrefix, prefix, err := dspPrefix.GetPrefixFromFile(path)
 	if err != nil {
 		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
@@ -1837,6 +1941,9 @@ func (this *Endpoint) EncryptFileAInDIr(path, address string) *DspErr {
 }
 
 func (this *Endpoint) DecryptFileA(path, fileName, privKey string) (string, *DspErr) {
+	if this.IsEnvelopeEncrypted(path) {
+		return this.decryptEnvelopeFile(path, fileName, privKey)
+	}
 	filePrefix, prefix, err := dspPrefix.GetPrefixFromFile(path)
 	if err != nil {
 		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
@@ -1973,7 +2080,7 @@ func (this *Endpoint) RegisterShareNotificationCh() {
 }
 
 func (this *Endpoint) GetUploadFiles(fileType DspFileListType, offset, limit, createdAt, createdAtEnd, updatedAt, updatedAtEnd uint64,
-	filterType UploadFileFilterType) ([]*FileResp, int, *DspErr) {
+	filterType UploadFileFilterType, whitelistGroup string) ([]*FileResp, int, *DspErr) {
 	dsp := this.getDsp()
 	if dsp == nil {
 		return nil, 0, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
@@ -1988,6 +2095,31 @@ func (this *Endpoint) GetUploadFiles(fileType DspFileListType, offset, limit, cr
 		return nil, 0, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND, Error: err}
 	}
 	log.Debugf("total upload task info length %v", len(taskInfos))
+	fileGroups := make(map[string]*db.FileWhitelistGroups)
+	fileTags := make(map[string][]string)
+	trashed := make(map[string]struct{})
+	if this.db != nil {
+		fileGroups, err = this.db.GetAllFileWhitelistGroups()
+		if err != nil {
+			return nil, 0, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		entries, err := this.db.GetAllFileIndexEntries()
+		if err != nil {
+			return nil, 0, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		for _, e := range entries {
+			if e.Kind == fileindex.KIND_UPLOAD && len(e.Tags) > 0 {
+				fileTags[e.FileHash] = e.Tags
+			}
+		}
+		items, err := this.db.GetAllTrashItems()
+		if err != nil {
+			return nil, 0, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		for _, item := range items {
+			trashed[item.FileHash] = struct{}{}
+		}
+	}
 	totalCount := 0
 	files := make([]*FileResp, 0, limit)
 	offsetCnt := uint64(0)
@@ -2018,6 +2150,12 @@ func (this *Endpoint) GetUploadFiles(fileType DspFileListType, offset, limit, cr
 			log.Warnf("task %s file hash is empty ", info.Id)
 			continue
 		}
+		if len(whitelistGroup) > 0 && (fileGroups[fileHashStr] == nil || !fileGroups[fileHashStr].HasGroup(whitelistGroup)) {
+			continue
+		}
+		if _, ok := trashed[fileHashStr]; ok {
+			continue
+		}
 		downloadedCount, _ := dsp.CountRecordByFileHash(fileHashStr)
 		profit, _ := dsp.SumRecordsProfitByFileHash(fileHashStr)
 		// init primary node map
@@ -2140,6 +2278,10 @@ func (this *Endpoint) GetUploadFiles(fileType DspFileListType, offset, limit, cr
 				RealFileSize:  info.RealFileSize,
 				Nodes:         nodesDetail,
 			}
+			if groups := fileGroups[fileHashStr]; groups != nil {
+				fr.Groups = groups.Groups
+			}
+			fr.Tags = fileTags[fileHashStr]
 			files = append(files, fr)
 		} else {
 			totalCount++
@@ -2171,6 +2313,7 @@ type fileInfoResp struct {
 	TotalBlockCount uint64
 	Encrypt         bool
 	Url             string
+	Tags            []string `json:",omitempty"`
 }
 
 func (this *Endpoint) GetFileInfo(fileHashStr string) (*fileInfoResp, *DspErr) {
@@ -2225,6 +2368,11 @@ func (this *Endpoint) GetFileInfo(fileHashStr string) (*fileInfoResp, *DspErr) {
 	} else {
 		result.CreatedAt = uint64(block.Header.Timestamp)
 	}
+	if this.db != nil {
+		if entry, _ := this.db.GetFileIndexEntry(fileindex.KIND_UPLOAD, fileHashStr); entry != nil {
+			result.Tags = entry.Tags
+		}
+	}
 	if info.Privilege != fs.WHITELIST {
 		return result, nil
 	}
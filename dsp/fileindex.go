@@ -0,0 +1,207 @@
+package dsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dspConsts "github.com/saveio/dsp-go-sdk/consts"
+	dspPrefix "github.com/saveio/dsp-go-sdk/types/prefix"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/utils/fileindex"
+	"github.com/saveio/themis/common/log"
+)
+
+// SearchFiles. search the local uploaded and downloaded files with the metadata index.
+// The index is synced by fileIndexService, the files added after the last sync are found in next sync.
+func (this *Endpoint) SearchFiles(query *fileindex.Query) (*fileindex.Result, *DspErr) {
+	if query == nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	entries, err := this.db.GetAllFileIndexEntries()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	result, err := fileindex.Search(entries, query)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	return result, nil
+}
+
+// SetFileMetadata. set the description and tags of the indexed file, all kinds of the file are updated if kind is empty
+func (this *Endpoint) SetFileMetadata(kind, fileHash, description string, tags []string) ([]*fileindex.Entry, *DspErr) {
+	entries, derr := this.SyncFileIndex()
+	if derr != nil {
+		return nil, derr
+	}
+	this.fileIndexLock.Lock()
+	defer this.fileIndexLock.Unlock()
+	updated := make([]*fileindex.Entry, 0)
+	for _, e := range entries {
+		if e.FileHash != fileHash || (len(kind) > 0 && e.Kind != kind) {
+			continue
+		}
+		e.Description = description
+		e.Tags = normalizeTags(tags)
+		e.UpdatedAt = uint64(time.Now().Unix())
+		if err := this.db.PutFileIndexEntry(e); err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		updated = append(updated, e)
+	}
+	if len(updated) == 0 {
+		return nil, &DspErr{Code: DSP_FILE_NOT_EXISTS, Error: ErrMaps[DSP_FILE_NOT_EXISTS]}
+	}
+	return updated, nil
+}
+
+// SyncFileIndex. index the new uploaded and downloaded files, and remove the entries of files which are gone.
+// The mime type is sniffed once when the file is indexed or its path changes,
+// user metadata such as description and tags are kept.
+func (this *Endpoint) SyncFileIndex() ([]*fileindex.Entry, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	this.fileIndexLock.Lock()
+	defer this.fileIndexLock.Unlock()
+	old, err := this.db.GetAllFileIndexEntries()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	indexed := make(map[string]*fileindex.Entry, len(old))
+	for _, e := range old {
+		indexed[e.Kind+e.FileHash] = e
+	}
+	current := make([]*fileindex.Entry, 0, len(old))
+	uploads, err := dsp.GetUploadTaskInfos()
+	if err != nil {
+		return nil, &DspErr{Code: DB_GET_FILEINFO_FAILED, Error: err}
+	}
+	for _, info := range uploads {
+		if info == nil || len(info.FileHash) == 0 {
+			continue
+		}
+		size := info.RealFileSize
+		if size == 0 {
+			size = info.FileSize * 1024
+		}
+		current = append(current, &fileindex.Entry{
+			FileHash:  info.FileHash,
+			Kind:      fileindex.KIND_UPLOAD,
+			Name:      info.FileName,
+			Path:      info.FilePath,
+			Size:      size,
+			Owner:     dsp.WalletAddress(),
+			CreatedAt: info.CreatedAt / dspConsts.MILLISECOND_PER_SECOND,
+			UpdatedAt: info.UpdatedAt / dspConsts.MILLISECOND_PER_SECOND,
+		})
+	}
+	downloads, _, err := dsp.AllDownloadFiles()
+	if err != nil {
+		return nil, &DspErr{Code: DB_GET_FILEINFO_FAILED, Error: err}
+	}
+	for _, info := range downloads {
+		if info == nil || len(info.FileHash) == 0 {
+			continue
+		}
+		name := filepath.Base(info.FilePath)
+		if len(info.FilePath) == 0 {
+			name = info.FileName
+		}
+		filePrefix := &dspPrefix.FilePrefix{}
+		filePrefix.Deserialize(info.Prefix)
+		size := filePrefix.FileSize
+		if size == 0 {
+			size = info.TotalBlockCount * dspConsts.CHUNK_SIZE
+		}
+		current = append(current, &fileindex.Entry{
+			FileHash:  info.FileHash,
+			Kind:      fileindex.KIND_DOWNLOAD,
+			Name:      name,
+			Path:      info.FilePath,
+			Size:      size,
+			Owner:     info.FileOwner,
+			CreatedAt: info.CreatedAt / dspConsts.MILLISECOND_PER_SECOND,
+			UpdatedAt: info.UpdatedAt / dspConsts.MILLISECOND_PER_SECOND,
+		})
+	}
+	entries := make([]*fileindex.Entry, 0, len(current))
+	for _, e := range current {
+		key := e.Kind + e.FileHash
+		prev, ok := indexed[key]
+		delete(indexed, key)
+		if ok && prev.Path == e.Path && prev.Name == e.Name && prev.Size == e.Size {
+			entries = append(entries, prev)
+			continue
+		}
+		if stat, err := os.Stat(e.Path); err == nil && !stat.IsDir() {
+			e.Size = uint64(stat.Size())
+		}
+		e.Mime = fileindex.SniffMime(e.Path, e.Name)
+		e.Category = fileindex.Categorize(e.Name, e.Mime)
+		if ok {
+			e.Description, e.Tags = prev.Description, prev.Tags
+		} else if e.Kind == fileindex.KIND_UPLOAD {
+			if info, _ := dsp.GetFileInfo(e.FileHash); info != nil && string(info.FileDesc) != e.Name {
+				e.Description = string(info.FileDesc)
+			}
+		}
+		if err := this.db.PutFileIndexEntry(e); err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		entries = append(entries, e)
+	}
+	for _, e := range indexed {
+		log.Debugf("remove file index of %s %s", e.Kind, e.FileHash)
+		if err := this.db.DeleteFileIndexEntry(e.Kind, e.FileHash); err != nil {
+			log.Errorf("remove file index of %s err %s", e.FileHash, err)
+		}
+	}
+	return entries, nil
+}
+
+// fileIndexService. sync the metadata index in background, so searching files doesn't wait for it
+func (this *Endpoint) fileIndexService() {
+	if _, derr := this.SyncFileIndex(); derr != nil {
+		log.Errorf("sync file index err %s", derr.Error)
+	}
+	ti := time.NewTicker(time.Duration(common.FILE_INDEX_SYNC_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			if _, derr := this.SyncFileIndex(); derr != nil {
+				log.Errorf("sync file index err %s", derr.Error)
+			}
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+// normalizeTags. trim and deduplicate tags case insensitively
+func normalizeTags(tags []string) []string {
+	exist := make(map[string]struct{}, len(tags))
+	list := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if len(t) == 0 {
+			continue
+		}
+		if _, ok := exist[strings.ToLower(t)]; ok {
+			continue
+		}
+		exist[strings.ToLower(t)] = struct{}{}
+		list = append(list, t)
+	}
+	return list
+}
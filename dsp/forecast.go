@@ -0,0 +1,154 @@
+package dsp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils/costforecast"
+	"github.com/saveio/edge/utils/fileindex"
+	"github.com/saveio/themis/cmd/utils"
+	"github.com/saveio/themis/common/log"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+type CostForecastResp struct {
+	*costforecast.Forecast
+	TotalFormat     string
+	HorizonMonths   uint64
+	UserspacePeriod uint64
+	GasPrice        uint64
+	FsConfig        *FsContractSettingResp
+	Failed          []string `json:",omitempty"` // files whose renewal cost can't be calculated
+}
+
+// ForecastCost. project the renewal costs of uploaded files and userspace in the next months.
+// The files with own duration are renewed with the same duration, copy number and prove level,
+// priced by the current fs setting and gas price. The files in userspace are renewed with userspace,
+// which is extended by userspacePeriod seconds each time, so they cost nothing and aren't counted as renewals.
+// The trashed files are not renewed.
+func (this *Endpoint) ForecastCost(months, userspacePeriod uint64) (*CostForecastResp, *DspErr) {
+	if months == 0 {
+		months = common.COST_FORECAST_DEFAULT_MONTHS
+	}
+	if months > common.COST_FORECAST_MAX_MONTHS {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: fmt.Errorf("forecast months should be no more than %d",
+			common.COST_FORECAST_MAX_MONTHS)}
+	}
+	if userspacePeriod == 0 {
+		userspacePeriod = common.DEFAULT_FORECAST_SPACE_PERIOD
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	fsConfig, derr := this.GetFsConfig()
+	if derr != nil {
+		return nil, derr
+	}
+	currentHeight, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: CHAIN_GET_HEIGHT_FAILED, Error: err}
+	}
+	gasPrice, derr := this.GetGasPrice()
+	if derr != nil {
+		return nil, derr
+	}
+	entries, derr := this.SyncFileIndex()
+	if derr != nil {
+		return nil, derr
+	}
+	trashed := make(map[string]struct{})
+	if items, err := this.db.GetAllTrashItems(); err == nil {
+		for _, item := range items {
+			trashed[item.FileHash] = struct{}{}
+		}
+	}
+	resp := &CostForecastResp{
+		HorizonMonths:   months,
+		UserspacePeriod: userspacePeriod,
+		GasPrice:        gasPrice,
+		FsConfig:        fsConfig,
+		Failed:          make([]string, 0),
+	}
+	commitments := make([]*costforecast.Commitment, 0, len(entries)+1)
+	// the renewal costs of files with same size and options are the same
+	costs := make(map[string]uint64)
+	for _, e := range entries {
+		if e.Kind != fileindex.KIND_UPLOAD {
+			continue
+		}
+		if _, ok := trashed[e.FileHash]; ok {
+			continue
+		}
+		info, err := dsp.GetFileInfo(e.FileHash)
+		if err != nil || info == nil || info.ExpiredHeight <= uint64(currentHeight) {
+			continue
+		}
+		c := &costforecast.Commitment{
+			Id:        e.FileHash,
+			Name:      e.Name,
+			Kind:      costforecast.KIND_USERSPACE_FILE,
+			Tags:      e.Tags,
+			ExpiresAt: blockHeightToTimestamp(uint64(currentHeight), info.ExpiredHeight),
+		}
+		commitments = append(commitments, c)
+		if fs.FileStoreType(info.StorageType) == fs.FileStoreTypeNormal {
+			continue
+		}
+		c.Kind = costforecast.KIND_FILE
+		if info.ExpiredHeight > info.BlockHeight {
+			c.Period = (info.ExpiredHeight - info.BlockHeight) * config.BlockTime()
+		}
+		copyNum, proveLevel := info.CopyNum, info.ProveLevel
+		if copyNum == 0 {
+			copyNum = fsConfig.DefaultCopyNum
+		}
+		if proveLevel == 0 {
+			proveLevel = fsConfig.DefaultProveLevel
+		}
+		size := info.FileBlockNum * info.FileBlockSize
+		key := fmt.Sprintf("%d-%d-%d-%d-%d", size, c.Period, copyNum, proveLevel, info.StorageType)
+		cost, ok := costs[key]
+		if !ok && c.Period > 0 {
+			fee, derr := this.calculateUploadFeeOfSize(size, c.Period, proveLevel, copyNum, uint64(0), info.StorageType)
+			if derr != nil {
+				log.Errorf("calculate renewal fee of %s err %s", e.FileHash, derr.Error)
+				resp.Failed = append(resp.Failed, e.FileHash)
+				continue
+			}
+			cost = fee.TxFee + fee.StorageFee + fee.ValidFee
+			costs[key] = cost
+		}
+		c.Cost = cost
+	}
+	space, derr := this.GetUserSpace("")
+	if derr != nil {
+		return nil, derr
+	}
+	if space.ExpiredHeight > uint64(currentHeight) {
+		spaceCost, derr := this.GetUserSpaceCost(this.getDspWalletAddress(), 0, uint64(fs.UserSpaceNone),
+			userspacePeriod, uint64(fs.UserSpaceAdd))
+		if derr != nil {
+			return nil, derr
+		}
+		commitments = append(commitments, &costforecast.Commitment{
+			Id:        this.getDspWalletAddress(),
+			Name:      costforecast.KIND_USERSPACE,
+			Kind:      costforecast.KIND_USERSPACE,
+			ExpiresAt: space.ExpiredAt,
+			Period:    userspacePeriod,
+			Cost:      spaceCost.Fee,
+		})
+	}
+	now := time.Now()
+	forecast, err := costforecast.Project(commitments, uint64(now.Unix()),
+		uint64(now.AddDate(0, int(months), 0).Unix()), time.Local)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	resp.Forecast = forecast
+	resp.TotalFormat = utils.FormatUsdt(forecast.Total)
+	return resp, nil
+}
@@ -0,0 +1,249 @@
+package dsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/saveio/dsp-go-sdk/store"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/fileindex"
+	"github.com/saveio/edge/utils/transferhistory"
+	"github.com/saveio/themis/common/log"
+)
+
+type TransferHistoryResp struct {
+	Path     string // full path of archive in the history directory
+	Total    int    // records in archive
+	Imported int    `json:",omitempty"`
+	Skipped  int    `json:",omitempty"` // records whose task id exists
+	Invalid  int    `json:",omitempty"`
+}
+
+// ExportTransferHistory. write all tasks with their progress info and file metadata to a JSON Lines archive.
+// The archive is named by a relative path in the history directory of data dir.
+// The imported records are exported too, so the history can be moved between nodes many times.
+func (this *Endpoint) ExportTransferHistory(name string) (*TransferHistoryResp, *DspErr) {
+	path, err := transferhistory.ArchivePath(config.TransferHistoryDirPath(), name)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	defer f.Close()
+	w := transferhistory.NewWriter(f)
+	now := uint64(time.Now().Unix())
+	for _, taskType := range []store.TaskType{store.TaskTypeUpload, store.TaskTypeDownload} {
+		for offset := uint32(0); ; offset += common.TRANSFER_HISTORY_PAGE_SIZE {
+			ids := dsp.GetTaskIdList(offset, common.TRANSFER_HISTORY_PAGE_SIZE, 0, 0, 0, 0, taskType,
+				false, false, true, true)
+			newIds := 0
+			for _, id := range ids {
+				if w.Written(id) {
+					continue
+				}
+				newIds++
+				task := dsp.GetTaskInfo(id)
+				if task == nil {
+					continue
+				}
+				history := &db.TransferHistory{
+					Version:    common.TRANSFER_HISTORY_VERSION,
+					TaskId:     id,
+					Task:       task,
+					Progress:   dsp.GetProgressInfo(id),
+					ExportedAt: now,
+				}
+				kind := fileindex.KIND_DOWNLOAD
+				if task.Type == store.TaskTypeUpload {
+					kind = fileindex.KIND_UPLOAD
+				}
+				history.File, _ = this.db.GetFileIndexEntry(kind, task.FileHash)
+				if _, err := w.Write(id, history); err != nil {
+					return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+				}
+			}
+			if len(ids) < common.TRANSFER_HISTORY_PAGE_SIZE || newIds == 0 {
+				break
+			}
+		}
+	}
+	imported, err := this.db.GetAllTransferHistories()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	for _, history := range imported {
+		history.ExportedAt, history.ImportedAt = now, 0
+		if _, err := w.Write(history.TaskId, history); err != nil {
+			return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	resp := &TransferHistoryResp{Path: path, Total: w.Total}
+	log.Infof("export %d transfer history records to %s", resp.Total, path)
+	return resp, nil
+}
+
+// ImportTransferHistory. read the JSON Lines archive in the history directory and merge it into local history.
+// The task store of sdk can't add tasks, so the records are saved in edge db, and they are listed with
+// the local tasks of the same type and state by GetTransferList.
+// The records whose task id exists in local tasks or imported history are skipped.
+// The file metadata is merged into the index of local files without description and tags.
+func (this *Endpoint) ImportTransferHistory(name string) (*TransferHistoryResp, *DspErr) {
+	path, err := transferhistory.ArchivePath(config.TransferHistoryDirPath(), name)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	defer f.Close()
+	resp := &TransferHistoryResp{Path: path}
+	now := uint64(time.Now().Unix())
+	var derr *DspErr
+	err = transferhistory.Read(f, func(line []byte) error {
+		resp.Total++
+		if derr = this.importTransferHistory(line, now, resp); derr != nil {
+			return derr.Error
+		}
+		return nil
+	})
+	if derr != nil {
+		return nil, derr
+	}
+	if err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	log.Infof("import transfer history from %s, total %d, imported %d, skipped %d, invalid %d",
+		path, resp.Total, resp.Imported, resp.Skipped, resp.Invalid)
+	return resp, nil
+}
+
+func (this *Endpoint) importTransferHistory(line []byte, now uint64, resp *TransferHistoryResp) *DspErr {
+	history := &db.TransferHistory{}
+	if err := transferhistory.Decode(line, common.TRANSFER_HISTORY_VERSION, history); err != nil ||
+		history.Task == nil {
+		resp.Invalid++
+		return nil
+	}
+	if this.getDsp().GetTaskInfo(history.TaskId) != nil {
+		resp.Skipped++
+		return nil
+	}
+	old, err := this.db.GetTransferHistory(history.TaskId)
+	if err != nil {
+		return &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if old != nil {
+		resp.Skipped++
+		return nil
+	}
+	history.ImportedAt = now
+	if err := this.db.PutTransferHistory(history); err != nil {
+		return &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	resp.Imported++
+	if history.File == nil {
+		return nil
+	}
+	entry, err := this.db.GetFileIndexEntry(history.File.Kind, history.File.FileHash)
+	if err != nil || entry == nil || len(entry.Description) > 0 || len(entry.Tags) > 0 {
+		return nil
+	}
+	entry.Description, entry.Tags = history.File.Description, history.File.Tags
+	entry.UpdatedAt = now
+	if err := this.db.PutFileIndexEntry(entry); err != nil {
+		log.Errorf("merge file metadata of %s err %s", entry.FileHash, err)
+	}
+	return nil
+}
+
+// getImportedTransfers. get the transfers of imported history in the list of pType, sorted by created time descending.
+// The complete list only has the done tasks, the uploading and downloading lists have the tasks of the type.
+func (this *Endpoint) getImportedTransfers(pType TransferType, createdAt, createdAtEnd, updatedAt,
+	updatedAtEnd uint64) []*Transfer {
+	if this.db == nil {
+		return nil
+	}
+	histories, err := this.db.GetAllTransferHistories()
+	if err != nil {
+		log.Errorf("get transfer histories err %s", err)
+		return nil
+	}
+	transfers := make([]*Transfer, 0, len(histories))
+	for _, history := range histories {
+		if history.Progress == nil {
+			continue
+		}
+		switch pType {
+		case transferTypeComplete:
+			if history.Progress.TaskState != store.TaskStateDone {
+				continue
+			}
+		case transferTypeUploading:
+			if history.Progress.Type != store.TaskTypeUpload {
+				continue
+			}
+		case transferTypeDownloading:
+			if history.Progress.Type != store.TaskTypeDownload {
+				continue
+			}
+		}
+		t := this.getTransferDetail(pType, history.Progress)
+		if t == nil {
+			continue
+		}
+		if createdAt != 0 && createdAtEnd != 0 && (t.CreatedAt < createdAt || t.CreatedAt > createdAtEnd) {
+			continue
+		}
+		if updatedAt != 0 && updatedAtEnd != 0 && (t.UpdatedAt < updatedAt || t.UpdatedAt > updatedAtEnd) {
+			continue
+		}
+		transfers = append(transfers, t)
+	}
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].CreatedAt > transfers[j].CreatedAt
+	})
+	return transfers
+}
+
+// deleteImportedTransfer. delete the imported task record, return false if it's not imported
+func (this *Endpoint) deleteImportedTransfer(taskId string) (bool, error) {
+	if this.db == nil {
+		return false, nil
+	}
+	history, err := this.db.GetTransferHistory(taskId)
+	if err != nil || history == nil {
+		return false, err
+	}
+	if err := this.db.DeleteTransferHistory(taskId); err != nil {
+		return true, fmt.Errorf("delete imported transfer %s err %s", taskId, err)
+	}
+	return true, nil
+}
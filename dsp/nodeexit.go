@@ -0,0 +1,299 @@
+package dsp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils/nodeexit"
+	"github.com/saveio/themis/common/log"
+)
+
+// StartNodeExit. start the planned exit of node. The empty sectors are deleted and the volume of node is reduced
+// to the used space, or the total size of sectors if more, so no new file is stored. The node keeps proving its
+// files until they are handed off to other nodes or expired. The exit is saved before any tx, so it can be
+// cancelled even if it fails halfway.
+func (this *Endpoint) StartNodeExit(minReplicas int) (*nodeexit.Exit, *DspErr) {
+	this.nodeExitLock.Lock()
+	defer this.nodeExitLock.Unlock()
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	if minReplicas < 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	exit, err := this.db.GetNodeExit()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if exit != nil && exit.State == nodeexit.STATE_DRAINING {
+		return nil, &DspErr{Code: DSP_NODE_EXIT_INVALID, Error: errors.New("node exit has been started")}
+	}
+	walletAddr := dsp.WalletAddress()
+	info, err := dsp.QueryNode(walletAddr)
+	if err != nil || info == nil {
+		return nil, &DspErr{Code: DSP_NODE_EXIT_INVALID, Error: errors.New("node is not registered")}
+	}
+	sectorInfos, derr := this.GetSectorInfosForNode(walletAddr)
+	if derr != nil {
+		return nil, derr
+	}
+	now := uint64(time.Now().Unix())
+	exit = &nodeexit.Exit{
+		State:       nodeexit.STATE_DRAINING,
+		MinReplicas: minReplicas,
+		StallBlocks: common.DEFAULT_EXIT_STALL_PERIOD / config.BlockTime(),
+		OldVolume:   info.Volume,
+		Volume:      info.Volume,
+		Sectors:     make([]*nodeexit.Sector, 0),
+		Files:       make([]*nodeexit.File, 0),
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := this.db.PutNodeExit(exit); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+
+	sectorsSize := uint64(0)
+	for _, sector := range sectorInfos.Sectors {
+		if len(sector.FileList.List) > 0 {
+			sectorsSize += sector.Size
+			continue
+		}
+		if _, err := this.confirmTx(this.DeleteSector(sector.SectorID)); err != nil {
+			log.Errorf("node exit delete empty sector %d err %s", sector.SectorID, err)
+			sectorsSize += sector.Size
+			continue
+		}
+		exit.Sectors = append(exit.Sectors, &nodeexit.Sector{
+			SectorId:   sector.SectorID,
+			Size:       sector.Size,
+			ProveLevel: sector.ProveLevel,
+			IsPlots:    sector.IsPlots,
+		})
+		if err := this.db.PutNodeExit(exit); err != nil {
+			log.Errorf("save node exit err %s", err)
+		}
+	}
+
+	volume := nodeexit.Volume(info.Volume-info.RestVol, sectorsSize)
+	tx, err := this.confirmTx(this.NodeUpdate(string(info.NodeAddr), volume, info.ServiceTime))
+	if err != nil {
+		// the tx may be confirmed after the timeout
+		if newInfo, qerr := dsp.QueryNode(walletAddr); qerr != nil || newInfo == nil || newInfo.Volume != volume {
+			this.restoreNodeExitSectors(exit)
+			exit.State = nodeexit.STATE_CANCELLED
+			exit.Error = err.Error()
+			if perr := this.db.PutNodeExit(exit); perr != nil {
+				log.Errorf("save node exit err %s", perr)
+			}
+			return nil, &DspErr{Code: DSP_NODE_UPDATE_FAILED, Error: err}
+		}
+	}
+	exit.Volume = volume
+	exit.VolumeTx = tx
+	log.Infof("start node exit, volume %d => %d, tx %s", info.Volume, volume, tx)
+	if derr := this.updateNodeExit(exit); derr != nil {
+		log.Errorf("update node exit err %s", derr.Error)
+	}
+	// the exit saved before the tx can still be cancelled if it isn't updated
+	if err := this.db.PutNodeExit(exit); err != nil {
+		log.Errorf("save node exit err %s", err)
+	}
+	return exit, nil
+}
+
+// GetNodeExit. get the progress of node exit, nil if never started
+func (this *Endpoint) GetNodeExit() (*nodeexit.Exit, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	exit, err := this.db.GetNodeExit()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return exit, nil
+}
+
+// CancelNodeExit. cancel the draining exit, and restore the volume of node to accept new files
+func (this *Endpoint) CancelNodeExit() (*nodeexit.Exit, *DspErr) {
+	this.nodeExitLock.Lock()
+	defer this.nodeExitLock.Unlock()
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	exit, derr := this.GetNodeExit()
+	if derr != nil {
+		return nil, derr
+	}
+	if exit == nil || exit.State != nodeexit.STATE_DRAINING {
+		return nil, &DspErr{Code: DSP_NODE_EXIT_INVALID, Error: errors.New("node exit is not started")}
+	}
+	info, err := dsp.QueryNode(dsp.WalletAddress())
+	if err != nil || info == nil {
+		return nil, &DspErr{Code: DSP_NODE_QUERY_FAILED, Error: ErrMaps[DSP_NODE_QUERY_FAILED]}
+	}
+	tx, err := this.confirmTx(this.NodeUpdate(string(info.NodeAddr), exit.OldVolume, info.ServiceTime))
+	if err != nil {
+		return nil, &DspErr{Code: DSP_NODE_UPDATE_FAILED, Error: err}
+	}
+	log.Infof("cancel node exit, volume %d => %d, tx %s", info.Volume, exit.OldVolume, tx)
+	this.restoreNodeExitSectors(exit)
+	exit.State = nodeexit.STATE_CANCELLED
+	exit.VolumeTx = tx
+	exit.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutNodeExit(exit); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return exit, nil
+}
+
+// restoreNodeExitSectors. create the sectors deleted by the exit again, the failed ones are kept in exit
+func (this *Endpoint) restoreNodeExitSectors(exit *nodeexit.Exit) {
+	failed := make([]*nodeexit.Sector, 0)
+	for _, sector := range exit.Sectors {
+		if _, err := this.confirmTx(this.CreateSector(sector.SectorId, sector.ProveLevel, sector.Size,
+			sector.IsPlots)); err != nil {
+			log.Errorf("node exit create sector %d again err %s", sector.SectorId, err)
+			exit.Error = err.Error()
+			failed = append(failed, sector)
+		}
+	}
+	exit.Sectors = failed
+}
+
+// nodeExitService. check the files handoff of node exit periodically
+func (this *Endpoint) nodeExitService() {
+	ti := time.NewTicker(time.Duration(common.NODE_EXIT_CHECK_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			this.checkNodeExit()
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+// checkNodeExit. update the files handoff, withdraw profit and unregister the node when all files are handed off.
+// A failed step is retried at next check.
+func (this *Endpoint) checkNodeExit() {
+	this.nodeExitLock.Lock()
+	defer this.nodeExitLock.Unlock()
+	if this.db == nil {
+		return
+	}
+	exit, err := this.db.GetNodeExit()
+	if err != nil || exit == nil || exit.State != nodeexit.STATE_DRAINING {
+		return
+	}
+	defer func() {
+		exit.UpdatedAt = uint64(time.Now().Unix())
+		if err := this.db.PutNodeExit(exit); err != nil {
+			log.Errorf("save node exit err %s", err)
+		}
+	}()
+	if derr := this.updateNodeExit(exit); derr != nil {
+		exit.Error = derr.Error.Error()
+		return
+	}
+	if !exit.Drained() {
+		log.Debugf("node exit pending files %d", exit.Pending)
+		return
+	}
+	if err := this.finishNodeExit(exit); err != nil {
+		exit.Error = err.Error()
+		log.Errorf("finish node exit err %s", err)
+	}
+}
+
+// updateNodeExit. update handoff of the files stored by node
+func (this *Endpoint) updateNodeExit(exit *nodeexit.Exit) *DspErr {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return &DspErr{Code: CONTRACT_ERROR, Error: err}
+	}
+	sectors, derr := this.nodeSectors()
+	if derr != nil {
+		return derr
+	}
+	walletAddr := dsp.WalletAddress()
+	files := make([]*nodeexit.FileStatus, 0)
+	for _, sector := range sectors {
+		for _, file := range sector.Files {
+			if file.IsPlot {
+				files = append(files, &nodeexit.FileStatus{FileHash: file.FileHash, IsPlot: true})
+				continue
+			}
+			info, err := dsp.GetFileInfo(file.FileHash)
+			if err != nil || info == nil {
+				// file is deleted from chain
+				continue
+			}
+			status := &nodeexit.FileStatus{
+				FileHash:      file.FileHash,
+				Owner:         info.FileOwner.ToBase58(),
+				ExpiredHeight: info.ExpiredHeight,
+				CopyNum:       info.CopyNum,
+			}
+			if details, err := dsp.GetFileProveDetails(file.FileHash); err == nil && details != nil {
+				for _, detail := range details.ProveDetails {
+					if detail.WalletAddr.ToBase58() != walletAddr && detail.ProveTimes > 0 {
+						status.Replicas++
+					}
+				}
+			}
+			files = append(files, status)
+		}
+	}
+	exit.Update(files, uint64(height))
+	exit.Error = ""
+	for _, file := range exit.Files {
+		if file.Stalled {
+			log.Warnf("file %s of exiting node gains no replica since height %d, its owner %s should add copies",
+				file.FileHash, file.ProgressHeight, file.Owner)
+		}
+	}
+	return nil
+}
+
+// finishNodeExit. withdraw profit before the node info is deleted by unregister, then unregister the node
+func (this *Endpoint) finishNodeExit(exit *nodeexit.Exit) error {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return ErrMaps[NO_DSP]
+	}
+	if len(exit.WithdrawTx) == 0 {
+		info, err := dsp.QueryNode(dsp.WalletAddress())
+		if err != nil || info == nil {
+			return ErrMaps[DSP_NODE_QUERY_FAILED]
+		}
+		if info.Profit > 0 {
+			tx, err := this.confirmTx(this.NodeWithdrawProfit())
+			if err != nil {
+				return err
+			}
+			exit.WithdrawTx = tx
+			log.Infof("node exit withdraw profit %d, tx %s", info.Profit, tx)
+		}
+	}
+	tx, err := this.confirmTx(this.UnregisterNode())
+	if err != nil {
+		return err
+	}
+	exit.UnregisterTx = tx
+	exit.State = nodeexit.STATE_DONE
+	log.Infof("node exit unregister node, tx %s", tx)
+	return nil
+}
@@ -0,0 +1,284 @@
+package dsp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/utils/nodemonitor"
+	"github.com/saveio/edge/utils/sectorview"
+	"github.com/saveio/themis/common/log"
+)
+
+// NodeMonitorResp. node samples of a period with the earnings, and the problems found at last check
+type NodeMonitorResp struct {
+	Policy        *nodemonitor.Policy
+	Latest        *nodemonitor.Sample
+	Earnings      *nodemonitor.Earnings
+	MissedSectors []uint64
+	FailedFiles   []string
+	Samples       []*nodemonitor.Sample
+	Actions       []*nodemonitor.ActionLog
+}
+
+// SetNodeMonitorPolicy. validate and save the node monitor policy, it takes effect on the next check
+func (this *Endpoint) SetNodeMonitorPolicy(policy *nodemonitor.Policy) (*nodemonitor.Policy, *DspErr) {
+	if policy == nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, &DspErr{Code: DSP_NODE_MONITOR_INVALID, Error: err}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	policy.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutNodeMonitorPolicy(policy); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return policy, nil
+}
+
+func (this *Endpoint) GetNodeMonitorPolicy() (*nodemonitor.Policy, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	policy, err := this.db.GetNodeMonitorPolicy()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return policy, nil
+}
+
+// GetNodeMonitor. get the node samples and earnings of the last days, the latest first
+func (this *Endpoint) GetNodeMonitor(days uint64) (*NodeMonitorResp, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	if days == 0 {
+		days = common.NODE_MONITOR_DEFAULT_DAYS
+	}
+	policy, err := this.db.GetNodeMonitorPolicy()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	state, err := this.db.GetNodeMonitorState()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	samples, err := this.db.GetAllNodeMonitorSamples()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	actions, err := this.db.GetAllNodeMonitorActions()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	from := uint64(0)
+	if now := uint64(time.Now().Unix()); now > days*nodemonitor.SECONDS_OF_DAY {
+		from = now - days*nodemonitor.SECONDS_OF_DAY
+	}
+	resp := &NodeMonitorResp{
+		Policy:        policy,
+		Earnings:      nodemonitor.ComputeEarnings(samples, from),
+		MissedSectors: state.SectorsAlerted,
+		FailedFiles:   state.FilesAlerted,
+		Samples:       make([]*nodemonitor.Sample, 0),
+		Actions:       actions,
+	}
+	for _, sample := range samples {
+		if sample.CreatedAt >= from {
+			resp.Samples = append(resp.Samples, sample)
+		}
+	}
+	sort.Slice(resp.Samples, func(i, j int) bool {
+		return resp.Samples[i].CreatedAt > resp.Samples[j].CreatedAt
+	})
+	sort.Slice(resp.Actions, func(i, j int) bool {
+		return resp.Actions[i].CreatedAt > resp.Actions[j].CreatedAt
+	})
+	if len(resp.Samples) > 0 {
+		resp.Latest = resp.Samples[0]
+	}
+	return resp, nil
+}
+
+// nodeMonitorService. sample the node info and check the proofs of node periodically
+func (this *Endpoint) nodeMonitorService() {
+	ti := time.NewTicker(time.Duration(common.NODE_MONITOR_CHECK_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			this.checkNode()
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+func (this *Endpoint) checkNode() {
+	dsp := this.getDsp()
+	if dsp == nil || this.db == nil {
+		return
+	}
+	info, err := dsp.QueryNode(dsp.WalletAddress())
+	if err != nil || info == nil {
+		// not a storage node
+		return
+	}
+	policy, err := this.db.GetNodeMonitorPolicy()
+	if err != nil {
+		log.Errorf("get node monitor policy err %s", err)
+		return
+	}
+	snapshot, derr := this.nodeSnapshot()
+	if derr != nil {
+		log.Errorf("get node snapshot err %s", derr.Error)
+		return
+	}
+	now := time.Now()
+	sample := snapshot.Sample
+	sample.Pledge = info.Pledge
+	sample.Profit = info.Profit
+	sample.Volume = info.Volume
+	sample.RestVol = info.RestVol
+	sample.ServiceTime = info.ServiceTime
+	sample.CreatedAt = uint64(now.Unix())
+	if err := this.db.PutNodeMonitorSample(sample); err != nil {
+		log.Errorf("save node monitor sample err %s", err)
+	}
+	this.pruneNodeMonitorSamples()
+
+	state, err := this.db.GetNodeMonitorState()
+	if err != nil {
+		log.Errorf("get node monitor state err %s", err)
+		return
+	}
+	alerts, action := policy.Check(snapshot, state, now)
+	for _, alert := range alerts {
+		log.Warnf("node %s alert, sector %d, file %s, value %d", alert.Kind, alert.SectorId, alert.FileHash,
+			alert.Value)
+		go this.notifyNodeAlert(alert)
+	}
+	if action != nil {
+		state.LastActionAt = uint64(now.Unix())
+		actionLog := this.runNodeMonitorAction(string(info.NodeAddr), info.Volume, action, now)
+		go this.notifyNodeAction(actionLog)
+	}
+	if err := this.db.PutNodeMonitorState(state); err != nil {
+		log.Errorf("save node monitor state err %s", err)
+	}
+}
+
+// nodeSnapshot. missed proofs of sectors, and prove times of files stored by the node.
+// Plot files are proved with their sectors, only the files stored for others are checked.
+func (this *Endpoint) nodeSnapshot() (*nodemonitor.Snapshot, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: CONTRACT_ERROR, Error: err}
+	}
+	sectors, derr := this.nodeSectors()
+	if derr != nil {
+		return nil, derr
+	}
+	snapshot := &nodemonitor.Snapshot{
+		Sample:        &nodemonitor.Sample{Height: uint64(height)},
+		MissedSectors: make([]uint64, 0),
+		Files:         make([]*nodemonitor.FileProof, 0),
+	}
+	walletAddr := dsp.WalletAddress()
+	for _, sector := range sectorview.Summarize(sectors, uint64(height)).Sectors {
+		if sector.LastProof == sectorview.PROOF_MISSED {
+			snapshot.MissedSectors = append(snapshot.MissedSectors, sector.SectorId)
+		}
+		for _, file := range sector.Files {
+			snapshot.Sample.FileCount++
+			if file.IsPlot {
+				continue
+			}
+			info, err := dsp.GetFileInfo(file.FileHash)
+			if err != nil || info == nil {
+				continue
+			}
+			proof := &nodemonitor.FileProof{
+				FileHash:      file.FileHash,
+				ProveInterval: info.ProveInterval,
+				ExpiredHeight: info.ExpiredHeight,
+			}
+			if details, err := dsp.GetFileProveDetails(file.FileHash); err == nil && details != nil {
+				for _, detail := range details.ProveDetails {
+					if detail.WalletAddr.ToBase58() == walletAddr {
+						proof.ProveTimes = detail.ProveTimes
+					}
+				}
+			}
+			snapshot.Sample.ProveTimes += proof.ProveTimes
+			snapshot.Files = append(snapshot.Files, proof)
+		}
+	}
+	return snapshot, nil
+}
+
+// runNodeMonitorAction. extend the service time of node, all actions tried are kept in the action logs
+func (this *Endpoint) runNodeMonitorAction(nodeAddr string, volume uint64, action *nodemonitor.Action,
+	now time.Time) *nodemonitor.ActionLog {
+	actionLog := &nodemonitor.ActionLog{
+		Id:          fmt.Sprintf("%d", now.UnixNano()),
+		ServiceTime: action.ServiceTime,
+		AddPeriod:   action.AddPeriod,
+		CreatedAt:   uint64(now.Unix()),
+	}
+	defer func() {
+		if err := this.db.PutNodeMonitorAction(actionLog); err != nil {
+			log.Errorf("save node monitor action err %s", err)
+		}
+		this.pruneNodeMonitorActions()
+	}()
+	tx, derr := this.NodeUpdate(nodeAddr, volume, action.ServiceTime)
+	actionLog.Tx = tx
+	if derr != nil {
+		actionLog.Error = derr.Error.Error()
+		log.Errorf("node monitor extend service time err %s", derr.Error)
+		return actionLog
+	}
+	log.Infof("node monitor extend service time to %d, tx %s", action.ServiceTime, tx)
+	return actionLog
+}
+
+// pruneNodeMonitorSamples. keep the latest samples
+func (this *Endpoint) pruneNodeMonitorSamples() {
+	samples, err := this.db.GetAllNodeMonitorSamples()
+	if err != nil || len(samples) <= common.MAX_NODE_MONITOR_SAMPLES {
+		return
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].CreatedAt > samples[j].CreatedAt
+	})
+	for _, sample := range samples[common.MAX_NODE_MONITOR_SAMPLES:] {
+		if err := this.db.DeleteNodeMonitorSample(sample.CreatedAt); err != nil {
+			log.Errorf("delete node monitor sample %d err %s", sample.CreatedAt, err)
+		}
+	}
+}
+
+// pruneNodeMonitorActions. keep the latest action logs
+func (this *Endpoint) pruneNodeMonitorActions() {
+	actions, err := this.db.GetAllNodeMonitorActions()
+	if err != nil || len(actions) <= common.MAX_NODE_MONITOR_ACTION_LOGS {
+		return
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].CreatedAt > actions[j].CreatedAt
+	})
+	for _, action := range actions[common.MAX_NODE_MONITOR_ACTION_LOGS:] {
+		if err := this.db.DeleteNodeMonitorAction(action.Id); err != nil {
+			log.Errorf("delete node monitor action %s err %s", action.Id, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dsp
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils/nodeexit"
+	"github.com/saveio/edge/utils/noderesize"
+	"github.com/saveio/edge/utils/plot"
+	"github.com/saveio/themis/common/log"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+// VolumeResizeResp. the checked plan and the tx of node update
+type VolumeResizeResp struct {
+	Plan *noderesize.Plan
+	Tx   string
+}
+
+// CheckVolumeResize. check the new volume with node info, sectors, free disk of FsRepoRoot and FsMaxStorage,
+// nothing is changed. The new data directories are reported as an error of plan, the fs store can't use them.
+func (this *Endpoint) CheckVolumeResize(volume uint64, dataDirs []string) (*noderesize.Plan, *DspErr) {
+	plan, _, derr := this.volumeResizePlan(volume, dataDirs)
+	return plan, derr
+}
+
+// ResizeVolume. update the node volume on chain if the check passed, the node keeps running with its config.
+// Growing onto new data directories is rejected. It's not run with node exit at the same time.
+func (this *Endpoint) ResizeVolume(volume uint64, dataDirs []string) (*VolumeResizeResp, *DspErr) {
+	this.nodeExitLock.Lock()
+	defer this.nodeExitLock.Unlock()
+	plan, info, derr := this.volumeResizePlan(volume, dataDirs)
+	if derr != nil {
+		return nil, derr
+	}
+	if len(plan.Errors) > 0 {
+		return nil, &DspErr{Code: DSP_NODE_RESIZE_INVALID, Error: errors.New(strings.Join(plan.Errors, ", "))}
+	}
+	tx, err := this.confirmTx(this.NodeUpdate(string(info.NodeAddr), volume, info.ServiceTime))
+	if err != nil {
+		return nil, &DspErr{Code: DSP_NODE_UPDATE_FAILED, Error: err}
+	}
+	log.Infof("resize node volume %d => %d, tx %s", plan.OldVolume, volume, tx)
+	return &VolumeResizeResp{Plan: plan, Tx: tx}, nil
+}
+
+func (this *Endpoint) volumeResizePlan(volume uint64, dataDirs []string) (*noderesize.Plan, *fs.FsNodeInfo,
+	*DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	walletAddr := dsp.WalletAddress()
+	info, err := dsp.QueryNode(walletAddr)
+	if err != nil || info == nil {
+		return nil, nil, &DspErr{Code: DSP_NODE_RESIZE_INVALID, Error: errors.New("node is not registered")}
+	}
+	if this.db != nil {
+		if exit, err := this.db.GetNodeExit(); err == nil && exit != nil && exit.State == nodeexit.STATE_DRAINING {
+			return nil, nil, &DspErr{Code: DSP_NODE_RESIZE_INVALID, Error: errors.New("node is exiting")}
+		}
+	}
+	fsConfig, derr := this.GetFsConfig()
+	if derr != nil {
+		return nil, nil, derr
+	}
+	sectorInfos, derr := this.GetSectorInfosForNode(walletAddr)
+	if derr != nil {
+		return nil, nil, derr
+	}
+	node := &noderesize.Node{
+		Volume:     info.Volume,
+		RestVol:    info.RestVol,
+		MinVolume:  fsConfig.MinVolume,
+		MaxStorage: parseMaxStorage(config.Parameters.FsConfig.FsMaxStorage),
+		DataDirs:   dataDirs,
+	}
+	for _, sector := range sectorInfos.Sectors {
+		node.SectorsSize += sector.Size
+	}
+	// the fs store only writes to FsRepoRoot
+	free, err := plot.FreeSpace(config.FsRepoRootPath())
+	if err != nil {
+		return nil, nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	node.DiskFree = free / 1024
+	return noderesize.MakePlan(node, volume), info, nil
+}
@@ -0,0 +1,193 @@
+package dsp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dspActorClient "github.com/saveio/dsp-go-sdk/actor/client"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils/nodesetup"
+	"github.com/saveio/edge/utils/plot"
+	"github.com/saveio/themis/common/log"
+)
+
+// NodeSetupResp. steps run by node setup, the finished steps are rolled back if the setup failed
+type NodeSetupResp struct {
+	Plan    *nodesetup.Plan
+	Steps   []*nodesetup.StepResult
+	Success bool
+}
+
+// CheckNodeSetup. check the machine and plan the setup of storage node, nothing is changed
+func (this *Endpoint) CheckNodeSetup(opts *nodesetup.Options) (*nodesetup.Plan, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	fsConfig, derr := this.GetFsConfig()
+	if derr != nil {
+		return nil, derr
+	}
+	if opts.ProveLevel == 0 {
+		opts.ProveLevel = fsConfig.DefaultProveLevel
+	}
+	if opts.ServiceTime == 0 {
+		opts.ServiceTime = uint64(time.Now().Unix()) + common.DEFAULT_NODE_SERVICE_PERIOD
+	}
+	walletAddr := dsp.WalletAddress()
+	machine := &nodesetup.Machine{
+		MaxStorage: parseMaxStorage(config.Parameters.FsConfig.FsMaxStorage),
+		PublicAddr: dspActorClient.P2PGetPublicAddr(),
+		HasChannel: dsp.HasDNS(),
+		SectorIds:  make([]uint64, 0),
+	}
+	free, err := plot.FreeSpace(config.FsRepoRootPath())
+	if err != nil {
+		free, err = plot.FreeSpace(config.Parameters.BaseConfig.BaseDir)
+	}
+	if err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	machine.DiskFree = free / 1024
+	machine.ExternalAddr, _ = this.GetExternalIP(walletAddr)
+	balance, err := dsp.BalanceOf(dsp.Address())
+	if err != nil {
+		return nil, &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	machine.Balance = balance
+	if info, err := dsp.QueryNode(walletAddr); err == nil && info != nil {
+		machine.Registered = true
+	}
+	if sectorInfos, derr := this.GetSectorInfosForNode(walletAddr); derr == nil && sectorInfos != nil {
+		for _, sector := range sectorInfos.Sectors {
+			machine.SectorIds = append(machine.SectorIds, sector.SectorID)
+		}
+	}
+	return nodesetup.MakePlan(machine, &nodesetup.Setting{
+		MinVolume:        fsConfig.MinVolume,
+		FsGasPrice:       fsConfig.FsGasPrice,
+		GasPerGBPerBlock: fsConfig.GasPerGBPerBlock,
+	}, opts), nil
+}
+
+// SetupNode. register the node, create sectors and open channel to dns in order,
+// each transaction is confirmed before the next step. The finished steps are rolled back if a step failed.
+func (this *Endpoint) SetupNode(opts *nodesetup.Options) (*NodeSetupResp, *DspErr) {
+	this.nodeSetupLock.Lock()
+	defer this.nodeSetupLock.Unlock()
+	plan, derr := this.CheckNodeSetup(opts)
+	if derr != nil {
+		return nil, derr
+	}
+	if len(plan.Errors) > 0 {
+		return nil, &DspErr{Code: DSP_NODE_SETUP_INVALID, Error: errors.New(strings.Join(plan.Errors, ", "))}
+	}
+	steps := []*nodesetup.Step{
+		{
+			Name: nodesetup.STEP_REGISTER_NODE,
+			Run: func() (string, error) {
+				return this.confirmTx(this.RegisterNode(plan.NodeAddr, plan.Volume, plan.ServiceTime))
+			},
+			Rollback: func() (string, error) {
+				return this.confirmTx(this.UnregisterNode())
+			},
+		},
+	}
+	for _, sector := range plan.Sectors {
+		sector := sector
+		steps = append(steps, &nodesetup.Step{
+			Name: fmt.Sprintf("%s %d", nodesetup.STEP_CREATE_SECTOR, sector.SectorId),
+			Run: func() (string, error) {
+				return this.confirmTx(this.CreateSector(sector.SectorId, sector.ProveLevel, sector.Size, false))
+			},
+			Rollback: func() (string, error) {
+				return this.confirmTx(this.DeleteSector(sector.SectorId))
+			},
+		})
+	}
+	if plan.ChannelAmount > 0 {
+		steps = append(steps, this.openChannelStep(plan.ChannelAmount))
+	}
+	results, success := nodesetup.Run(steps)
+	resp := &NodeSetupResp{
+		Plan:    plan,
+		Steps:   results,
+		Success: success,
+	}
+	if !success {
+		log.Errorf("setup node failed, steps %v", results)
+	}
+	return resp, nil
+}
+
+// openChannelStep. open channel to the first online dns
+func (this *Endpoint) openChannelStep(amount uint64) *nodesetup.Step {
+	partner := ""
+	return &nodesetup.Step{
+		Name: nodesetup.STEP_OPEN_CHANNEL,
+		Run: func() (string, error) {
+			dsp := this.getDsp()
+			if dsp == nil {
+				return "", ErrMaps[NO_DSP]
+			}
+			addrs := make([]string, 0)
+			for addr := range dsp.GetAllOnlineDNS() {
+				addrs = append(addrs, addr)
+			}
+			if len(addrs) == 0 {
+				return "", ErrMaps[NO_DNS]
+			}
+			sort.Strings(addrs)
+			partner = addrs[0]
+			id, derr := this.OpenPaymentChannel(partner, amount)
+			if derr != nil {
+				return "", derr.Error
+			}
+			return fmt.Sprintf("%d", id), nil
+		},
+		Rollback: func() (string, error) {
+			if derr := this.ClosePaymentChannel(partner); derr != nil {
+				return "", derr.Error
+			}
+			return "", nil
+		},
+	}
+}
+
+// confirmTx. wait for the tx confirmed
+func (this *Endpoint) confirmTx(tx string, derr *DspErr) (string, error) {
+	if derr != nil {
+		return tx, derr.Error
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return tx, ErrMaps[NO_DSP]
+	}
+	if _, err := dsp.PollForTxConfirmed(time.Duration(common.POLL_TX_COMFIRMED_TIMEOUT)*time.Second, tx); err != nil {
+		return tx, err
+	}
+	return tx, nil
+}
+
+// parseMaxStorage. parse FsMaxStorage like "1T", "500G" to KB, 0 if invalid
+func parseMaxStorage(value string) uint64 {
+	value = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B")
+	units := map[string]uint64{"K": 1, "M": 1024, "G": 1024 * 1024, "T": 1024 * 1024 * 1024}
+	unit := uint64(1)
+	if len(value) > 0 {
+		if u, ok := units[value[len(value)-1:]]; ok {
+			unit = u
+			value = value[:len(value)-1]
+		}
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v * unit
+}
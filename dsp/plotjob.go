@@ -0,0 +1,189 @@
+package dsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/saveio/dsp-go-sdk/task/poc"
+	dspOS "github.com/saveio/dsp-go-sdk/utils/os"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/dsp/actor/client"
+	"github.com/saveio/edge/utils/plot"
+	"github.com/saveio/edge/utils/plotjob"
+	"github.com/saveio/themis/common/log"
+)
+
+// PlotJobsResp. plot jobs and their limits
+type PlotJobsResp struct {
+	Limits plotjob.Limits
+	Jobs   []plotjob.Job
+}
+
+// plotJobLimits. limits of plot jobs from config, min free space is configured in MB
+func plotJobLimits() plotjob.Limits {
+	return plotjob.Limits{
+		MaxJobs:      config.Parameters.BaseConfig.PlotJobs,
+		Threads:      config.Parameters.BaseConfig.PlotThreads,
+		MinFreeSpace: config.Parameters.BaseConfig.PlotMinFreeSpace * 1024 * 1024,
+	}
+}
+
+// newPlotJobManager. the poc task of a failed job is marked failed, including the jobs failed before started
+func (this *Endpoint) newPlotJobManager() *plotjob.Manager {
+	return plotjob.NewManager(plotJobLimits(), plot.FreeSpace, func(job plotjob.Job) {
+		if job.State == plotjob.STATE_FAILED {
+			if dsp := this.getDsp(); dsp != nil {
+				dsp.SetPocTaskFailed(job.Id, job.Error)
+			}
+		}
+		go client.EventNotifyPlotJob(&job)
+	})
+}
+
+// submitPlotJob. plot the file and generate its pdp data in a job of poc task,
+// onPlotted is called in the job after the pdp data generated if it's not nil.
+// The plot path is created here, so the free space of its disk can be checked before the job starts.
+func (this *Endpoint) submitPlotJob(taskId string, cfg *plot.PlotConfig, onPlotted func() error) *DspErr {
+	if err := dspOS.CreateDirIfNeed(cfg.Path); err != nil {
+		return NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	size := plot.GetPlotFileSize(cfg)
+	name := plot.GetPlotFileName(cfg)
+	_, err := this.plotJobs.Submit(taskId, cfg.Path, name, size, func(ctx context.Context, threads uint64,
+		progress func(float64)) error {
+		dsp := this.getDsp()
+		if dsp == nil {
+			return ErrMaps[NO_DSP]
+		}
+		jobCfg := *cfg
+		jobCfg.Threads = threads
+		jobCfg.Native = config.Parameters.BaseConfig.PlotNative
+		cfgData, _ := json.Marshal(jobCfg)
+		log.Infof("plot task %s start with config %s", taskId, cfgData)
+		err := plot.PlotWithContext(ctx, &jobCfg, func(p plot.Progress) {
+			progress(p.Percent())
+		})
+		if ctx.Err() != nil {
+			log.Infof("plot task %s is canceled", taskId)
+			return ctx.Err()
+		}
+		if err != nil {
+			log.Errorf("plot task %s with cfg %v err %s", taskId, cfg, err)
+			return err
+		}
+		err = dsp.GenPlotPDPData(taskId, &poc.PlotConfig{
+			Sys:        cfg.Sys,
+			NumericID:  cfg.NumericID,
+			StartNonce: cfg.StartNonce,
+			Nonces:     cfg.Nonces,
+			Path:       cfg.Path,
+		})
+		if err != nil {
+			log.Errorf("generate new plot file err %s", err)
+			return err
+		}
+		if onPlotted != nil {
+			return onPlotted()
+		}
+		return nil
+	})
+	if err == plotjob.ErrJobExists {
+		log.Debugf("plot task %s is running", taskId)
+		return nil
+	}
+	if err != nil {
+		return NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	return nil
+}
+
+// GetPlotJobs. get all plot jobs with their progress
+func (this *Endpoint) GetPlotJobs() *PlotJobsResp {
+	return &PlotJobsResp{
+		Limits: this.plotJobs.Limits(),
+		Jobs:   this.plotJobs.Jobs(),
+	}
+}
+
+// CancelPlotJob. cancel a pending or running plot job, remove the partial plot file and its poc task
+func (this *Endpoint) CancelPlotJob(taskId string) (*plotjob.Job, *DspErr) {
+	job, err := this.plotJobs.Cancel(taskId)
+	switch err {
+	case nil:
+	case plotjob.ErrJobNotFound:
+		return nil, NewDspErr(DSP_TASK_PLOT_NOT_FOUND)
+	case plotjob.ErrJobFinished:
+		return nil, NewDspErr(DSP_TASK_PLOT_FINISHED)
+	default:
+		return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	// the partial plot file of running job is removed when the plot tool is killed
+	dsp := this.getDsp()
+	if dsp == nil {
+		return &job, nil
+	}
+	if err := dsp.DeletePocTask(taskId); err != nil {
+		log.Errorf("delete poc task %s of canceled plot job err %s", taskId, err)
+		return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	return &job, nil
+}
+
+// SetPlotJobLimits. update and save the limits of plot jobs, min free space is in MB
+func (this *Endpoint) SetPlotJobLimits(maxJobs int, threads, minFreeSpace uint64) (*plotjob.Limits, *DspErr) {
+	if maxJobs <= 0 {
+		return nil, NewDspErr(INVALID_PARAMS, fmt.Errorf("invalid max plot jobs %d", maxJobs))
+	}
+	config.Parameters.BaseConfig.PlotJobs = maxJobs
+	config.Parameters.BaseConfig.PlotThreads = threads
+	config.Parameters.BaseConfig.PlotMinFreeSpace = minFreeSpace
+	if err := config.Save(); err != nil {
+		return nil, NewDspErr(INTERNAL_ERROR, fmt.Errorf("save config err %s", err))
+	}
+	limits := plotJobLimits()
+	this.plotJobs.SetLimits(limits)
+	return &limits, nil
+}
+
+// attachPlotJobs. attach the plot job to the poc task with the same task id.
+// The tasks are converted to json values, the original tasks are returned if it fails.
+func (this *Endpoint) attachPlotJobs(tasks interface{}) interface{} {
+	jobs := this.plotJobs.Jobs()
+	if len(jobs) == 0 {
+		return tasks
+	}
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		return tasks
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return tasks
+	}
+	jobMap := make(map[string]plotjob.Job, len(jobs))
+	for _, job := range jobs {
+		jobMap[job.Id] = job
+	}
+	var attach func(v interface{})
+	attach = func(v interface{}) {
+		switch item := v.(type) {
+		case []interface{}:
+			for _, elem := range item {
+				attach(elem)
+			}
+		case map[string]interface{}:
+			if taskId, ok := item["TaskId"].(string); ok {
+				if job, ok := jobMap[taskId]; ok {
+					item["PlotJob"] = job
+					return
+				}
+			}
+			for _, elem := range item {
+				attach(elem)
+			}
+		}
+	}
+	attach(value)
+	return value
+}
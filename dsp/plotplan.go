@@ -0,0 +1,207 @@
+package dsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/saveio/dsp-go-sdk/task/poc"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils"
+	"github.com/saveio/edge/utils/plot"
+	"github.com/saveio/edge/utils/plotjob"
+	"github.com/saveio/themis/common/log"
+)
+
+// PlanPlotFiles. plan plot files of capacity MB in all plot directories with continuous nonces,
+// each file has at most fileSize KB. Use all capacity of the directories if capacity is 0.
+// The capacity is in MB as the capacity of PlotDirs in config, the file size is in KB as other plot apis.
+func (this *Endpoint) PlanPlotFiles(capacity, fileSize uint64) (*plot.PlotPlan, *DspErr) {
+	acc, derr := this.GetCurrentAccount()
+	if derr != nil {
+		return nil, derr
+	}
+	numericID := fmt.Sprintf("%v", utils.WalletAddressToId([]byte(acc.Address)))
+	return this.makePlotPlan(numericID, capacity, fileSize)
+}
+
+// StartPlotPlan. plan plot files and submit plot jobs of them, the plot files are added to mine
+// after plotted, sectors are created if space not enough when createSector is true.
+// If a job can't be submitted, the jobs submitted before are canceled with their poc tasks.
+func (this *Endpoint) StartPlotPlan(capacity, fileSize uint64, createSector bool) (*plot.PlotPlan, *DspErr) {
+	acc, derr := this.GetCurrentAccount()
+	if derr != nil {
+		return nil, derr
+	}
+	numericID := fmt.Sprintf("%v", utils.WalletAddressToId([]byte(acc.Address)))
+
+	this.plotPlanLock.Lock()
+	defer this.plotPlanLock.Unlock()
+	plan, derr := this.makePlotPlan(numericID, capacity, fileSize)
+	if derr != nil {
+		return nil, derr
+	}
+	system := plotSystem()
+	submitted := make([]string, 0, len(plan.Files))
+	for _, file := range plan.Files {
+		cfg := &plot.PlotConfig{
+			Sys:        system,
+			NumericID:  numericID,
+			StartNonce: file.StartNonce,
+			Nonces:     file.Nonces,
+			Path:       file.Path,
+		}
+		taskId, derr := this.NewPocTask(cfg)
+		if derr != nil {
+			this.cancelPlannedPlotJobs(submitted)
+			return nil, derr
+		}
+		if derr := this.submitPlotJob(taskId, cfg, func() error {
+			return this.addPlannedPlotFile(taskId, cfg, createSector)
+		}); derr != nil {
+			this.cancelPlannedPlotJobs(append(submitted, taskId))
+			return nil, derr
+		}
+		submitted = append(submitted, taskId)
+		file.TaskId = taskId
+		log.Infof("plot task %s planned for %s in %s", taskId, file.Name, file.Path)
+	}
+	return plan, nil
+}
+
+// cancelPlannedPlotJobs. cancel the plot jobs of a plan which fails to start, and delete their poc tasks
+func (this *Endpoint) cancelPlannedPlotJobs(taskIds []string) {
+	dsp := this.getDsp()
+	for _, taskId := range taskIds {
+		if _, err := this.plotJobs.Cancel(taskId); err != nil && err != plotjob.ErrJobNotFound &&
+			err != plotjob.ErrJobFinished {
+			log.Errorf("cancel planned plot job %s err %s", taskId, err)
+		}
+		if dsp == nil {
+			continue
+		}
+		if err := dsp.DeletePocTask(taskId); err != nil {
+			log.Errorf("delete poc task %s of planned plot job err %s", taskId, err)
+		}
+	}
+}
+
+// addPlannedPlotFile. add the plotted file to mine
+func (this *Endpoint) addPlannedPlotFile(taskId string, cfg *plot.PlotConfig, createSector bool) error {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return ErrMaps[NO_DSP]
+	}
+	_, err := dsp.AddNewPlotFile(taskId, createSector, &poc.PlotConfig{
+		Sys:        cfg.Sys,
+		NumericID:  cfg.NumericID,
+		StartNonce: cfg.StartNonce,
+		Nonces:     cfg.Nonces,
+		Path:       cfg.Path,
+	})
+	if err != nil {
+		log.Errorf("add planned plot file of task %s err %s", taskId, err)
+		return err
+	}
+	return nil
+}
+
+// makePlotPlan. plan plot files after the nonces of existing plot files and unfinished plot jobs,
+// the space of unfinished plot jobs are reserved in their directories.
+func (this *Endpoint) makePlotPlan(numericID string, capacity, fileSize uint64) (*plot.PlotPlan, *DspErr) {
+	if fileSize == 0 {
+		fileSize = common.DEFAULT_PLOT_FILE_SIZE
+	}
+	startNonce, err := plot.GetMinStartNonceOfPaths(numericID, config.PlotDirPaths())
+	if err != nil {
+		return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	jobs := this.plotJobs.Jobs()
+	for _, job := range jobs {
+		if job.Finished() {
+			continue
+		}
+		file, err := plot.ParsePlotFileName(job.Name)
+		if err != nil || file.NumericID != numericID {
+			continue
+		}
+		if end := file.StartNonce + file.Nonces; end > startNonce {
+			startNonce = end
+		}
+	}
+
+	dirs := make([]plot.PlotDir, 0)
+	for _, dir := range config.PlotDirs() {
+		used, err := plot.GetPlotFilesSize(numericID, dir.Path)
+		if err != nil {
+			return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+		}
+		free, err := plot.FreeSpace(dir.Path)
+		if err != nil {
+			return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+		}
+		for _, job := range jobs {
+			if job.Finished() || filepath.Clean(job.Path) != filepath.Clean(dir.Path) {
+				continue
+			}
+			// the file of running job may be counted
+			if info, err := os.Stat(filepath.Join(job.Path, job.Name)); err == nil && uint64(info.Size()) <= used {
+				used -= uint64(info.Size())
+			}
+			used += job.Size
+			left := job.Size - uint64(float64(job.Size)*job.Percent/100)
+			if free > left {
+				free -= left
+			} else {
+				free = 0
+			}
+		}
+		dirs = append(dirs, plot.PlotDir{
+			Path:     dir.Path,
+			Capacity: dir.Capacity * 1024 * 1024,
+			Used:     used,
+			Free:     free,
+		})
+	}
+
+	minFree := config.Parameters.BaseConfig.PlotMinFreeSpace * 1024 * 1024
+	plan, err := plot.MakePlotPlan(numericID, dirs, startNonce, capacity*1024*1024, fileSize*1024, minFree)
+	if err == plot.ErrNoCapacity {
+		return nil, NewDspErr(DSP_TASK_PLOT_NO_SPACE, fmt.Errorf("%s, %d MB available", err, plan.Size/1024/1024))
+	}
+	if err != nil {
+		return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	return plan, nil
+}
+
+// plotFileFullPath. full path of the plot file in plot directories, the file is in PlotPath if not found
+func plotFileFullPath(fileName string) string {
+	if filepath.IsAbs(fileName) {
+		if _, err := os.Stat(fileName); err == nil {
+			return fileName
+		}
+	}
+	baseName := filepath.Base(fileName)
+	for _, path := range config.PlotDirPaths() {
+		if _, err := os.Stat(filepath.Join(path, baseName)); err == nil {
+			return filepath.Join(path, baseName)
+		}
+	}
+	return filepath.Join(config.PlotPath(), baseName)
+}
+
+// plotSystem. system name of plot tool for current os
+func plotSystem() string {
+	system := runtime.GOOS
+	if strings.Contains(system, plot.SYS_WIN) {
+		return plot.SYS_WIN
+	}
+	if !strings.Contains(system, "darwin") {
+		return plot.SYS_LINUX
+	}
+	return system
+}
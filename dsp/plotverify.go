@@ -0,0 +1,40 @@
+package dsp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils"
+	"github.com/saveio/edge/utils/plot"
+)
+
+// VerifyPlotFiles. verify plot files of current account in path, use all plot directories if path is empty.
+// samples nonces of each file are regenerated to compare with the file, 0 for only checking the file sizes.
+func (this *Endpoint) VerifyPlotFiles(path string, samples uint64) (*plot.VerifyReport, *DspErr) {
+	acc, derr := this.GetCurrentAccount()
+	if derr != nil {
+		return nil, derr
+	}
+	numericID := fmt.Sprintf("%v", utils.WalletAddressToId([]byte(acc.Address)))
+	paths := []string{path}
+	if len(path) == 0 {
+		paths = config.PlotDirPaths()
+	}
+	report, err := plot.VerifyPlots(context.Background(), numericID, paths, int(samples))
+	if err != nil {
+		return nil, NewDspErr(DSP_TASK_POC_ERROR, err)
+	}
+	return report, nil
+}
+
+// checkPlotFile. check the plot file is completed before mining with it
+func checkPlotFile(fullPath string) *DspErr {
+	report := plot.VerifyFile(context.Background(), fullPath, 0)
+	if report.Valid {
+		return nil
+	}
+	return NewDspErr(DSP_TASK_PLOT_INVALID, fmt.Errorf("plot file %s is invalid: %s",
+		report.Name, strings.Join(report.Errors, ", ")))
+}
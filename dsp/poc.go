@@ -40,6 +40,8 @@ func (this *Endpoint) NewPocTask(cfg *plot.PlotConfig) (string, *DspErr) {
 	return taskId, nil
 }
 
+// GenPlotPDPData. submit a plot job to plot the file and generate its pdp data,
+// the job is started when the limits of plot jobs allowed.
 func (this *Endpoint) GenPlotPDPData(taskId string, cfg *plot.PlotConfig) *DspErr {
 
 	dsp := this.getDsp()
@@ -48,27 +50,7 @@ func (this *Endpoint) GenPlotPDPData(taskId string, cfg *plot.PlotConfig) *DspEr
 	}
 	cfgData, _ := json.Marshal(cfg)
 	log.Infof("plot config cfg with no size %s", cfgData)
-	// var err error
-	err := plot.Plot(cfg)
-	if err != nil {
-		dsp.SetPocTaskFailed(taskId, err.Error())
-		log.Errorf("plot task %s with cfg %v err %s", taskId, cfg, err)
-		return NewDspErr(DSP_TASK_POC_ERROR)
-	}
-
-	err = dsp.GenPlotPDPData(taskId, &poc.PlotConfig{
-		Sys:        cfg.Sys,
-		NumericID:  cfg.NumericID,
-		StartNonce: cfg.StartNonce,
-		Nonces:     cfg.Nonces,
-		Path:       cfg.Path,
-	})
-	if err != nil {
-		log.Errorf("generate new plot file err %s", err)
-		return NewDspErr(DSP_TASK_POC_ERROR, err)
-	}
-
-	return nil
+	return this.submitPlotJob(taskId, cfg, nil)
 }
 
 func (this *Endpoint) AddPlotFile(taskId, fileName string, createSector bool) (interface{}, *DspErr) {
@@ -94,13 +76,17 @@ func (this *Endpoint) AddPlotFile(taskId, fileName string, createSector bool) (i
 	if nonce == 0 {
 		return nil, &DspErr{Code: DSP_TASK_POC_WRONG_NONCE, Error: fmt.Errorf("wrong start nonce or nonces")}
 	}
+	plotDir := filepath.Dir(plotFileFullPath(fileName))
+	if derr := checkPlotFile(filepath.Join(plotDir, fileBaseName)); derr != nil {
+		return nil, derr
+	}
 
 	cfg := &poc.PlotConfig{
 		Sys:        system,
 		NumericID:  numericID,
 		StartNonce: startNonce,
 		Nonces:     nonce,
-		Path:       config.PlotPath(),
+		Path:       plotDir,
 	}
 
 	dsp := this.getDsp()
@@ -116,7 +102,19 @@ func (this *Endpoint) AddPlotFile(taskId, fileName string, createSector bool) (i
 	return resp, nil
 }
 
+// AddPlotFiles. add plot files in directory to mine, use all plot directories if directory is empty
 func (this *Endpoint) AddPlotFiles(directory string, createSector bool) (interface{}, *DspErr) {
+	if len(directory) == 0 {
+		resps := make([]interface{}, 0)
+		for _, path := range config.PlotDirPaths() {
+			ret, derr := this.AddPlotFiles(path, createSector)
+			if derr != nil {
+				return nil, derr
+			}
+			resps = append(resps, ret.([]interface{})...)
+		}
+		return resps, nil
+	}
 	system := runtime.GOOS
 	if strings.Contains(system, plot.SYS_WIN) {
 		system = plot.SYS_WIN
@@ -169,13 +167,20 @@ func (this *Endpoint) AddPlotFiles(directory string, createSector bool) (interfa
 			})
 			continue
 		}
+		if derr := checkPlotFile(filepath.Join(directory, fileName)); derr != nil {
+			resps = append(resps, &errResp{
+				FileName: fileName,
+				Err:      derr,
+			})
+			continue
+		}
 
 		cfg := &poc.PlotConfig{
 			Sys:        system,
 			NumericID:  numericID,
 			StartNonce: startNonce,
 			Nonces:     nonce,
-			Path:       config.PlotPath(),
+			Path:       directory,
 		}
 
 		resp, err := dsp.AddNewPlotFile("", createSector, cfg)
@@ -218,7 +223,7 @@ func (this *Endpoint) GetAllPocTasks() (interface{}, *DspErr) {
 		return nil, &DspErr{Code: DSP_TASK_POC_ERROR, Error: err}
 
 	}
-	return resp, nil
+	return this.attachPlotJobs(resp), nil
 }
 
 func (this *Endpoint) DeletePlotFile(fileHash string, gasLimit uint64) (*DeleteFileResp, *DspErr) {
@@ -240,7 +245,7 @@ func (this *Endpoint) DeletePlotFile(fileHash string, gasLimit uint64) (*DeleteF
 			return nil, nil
 		}
 		baseFileName := filepath.Base(string(fileName))
-		fullFileName := filepath.Join(config.PlotPath(), baseFileName)
+		fullFileName := plotFileFullPath(baseFileName)
 		log.Infof("fullFileName %v, basefilename %s %s", fullFileName, fileName, baseFileName)
 
 		cleanTaskErr := dsp.DeletePocTask(taskId)
@@ -253,7 +258,7 @@ func (this *Endpoint) DeletePlotFile(fileHash string, gasLimit uint64) (*DeleteF
 	}
 	if fi != nil && err == nil && fi.FileOwner.ToBase58() == dsp.WalletAddress() {
 		baseFileName := filepath.Base(string(fi.FileDesc))
-		fullFileName := filepath.Join(config.PlotPath(), baseFileName)
+		fullFileName := plotFileFullPath(baseFileName)
 		log.Infof("fullFileName %v, basefilename %s %s", fullFileName, baseFileName)
 
 		taskId := dsp.GetPlotTaskId(fileHash)
@@ -290,7 +295,7 @@ func (this *Endpoint) BatchDeletePlotFiles(taskIds []string, gasLimit uint64) ([
 			log.Debugf("poc task %s has null filehash its name %s", taskId, fileName)
 			if len(fileName) > 0 {
 				baseFileName := filepath.Base(string(fileName))
-				fullFileName := filepath.Join(config.PlotPath(), baseFileName)
+				fullFileName := plotFileFullPath(baseFileName)
 				os.Remove(fullFileName)
 			}
 			cleanTaskErr := dsp.DeletePocTask(taskId)
@@ -312,7 +317,7 @@ func (this *Endpoint) BatchDeletePlotFiles(taskIds []string, gasLimit uint64) ([
 				continue
 			}
 			baseFileName := filepath.Base(string(fileName))
-			fullFileName := filepath.Join(config.PlotPath(), baseFileName)
+			fullFileName := plotFileFullPath(baseFileName)
 			log.Infof("fullFileName %v, basefilename %s %s", fullFileName, fileName, baseFileName)
 			cleanTaskErr := dsp.DeletePocTask(taskId)
 			if cleanTaskErr != nil {
@@ -326,7 +331,7 @@ func (this *Endpoint) BatchDeletePlotFiles(taskIds []string, gasLimit uint64) ([
 
 		if fi != nil && err == nil && fi.FileOwner.ToBase58() == dsp.WalletAddress() {
 			baseFileName := filepath.Base(string(fi.FileDesc))
-			fullFileName := filepath.Join(config.PlotPath(), baseFileName)
+			fullFileName := plotFileFullPath(baseFileName)
 			log.Infof("fullFileName %v, basefilename %s %s", fullFileName, baseFileName)
 
 			tx, _, _ := dsp.DeleteUploadFilesFromChain([]string{fileHash}, gasLimit)
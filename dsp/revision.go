@@ -0,0 +1,205 @@
+package dsp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/themis/common/log"
+)
+
+// AddFileRevision. append a uploaded file as the newest revision of a versioned file.
+// If the versioned file has a url, the url will be updated to link the new revision.
+func (this *Endpoint) AddFileRevision(name, fileHash, comment, url string) (*db.VersionedFile, *DspErr) {
+	if len(name) == 0 || len(fileHash) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	fileName := ""
+	info, _ := dsp.GetFileInfo(fileHash)
+	if info != nil {
+		fileName = string(info.FileDesc)
+	} else {
+		taskId := dsp.GetUploadTaskId(fileHash)
+		if len(taskId) == 0 {
+			return nil, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND, Error: ErrMaps[DSP_FILE_INFO_NOT_FOUND]}
+		}
+		fileName = dsp.GetTaskFileName(taskId)
+	}
+	file, err := this.db.GetVersionedFile(name)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if file == nil {
+		file = &db.VersionedFile{
+			Name:      name,
+			Revisions: make([]*db.FileRevision, 0),
+		}
+	}
+	if file.GetRevisionByHash(fileHash) != nil {
+		return nil, &DspErr{Code: DSP_FILE_REVISION_EXIST, Error: ErrMaps[DSP_FILE_REVISION_EXIST]}
+	}
+	if len(url) > 0 {
+		file.Url = url
+	}
+	revision := &db.FileRevision{
+		FileHash:  fileHash,
+		FileName:  fileName,
+		Comment:   comment,
+		CreatedAt: uint64(time.Now().Unix()),
+	}
+	if len(file.Url) > 0 {
+		tx, derr := this.UpdateFileUrlLink(file.Url, fileHash, fileName, 0, 0)
+		if derr != nil {
+			log.Errorf("update url %s to revision %d of %s err %s", file.Url, file.NextVersion(), name, derr.Error)
+			return nil, derr
+		}
+		revision.UrlTx = tx
+	}
+	file.AddRevision(revision)
+	file.UpdatedAt = revision.CreatedAt
+	if err := this.db.PutVersionedFile(file); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	log.Debugf("add revision %d of %s, hash %s", revision.Version, name, fileHash)
+	return file, nil
+}
+
+// GetFileRevisions. get all revisions of a versioned file
+func (this *Endpoint) GetFileRevisions(name string) (*db.VersionedFile, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	file, err := this.db.GetVersionedFile(name)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if file == nil {
+		return nil, &DspErr{Code: DSP_FILE_REVISION_NOT_FOUND, Error: ErrMaps[DSP_FILE_REVISION_NOT_FOUND]}
+	}
+	return file, nil
+}
+
+// GetVersionedFiles. get all versioned files
+func (this *Endpoint) GetVersionedFiles() ([]*db.VersionedFile, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	files, err := this.db.GetAllVersionedFiles()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return files, nil
+}
+
+// RollbackFileRevision. set an earlier revision as the current one, and update the url link to it
+func (this *Endpoint) RollbackFileRevision(name string, version uint32) (*db.VersionedFile, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	file, derr := this.GetFileRevisions(name)
+	if derr != nil {
+		return nil, derr
+	}
+	this.syncTrashedRevisions(file)
+	revision := file.GetRevision(version)
+	if revision == nil {
+		return nil, &DspErr{Code: DSP_FILE_REVISION_NOT_FOUND, Error: ErrMaps[DSP_FILE_REVISION_NOT_FOUND]}
+	}
+	if file.Current == version {
+		return file, nil
+	}
+	if revision.InTrash {
+		return nil, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND,
+			Error: fmt.Errorf("revision %d of %s is in trash", version, name)}
+	}
+	if info, err := dsp.GetFileInfo(revision.FileHash); info == nil && dsp.IsFileInfoDeleted(err) {
+		return nil, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND,
+			Error: fmt.Errorf("revision %d of %s has been deleted", version, name)}
+	}
+	if len(file.Url) > 0 {
+		tx, derr := this.UpdateFileUrlLink(file.Url, revision.FileHash, revision.FileName, 0, 0)
+		if derr != nil {
+			return nil, derr
+		}
+		revision.UrlTx = tx
+	}
+	file.Current = version
+	file.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutVersionedFile(file); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	log.Debugf("rollback %s to revision %d, hash %s", name, version, revision.FileHash)
+	return file, nil
+}
+
+// PruneFileRevisions. delete old revisions and only keep the newest "keep" revisions.
+// The current revision will never be pruned. The revisions are moved into trash instead of deleted from chain
+// if trash is enabled by TrashGracePeriod. A revision moved into trash is kept and marked,
+// it's removed once the file is deleted from chain, or unmarked if the file is restored.
+func (this *Endpoint) PruneFileRevisions(name string, keep uint32, gasLimit uint64) ([]*DeleteFileResp, *DspErr) {
+	file, derr := this.GetFileRevisions(name)
+	if derr != nil {
+		return nil, derr
+	}
+	this.syncTrashedRevisions(file)
+	resps := make([]*DeleteFileResp, 0)
+	pruneHashes := file.PruneHashes(keep)
+	if len(pruneHashes) > 0 {
+		resps, derr = this.DeleteUploadFiles(pruneHashes, gasLimit)
+		if derr != nil {
+			return nil, derr
+		}
+	}
+	deleted := make([]string, 0, len(resps))
+	for _, resp := range resps {
+		if resp == nil || len(resp.FileHash) == 0 {
+			continue
+		}
+		if !resp.InTrash {
+			deleted = append(deleted, resp.FileHash)
+			continue
+		}
+		if r := file.GetRevisionByHash(resp.FileHash); r != nil {
+			r.InTrash = true
+		}
+	}
+	file.RemoveRevisions(deleted)
+	file.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutVersionedFile(file); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	log.Debugf("prune revisions of %s: %s", name, strings.Join(pruneHashes, ","))
+	return resps, nil
+}
+
+// syncTrashedRevisions. remove the trashed revisions deleted from chain, and unmark the restored ones
+func (this *Endpoint) syncTrashedRevisions(file *db.VersionedFile) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	deleted := make([]string, 0)
+	for _, r := range file.Revisions {
+		if !r.InTrash {
+			continue
+		}
+		if item, err := this.db.GetTrashItem(r.FileHash); err != nil || item != nil {
+			continue
+		}
+		if info, err := dsp.GetFileInfo(r.FileHash); info == nil && dsp.IsFileInfoDeleted(err) {
+			deleted = append(deleted, r.FileHash)
+			continue
+		}
+		r.InTrash = false
+	}
+	file.RemoveRevisions(deleted)
+}
@@ -1,10 +1,42 @@
 package dsp
 
 import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/saveio/dsp-go-sdk/task/poc"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/utils/plot"
+	"github.com/saveio/edge/utils/sectorview"
 	"github.com/saveio/themis/common/log"
 	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
 )
 
+// SectorMoveResult. result of moving a file out of sector
+type SectorMoveResult struct {
+	FileHash string
+	Name     string `json:",omitempty"`
+	Planned  uint64 `json:",omitempty"` // advisory, the sdk chooses the sector when the file is added
+	Sector   uint64 `json:",omitempty"` // sector the file is added to, found on chain after adding
+	Error    string `json:",omitempty"`
+}
+
+// SECTOR_TARGET_ADVISORY. the sdk can't add a plot file to a given sector, the targets of plan are hints
+const SECTOR_TARGET_ADVISORY = "target sectors of the plan are advisory, the sdk chooses the sector of each " +
+	"added file, see Results[].Sector"
+
+// SectorRebalanceResp. moves of a sector rebalance
+type SectorRebalanceResp struct {
+	Plan      *sectorview.RebalancePlan // the targets of moves are advisory
+	Note      string
+	Tx        string `json:",omitempty"` // tx deleting the plot files from chain
+	Results   []*SectorMoveResult
+	NewSector uint64 `json:",omitempty"` // sector created for the files not fitting other sectors
+	Deleted   bool   // the sector is deleted after its files are moved out
+	Error     string `json:",omitempty"`
+}
+
 //Dsp api
 func (this *Endpoint) CreateSector(sectorId uint64, proveLevel uint64, size uint64, isPlots bool) (string, *DspErr) {
 	dsp := this.getDsp()
@@ -14,22 +46,31 @@ func (this *Endpoint) CreateSector(sectorId uint64, proveLevel uint64, size uint
 	tx, err := dsp.CreateSector(sectorId, proveLevel, size, isPlots)
 	if err != nil {
 		log.Errorf("create sector err:%s", err)
-		return "", &DspErr{Code: DSP_NODE_REGISTER_FAILED, Error: err}
+		return "", &DspErr{Code: DSP_SECTOR_CREATE_FAILED, Error: err}
 	}
 	log.Infof("tx: %s", tx)
 	return tx, nil
 }
 
+// DeleteSector. delete an empty sector, files in the sector should be moved out by RebalanceSector
 func (this *Endpoint) DeleteSector(sectorId uint64) (string, *DspErr) {
 	dsp := this.getDsp()
 	if dsp == nil {
 		return "", &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
 	}
+	sectorInfo, derr := this.GetSectorInfo(sectorId)
+	if derr != nil {
+		return "", derr
+	}
+	if len(sectorInfo.FileList.List) > 0 {
+		return "", &DspErr{Code: DSP_SECTOR_NOT_EMPTY,
+			Error: fmt.Errorf("sector %d has %d files, rebalance it before deleting", sectorId,
+				len(sectorInfo.FileList.List))}
+	}
 	tx, err := dsp.DeleteSector(sectorId)
 	if err != nil {
 		log.Errorf("delete sector err:%s", err)
-		// TODO: define error code for sector operations
-		return "", &DspErr{Code: DSP_NODE_REGISTER_FAILED, Error: err}
+		return "", &DspErr{Code: DSP_SECTOR_DELETE_FAILED, Error: err}
 	}
 	log.Infof("tx: %s", tx)
 	return tx, nil
@@ -43,8 +84,10 @@ func (this *Endpoint) GetSectorInfo(sectorId uint64) (*fs.SectorInfo, *DspErr) {
 	sectorInfo, err := dsp.GetSectorInfo(sectorId)
 	if err != nil {
 		log.Errorf("get sectorInfo err:%s", err)
-		// TODO: define error code for sector operations
-		return nil, &DspErr{Code: DSP_NODE_REGISTER_FAILED, Error: err}
+		return nil, &DspErr{Code: DSP_SECTOR_QUERY_FAILED, Error: err}
+	}
+	if sectorInfo == nil {
+		return nil, &DspErr{Code: DSP_SECTOR_NOT_FOUND, Error: ErrMaps[DSP_SECTOR_NOT_FOUND]}
 	}
 	return sectorInfo, nil
 
@@ -58,9 +101,263 @@ func (this *Endpoint) GetSectorInfosForNode(addr string) (*fs.SectorInfos, *DspE
 	sectorInfos, err := dsp.GetSectorInfosForNode(addr)
 	if err != nil {
 		log.Errorf("Get sectorInfos for node err:%s", err)
-		// TODO: define error code for sector operations
-		return nil, &DspErr{Code: DSP_NODE_REGISTER_FAILED, Error: err}
+		return nil, &DspErr{Code: DSP_SECTOR_QUERY_FAILED, Error: err}
 	}
 	return sectorInfos, nil
 
 }
+
+// GetSectorsSummary. sectors of current node with their usage, files, plots and proof outcome
+func (this *Endpoint) GetSectorsSummary() (*sectorview.Summary, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	sectors, derr := this.nodeSectors()
+	if derr != nil {
+		return nil, derr
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: DSP_SECTOR_QUERY_FAILED, Error: err}
+	}
+	return sectorview.Summarize(sectors, uint64(height)), nil
+}
+
+// PlanSectorRebalance. plan moving files out of the sector, only plot files of the node can be moved
+func (this *Endpoint) PlanSectorRebalance(sectorId uint64, createSector bool) (*sectorview.RebalancePlan, *DspErr) {
+	sectors, derr := this.nodeSectors()
+	if derr != nil {
+		return nil, derr
+	}
+	var source *sectorview.Sector
+	for _, sector := range sectors {
+		sector.Fill(0)
+		if sector.SectorId == sectorId {
+			source = sector
+		}
+	}
+	if source == nil {
+		return nil, &DspErr{Code: DSP_SECTOR_NOT_FOUND, Error: ErrMaps[DSP_SECTOR_NOT_FOUND]}
+	}
+	plan, err := sectorview.PlanRebalance(source, sectors, createSector)
+	if err == sectorview.ErrNoSpace {
+		return nil, &DspErr{Code: DSP_SECTOR_NO_SPACE, Error: ErrMaps[DSP_SECTOR_NO_SPACE]}
+	}
+	if err != nil {
+		return nil, &DspErr{Code: DSP_SECTOR_REBALANCE_FAILED, Error: err}
+	}
+	return plan, nil
+}
+
+// RebalanceSector. move plot files out of the sector and delete it. The plot files are deleted from chain in one tx,
+// then the emptied sector is deleted so it can't be chosen again, and the files are added to mine again.
+// A new sector of the same size is created for the files not fitting other sectors.
+// The sdk adds a plot file to a sector chosen by itself, so the planned targets are advisory and
+// the sector of each file in the results is the only real result. The files stored for others can't be moved.
+func (this *Endpoint) RebalanceSector(sectorId uint64, createSector bool, gasLimit uint64) (*SectorRebalanceResp,
+	*DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	plan, derr := this.PlanSectorRebalance(sectorId, createSector)
+	if derr != nil {
+		return nil, derr
+	}
+	if len(plan.Unmovable) > 0 {
+		return nil, &DspErr{Code: DSP_SECTOR_REBALANCE_FAILED,
+			Error: fmt.Errorf("sector %d has %d files stored for others can't be moved", sectorId,
+				len(plan.Unmovable))}
+	}
+	source, derr := this.GetSectorInfo(sectorId)
+	if derr != nil {
+		return nil, derr
+	}
+	resp := &SectorRebalanceResp{
+		Plan:    plan,
+		Note:    SECTOR_TARGET_ADVISORY,
+		Results: make([]*SectorMoveResult, 0, len(plan.Moves)),
+	}
+	files := make(map[string]*plot.PlotFileInfo)
+	fileHashes := make([]string, 0, len(plan.Moves))
+	for _, move := range plan.Moves {
+		file, err := plot.ParsePlotFileName(move.Name)
+		if err != nil {
+			return nil, &DspErr{Code: DSP_SECTOR_REBALANCE_FAILED, Error: err}
+		}
+		files[move.FileHash] = file
+		fileHashes = append(fileHashes, move.FileHash)
+	}
+
+	if len(fileHashes) > 0 {
+		taskIds := make([]string, 0, len(fileHashes))
+		for _, fileHash := range fileHashes {
+			taskIds = append(taskIds, dsp.GetPlotTaskId(fileHash))
+		}
+		tx, _, err := dsp.DeleteUploadFilesFromChain(fileHashes, gasLimit)
+		if err != nil {
+			return nil, &DspErr{Code: DSP_SECTOR_REBALANCE_FAILED, Error: err}
+		}
+		if _, err := dsp.PollForTxConfirmed(time.Duration(common.POLL_TX_COMFIRMED_TIMEOUT)*time.Second,
+			tx); err != nil {
+			return nil, &DspErr{Code: DSP_SECTOR_REBALANCE_FAILED, Error: err}
+		}
+		resp.Tx = tx
+		for _, taskId := range taskIds {
+			if err := dsp.DeletePocTask(taskId); err != nil {
+				log.Errorf("delete poc task %s of moved plot file err %s", taskId, err)
+			}
+		}
+	}
+
+	// the files are added again even if the sector isn't deleted, so they keep being mined
+	if err := this.deleteSectorConfirmed(sectorId); err != nil {
+		log.Errorf("delete sector %d after moving files out err %s", sectorId, err)
+		resp.Error = err.Error()
+	} else {
+		resp.Deleted = true
+	}
+	if plan.NewSize > 0 {
+		newSectorId, err := this.createSectorConfirmed(source.ProveLevel, source.Size, source.IsPlots)
+		if err != nil {
+			log.Errorf("create sector for moved plot files err %s", err)
+			resp.Error = err.Error()
+		} else {
+			resp.NewSector = newSectorId
+		}
+	}
+
+	// let the sdk create sectors if the planned new sector can't be created, so the files keep being mined
+	createByAdd := plan.NewSize > 0 && resp.NewSector == 0
+	system := plotSystem()
+	for _, move := range plan.Moves {
+		result := &SectorMoveResult{FileHash: move.FileHash, Name: move.Name, Planned: move.To}
+		if result.Planned == 0 {
+			result.Planned = resp.NewSector
+		}
+		resp.Results = append(resp.Results, result)
+		file := files[move.FileHash]
+		_, err := dsp.AddNewPlotFile("", createByAdd, &poc.PlotConfig{
+			Sys:        system,
+			NumericID:  file.NumericID,
+			StartNonce: file.StartNonce,
+			Nonces:     file.Nonces,
+			Path:       filepath.Dir(plotFileFullPath(move.Name)),
+		})
+		if err != nil {
+			log.Errorf("add moved plot file %s err %s", move.Name, err)
+			result.Error = fmt.Sprintf("plot file is deleted from chain but not added again: %s", err)
+			continue
+		}
+		log.Infof("plot file %s is moved out of sector %d", move.Name, sectorId)
+	}
+
+	// find the sectors where the files are added
+	sectors, derr := this.nodeSectors()
+	if derr != nil {
+		return resp, nil
+	}
+	for _, sector := range sectors {
+		for _, file := range sector.Files {
+			for _, result := range resp.Results {
+				if file.FileHash != result.FileHash || len(result.Error) > 0 {
+					continue
+				}
+				result.Sector = sector.SectorId
+				if sector.SectorId != result.Planned {
+					log.Debugf("plot file %s is added to sector %d, planned %d", result.Name, sector.SectorId,
+						result.Planned)
+				}
+			}
+		}
+	}
+	return resp, nil
+}
+
+// deleteSectorConfirmed. delete the sector and wait for the tx confirmed
+func (this *Endpoint) deleteSectorConfirmed(sectorId uint64) error {
+	tx, derr := this.DeleteSector(sectorId)
+	if derr != nil {
+		return derr.Error
+	}
+	_, err := this.getDsp().PollForTxConfirmed(time.Duration(common.POLL_TX_COMFIRMED_TIMEOUT)*time.Second, tx)
+	return err
+}
+
+// createSectorConfirmed. create a sector with the next id of node and wait for the tx confirmed
+func (this *Endpoint) createSectorConfirmed(proveLevel, size uint64, isPlots bool) (uint64, error) {
+	sectors, derr := this.nodeSectors()
+	if derr != nil {
+		return 0, derr.Error
+	}
+	sectorId := uint64(1)
+	for _, sector := range sectors {
+		if sector.SectorId >= sectorId {
+			sectorId = sector.SectorId + 1
+		}
+	}
+	tx, derr := this.CreateSector(sectorId, proveLevel, size, isPlots)
+	if derr != nil {
+		return 0, derr.Error
+	}
+	if _, err := this.getDsp().PollForTxConfirmed(time.Duration(common.POLL_TX_COMFIRMED_TIMEOUT)*time.Second,
+		tx); err != nil {
+		return 0, err
+	}
+	return sectorId, nil
+}
+
+// nodeSectors. sectors of current node with their files
+func (this *Endpoint) nodeSectors() ([]*sectorview.Sector, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	sectorInfos, derr := this.GetSectorInfosForNode(dsp.WalletAddress())
+	if derr != nil {
+		return nil, derr
+	}
+	sectors := make([]*sectorview.Sector, 0, len(sectorInfos.Sectors))
+	for _, info := range sectorInfos.Sectors {
+		sector := &sectorview.Sector{
+			SectorId:         info.SectorID,
+			Size:             info.Size,
+			ProveLevel:       info.ProveLevel,
+			IsPlots:          info.IsPlots,
+			FirstProveHeight: info.FirstProveHeight,
+			NextProveHeight:  info.NextProveHeight,
+			Files:            make([]*sectorview.File, 0),
+		}
+		for _, hash := range info.FileList.List {
+			sector.Files = append(sector.Files, this.sectorFile(string(hash.Hash)))
+		}
+		sectors = append(sectors, sector)
+	}
+	return sectors, nil
+}
+
+// sectorFile. size of file in sector, name of plot file and height of its last proof by the node
+func (this *Endpoint) sectorFile(fileHash string) *sectorview.File {
+	file := &sectorview.File{FileHash: fileHash}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return file
+	}
+	if fi, err := dsp.GetFileInfo(fileHash); err == nil && fi != nil {
+		file.Size = fi.FileBlockNum * fi.FileBlockSize
+	}
+	if len(dsp.GetPlotTaskId(fileHash)) > 0 {
+		file.IsPlot = true
+		file.Name = filepath.Base(string(dsp.GetPlotTaskFileName(fileHash)))
+	}
+	if details, err := dsp.GetFileProveDetails(fileHash); err == nil && details != nil {
+		walletAddr := dsp.WalletAddress()
+		for _, detail := range details.ProveDetails {
+			if detail.WalletAddr.ToBase58() == walletAddr && detail.ProveTimes > 0 {
+				file.ProveHeight = detail.BlockHeight
+			}
+		}
+	}
+	return file
+}
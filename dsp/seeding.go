@@ -0,0 +1,373 @@
+package dsp
+
+import (
+	"sort"
+	"time"
+
+	carNet "github.com/saveio/carrier/network"
+	dspNetCom "github.com/saveio/dsp-go-sdk/network/common"
+	"github.com/saveio/dsp-go-sdk/network/message"
+	"github.com/saveio/dsp-go-sdk/network/message/types/file"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/dsp/actor/client"
+	"github.com/saveio/edge/utils/seedpolicy"
+	"github.com/saveio/themis/common/log"
+)
+
+// SetSeedingPolicy. validate and save the seeding policy, it takes effect on the next share request
+func (this *Endpoint) SetSeedingPolicy(policy *seedpolicy.Policy) (*seedpolicy.Policy, *DspErr) {
+	if policy == nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, &DspErr{Code: DSP_SEEDING_POLICY_INVALID, Error: err}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	policy.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutSeedingPolicy(policy); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	this.seedStateLock.Lock()
+	this.seedPolicy = policy
+	this.seedStateLock.Unlock()
+	return policy, nil
+}
+
+func (this *Endpoint) GetSeedingPolicy() (*seedpolicy.Policy, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	policy, err := this.db.GetSeedingPolicy()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return policy, nil
+}
+
+// GetSeedStates. get seeding states of all files which have been requested, the states not saved yet are included
+func (this *Endpoint) GetSeedStates() ([]*seedpolicy.FileState, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	states, err := this.db.GetAllSeedStates()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	this.seedStateLock.Lock()
+	defer this.seedStateLock.Unlock()
+	this.cacheSeedStates(states)
+	result := make([]*seedpolicy.FileState, 0, len(this.seedStates))
+	for _, state := range this.seedStates {
+		copied := *state
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FileHash < result[j].FileHash })
+	return result, nil
+}
+
+// ResumeSeeding. resume seeding a file which was stopped, the seeding ratio and time are restarted
+func (this *Endpoint) ResumeSeeding(fileHash string) (*seedpolicy.FileState, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	this.seedStateLock.Lock()
+	defer this.seedStateLock.Unlock()
+	state, ok := this.seedStates[fileHash]
+	if !ok {
+		stored, err := this.db.GetSeedState(fileHash)
+		if err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		if stored == nil {
+			return nil, &DspErr{Code: DSP_FILE_NOT_EXISTS, Error: ErrMaps[DSP_FILE_NOT_EXISTS]}
+		}
+		state = stored
+		this.seedStates[fileHash] = state
+	}
+	state.Stopped, state.StopReason = false, ""
+	state.SeedStartAt = uint64(time.Now().Unix())
+	state.ServedBytes = 0
+	state.UpdatedAt = state.SeedStartAt
+	if err := this.db.PutSeedState(state); err != nil {
+		this.seedStatesDirty[fileHash] = true
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	delete(this.seedStatesDirty, fileHash)
+	copied := *state
+	return &copied, nil
+}
+
+// CheckSeedingRequest. check the share request of downloader with the seeding policy,
+// the seeding state of file is updated in memory and the denied decision is notified.
+// The allowed requests are reported by the share notifications of sdk.
+// It's called for every download ask, so nothing is sent to chain here and the state is saved by seedStateService.
+// The served bytes are counted from the blocks sent, see countServedBlocks.
+func (this *Endpoint) CheckSeedingRequest(downloader, fileHash string, unitPrice uint64) *seedpolicy.Decision {
+	req := &seedpolicy.Request{
+		Downloader: downloader,
+		FileHash:   fileHash,
+		UnitPrice:  unitPrice,
+		Time:       time.Now(),
+	}
+	if this.db == nil {
+		return (&seedpolicy.Policy{}).Check(req, nil)
+	}
+	this.seedStateLock.Lock()
+	policy := this.loadSeedingPolicy()
+	state := this.loadSeedState(fileHash, uint64(req.Time.Unix()))
+	decision := policy.Check(req, state)
+	state.Requests++
+	if !decision.Allowed {
+		state.Denied++
+	}
+	state.UpdatedAt = uint64(req.Time.Unix())
+	this.seedStatesDirty[fileHash] = true
+	this.seedStateLock.Unlock()
+	if !decision.Allowed {
+		log.Debugf("deny share request of %s from %s, reason: %s", fileHash, downloader, decision.Reason)
+		go client.EventNotifySeedingDecision(decision)
+	}
+	return decision
+}
+
+// addSeedServedBytes. count the bytes sent to downloader to the seeding state of file,
+// only the files with seeding states created by share requests are counted
+func (this *Endpoint) addSeedServedBytes(fileHash string, served uint64) {
+	if this.db == nil || len(fileHash) == 0 || served == 0 {
+		return
+	}
+	this.seedStateLock.Lock()
+	defer this.seedStateLock.Unlock()
+	state, ok := this.seedStates[fileHash]
+	if !ok {
+		stored, err := this.db.GetSeedState(fileHash)
+		if err != nil || stored == nil {
+			return
+		}
+		state = stored
+		this.seedStates[fileHash] = state
+	}
+	state.ServedBytes += served
+	this.seedStatesDirty[fileHash] = true
+}
+
+// loadSeedingPolicy. the cached seeding policy, it's loaded from db at first. seedStateLock should be held.
+func (this *Endpoint) loadSeedingPolicy() *seedpolicy.Policy {
+	if this.seedPolicy != nil {
+		return this.seedPolicy
+	}
+	policy, err := this.db.GetSeedingPolicy()
+	if err != nil {
+		log.Errorf("get seeding policy err %s", err)
+		return &seedpolicy.Policy{}
+	}
+	this.seedPolicy = policy
+	return policy
+}
+
+// loadSeedState. the seeding state of file in memory, it's loaded from db or created at first.
+// The file size is fetched from chain in background, the ratio limit is not checked until it's known.
+// seedStateLock should be held.
+func (this *Endpoint) loadSeedState(fileHash string, now uint64) *seedpolicy.FileState {
+	if state, ok := this.seedStates[fileHash]; ok {
+		return state
+	}
+	state, err := this.db.GetSeedState(fileHash)
+	if err != nil {
+		log.Errorf("get seed state of %s err %s", fileHash, err)
+	}
+	if state == nil {
+		state = &seedpolicy.FileState{
+			FileHash:    fileHash,
+			SeedStartAt: now,
+		}
+		this.seedStatesDirty[fileHash] = true
+	}
+	if state.FileSize == 0 {
+		go this.updateSeedFileSize(fileHash)
+	}
+	this.seedStates[fileHash] = state
+	return state
+}
+
+// cacheSeedStates. add the states loaded from db to memory, the states in memory are newer. seedStateLock should be held.
+func (this *Endpoint) cacheSeedStates(states []*seedpolicy.FileState) {
+	for _, state := range states {
+		if _, ok := this.seedStates[state.FileHash]; !ok {
+			this.seedStates[state.FileHash] = state
+		}
+	}
+}
+
+// updateSeedFileSize. set the file size of seeding state from chain
+func (this *Endpoint) updateSeedFileSize(fileHash string) {
+	size := this.seedFileSize(fileHash)
+	if size == 0 {
+		return
+	}
+	this.seedStateLock.Lock()
+	defer this.seedStateLock.Unlock()
+	if state, ok := this.seedStates[fileHash]; ok && state.FileSize == 0 {
+		state.FileSize = size
+		this.seedStatesDirty[fileHash] = true
+	}
+}
+
+// saveSeedStates. save the changed seeding states to db
+func (this *Endpoint) saveSeedStates() {
+	if this.db == nil {
+		return
+	}
+	this.seedStateLock.Lock()
+	states := make([]seedpolicy.FileState, 0, len(this.seedStatesDirty))
+	for fileHash := range this.seedStatesDirty {
+		if state, ok := this.seedStates[fileHash]; ok {
+			states = append(states, *state)
+		}
+	}
+	this.seedStatesDirty = make(map[string]bool)
+	this.seedStateLock.Unlock()
+	for i := range states {
+		if err := this.db.PutSeedState(&states[i]); err != nil {
+			log.Errorf("save seed state of %s err %s", states[i].FileHash, err)
+			this.seedStateLock.Lock()
+			this.seedStatesDirty[states[i].FileHash] = true
+			this.seedStateLock.Unlock()
+		}
+	}
+}
+
+// seedStateService. save the seeding states changed by share requests and the served blocks periodically
+func (this *Endpoint) seedStateService() {
+	ti := time.NewTicker(time.Duration(common.SEED_STATE_SAVE_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			this.saveSeedStates()
+			this.saveServedBlocks()
+		case <-this.closeCh:
+			this.saveSeedStates()
+			this.saveServedBlocks()
+			return
+		}
+	}
+}
+
+// seedingMsgHandler. check the download asks of share seeded files with file privilege and seeding policy
+// before the msgs handled by dsp, other msgs and the asks of files stored as primary node are passed through.
+// The denied asks are dropped, so the downloader will choose other peers. The wallet address is authenticated
+// by the network session, and the requester may be allowed by a share link presented over http.
+func (this *Endpoint) seedingMsgHandler(ctx *carNet.ComponentContext, walletAddr string) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	msg := message.ReadMessage(ctx.Message())
+	if msg != nil && msg.Header != nil && msg.Header.Type == dspNetCom.MSG_TYPE_FILE {
+		fileMsg, ok := msg.Payload.(*file.File)
+		if ok && fileMsg.Operation == dspNetCom.FILE_OP_DOWNLOAD_ASK && this.isShareSeeded(fileMsg.Hash) {
+			if !this.filePrivilegeAllowed(fileMsg.Hash, walletAddr) && !this.shareLinkPresented(fileMsg.Hash, walletAddr) {
+				log.Debugf("deny share request of %s from %s, no privilege", fileMsg.Hash, walletAddr)
+				return
+			}
+			unitPrice := uint64(0)
+			if fileMsg.PayInfo != nil {
+				unitPrice = fileMsg.PayInfo.UnitPrice
+			}
+			if decision := this.CheckSeedingRequest(walletAddr, fileMsg.Hash, unitPrice); !decision.Allowed {
+				return
+			}
+		}
+	}
+	dsp.Receive(ctx, walletAddr)
+}
+
+// isShareSeeded. the file is shared from a local copy, not stored by local node as a primary node of the file
+func (this *Endpoint) isShareSeeded(fileHash string) bool {
+	info := this.cachedFileInfo(fileHash)
+	if info == nil {
+		return false
+	}
+	walletAddr := this.getDspWalletAddr()
+	for _, addr := range info.PrimaryNodes.AddrList {
+		if addr == walletAddr {
+			return false
+		}
+	}
+	return true
+}
+
+// seedingProfitService. stop seeding the files whose profit is lower than the threshold of policy
+func (this *Endpoint) seedingProfitService() {
+	ti := time.NewTicker(time.Duration(common.SEEDING_PROFIT_CHECK_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			this.stopUnprofitableSeeding()
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+func (this *Endpoint) stopUnprofitableSeeding() {
+	policy, derr := this.GetSeedingPolicy()
+	if derr != nil || policy.MinProfit == 0 {
+		return
+	}
+	now := time.Now()
+	stats, derr := this.GetShareStats(uint64(now.Unix())-policy.ProfitPeriod, uint64(now.Unix()), "", 0)
+	if derr != nil {
+		log.Errorf("get share stats for seeding err %s", derr.Error)
+		return
+	}
+	profits := make(map[string]uint64, len(stats.Files))
+	for _, f := range stats.Files {
+		profits[f.Key] = f.Profit
+	}
+	states, err := this.db.GetAllSeedStates()
+	if err != nil {
+		log.Errorf("get seed states err %s", err)
+		return
+	}
+	this.seedStateLock.Lock()
+	this.cacheSeedStates(states)
+	stopped := make([]*seedpolicy.Decision, 0)
+	for _, state := range this.seedStates {
+		if state.Stopped || !policy.Unprofitable(state, profits[state.FileHash], now) {
+			continue
+		}
+		state.Stopped, state.StopReason = true, seedpolicy.REASON_UNPROFITABLE
+		state.UpdatedAt = uint64(now.Unix())
+		this.seedStatesDirty[state.FileHash] = true
+		log.Infof("stop seeding %s, profit %d is lower than %d", state.FileHash,
+			profits[state.FileHash], policy.MinProfit)
+		stopped = append(stopped, &seedpolicy.Decision{
+			FileHash:  state.FileHash,
+			Reason:    state.StopReason,
+			CheckedAt: state.UpdatedAt,
+		})
+	}
+	this.seedStateLock.Unlock()
+	this.saveSeedStates()
+	for _, decision := range stopped {
+		go client.EventNotifySeedingDecision(decision)
+	}
+}
+
+// seedFileSize. file size in bytes from chain, 0 if the file info is not found
+func (this *Endpoint) seedFileSize(fileHash string) uint64 {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return 0
+	}
+	info, _ := dsp.GetFileInfo(fileHash)
+	if info == nil {
+		return 0
+	}
+	return info.FileBlockNum * info.FileBlockSize * 1024
+}
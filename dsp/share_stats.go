@@ -0,0 +1,132 @@
+package dsp
+
+import (
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	dspNetCom "github.com/saveio/dsp-go-sdk/network/common"
+	"github.com/saveio/dsp-go-sdk/network/message"
+	"github.com/saveio/dsp-go-sdk/network/message/types/block"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/sharestats"
+	"github.com/saveio/themis/cmd/utils"
+	"github.com/saveio/themis/common/log"
+)
+
+// GetShareStats. aggregate the share records created between start and end.
+// The profit comes from the share records of sdk, and the served bandwidth comes from
+// the blocks sent to downloaders, which are counted by countServedBlocks in hours.
+func (this *Endpoint) GetShareStats(start, end uint64, period string, topN int) (*sharestats.Stats, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if end == 0 {
+		end = uint64(time.Now().Unix())
+	}
+	if start > end {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	records := make([]*sharestats.Record, 0)
+	for offset := 0; ; offset += common.SHARE_STATS_PAGE_SIZE {
+		page, total, err := dsp.FindShareRecordsByCreatedAt(int64(start), int64(end), int64(offset),
+			int64(common.SHARE_STATS_PAGE_SIZE))
+		if err != nil {
+			return nil, &DspErr{Code: DB_FIND_SHARE_RECORDS_FAILED, Error: err}
+		}
+		for _, record := range page {
+			if record.Profit == 0 {
+				continue
+			}
+			records = append(records, &sharestats.Record{
+				FileHash:   record.FileHash,
+				FileName:   record.FileName,
+				Downloader: record.ToWalletAddr,
+				Profit:     record.Profit,
+				CreatedAt:  uint64(record.CreatedAt),
+			})
+		}
+		if len(page) < common.SHARE_STATS_PAGE_SIZE || offset+len(page) >= int(total) {
+			break
+		}
+	}
+	if this.db != nil {
+		served, err := this.db.GetServedBlocks(start, end)
+		if err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		for _, s := range served {
+			records = append(records, &sharestats.Record{
+				FileHash:    s.FileHash,
+				Downloader:  s.Downloader,
+				ServedBytes: s.Bytes,
+				CreatedAt:   s.Hour,
+			})
+		}
+	}
+	stats, err := sharestats.Aggregate(records, period, topN, time.Local)
+	if err != nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: err}
+	}
+	stats.Begin, stats.End = start, end
+	stats.ProfitFormat = utils.FormatUsdt(stats.Profit)
+	for _, list := range [][]*sharestats.Stat{stats.Files, stats.Downloaders, stats.Periods} {
+		for _, s := range list {
+			s.ProfitFormat = utils.FormatUsdt(s.Profit)
+		}
+	}
+	log.Debugf("share stats from %d to %d, records %d, profit %d", start, end, stats.Records, stats.Profit)
+	return stats, nil
+}
+
+// countServedBlocks. count the blocks with data in the block flights sent to the downloader,
+// it's hooked to the dsp messages sent by p2p actor. The counts are saved by seedStateService.
+func (this *Endpoint) countServedBlocks(msg proto.Message, walletAddr string) {
+	m := message.ReadMessage(msg)
+	if m == nil || m.Header == nil || m.Header.Type != dspNetCom.MSG_TYPE_BLOCK_FLIGHTS {
+		return
+	}
+	flights, ok := m.Payload.(*block.BlockFlights)
+	if !ok {
+		return
+	}
+	now := uint64(time.Now().Unix())
+	hour := now - now%3600
+	for _, b := range flights.Blocks {
+		if b == nil || len(b.Data) == 0 {
+			continue
+		}
+		this.servedLock.Lock()
+		key := db.ShareServedKey(hour, b.FileHash, walletAddr)
+		served, ok := this.servedBlocks[key]
+		if !ok {
+			served = &db.ServedBlocks{FileHash: b.FileHash, Downloader: walletAddr, Hour: hour}
+			this.servedBlocks[key] = served
+		}
+		served.Blocks++
+		served.Bytes += uint64(len(b.Data))
+		this.servedLock.Unlock()
+		this.addSeedServedBytes(b.FileHash, uint64(len(b.Data)))
+	}
+}
+
+// saveServedBlocks. add the served blocks in memory to db
+func (this *Endpoint) saveServedBlocks() {
+	if this.db == nil {
+		return
+	}
+	this.servedLock.Lock()
+	list := make([]*db.ServedBlocks, 0, len(this.servedBlocks))
+	for _, served := range this.servedBlocks {
+		list = append(list, served)
+	}
+	this.servedBlocks = make(map[string]*db.ServedBlocks)
+	this.servedLock.Unlock()
+	if len(list) == 0 {
+		return
+	}
+	if err := this.db.AddServedBlocks(list); err != nil {
+		log.Errorf("save served blocks err %s", err)
+	}
+}
@@ -0,0 +1,594 @@
+package dsp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pborman/uuid"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/edge/utils/sharelink"
+	chainSdk "github.com/saveio/themis-go-sdk/utils"
+	chainCom "github.com/saveio/themis/common"
+	"github.com/saveio/themis/common/log"
+	"github.com/saveio/themis/core/types"
+	"github.com/saveio/themis/crypto/keypair"
+	"github.com/saveio/themis/crypto/signature"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+type ShareLinkVerifyResp struct {
+	Id            string
+	FileHash      string
+	Owner         string
+	Recipient     string
+	ExpiredHeight uint64
+	DownloadLimit uint32
+	DownloadCount uint32
+}
+
+// CreateShareLink. issue a share link of the file signed by local account.
+// The link is bound to the recipient, or anyone who has it if the recipient is empty.
+// The link expires after duration blocks, and it can't outlive the file.
+// The downloads of a link with limit are counted by the owner, so only the owner serves it.
+func (this *Endpoint) CreateShareLink(fileHash, recipient string, duration uint64, downloadLimit uint32) (*db.ShareLink, *DspErr) {
+	if len(fileHash) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if len(recipient) > 0 {
+		if _, err := chainCom.AddressFromBase58(recipient); err != nil {
+			return nil, &DspErr{Code: INVALID_WALLET_ADDRESS, Error: err}
+		}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	acc := this.GetDspAccount()
+	if acc == nil {
+		return nil, &DspErr{Code: ACCOUNT_NOT_LOGIN, Error: ErrMaps[ACCOUNT_NOT_LOGIN]}
+	}
+	info, err := dsp.GetFileInfo(fileHash)
+	if err != nil || info == nil {
+		return nil, &DspErr{Code: DSP_FILE_INFO_NOT_FOUND, Error: ErrMaps[DSP_FILE_INFO_NOT_FOUND]}
+	}
+	if info.FileOwner.ToBase58() != acc.Address.ToBase58() {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: errors.New("only the file owner can share the file")}
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: CHAIN_GET_HEIGHT_FAILED, Error: err}
+	}
+	expiredHeight := info.ExpiredHeight
+	if duration > 0 && uint64(height)+duration < expiredHeight {
+		expiredHeight = uint64(height) + duration
+	}
+	grant := &sharelink.Grant{
+		Version:       sharelink.VERSION,
+		Action:        sharelink.ACTION_GRANT,
+		Id:            uuid.NewUUID().String(),
+		FileHash:      fileHash,
+		Owner:         acc.Address.ToBase58(),
+		OwnerPubKey:   hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey)),
+		Recipient:     recipient,
+		ExpiredHeight: expiredHeight,
+		DownloadLimit: downloadLimit,
+		CreatedAt:     uint64(time.Now().Unix()),
+	}
+	link, derr := this.signGrant(grant)
+	if derr != nil {
+		return nil, derr
+	}
+	shareLink := &db.ShareLink{
+		Id:            grant.Id,
+		FileHash:      fileHash,
+		FileName:      string(info.FileDesc),
+		Recipient:     recipient,
+		ExpiredHeight: expiredHeight,
+		DownloadLimit: downloadLimit,
+		Link:          link,
+		CreatedAt:     grant.CreatedAt,
+	}
+	if err := this.db.PutShareLink(shareLink); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	log.Debugf("create share link %s of %s for %s, expired at %d", grant.Id, fileHash, recipient, expiredHeight)
+	return shareLink, nil
+}
+
+// GetShareLinks. get share links issued by local account, filter by file hash if it's not empty
+func (this *Endpoint) GetShareLinks(fileHash string) ([]*db.ShareLink, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	all, err := this.db.GetAllShareLinks()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	links := make([]*db.ShareLink, 0, len(all))
+	for _, link := range all {
+		if len(fileHash) > 0 && link.FileHash != fileHash {
+			continue
+		}
+		links = append(links, link)
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].CreatedAt > links[j].CreatedAt
+	})
+	return links, nil
+}
+
+// RevokeShareLink. revoke a issued share link without changing the chain whitelist.
+// The returned link carries a signed revocation which can be sent to the serving nodes.
+func (this *Endpoint) RevokeShareLink(id string) (*db.ShareLink, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	link, err := this.db.GetShareLink(id)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if link == nil {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_NOT_FOUND, Error: ErrMaps[DSP_SHARE_LINK_NOT_FOUND]}
+	}
+	if link.Revoked {
+		return link, nil
+	}
+	grant, _, _, err := sharelink.Decode(link.Link)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	grant.Action = sharelink.ACTION_REVOKE
+	grant.CreatedAt = uint64(time.Now().Unix())
+	revocation, derr := this.signGrant(grant)
+	if derr != nil {
+		return nil, derr
+	}
+	link.Revoked = true
+	link.Revocation = revocation
+	link.RevokedAt = grant.CreatedAt
+	if err := this.db.PutShareLink(link); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	// the owner may serve the file too
+	if derr := this.markShareLinkRevoked(grant); derr != nil {
+		return nil, derr
+	}
+	log.Debugf("revoke share link %s of %s", id, link.FileHash)
+	return link, nil
+}
+
+// VerifyShareLink. verify the share link for the requester without using it
+func (this *Endpoint) VerifyShareLink(link, fileHash, requester string) (*ShareLinkVerifyResp, *DspErr) {
+	grant, derr := this.verifyShareLink(link, fileHash, requester)
+	if derr != nil {
+		return nil, derr
+	}
+	usage, err := this.db.GetShareLinkUsage(grant.Id)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if derr := checkShareLinkUsage(grant, usage); derr != nil {
+		return nil, derr
+	}
+	return newShareLinkVerifyResp(grant, usage), nil
+}
+
+// RedeemShareLink. verify the share link before serving the file to the requester,
+// and count one download of the link.
+func (this *Endpoint) RedeemShareLink(link, fileHash, requester string) (*ShareLinkVerifyResp, *DspErr) {
+	grant, derr := this.verifyShareLink(link, fileHash, requester)
+	if derr != nil {
+		return nil, derr
+	}
+	if grant.DownloadLimit > 0 && grant.Owner != this.getDspWalletAddr() {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID,
+			Error: errors.New("share link with download limit is only served by the owner")}
+	}
+	var usageErr *DspErr
+	usage, err := this.db.UpdateShareLinkUsage(grant.Id, func(usage *db.ShareLinkUsage) error {
+		if usageErr = checkShareLinkUsage(grant, usage); usageErr != nil {
+			return usageErr.Error
+		}
+		usage.FileHash = grant.FileHash
+		usage.DownloadCount++
+		usage.UpdatedAt = uint64(time.Now().Unix())
+		return nil
+	})
+	if usageErr != nil {
+		return nil, usageErr
+	}
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	log.Debugf("redeem share link %s of %s by %s, count %d", grant.Id, grant.FileHash, requester, usage.DownloadCount)
+	return newShareLinkVerifyResp(grant, usage), nil
+}
+
+// SignShareRequest. sign a download request of the file with local account. The request is sent to the node
+// which serves the file over http, with the share link if the account isn't allowed by the file privilege.
+func (this *Endpoint) SignShareRequest(fileHash, link string) (string, *DspErr) {
+	if len(fileHash) == 0 {
+		return "", &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	acc := this.GetDspAccount()
+	if acc == nil {
+		return "", &DspErr{Code: ACCOUNT_NOT_LOGIN, Error: ErrMaps[ACCOUNT_NOT_LOGIN]}
+	}
+	req := &sharelink.Request{
+		Version:   sharelink.VERSION,
+		FileHash:  fileHash,
+		Link:      link,
+		Requester: acc.Address.ToBase58(),
+		PubKey:    hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey)),
+		Timestamp: uint64(time.Now().Unix()),
+	}
+	payload, err := req.Payload()
+	if err != nil {
+		return "", &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	sig, err := chainSdk.Sign(acc, payload)
+	if err != nil {
+		return "", &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	return sharelink.EncodeRequest(payload, sig), nil
+}
+
+// AuthorizeShareDownload. check the signed request before serving the file over http, return the requester.
+// A file is served to the requester allowed by the file privilege, or the one with a valid share link,
+// and the link is redeemed once for each request. The clients without share links send no request,
+// then the peer is checked by the file privilege as before, or allowed by a share link it presented.
+func (this *Endpoint) AuthorizeShareDownload(fileHash, request, peerAddr string) (string, *DspErr) {
+	if len(request) == 0 {
+		if !this.filePrivilegeAllowed(fileHash, peerAddr) && !this.shareLinkPresented(fileHash, peerAddr) {
+			return "", &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
+				Error: fmt.Errorf("user %s has no privilege to download this file", peerAddr)}
+		}
+		return peerAddr, nil
+	}
+	req, derr := decodeShareRequest(fileHash, request)
+	if derr != nil {
+		return "", derr
+	}
+	if len(req.Link) > 0 {
+		if _, derr := this.RedeemShareLink(req.Link, fileHash, req.Requester); derr != nil {
+			return "", derr
+		}
+		return req.Requester, nil
+	}
+	if !this.filePrivilegeAllowed(fileHash, req.Requester) {
+		return "", &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
+			Error: fmt.Errorf("user %s has no privilege to download this file", req.Requester)}
+	}
+	return req.Requester, nil
+}
+
+// AcceptShareLinkPresent. accept a signed request with share link, which is sent by the requester before
+// asking for the file over p2p since the p2p ask can't carry the link. The link is redeemed once,
+// and the p2p asks of the requester for the file are served for SHARE_LINK_PRESENT_TIME.
+func (this *Endpoint) AcceptShareLinkPresent(request string) *DspErr {
+	req, derr := decodeShareRequest("", request)
+	if derr != nil {
+		return derr
+	}
+	if len(req.Link) == 0 {
+		return &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: ErrMaps[DSP_SHARE_LINK_INVALID]}
+	}
+	if _, derr := this.RedeemShareLink(req.Link, req.FileHash, req.Requester); derr != nil {
+		return derr
+	}
+	this.sharePresents.Store(req.FileHash+req.Requester, time.Now().Unix()+common.SHARE_LINK_PRESENT_TIME)
+	log.Debugf("accept share link of %s presented by %s", req.FileHash, req.Requester)
+	return nil
+}
+
+// shareLinkPresented. the address has presented a valid share link of the file recently
+func (this *Endpoint) shareLinkPresented(fileHash, walletAddr string) bool {
+	key := fileHash + walletAddr
+	expired, ok := this.sharePresents.Load(key)
+	if !ok {
+		return false
+	}
+	if expired.(int64) < time.Now().Unix() {
+		this.sharePresents.Delete(key)
+		return false
+	}
+	return true
+}
+
+// presentShareLink. send the share link in a signed request to the nodes which serve the file,
+// then they serve the p2p asks of local account for the file. A link with download limit is only
+// presented to the owner, others are presented to the owner and the primary nodes of the file.
+// The p2p http port of a node is derived from its dsp port with the port offsets of local config.
+func (this *Endpoint) presentShareLink(fileHash, link string) *DspErr {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	grant, derr := this.verifyShareLink(link, fileHash, this.getDspWalletAddr())
+	if derr != nil {
+		return derr
+	}
+	request, derr := this.SignShareRequest(fileHash, link)
+	if derr != nil {
+		return derr
+	}
+	owner, err := chainCom.AddressFromBase58(grant.Owner)
+	if err != nil {
+		return &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	nodes := []chainCom.Address{owner}
+	if grant.DownloadLimit == 0 {
+		if info, _ := dsp.GetFileInfo(fileHash); info != nil {
+			for _, addr := range info.PrimaryNodes.AddrList {
+				if addr != owner {
+					nodes = append(nodes, addr)
+				}
+			}
+		}
+	}
+	hostAddrs, err := dsp.GetNodeHostAddrListByWallets(nodes)
+	if err != nil {
+		return &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	accepted := 0
+	for i, hostAddr := range hostAddrs {
+		if len(hostAddr) == 0 {
+			continue
+		}
+		if err := postSharePresent(string(hostAddr), request); err != nil {
+			log.Warnf("present share link of %s to %s err %s", fileHash, nodes[i].ToBase58(), err)
+			continue
+		}
+		accepted++
+	}
+	if accepted == 0 {
+		return &DspErr{Code: DSP_NO_PRIVILEGE_TO_DOWNLOAD,
+			Error: errors.New("share link is not accepted by any node of the file")}
+	}
+	return nil
+}
+
+// postSharePresent. post the signed request to the p2p http server of the node with the dsp host address
+func postSharePresent(hostAddr, request string) error {
+	hostPort := hostAddr
+	if i := strings.Index(hostPort, "://"); i >= 0 {
+		hostPort = hostPort[i+3:]
+	}
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return err
+	}
+	port += config.Parameters.BaseConfig.HttpP2pPortOffset - config.Parameters.BaseConfig.DspPortOffset
+	body, err := json.Marshal(map[string]string{"request": request})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: time.Duration(common.SHARE_LINK_PRESENT_TIMEOUT) * time.Second}
+	resp, err := client.Post(fmt.Sprintf("http://%s/api/v1/p2p/http/share/present",
+		net.JoinHostPort(host, strconv.Itoa(port))), "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	ret := struct {
+		Data map[string]string `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return err
+	}
+	if len(ret.Data["error"]) > 0 {
+		return errors.New(ret.Data["error"])
+	}
+	return nil
+}
+
+// decodeShareRequest. decode the request and check its time and signature, the file hash is checked if not empty
+func decodeShareRequest(fileHash, request string) (*sharelink.Request, *DspErr) {
+	req, payload, sig, err := sharelink.DecodeRequest(request)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	if len(fileHash) > 0 && req.FileHash != fileHash {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: sharelink.ErrFileMismatch}
+	}
+	if err := req.CheckTime(uint64(time.Now().Unix())); err != nil {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	if err := verifySignature(req.Requester, req.PubKey, payload, sig); err != nil {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	return req, nil
+}
+
+// filePrivilegeAllowed. check the address can download the file by the privilege on chain.
+// The file info is cached for a while since it's checked for every share request.
+func (this *Endpoint) filePrivilegeAllowed(fileHash, walletAddr string) bool {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return false
+	}
+	info := this.cachedFileInfo(fileHash)
+	if info == nil {
+		return false
+	}
+	return dsp.CheckFilePrivilege(info, fileHash, walletAddr)
+}
+
+// cachedFileInfo. the file info from chain cached for SHARE_PRIVILEGE_CACHE_TIME, nil if not found
+func (this *Endpoint) cachedFileInfo(fileHash string) *fs.FileInfo {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil
+	}
+	var info *fs.FileInfo
+	if this.cache != nil {
+		info = this.cache.ChainCache.FileInfo(fileHash, time.Duration(common.SHARE_PRIVILEGE_CACHE_TIME)*time.Second)
+	}
+	if info == nil {
+		var err error
+		info, err = dsp.GetFileInfo(fileHash)
+		if err != nil || info == nil {
+			return nil
+		}
+		if this.cache != nil {
+			this.cache.ChainCache.SetFileInfo(fileHash, info)
+		}
+	}
+	return info
+}
+
+// AcceptShareLinkRevocation. accept a revocation signed by the file owner, the link is rejected after that
+func (this *Endpoint) AcceptShareLinkRevocation(revocation string) *DspErr {
+	grant, payload, sig, err := sharelink.Decode(revocation)
+	if err != nil || grant.Action != sharelink.ACTION_REVOKE {
+		return &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: ErrMaps[DSP_SHARE_LINK_INVALID]}
+	}
+	if derr := this.checkGrant(grant, payload, sig); derr != nil {
+		return derr
+	}
+	if derr := this.markShareLinkRevoked(grant); derr != nil {
+		return derr
+	}
+	log.Debugf("accept revocation of share link %s of %s", grant.Id, grant.FileHash)
+	return nil
+}
+
+func (this *Endpoint) markShareLinkRevoked(grant *sharelink.Grant) *DspErr {
+	if this.db == nil {
+		return &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	_, err := this.db.UpdateShareLinkUsage(grant.Id, func(usage *db.ShareLinkUsage) error {
+		usage.FileHash = grant.FileHash
+		usage.Revoked = true
+		usage.UpdatedAt = uint64(time.Now().Unix())
+		return nil
+	})
+	if err != nil {
+		return &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return nil
+}
+
+// verifyShareLink. decode the link and check the signature, file owner, recipient and expired height
+func (this *Endpoint) verifyShareLink(link, fileHash, requester string) (*sharelink.Grant, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	grant, payload, sig, err := sharelink.Decode(link)
+	if err != nil {
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	if derr := this.checkGrant(grant, payload, sig); derr != nil {
+		return nil, derr
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: CHAIN_GET_HEIGHT_FAILED, Error: err}
+	}
+	if err := grant.Check(fileHash, requester, uint64(height)); err != nil {
+		if err == sharelink.ErrExpired {
+			return nil, &DspErr{Code: DSP_SHARE_LINK_EXPIRED, Error: err}
+		}
+		return nil, &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	return grant, nil
+}
+
+// checkGrant. the grant must be signed by the key of the owner, and the owner must own the file on chain
+func (this *Endpoint) checkGrant(grant *sharelink.Grant, payload, sig []byte) *DspErr {
+	if err := verifySignature(grant.Owner, grant.OwnerPubKey, payload, sig); err != nil {
+		return &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: err}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	info, err := dsp.GetFileInfo(grant.FileHash)
+	if err != nil || info == nil {
+		return &DspErr{Code: DSP_FILE_INFO_NOT_FOUND, Error: ErrMaps[DSP_FILE_INFO_NOT_FOUND]}
+	}
+	if info.FileOwner.ToBase58() != grant.Owner {
+		return &DspErr{Code: DSP_SHARE_LINK_INVALID, Error: errors.New("share link is not signed by the file owner")}
+	}
+	return nil
+}
+
+// signGrant. sign the grant with local account and encode it as link
+func (this *Endpoint) signGrant(grant *sharelink.Grant) (string, *DspErr) {
+	acc := this.GetDspAccount()
+	if acc == nil {
+		return "", &DspErr{Code: ACCOUNT_NOT_LOGIN, Error: ErrMaps[ACCOUNT_NOT_LOGIN]}
+	}
+	payload, err := grant.Payload()
+	if err != nil {
+		return "", &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	sig, err := chainSdk.Sign(acc, payload)
+	if err != nil {
+		return "", &DspErr{Code: INTERNAL_ERROR, Error: err}
+	}
+	return sharelink.Encode(payload, sig), nil
+}
+
+// verifySignature. the payload must be signed by the key of the address
+func verifySignature(address, pubKeyHex string, payload, sig []byte) error {
+	pubKeyBuf, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return err
+	}
+	pubKey, err := keypair.DeserializePublicKey(pubKeyBuf)
+	if err != nil {
+		return err
+	}
+	if types.AddressFromPubKey(pubKey).ToBase58() != address {
+		return errors.New("address mismatch with public key")
+	}
+	s, err := signature.Deserialize(sig)
+	if err != nil || !signature.Verify(pubKey, payload, s) {
+		return errors.New("signature is invalid")
+	}
+	return nil
+}
+
+func checkShareLinkUsage(grant *sharelink.Grant, usage *db.ShareLinkUsage) *DspErr {
+	if usage.Revoked {
+		return &DspErr{Code: DSP_SHARE_LINK_REVOKED, Error: ErrMaps[DSP_SHARE_LINK_REVOKED]}
+	}
+	if grant.DownloadLimit > 0 && usage.DownloadCount >= grant.DownloadLimit {
+		return &DspErr{Code: DSP_SHARE_LINK_EXHAUSTED, Error: ErrMaps[DSP_SHARE_LINK_EXHAUSTED]}
+	}
+	return nil
+}
+
+func newShareLinkVerifyResp(grant *sharelink.Grant, usage *db.ShareLinkUsage) *ShareLinkVerifyResp {
+	return &ShareLinkVerifyResp{
+		Id:            grant.Id,
+		FileHash:      grant.FileHash,
+		Owner:         grant.Owner,
+		Recipient:     grant.Recipient,
+		ExpiredHeight: grant.ExpiredHeight,
+		DownloadLimit: grant.DownloadLimit,
+		DownloadCount: usage.DownloadCount,
+	}
+}
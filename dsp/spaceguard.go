@@ -0,0 +1,194 @@
+package dsp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/dsp/actor/client"
+	"github.com/saveio/edge/utils/spaceguard"
+	"github.com/saveio/themis/cmd/utils"
+	"github.com/saveio/themis/common/log"
+	fs "github.com/saveio/themis/smartcontract/service/native/savefs"
+)
+
+// SetSpaceGuardPolicy. validate and save the userspace guardian policy, it takes effect on the next check
+func (this *Endpoint) SetSpaceGuardPolicy(policy *spaceguard.Policy) (*spaceguard.Policy, *DspErr) {
+	if policy == nil {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, &DspErr{Code: DSP_SPACE_POLICY_INVALID, Error: err}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	policy.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutSpaceGuardPolicy(policy); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return policy, nil
+}
+
+func (this *Endpoint) GetSpaceGuardPolicy() (*spaceguard.Policy, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	policy, err := this.db.GetSpaceGuardPolicy()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return policy, nil
+}
+
+// GetSpaceGuardActions. get the automatic actions of userspace guardian, the latest first
+func (this *Endpoint) GetSpaceGuardActions() ([]*spaceguard.ActionLog, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	actions, err := this.db.GetAllSpaceGuardActions()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].CreatedAt > actions[j].CreatedAt
+	})
+	return actions, nil
+}
+
+// spaceGuardService. check the userspace of current wallet with the guardian policy periodically
+func (this *Endpoint) spaceGuardService() {
+	ti := time.NewTicker(time.Duration(common.SPACE_GUARD_CHECK_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			this.checkUserSpace()
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+func (this *Endpoint) checkUserSpace() {
+	if this.db == nil {
+		return
+	}
+	policy, err := this.db.GetSpaceGuardPolicy()
+	if err != nil {
+		log.Errorf("get space guard policy err %s", err)
+		return
+	}
+	if len(policy.UsageThresholds) == 0 && len(policy.ExpiryThresholds) == 0 && !policy.AutoExtend {
+		return
+	}
+	userspace, derr := this.GetUserSpace("")
+	if derr != nil {
+		log.Errorf("get userspace err %s", derr.Error)
+		return
+	}
+	state, err := this.db.GetSpaceGuardState()
+	if err != nil {
+		log.Errorf("get space guard state err %s", err)
+		return
+	}
+	now := time.Now()
+	alerts, action := policy.Check(&spaceguard.Space{
+		Used:      userspace.Used,
+		Remain:    userspace.Remain,
+		ExpiredAt: userspace.ExpiredAt,
+	}, state, now)
+	for _, alert := range alerts {
+		log.Infof("userspace %s alert, threshold %d, value %d", alert.Kind, alert.Threshold, alert.Value)
+		go client.EventNotifyUserspaceAlert(alert)
+	}
+	if action != nil {
+		state.LastActionAt = uint64(now.Unix())
+		actionLog := this.runSpaceGuardAction(policy, action, now)
+		go client.EventNotifyUserspaceAction(actionLog)
+	}
+	if err := this.db.PutSpaceGuardState(state); err != nil {
+		log.Errorf("save space guard state err %s", err)
+	}
+}
+
+// runSpaceGuardAction. extend the userspace if the cost is within the budget.
+// The executed action is inserted to the userspace records by SetUserSpace,
+// and all actions tried are kept in the action logs of guardian.
+func (this *Endpoint) runSpaceGuardAction(policy *spaceguard.Policy, action *spaceguard.Action,
+	now time.Time) *spaceguard.ActionLog {
+	actionLog := &spaceguard.ActionLog{
+		Id:        fmt.Sprintf("%d", now.UnixNano()),
+		AddSize:   action.AddSize,
+		AddPeriod: action.AddPeriod,
+		Reasons:   action.Reasons,
+		CreatedAt: uint64(now.Unix()),
+	}
+	defer func() {
+		if err := this.db.PutSpaceGuardAction(actionLog); err != nil {
+			log.Errorf("save space guard action err %s", err)
+		}
+		this.pruneSpaceGuardActions(policy)
+	}()
+	sizeOp, countOp := uint64(fs.UserSpaceNone), uint64(fs.UserSpaceNone)
+	if action.AddSize > 0 {
+		sizeOp = uint64(fs.UserSpaceAdd)
+	}
+	if action.AddPeriod > 0 {
+		countOp = uint64(fs.UserSpaceAdd)
+	}
+	cost, derr := this.GetUserSpaceCost("", action.AddSize, sizeOp, action.AddPeriod, countOp)
+	if derr != nil {
+		actionLog.Error = derr.Error.Error()
+		log.Errorf("get userspace cost of guardian action err %s", derr.Error)
+		return actionLog
+	}
+	actionLog.Cost = cost.Fee
+	logs, err := this.db.GetAllSpaceGuardActions()
+	if err != nil {
+		actionLog.Error = err.Error()
+		return actionLog
+	}
+	if !policy.Affordable(cost.Fee, logs, now) {
+		remain := uint64(0)
+		if spent := policy.Spent(logs, now); spent < policy.Budget {
+			remain = policy.Budget - spent
+		}
+		actionLog.Skipped = true
+		actionLog.Error = fmt.Sprintf("cost %s exceeds the remaining budget %s",
+			utils.FormatUsdt(cost.Fee), utils.FormatUsdt(remain))
+		log.Warnf("skip userspace guardian action, %s", actionLog.Error)
+		return actionLog
+	}
+	tx, derr := this.SetUserSpace("", action.AddSize, sizeOp, action.AddPeriod, countOp)
+	actionLog.Tx = tx
+	if derr != nil {
+		actionLog.Error = derr.Error.Error()
+		log.Errorf("userspace guardian action err %s", derr.Error)
+		return actionLog
+	}
+	log.Infof("userspace guardian add size %d, period %d, cost %s, tx %s", action.AddSize, action.AddPeriod,
+		utils.FormatUsdt(cost.Fee), tx)
+	return actionLog
+}
+
+// pruneSpaceGuardActions. keep the latest action logs, and all the logs in budget period
+func (this *Endpoint) pruneSpaceGuardActions(policy *spaceguard.Policy) {
+	actions, err := this.db.GetAllSpaceGuardActions()
+	if err != nil || len(actions) <= common.MAX_SPACE_GUARD_ACTION_LOGS {
+		return
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].CreatedAt > actions[j].CreatedAt
+	})
+	now := uint64(time.Now().Unix())
+	for _, action := range actions[common.MAX_SPACE_GUARD_ACTION_LOGS:] {
+		if policy.BudgetPeriod == 0 || action.CreatedAt+policy.BudgetPeriod > now {
+			continue
+		}
+		if err := this.db.DeleteSpaceGuardAction(action.Id); err != nil {
+			log.Errorf("delete space guard action %s err %s", action.Id, err)
+		}
+	}
+}
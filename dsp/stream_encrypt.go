@@ -0,0 +1,331 @@
+package dsp
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pborman/uuid"
+	"github.com/saveio/dsp-go-sdk/store"
+	dspPrefix "github.com/saveio/dsp-go-sdk/types/prefix"
+	dspOS "github.com/saveio/dsp-go-sdk/utils/os"
+	dspTask "github.com/saveio/dsp-go-sdk/utils/task"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/utils/gcmstream"
+	"github.com/saveio/themis/common/log"
+)
+
+const (
+	ENCRYPT_TYPE_AES = "aes" // encrypt the whole file by sdk
+	ENCRYPT_TYPE_GCM = "gcm" // chunked aes-gcm stream
+)
+
+const (
+	STREAM_ENCRYPT_SUFFIX = ".ept"
+	STREAM_SEALED_DIR     = "sealed" // dir of encrypted files waiting for upload
+)
+
+// EncryptFileStream. encrypt the file to path.ept with chunked aes-gcm in one pass.
+// The output starts with the dsp encrypt prefix, followed by the versioned gcm stream.
+func (this *Endpoint) EncryptFileStream(path, password string) (string, *DspErr) {
+	output := path + STREAM_ENCRYPT_SUFFIX
+	if derr := this.sealFile(path, output, password); derr != nil {
+		return "", derr
+	}
+	return output, nil
+}
+
+// DecryptFileStream. decrypt a gcm stream file to the file name, the partial output is removed if failed
+func (this *Endpoint) DecryptFileStream(path, fileName, password string) (string, *DspErr) {
+	filePrefix, f, derr := openStreamFile(path)
+	if derr != nil {
+		return "", derr
+	}
+	defer f.Close()
+	if !dspPrefix.VerifyEncryptPassword(password, filePrefix.EncryptSalt, filePrefix.EncryptHash) {
+		return "", &DspErr{Code: DSP_FILE_DECRYPTED_WRONG_PWD, Error: ErrMaps[DSP_FILE_DECRYPTED_WRONG_PWD]}
+	}
+	if len(fileName) == 0 {
+		fileName = filePrefix.FileName
+	}
+	if len(fileName) == 0 {
+		fileName = strings.TrimSuffix(filepath.Base(path), STREAM_ENCRYPT_SUFFIX)
+	}
+	r, err := gcmstream.NewReader(f, []byte(password))
+	if err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	outPath := dspTask.GetDecryptedFilePath(path, fileName)
+	if err := writeDecrypted(outPath, r); err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	log.Debugf("decrypted gcm stream %s to %s", path, outPath)
+	return outPath, nil
+}
+
+// SealFileForUpload. encrypt the file into the sealed dir, the sealed file is uploaded instead of the origin file.
+// This is not streamed into the upload: the sdk only uploads from a file path and has no reader hook,
+// so one encrypted copy of the file is written before uploading and the disk space of the file is needed twice.
+// No other temp file is written. The copy is removed when the task is done or cancelled,
+// or its failed record is deleted, and the ones left by a crash are removed on start.
+func (this *Endpoint) SealFileForUpload(path, password string) (string, *DspErr) {
+	dir := filepath.Join(config.FsFileRootPath(), STREAM_SEALED_DIR)
+	if err := dspOS.CreateDirIfNeed(dir); err != nil {
+		return "", &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	output := common.GetNewPathIfExisted(filepath.Join(dir, filepath.Base(path)+STREAM_ENCRYPT_SUFFIX))
+	if derr := this.sealFile(path, output, password); derr != nil {
+		return "", derr
+	}
+	return output, nil
+}
+
+// IsStreamEncrypted. check whether the file is encrypted as gcm stream
+func (this *Endpoint) IsStreamEncrypted(path string) bool {
+	_, f, derr := openStreamFile(path)
+	if derr != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+func (this *Endpoint) sealFile(path, output, password string) *DspErr {
+	if len(password) == 0 {
+		return &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	if stat.IsDir() {
+		return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: fmt.Errorf("%s is a directory", path)}
+	}
+	prefix := dspPrefix.NewEncryptPrefix(password, this.getDspWalletAddr(), uint64(stat.Size()),
+		false, dspPrefix.ENCRYPTTYPE_AES)
+	if prefix == nil {
+		return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: fmt.Errorf("prefix is nil")}
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	defer src.Close()
+	out, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	err = writeStream(out, src, prefix.Serialize(), password)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return &DspErr{Code: DSP_ENCRYPTED_FILE_FAILED, Error: err}
+	}
+	log.Debugf("encrypted %s to gcm stream %s", path, output)
+	return nil
+}
+
+// removeSealedUpload. remove the sealed file of a finished upload task
+func (this *Endpoint) removeSealedUpload(taskId string) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	info := dsp.GetTaskInfo(taskId)
+	if info == nil || len(info.FilePath) == 0 {
+		return
+	}
+	if filepath.Dir(info.FilePath) != filepath.Join(config.FsFileRootPath(), STREAM_SEALED_DIR) {
+		return
+	}
+	if err := os.Remove(info.FilePath); err != nil && !os.IsNotExist(err) {
+		log.Errorf("remove sealed file %s err %s", info.FilePath, err)
+	}
+}
+
+// cleanSealedUploads. remove the sealed files which are not used by any unfinished upload task
+func (this *Endpoint) cleanSealedUploads() {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	dir := filepath.Join(config.FsFileRootPath(), STREAM_SEALED_DIR)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	taskInfos, err := dsp.GetUploadTaskInfos()
+	if err != nil {
+		log.Errorf("get upload tasks err %s", err)
+		return
+	}
+	used := make(map[string]bool)
+	for _, info := range taskInfos {
+		if info == nil || info.TaskState == store.TaskStateDone || info.TaskState == store.TaskStateCancel {
+			continue
+		}
+		used[info.FilePath] = true
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+		if file.IsDir() || used[path] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			log.Errorf("remove sealed file %s err %s", path, err)
+		}
+	}
+}
+
+// DownloadFileStream. download a file which is uploaded as gcm stream. The sdk only downloads to a file path,
+// so the ciphertext is downloaded as is and decrypted in place after the task is done, see decryptInPlace.
+func (this *Endpoint) DownloadFileStream(taskId, fileHash, url, linkStr, password string, max uint64,
+	setFileName, inOrder bool, shareLink string) *DspErr {
+	if len(password) == 0 {
+		return &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	taskId = this.watchStreamDecrypt(taskId, password)
+	if derr := this.DownloadFile(taskId, fileHash, url, linkStr, "", max, setFileName, inOrder, shareLink); derr != nil {
+		this.streamDecryptPwds.Delete(taskId)
+		return derr
+	}
+	return nil
+}
+
+// watchStreamDecrypt. remember the password of download task to decrypt the file after downloaded,
+// a task id is generated if it's empty.
+func (this *Endpoint) watchStreamDecrypt(taskId, password string) string {
+	if len(password) == 0 {
+		return taskId
+	}
+	if len(taskId) == 0 {
+		taskId = uuid.NewUUID().String()
+	}
+	this.streamDecryptPwds.Store(taskId, password)
+	return taskId
+}
+
+// decryptDownloadedStream. decrypt the downloaded file in place if it is a gcm stream,
+// and rename it to the decrypted file name.
+func (this *Endpoint) decryptDownloadedStream(taskId string) {
+	pwd, ok := this.streamDecryptPwds.Load(taskId)
+	if !ok {
+		return
+	}
+	this.streamDecryptPwds.Delete(taskId)
+	dsp := this.getDsp()
+	if dsp == nil {
+		return
+	}
+	info := dsp.GetTaskInfo(taskId)
+	if info == nil || !this.IsStreamEncrypted(info.FilePath) {
+		return
+	}
+	outPath, derr := decryptInPlace(info.FilePath, pwd.(string))
+	if derr != nil {
+		log.Errorf("decrypt downloaded file %s err %s", info.FilePath, derr.Error)
+		return
+	}
+	log.Infof("decrypt downloaded file %s to %s", info.FilePath, outPath)
+}
+
+// decryptInPlace. decrypt the gcm stream file over itself, truncate it to the plaintext size and rename it.
+// The plaintext is shorter than the ciphertext before it, so the writer never passes the reader and
+// no second copy is written. The file is removed if a chunk fails to decrypt, its head is overwritten by then.
+func decryptInPlace(path, password string) (string, *DspErr) {
+	filePrefix, f, derr := openStreamFile(path)
+	if derr != nil {
+		return "", derr
+	}
+	defer f.Close()
+	if !dspPrefix.VerifyEncryptPassword(password, filePrefix.EncryptSalt, filePrefix.EncryptHash) {
+		return "", &DspErr{Code: DSP_FILE_DECRYPTED_WRONG_PWD, Error: ErrMaps[DSP_FILE_DECRYPTED_WRONG_PWD]}
+	}
+	fileName := filePrefix.FileName
+	if len(fileName) == 0 {
+		fileName = strings.TrimSuffix(filepath.Base(path), STREAM_ENCRYPT_SUFFIX)
+	}
+	r, err := gcmstream.NewReader(f, []byte(password))
+	if err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	out, err := os.OpenFile(path, os.O_WRONLY, 0666)
+	if err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	size, err := io.Copy(out, r)
+	if err == nil {
+		err = out.Truncate(size)
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	out.Close()
+	if err != nil {
+		os.Remove(path)
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	outPath := dspTask.GetDecryptedFilePath(path, fileName)
+	if err := os.Rename(path, outPath); err != nil {
+		return "", &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	return outPath, nil
+}
+
+func writeStream(out io.Writer, src io.Reader, prefix []byte, password string) error {
+	if _, err := out.Write(prefix); err != nil {
+		return err
+	}
+	w, err := gcmstream.NewWriter(out, []byte(password), gcmstream.DEFAULT_CHUNK_SIZE)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// writeDecrypted. write the plaintext to the output path, the partial output is removed if failed
+func writeDecrypted(outPath string, r io.Reader) error {
+	out, err := os.OpenFile(outPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, r); err == nil {
+		err = out.Sync()
+	}
+	out.Close()
+	if err != nil {
+		os.Remove(outPath)
+	}
+	return err
+}
+
+// openStreamFile. parse the dsp prefix and return the file which is positioned at the gcm stream header
+func openStreamFile(path string) (*dspPrefix.FilePrefix, *os.File, *DspErr) {
+	filePrefix, prefix, err := dspPrefix.GetPrefixFromFile(path)
+	if err != nil || filePrefix == nil {
+		return nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: fmt.Errorf("get prefix of %s failed", path)}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	head := make([]byte, len(gcmstream.MAGIC))
+	if _, err := f.ReadAt(head, int64(len(prefix))); err != nil || !gcmstream.IsStream(head) {
+		f.Close()
+		return nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: fmt.Errorf("%s is not a gcm stream", path)}
+	}
+	if _, err := f.Seek(int64(len(prefix)), io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, &DspErr{Code: DSP_DECRYPTED_FILE_FAILED, Error: err}
+	}
+	return filePrefix, f, nil
+}
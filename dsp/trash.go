@@ -0,0 +1,323 @@
+package dsp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	ethCom "github.com/ethereum/go-ethereum/common"
+	dspTypes "github.com/saveio/dsp-go-sdk/task/types"
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/common/config"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/themis/common/log"
+)
+
+type TrashResp struct {
+	GracePeriod uint64
+	Items       []*db.TrashItem
+	Fee         *dspTypes.Gas `json:",omitempty"` // the estimated fee of deleting all trashed files
+	Purges      []*db.TrashPurge
+}
+
+// trashGracePeriod. the seconds of trashed files kept before deleted from chain, zero means trash is disabled
+func trashGracePeriod() uint64 {
+	if config.Parameters.DspConfig.TrashGracePeriod < 0 {
+		return 0
+	}
+	return uint64(config.Parameters.DspConfig.TrashGracePeriod)
+}
+
+// TrashUploadFiles. move the upload files into trash, they are deleted from chain when the grace period ends.
+// The grace period never goes past the expired time of file.
+func (this *Endpoint) TrashUploadFiles(fileHashes []string, gasLimit uint64) ([]*DeleteFileResp, *DspErr) {
+	if len(fileHashes) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: CHAIN_GET_HEIGHT_FAILED, Error: err}
+	}
+	this.trashLock.Lock()
+	defer this.trashLock.Unlock()
+	now := uint64(time.Now().Unix())
+	resps := make([]*DeleteFileResp, 0, len(fileHashes))
+	for _, fileHash := range fileHashes {
+		fi, err := dsp.GetFileInfo(fileHash)
+		if fi == nil && dsp.IsFileInfoDeleted(err) {
+			log.Debugf("file info of %s is deleted, skip trash", fileHash)
+			continue
+		}
+		if fi == nil || err != nil {
+			return nil, &DspErr{Code: DSP_DELETE_FILE_FAILED, Error: fmt.Errorf("get file info of %s err %v", fileHash, err)}
+		}
+		if fi.FileOwner.ToBase58() != dsp.WalletAddress() &&
+			ethCom.BytesToAddress(fi.FileOwner[:]) != dsp.CurrentAccount().EthAddress {
+			return nil, &DspErr{Code: DSP_DELETE_FILE_FAILED, Error: fmt.Errorf("file %s is not owned by you", fileHash)}
+		}
+		item, err := this.db.GetTrashItem(fileHash)
+		if err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		if item == nil {
+			item = &db.TrashItem{
+				FileHash:      fileHash,
+				FileName:      string(fi.FileDesc),
+				Size:          fi.FileBlockNum * fi.FileBlockSize,
+				ExpiredHeight: fi.ExpiredHeight,
+				DeletedAt:     now,
+				PurgeAt:       now + trashGracePeriod(),
+			}
+			if taskInfo := dsp.GetUploadTaskInfoByHash(fileHash); taskInfo != nil && len(taskInfo.FileName) > 0 {
+				item.FileName = taskInfo.FileName
+			}
+			if expiredAt := blockHeightToTimestamp(uint64(height), fi.ExpiredHeight); expiredAt < item.PurgeAt {
+				item.PurgeAt = expiredAt
+			}
+		}
+		item.GasLimit = gasLimit
+		if err := this.db.PutTrashItem(item); err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		log.Debugf("move file %s into trash, purge at %d", fileHash, item.PurgeAt)
+		resp := &DeleteFileResp{IsUploaded: true, InTrash: true, PurgeAt: item.PurgeAt}
+		resp.FileHash = fileHash
+		resp.FileName = item.FileName
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+// GetTrash. get the trashed files sorted by purge time, and the records of files deleted from chain
+func (this *Endpoint) GetTrash() (*TrashResp, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	items, err := this.db.GetAllTrashItems()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].PurgeAt < items[j].PurgeAt
+	})
+	purges, err := this.db.GetAllTrashPurges()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	sort.Slice(purges, func(i, j int) bool {
+		return purges[i].PurgedAt > purges[j].PurgedAt
+	})
+	resp := &TrashResp{
+		GracePeriod: trashGracePeriod(),
+		Items:       items,
+		Purges:      purges,
+	}
+	if len(items) == 0 {
+		return resp, nil
+	}
+	fileHashes := make([]string, 0, len(items))
+	for _, item := range items {
+		fileHashes = append(fileHashes, item.FileHash)
+	}
+	fee, derr := this.CalculateDeleteFilesFee(fileHashes)
+	if derr != nil {
+		log.Errorf("calculate delete fee of trash err %s", derr.Error)
+	} else {
+		resp.Fee = fee
+	}
+	return resp, nil
+}
+
+// RestoreTrashFiles. move the files out of trash, all trashed files are restored if fileHashes is empty
+func (this *Endpoint) RestoreTrashFiles(fileHashes []string) ([]*db.TrashItem, *DspErr) {
+	items, derr := this.getTrashItems(fileHashes)
+	if derr != nil {
+		return nil, derr
+	}
+	this.trashLock.Lock()
+	defer this.trashLock.Unlock()
+	for _, item := range items {
+		if err := this.db.DeleteTrashItem(item.FileHash); err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		log.Debugf("restore file %s from trash", item.FileHash)
+	}
+	return items, nil
+}
+
+// EmptyTrash. delete the trashed files from chain now, all trashed files are deleted if fileHashes is empty
+func (this *Endpoint) EmptyTrash(fileHashes []string) ([]*db.TrashPurge, *DspErr) {
+	items, derr := this.getTrashItems(fileHashes)
+	if derr != nil {
+		return nil, derr
+	}
+	if len(items) == 0 {
+		return []*db.TrashPurge{}, nil
+	}
+	return this.purgeTrashItems(items)
+}
+
+// getTrashItems. get the trashed files by hashes, it's failed if any of the files is not in trash
+func (this *Endpoint) getTrashItems(fileHashes []string) ([]*db.TrashItem, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	if len(fileHashes) == 0 {
+		items, err := this.db.GetAllTrashItems()
+		if err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		return items, nil
+	}
+	items := make([]*db.TrashItem, 0, len(fileHashes))
+	for _, fileHash := range fileHashes {
+		item, err := this.db.GetTrashItem(fileHash)
+		if err != nil {
+			return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+		}
+		if item == nil {
+			return nil, &DspErr{Code: DSP_FILE_NOT_EXISTS, Error: fmt.Errorf("file %s is not in trash", fileHash)}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// purgeTrashItems. delete the trashed files from chain in batches, the fee of each batch is calculated
+// before deleting and recorded with the txs. The failed files are kept in trash and retried later.
+func (this *Endpoint) purgeTrashItems(items []*db.TrashItem) ([]*db.TrashPurge, *DspErr) {
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	this.trashLock.Lock()
+	defer this.trashLock.Unlock()
+	purges := make([]*db.TrashPurge, 0)
+	for start := 0; start < len(items); start += common.MAX_TRASH_DELETE_BATCH_SIZE {
+		end := start + common.MAX_TRASH_DELETE_BATCH_SIZE
+		if end > len(items) {
+			end = len(items)
+		}
+		fileHashes := make([]string, 0, end-start)
+		gasLimit := uint64(0)
+		for _, item := range items[start:end] {
+			// the file may be restored before the lock acquired
+			if cur, _ := this.db.GetTrashItem(item.FileHash); cur == nil {
+				continue
+			}
+			// the file may be expired or deleted by other way
+			if fi, err := dsp.GetFileInfo(item.FileHash); fi == nil && dsp.IsFileInfoDeleted(err) {
+				log.Debugf("file info of trashed file %s is deleted", item.FileHash)
+				if err := this.db.DeleteTrashItem(item.FileHash); err != nil {
+					log.Errorf("delete trash item %s err %s", item.FileHash, err)
+				}
+				continue
+			}
+			fileHashes = append(fileHashes, item.FileHash)
+			if item.GasLimit > gasLimit {
+				gasLimit = item.GasLimit
+			}
+		}
+		if len(fileHashes) == 0 {
+			continue
+		}
+		now := time.Now()
+		purge := &db.TrashPurge{
+			Id:         fmt.Sprintf("%d", now.UnixNano()),
+			FileHashes: fileHashes,
+			Txs:        make([]string, 0),
+			PurgedAt:   uint64(now.Unix()),
+		}
+		if fee, derr := this.CalculateDeleteFilesFee(fileHashes); derr != nil {
+			log.Errorf("calculate delete fee of trashed files err %s", derr.Error)
+		} else {
+			purge.GasPrice, purge.Fee = fee.GasPrice, fee.GasLimit
+		}
+		resps, derr := this.deleteUploadFiles(fileHashes, gasLimit)
+		if derr != nil {
+			purge.Error = derr.Error.Error()
+			log.Errorf("delete trashed files from chain err %s", derr.Error)
+		}
+		txs := make(map[string]struct{})
+		for _, resp := range resps {
+			if resp == nil || len(resp.FileHash) == 0 {
+				continue
+			}
+			if err := this.db.DeleteTrashItem(resp.FileHash); err != nil {
+				log.Errorf("delete trash item %s err %s", resp.FileHash, err)
+			}
+			if _, ok := txs[resp.Tx]; !ok && len(resp.Tx) > 0 {
+				txs[resp.Tx] = struct{}{}
+				purge.Txs = append(purge.Txs, resp.Tx)
+			}
+		}
+		log.Infof("delete %d trashed files from chain, txs %v, fee %d", len(fileHashes), purge.Txs, purge.Fee)
+		if err := this.db.PutTrashPurge(purge); err != nil {
+			log.Errorf("save trash purge record err %s", err)
+		}
+		purges = append(purges, purge)
+	}
+	this.pruneTrashPurges()
+	return purges, nil
+}
+
+// pruneTrashPurges. keep the latest purge records only
+func (this *Endpoint) pruneTrashPurges() {
+	purges, err := this.db.GetAllTrashPurges()
+	if err != nil || len(purges) <= common.MAX_TRASH_PURGE_RECORDS {
+		return
+	}
+	sort.Slice(purges, func(i, j int) bool {
+		return purges[i].PurgedAt > purges[j].PurgedAt
+	})
+	for _, purge := range purges[common.MAX_TRASH_PURGE_RECORDS:] {
+		if err := this.db.DeleteTrashPurge(purge.Id); err != nil {
+			log.Errorf("delete trash purge record %s err %s", purge.Id, err)
+		}
+	}
+}
+
+// trashService. delete the trashed files from chain when their grace period ends
+func (this *Endpoint) trashService() {
+	ti := time.NewTicker(time.Duration(common.TRASH_CHECK_INTERVAL) * time.Second)
+	defer ti.Stop()
+	for {
+		select {
+		case <-ti.C:
+			this.purgeExpiredTrash()
+		case <-this.closeCh:
+			return
+		}
+	}
+}
+
+func (this *Endpoint) purgeExpiredTrash() {
+	if this.db == nil {
+		return
+	}
+	items, err := this.db.GetAllTrashItems()
+	if err != nil {
+		log.Errorf("get trash items err %s", err)
+		return
+	}
+	now := uint64(time.Now().Unix())
+	expired := make([]*db.TrashItem, 0)
+	for _, item := range items {
+		if item.PurgeAt <= now {
+			expired = append(expired, item)
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+	if _, derr := this.purgeTrashItems(expired); derr != nil {
+		log.Errorf("purge trash err %s", derr.Error)
+	}
+}
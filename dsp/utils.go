@@ -11,47 +11,18 @@ import (
 	"strings"
 
 	ethCom "github.com/ethereum/go-ethereum/common"
+	"github.com/saveio/edge/utils/fileindex"
 	"github.com/saveio/themis/common"
 	"github.com/saveio/themis/common/log"
 	sUtils "github.com/saveio/themis/smartcontract/service/native/utils"
 )
 
+// FileNameMatchType. match the file list type by the exact extension of file name
 func FileNameMatchType(fileType DspFileListType, fileName string) bool {
-	switch fileType {
-	case DspFileListTypeAll:
+	if fileType == DspFileListTypeAll {
 		return true
-	case DspFileListTypeImage:
-		if strings.Index(fileName, ".jpg") != -1 ||
-			strings.Index(fileName, ".gif") != -1 ||
-			strings.Index(fileName, ".svg") != -1 ||
-			strings.Index(fileName, ".png") != -1 ||
-			strings.Index(fileName, ".jpeg") != -1 {
-			return true
-		}
-	case DspFileListTypeDoc:
-		if strings.Index(fileName, ".doc") != -1 ||
-			strings.Index(fileName, ".txt") != -1 ||
-			strings.Index(fileName, ".dat") != -1 ||
-			strings.Index(fileName, ".docx") != -1 ||
-			strings.Index(fileName, ".md") != -1 ||
-			strings.Index(fileName, ".pdf") != -1 ||
-			strings.Index(fileName, ".xlx") != -1 {
-			return true
-		}
-	case DspFileListTypeVideo:
-		if strings.Index(fileName, ".mp4") != -1 ||
-			strings.Index(fileName, ".mov") != -1 ||
-			strings.Index(fileName, ".rmvb") != -1 ||
-			strings.Index(fileName, ".avi") != -1 ||
-			strings.Index(fileName, ".rm") != -1 {
-			return true
-		}
-	case DspFileListTypeMusic:
-		if strings.Index(fileName, ".mp3") != -1 {
-			return true
-		}
 	}
-	return false
+	return fileindex.CategoryOfName(fileName) == int(fileType)
 }
 
 func RequiredStrToUint64(in interface{}) (uint64, error) {
@@ -37,6 +37,33 @@ func TestIsDirEmpty(t *testing.T) {
 	}
 }
 
+func TestFileNameMatchType(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType DspFileListType
+		fileName string
+		want     bool
+	}{
+		{name: "all", fileType: DspFileListTypeAll, fileName: "noext", want: true},
+		{name: "music", fileType: DspFileListTypeMusic, fileName: "song.mp3", want: true},
+		{name: "music in middle", fileType: DspFileListTypeMusic, fileName: "my.mp3.txt", want: false},
+		{name: "doc by last ext", fileType: DspFileListTypeDoc, fileName: "my.mp3.txt", want: true},
+		{name: "rmvb", fileType: DspFileListTypeVideo, fileName: "movie.rmvb", want: true},
+		{name: "rm", fileType: DspFileListTypeVideo, fileName: "movie.rm", want: true},
+		{name: "rm prefix", fileType: DspFileListTypeVideo, fileName: "movie.rmx", want: false},
+		{name: "upper case", fileType: DspFileListTypeImage, fileName: "PHOTO.JPG", want: true},
+		{name: "docx not doc prefix", fileType: DspFileListTypeDoc, fileName: "a.docx", want: true},
+		{name: "name without dot", fileType: DspFileListTypeImage, fileName: "jpg", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileNameMatchType(tt.fileType, tt.fileName); got != tt.want {
+				t.Errorf("FileNameMatchType(%d, %s) = %v, want %v", tt.fileType, tt.fileName, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestAddPrefixToFile(t *testing.T) {
 	prefix := "test"
 	prefixBuf := []byte(prefix)
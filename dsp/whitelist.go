@@ -0,0 +1,258 @@
+package dsp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saveio/edge/common"
+	"github.com/saveio/edge/dsp/db"
+	chainCom "github.com/saveio/themis/common"
+	"github.com/saveio/themis/common/log"
+)
+
+// whitelist operations of fs contract
+const (
+	WHITELIST_OP_ADD     = 0 // add rules to whitelist
+	WHITELIST_OP_DEL     = 1 // delete rules from whitelist
+	WHITELIST_OP_ADD_COV = 2 // cover whitelist with rules
+)
+
+// bulk operations of whitelist group
+const (
+	WHITELIST_GROUP_ADD     = "add"
+	WHITELIST_GROUP_REMOVE  = "remove"
+	WHITELIST_GROUP_REPLACE = "replace"
+)
+
+type WhitelistGroupResult struct {
+	FileHash string
+	Tx       string
+	Code     int64
+	Error    string
+}
+
+type WhitelistGroupApplyResp struct {
+	Name      string
+	Operation string
+	Success   int
+	Failed    int
+	Results   []*WhitelistGroupResult
+}
+
+// SetWhitelistGroup. create or update a whitelist group
+func (this *Endpoint) SetWhitelistGroup(group *db.WhitelistGroup) (*db.WhitelistGroup, *DspErr) {
+	if group == nil || len(group.Name) == 0 || len(group.Members) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	exist := make(map[string]struct{}, len(group.Members))
+	members := make([]*db.WhitelistMember, 0, len(group.Members))
+	for _, m := range group.Members {
+		if _, err := chainCom.AddressFromBase58(m.Addr); err != nil {
+			return nil, &DspErr{Code: INVALID_WALLET_ADDRESS, Error: fmt.Errorf("invalid address %s", m.Addr)}
+		}
+		if _, ok := exist[m.Addr]; ok {
+			continue
+		}
+		exist[m.Addr] = struct{}{}
+		members = append(members, m)
+	}
+	group.Members = members
+	old, err := this.db.GetWhitelistGroup(group.Name)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	group.UpdatedAt = uint64(time.Now().Unix())
+	group.CreatedAt = group.UpdatedAt
+	if old != nil {
+		group.CreatedAt = old.CreatedAt
+	}
+	if err := this.db.PutWhitelistGroup(group); err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return group, nil
+}
+
+// GetWhitelistGroups. get all whitelist groups sorted by name
+func (this *Endpoint) GetWhitelistGroups() ([]*db.WhitelistGroup, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	groups, err := this.db.GetAllWhitelistGroups()
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups, nil
+}
+
+func (this *Endpoint) GetWhitelistGroup(name string) (*db.WhitelistGroup, *DspErr) {
+	if this.db == nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: ErrMaps[DB_LOCAL_STORE_FAILED]}
+	}
+	group, err := this.db.GetWhitelistGroup(name)
+	if err != nil {
+		return nil, &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	if group == nil {
+		return nil, &DspErr{Code: DSP_WHITELIST_GROUP_NOT_FOUND, Error: ErrMaps[DSP_WHITELIST_GROUP_NOT_FOUND]}
+	}
+	return group, nil
+}
+
+// DeleteWhitelistGroup. delete the local group, the applied chain whitelist is not changed
+func (this *Endpoint) DeleteWhitelistGroup(name string) *DspErr {
+	if _, derr := this.GetWhitelistGroup(name); derr != nil {
+		return derr
+	}
+	if err := this.db.DeleteWhitelistGroup(name); err != nil {
+		return &DspErr{Code: DB_LOCAL_STORE_FAILED, Error: err}
+	}
+	return nil
+}
+
+// ApplyWhitelistGroup. apply the group to the files with add, remove or replace semantics.
+// The contract updates the whitelist of one file in a tx, so one tx is sent for each file. The txs are sent
+// without waiting in groups of MAX_WHITELIST_BATCH_SIZE, and each group waits for its txs confirmed before the next one.
+func (this *Endpoint) ApplyWhitelistGroup(name string, fileHashes []string, operation string) (
+	*WhitelistGroupApplyResp, *DspErr) {
+	if len(fileHashes) == 0 {
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: ErrMaps[INVALID_PARAMS]}
+	}
+	var op uint64
+	switch operation {
+	case WHITELIST_GROUP_ADD:
+		op = WHITELIST_OP_ADD
+	case WHITELIST_GROUP_REMOVE:
+		op = WHITELIST_OP_DEL
+	case WHITELIST_GROUP_REPLACE:
+		op = WHITELIST_OP_ADD_COV
+	default:
+		return nil, &DspErr{Code: INVALID_PARAMS, Error: fmt.Errorf("unknown operation %s", operation)}
+	}
+	group, derr := this.GetWhitelistGroup(name)
+	if derr != nil {
+		return nil, derr
+	}
+	dsp := this.getDsp()
+	if dsp == nil {
+		return nil, &DspErr{Code: NO_DSP, Error: ErrMaps[NO_DSP]}
+	}
+	height, err := dsp.GetCurrentBlockHeight()
+	if err != nil {
+		return nil, &DspErr{Code: CHAIN_GET_HEIGHT_FAILED, Error: err}
+	}
+	resp := &WhitelistGroupApplyResp{
+		Name:      name,
+		Operation: operation,
+		Results:   make([]*WhitelistGroupResult, 0, len(fileHashes)),
+	}
+	for start := 0; start < len(fileHashes); start += common.MAX_WHITELIST_BATCH_SIZE {
+		end := start + common.MAX_WHITELIST_BATCH_SIZE
+		if end > len(fileHashes) {
+			end = len(fileHashes)
+		}
+		batch := make([]*WhitelistGroupResult, 0, end-start)
+		for _, fileHash := range fileHashes[start:end] {
+			result := &WhitelistGroupResult{FileHash: fileHash}
+			batch = append(batch, result)
+			info, err := dsp.GetFileInfo(fileHash)
+			if err != nil || info == nil {
+				result.Code, result.Error = DSP_FILE_INFO_NOT_FOUND, ErrMaps[DSP_FILE_INFO_NOT_FOUND].Error()
+				continue
+			}
+			rules := whitelistGroupRules(group, uint64(height), info.ExpiredHeight)
+			tx, derr := this.WhiteListOperation(fileHash, op, rules)
+			if derr != nil {
+				result.Code, result.Error = derr.Code, derr.Error.Error()
+				continue
+			}
+			result.Tx = tx
+		}
+		for _, result := range batch {
+			if len(result.Tx) == 0 {
+				continue
+			}
+			if _, err := dsp.PollForTxConfirmed(time.Duration(common.POLL_TX_COMFIRMED_TIMEOUT)*time.Second,
+				result.Tx); err != nil {
+				result.Code, result.Error = DSP_WHITELIST_OP_FAILED, err.Error()
+				continue
+			}
+			this.updateFileWhitelistGroups(result.FileHash, name, operation)
+		}
+		resp.Results = append(resp.Results, batch...)
+	}
+	for _, result := range resp.Results {
+		if result.Code == 0 {
+			resp.Success++
+		} else {
+			resp.Failed++
+		}
+	}
+	log.Debugf("apply whitelist group %s %s to %d files, success %d, failed %d",
+		name, operation, len(fileHashes), resp.Success, resp.Failed)
+	return resp, nil
+}
+
+// updateFileWhitelistGroups. record the applied group of file after the whitelist tx confirmed
+func (this *Endpoint) updateFileWhitelistGroups(fileHash, name, operation string) {
+	if this.db == nil {
+		return
+	}
+	groups, err := this.db.GetFileWhitelistGroups(fileHash)
+	if err != nil {
+		log.Errorf("get whitelist groups of %s err %s", fileHash, err)
+		return
+	}
+	switch operation {
+	case WHITELIST_GROUP_ADD:
+		if !groups.HasGroup(name) {
+			groups.Groups = append(groups.Groups, name)
+		}
+	case WHITELIST_GROUP_REMOVE:
+		left := make([]string, 0, len(groups.Groups))
+		for _, g := range groups.Groups {
+			if g != name {
+				left = append(left, g)
+			}
+		}
+		groups.Groups = left
+	case WHITELIST_GROUP_REPLACE:
+		groups.Groups = []string{name}
+	}
+	groups.UpdatedAt = uint64(time.Now().Unix())
+	if err := this.db.PutFileWhitelistGroups(groups); err != nil {
+		log.Errorf("save whitelist groups of %s err %s", fileHash, err)
+	}
+}
+
+// whitelistGroupRules. convert the group to whitelist rules, the default heights are filled
+func whitelistGroupRules(group *db.WhitelistGroup, height, fileExpiredHeight uint64) []*WhiteListRule {
+	rules := make([]*WhiteListRule, 0, len(group.Members))
+	for _, m := range group.Members {
+		rule := &WhiteListRule{
+			Addr:          m.Addr,
+			StartHeight:   m.StartHeight,
+			ExpiredHeight: m.ExpiredHeight,
+		}
+		if rule.StartHeight == 0 {
+			rule.StartHeight = group.StartHeight
+		}
+		if rule.StartHeight == 0 {
+			rule.StartHeight = height
+		}
+		if rule.ExpiredHeight == 0 {
+			rule.ExpiredHeight = group.ExpiredHeight
+		}
+		if rule.ExpiredHeight == 0 || rule.ExpiredHeight > fileExpiredHeight {
+			rule.ExpiredHeight = fileExpiredHeight
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
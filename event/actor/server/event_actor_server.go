@@ -119,6 +119,36 @@ func (this *EventActorServer) Receive(ctx actor.Context) {
 			websocket.Server().NotifyNewTask(msg.Type, msg.Id)
 			msg.Response <- &edgeCli.NotifyResp{}
 		}()
+	case *edgeCli.NotifySeedingDecision:
+		go func() {
+			websocket.Server().PushSeedingDecision(msg.Decision)
+			msg.Response <- &edgeCli.NotifyResp{}
+		}()
+	case *edgeCli.NotifyUserspaceAlert:
+		go func() {
+			websocket.Server().PushUserspaceAlert(msg.Alert)
+			msg.Response <- &edgeCli.NotifyResp{}
+		}()
+	case *edgeCli.NotifyUserspaceAction:
+		go func() {
+			websocket.Server().PushUserspaceAction(msg.Action)
+			msg.Response <- &edgeCli.NotifyResp{}
+		}()
+	case *edgeCli.NotifyPlotJob:
+		go func() {
+			websocket.Server().PushPlotJob(msg.Job)
+			msg.Response <- &edgeCli.NotifyResp{}
+		}()
+	case *edgeCli.NotifyNodeAlert:
+		go func() {
+			websocket.Server().PushNodeAlert(msg.Alert)
+			msg.Response <- &edgeCli.NotifyResp{}
+		}()
+	case *edgeCli.NotifyNodeAction:
+		go func() {
+			websocket.Server().PushNodeAction(msg.Action)
+			msg.Response <- &edgeCli.NotifyResp{}
+		}()
 	case *edgeCli.NotifyNetworkState:
 		go func() {
 			websocket.Server().PushNetworkState()
@@ -0,0 +1,114 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func GetTags(cmd []interface{}) map[string]interface{} {
+	v := rest.GetTags(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func TagFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"FileHashes", "Tags"})
+	v := rest.TagFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func UntagFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"FileHashes", "Tags"})
+	v := rest.UntagFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func RenameTag(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "NewName"})
+	v := rest.RenameTag(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func DeleteTag(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name"})
+	v := rest.DeleteTag(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetCollections(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name"})
+	v := rest.GetCollections(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func SetCollection(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "Description", "FileHashes"})
+	v := rest.SetCollection(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func DeleteCollection(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name"})
+	v := rest.DeleteCollection(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func AddCollectionFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "FileHashes"})
+	v := rest.AddCollectionFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func RemoveCollectionFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "FileHashes"})
+	v := rest.RemoveCollectionFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func CollectionAction(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "Action", "Duration", "GasLimit", "Group", "Operation", "Recipient", "DownloadLimit", "Password"})
+	v := rest.CollectionAction(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
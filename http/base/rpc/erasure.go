@@ -0,0 +1,36 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func UploadFileErasure(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path", "Desc", "DataShards", "ParityShards", "Duration", "ProveLevel",
+		"Privilege", "StoreType", "EncryptPassword", "WhiteList", "Password"})
+	v := rest.UploadFileErasure(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetErasureManifests(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Id"})
+	v := rest.GetErasureManifests(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func DownloadFileErasure(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Id", "DecryptPassword", "Password"})
+	v := rest.DownloadFileErasure(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -10,8 +10,13 @@ import (
 // file apis
 
 func UploadFile(cmd []interface{}) map[string]interface{} {
+	// CheckDuplicate is optional
+	if len(cmd) == 16 {
+		cmd = append(cmd, false)
+	}
 	params := convertSliceToMap(cmd, []string{"Path", "Password", "Desc", "WhiteList", "EncryptPassword",
-		"EncryptNodeAddr", "Url", "Share", "Duration", "ProveLevel", "Privilege", "CopyNum", "StoreType", "RealFileSize"})
+		"EncryptNodeAddr", "Url", "Share", "Duration", "ProveLevel", "Privilege", "CopyNum", "StoreType", "RealFileSize",
+		"RejectDuplicate", "EncryptType", "CheckDuplicate"})
 	v := rest.UploadFile(params)
 	ret, err := parseRestResult(v)
 	if err != nil {
@@ -20,6 +25,25 @@ func UploadFile(cmd []interface{}) map[string]interface{} {
 	return responseSuccess(ret)
 }
 
+func ReuseDuplicateUpload(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path", "Action", "Url", "Duration", "Password"})
+	v := rest.ReuseDuplicateUpload(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetResumableUploads(cmd []interface{}) map[string]interface{} {
+	v := rest.GetResumableUploads(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
 func DeleteFile(cmd []interface{}) map[string]interface{} {
 	params := convertSliceToMap(cmd, []string{"Hash", "Password", "GasLimit"})
 	v := rest.DeleteUploadFile(params)
@@ -31,7 +55,16 @@ func DeleteFile(cmd []interface{}) map[string]interface{} {
 }
 
 func DownloadFile(cmd []interface{}) map[string]interface{} {
-	params := convertSliceToMap(cmd, []string{"Hash", "Url", "Link", "DecryptPassword", "MaxPeerNum", "SetFileName", "Password"})
+	if len(cmd) == 7 {
+		// encrypt type is optional
+		cmd = append(cmd, "")
+	}
+	if len(cmd) == 8 {
+		// share link is optional
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"Hash", "Url", "Link", "DecryptPassword", "MaxPeerNum", "SetFileName",
+		"Password", "EncryptType", "ShareLink"})
 	checkPasswordRet := rest.CheckPassword(params)
 	errorCode, _ := checkPasswordRet["Error"].(int64)
 	if errorCode != 0 {
@@ -127,7 +160,11 @@ func EncryptFileA(cmd []interface{}) map[string]interface{} {
 }
 
 func DecryptFileA(cmd []interface{}) map[string]interface{} {
-	params := convertSliceToMap(cmd, []string{"Path", "PrivateKey"})
+	if len(cmd) == 2 {
+		// password is only required to decrypt with the key of local account
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"Path", "PrivateKey", "Password"})
 	v := rest.DecryptFileA(params)
 	ret, err := parseRestResult(v)
 	if err != nil {
@@ -136,6 +173,26 @@ func DecryptFileA(cmd []interface{}) map[string]interface{} {
 	return responseSuccess(ret)
 }
 
+func AddFileRecipients(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path", "Addresses", "Password"})
+	v := rest.AddFileRecipients(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetFileRecipients(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path"})
+	v := rest.GetFileRecipients(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
 func GetFileShareIncome(cmd []interface{}) map[string]interface{} {
 	params := convertSliceToMap(cmd, []string{"Begin", "End", "Offset", "Limit"})
 	v := rest.GetFileShareIncome(params)
@@ -0,0 +1,26 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func SearchFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Kind", "Type", "Mime", "Tag", "MinSize", "MaxSize", "CreatedFrom",
+		"CreatedTo", "Owner", "Name", "Text", "Sort", "Desc", "Cursor", "Limit"})
+	v := rest.SearchFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func SetFileMetadata(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Kind", "Hash", "Description", "Tags"})
+	v := rest.SetFileMetadata(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,15 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func GetCostForecast(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Months", "UserspacePeriod"})
+	v := rest.GetCostForecast(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
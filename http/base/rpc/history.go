@@ -0,0 +1,25 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func ExportTransferHistory(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path"})
+	v := rest.ExportTransferHistory(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func ImportTransferHistory(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path"})
+	v := rest.ImportTransferHistory(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,34 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func GetNodeExit(cmd []interface{}) map[string]interface{} {
+	v := rest.GetNodeExit(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func StartNodeExit(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Password", "MinReplicas"})
+	v := rest.StartNodeExit(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func CancelNodeExit(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Password"})
+	v := rest.CancelNodeExit(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func SetNodeMonitorPolicy(cmd []interface{}) map[string]interface{} {
+	if len(cmd) == 4 {
+		// password is optional, it's required if auto extend is enabled
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"ServiceTimeThresholds", "AutoExtend", "AutoExtendBefore",
+		"ExtendPeriod", "Password"})
+	v := rest.SetNodeMonitorPolicy(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetNodeMonitorPolicy(cmd []interface{}) map[string]interface{} {
+	v := rest.GetNodeMonitorPolicy(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetNodeMonitor(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Days"})
+	v := rest.GetNodeMonitor(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,33 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func CheckVolumeResize(cmd []interface{}) map[string]interface{} {
+	if len(cmd) == 1 {
+		// data directories are optional
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"Volume", "DataDirs"})
+	v := rest.CheckVolumeResize(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func ResizeVolume(cmd []interface{}) map[string]interface{} {
+	if len(cmd) == 2 {
+		// data directories are optional
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"Volume", "Password", "DataDirs"})
+	v := rest.ResizeVolume(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func CheckNodeSetup(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"NodeAddr", "Volume", "ServicePeriod", "SectorSize", "ProveLevel",
+		"ChannelAmount"})
+	v := rest.CheckNodeSetup(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func SetupNode(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"NodeAddr", "Volume", "ServicePeriod", "SectorSize", "ProveLevel",
+		"ChannelAmount", "Password"})
+	v := rest.SetupNode(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -83,3 +83,62 @@ func GetAllPocTasks(cmd []interface{}) map[string]interface{} {
 	}
 	return responseSuccess(ret)
 }
+
+func GetPlotJobs(cmd []interface{}) map[string]interface{} {
+	v := rest.GetPlotJobs(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func CancelPlotJob(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"TaskId"})
+	v := rest.CancelPlotJob(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func VerifyPlotFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Path", "Samples"})
+	v := rest.VerifyPlotFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func PlanPlotFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Capacity", "FileSize"})
+	v := rest.PlanPlotFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func StartPlotPlan(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Capacity", "FileSize", "CreateSector"})
+	v := rest.StartPlotPlan(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func SetPlotJobLimits(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"MaxJobs", "Threads", "MinFreeSpace"})
+	v := rest.SetPlotJobLimits(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/http/rest"
+)
+
+func AddFileRevision(cmd []interface{}) map[string]interface{} {
+	if len(cmd) < 5 {
+		return responsePackError(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	params := convertSliceToMap(cmd, []string{"Name", "Hash", "Password", "Comment", "Url"})
+	v := rest.AddFileRevision(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetFileRevisions(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name"})
+	v := rest.GetFileRevisions(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func RollbackFileRevision(cmd []interface{}) map[string]interface{} {
+	if len(cmd) < 3 {
+		return responsePackError(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	params := convertSliceToMap(cmd, []string{"Name", "Version", "Password"})
+	v := rest.RollbackFileRevision(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func PruneFileRevisions(cmd []interface{}) map[string]interface{} {
+	if len(cmd) < 3 {
+		return responsePackError(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if len(cmd) == 3 {
+		// gas limit is optional
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"Name", "Keep", "Password", "GasLimit"})
+	v := rest.PruneFileRevisions(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
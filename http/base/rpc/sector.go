@@ -44,6 +44,41 @@ func GetSectorInfo(cmd []interface{}) map[string]interface{} {
 	return responseSuccess(ret)
 }
 
+func GetSectorsSummary(cmd []interface{}) map[string]interface{} {
+	v := rest.GetSectorsSummary(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func PlanSectorRebalance(cmd []interface{}) map[string]interface{} {
+	if len(cmd) < 1 {
+		return responsePackError(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	params := convertSliceToMap(cmd, []string{"SectorId", "CreateSector"})
+	v := rest.PlanSectorRebalance(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func RebalanceSector(cmd []interface{}) map[string]interface{} {
+	if len(cmd) < 3 {
+		return responsePackError(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	params := convertSliceToMap(cmd, []string{"SectorId", "CreateSector", "Password", "GasLimit"})
+	v := rest.RebalanceSector(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
 func GetSectorInfosForNode(cmd []interface{}) map[string]interface{} {
 	if len(cmd) < 1 {
 		return responsePackError(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
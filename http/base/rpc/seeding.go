@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func SetSeedingPolicy(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"AllowList", "DenyList", "MinPricePerBlock", "MaxRatio", "MaxDuration",
+		"FileLimits", "Windows", "MinProfit", "ProfitPeriod"})
+	v := rest.SetSeedingPolicy(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetSeedingPolicy(cmd []interface{}) map[string]interface{} {
+	v := rest.GetSeedingPolicy(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetSeedStates(cmd []interface{}) map[string]interface{} {
+	v := rest.GetSeedStates(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func ResumeSeeding(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"FileHash"})
+	v := rest.ResumeSeeding(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
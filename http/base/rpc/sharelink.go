@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func CreateShareLink(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Hash", "Recipient", "Duration", "DownloadLimit", "Password"})
+	v := rest.CreateShareLink(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetShareLinks(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Hash"})
+	v := rest.GetShareLinks(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func RevokeShareLink(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Id", "Password"})
+	v := rest.RevokeShareLink(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func VerifyShareLink(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Link", "Hash", "Address"})
+	v := rest.VerifyShareLink(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func SignShareRequest(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Hash", "Link", "Password"})
+	v := rest.SignShareRequest(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,38 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func SetSpaceGuardPolicy(cmd []interface{}) map[string]interface{} {
+	if len(cmd) == 9 {
+		// password is optional, it's required if auto extend is enabled
+		cmd = append(cmd, "")
+	}
+	params := convertSliceToMap(cmd, []string{"UsageThresholds", "ExpiryThresholds", "AutoExtend", "AutoUsage",
+		"AddSize", "AutoExpiry", "AddPeriod", "Budget", "BudgetPeriod", "Password"})
+	v := rest.SetSpaceGuardPolicy(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetSpaceGuardPolicy(cmd []interface{}) map[string]interface{} {
+	v := rest.GetSpaceGuardPolicy(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetSpaceGuardActions(cmd []interface{}) map[string]interface{} {
+	v := rest.GetSpaceGuardActions(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -0,0 +1,34 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func GetTrash(cmd []interface{}) map[string]interface{} {
+	v := rest.GetTrash(nil)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func RestoreTrashFiles(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"FileHashes"})
+	v := rest.RestoreTrashFiles(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func EmptyTrash(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"FileHashes", "Password"})
+	v := rest.EmptyTrash(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
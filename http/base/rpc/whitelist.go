@@ -0,0 +1,45 @@
+package rpc
+
+import (
+	"github.com/saveio/edge/http/rest"
+)
+
+func SetWhitelistGroup(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "Members", "StartHeight", "ExpiredHeight"})
+	v := rest.SetWhitelistGroup(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func GetWhitelistGroups(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name"})
+	v := rest.GetWhitelistGroups(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func DeleteWhitelistGroup(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name"})
+	v := rest.DeleteWhitelistGroup(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
+
+func ApplyWhitelistGroup(cmd []interface{}) map[string]interface{} {
+	params := convertSliceToMap(cmd, []string{"Name", "FileHashes", "Operation", "Password"})
+	v := rest.ApplyWhitelistGroup(params)
+	ret, err := parseRestResult(v)
+	if err != nil {
+		return responsePackError(err.Code, err.Error.Error())
+	}
+	return responseSuccess(ret)
+}
@@ -78,10 +78,47 @@ func StartRPCServer() error {
 	rpc.HandleFunc("channelcooperativesettle", rpc.ChannelCooperativeSettle)
 
 	rpc.HandleFunc("uploadfile", rpc.UploadFile)
+	rpc.HandleFunc("reuseduplicateupload", rpc.ReuseDuplicateUpload)
+	rpc.HandleFunc("getresumableuploads", rpc.GetResumableUploads)
+	rpc.HandleFunc("uploadfileerasure", rpc.UploadFileErasure)
+	rpc.HandleFunc("geterasuremanifests", rpc.GetErasureManifests)
+	rpc.HandleFunc("downloadfileerasure", rpc.DownloadFileErasure)
 	rpc.HandleFunc("deletefile", rpc.DeleteFile)
 	rpc.HandleFunc("downloadFile", rpc.DownloadFile)
 	rpc.HandleFunc("getuploadfiles", rpc.GetUploadFiles)
 	rpc.HandleFunc("getdownloadfiles", rpc.GetDownloadFiles)
+	rpc.HandleFunc("searchfiles", rpc.SearchFiles)
+	rpc.HandleFunc("setfilemetadata", rpc.SetFileMetadata)
+	rpc.HandleFunc("gettags", rpc.GetTags)
+	rpc.HandleFunc("tagfiles", rpc.TagFiles)
+	rpc.HandleFunc("untagfiles", rpc.UntagFiles)
+	rpc.HandleFunc("renametag", rpc.RenameTag)
+	rpc.HandleFunc("deletetag", rpc.DeleteTag)
+	rpc.HandleFunc("getcollections", rpc.GetCollections)
+	rpc.HandleFunc("setcollection", rpc.SetCollection)
+	rpc.HandleFunc("deletecollection", rpc.DeleteCollection)
+	rpc.HandleFunc("addcollectionfiles", rpc.AddCollectionFiles)
+	rpc.HandleFunc("removecollectionfiles", rpc.RemoveCollectionFiles)
+	rpc.HandleFunc("collectionaction", rpc.CollectionAction)
+	rpc.HandleFunc("gettrash", rpc.GetTrash)
+	rpc.HandleFunc("restoretrashfiles", rpc.RestoreTrashFiles)
+	rpc.HandleFunc("emptytrash", rpc.EmptyTrash)
+	rpc.HandleFunc("exporttransferhistory", rpc.ExportTransferHistory)
+	rpc.HandleFunc("importtransferhistory", rpc.ImportTransferHistory)
+	rpc.HandleFunc("getcostforecast", rpc.GetCostForecast)
+	rpc.HandleFunc("setspaceguardpolicy", rpc.SetSpaceGuardPolicy)
+	rpc.HandleFunc("getspaceguardpolicy", rpc.GetSpaceGuardPolicy)
+	rpc.HandleFunc("getspaceguardactions", rpc.GetSpaceGuardActions)
+	rpc.HandleFunc("setnodemonitorpolicy", rpc.SetNodeMonitorPolicy)
+	rpc.HandleFunc("getnodemonitorpolicy", rpc.GetNodeMonitorPolicy)
+	rpc.HandleFunc("getnodemonitor", rpc.GetNodeMonitor)
+	rpc.HandleFunc("checknodesetup", rpc.CheckNodeSetup)
+	rpc.HandleFunc("setupnode", rpc.SetupNode)
+	rpc.HandleFunc("getnodeexit", rpc.GetNodeExit)
+	rpc.HandleFunc("startnodeexit", rpc.StartNodeExit)
+	rpc.HandleFunc("cancelnodeexit", rpc.CancelNodeExit)
+	rpc.HandleFunc("checkvolumeresize", rpc.CheckVolumeResize)
+	rpc.HandleFunc("resizevolume", rpc.ResizeVolume)
 	rpc.HandleFunc("gettransferlist", rpc.GetTransferList)
 	rpc.HandleFunc("calculateuploadfee", rpc.CalculateUploadFee)
 	rpc.HandleFunc("getdownloadfileinfo", rpc.GetDownloadFileInfo)
@@ -89,14 +126,34 @@ func StartRPCServer() error {
 	rpc.HandleFunc("decryptfile", rpc.DecryptFile)
 	rpc.HandleFunc("encryptfilea", rpc.EncryptFileA)
 	rpc.HandleFunc("decryptfilea", rpc.DecryptFileA)
+	rpc.HandleFunc("addfilerecipients", rpc.AddFileRecipients)
+	rpc.HandleFunc("getfilerecipients", rpc.GetFileRecipients)
 	rpc.HandleFunc("getfileshareincome", rpc.GetFileShareIncome)
 	rpc.HandleFunc("getfilesharerevenue", rpc.GetFileShareRevenue)
+	rpc.HandleFunc("getsharestats", rpc.GetShareStats)
+	rpc.HandleFunc("setseedingpolicy", rpc.SetSeedingPolicy)
+	rpc.HandleFunc("getseedingpolicy", rpc.GetSeedingPolicy)
+	rpc.HandleFunc("getseedstates", rpc.GetSeedStates)
+	rpc.HandleFunc("resumeseeding", rpc.ResumeSeeding)
+	rpc.HandleFunc("createsharelink", rpc.CreateShareLink)
+	rpc.HandleFunc("getsharelinks", rpc.GetShareLinks)
+	rpc.HandleFunc("revokesharelink", rpc.RevokeShareLink)
+	rpc.HandleFunc("verifysharelink", rpc.VerifyShareLink)
+	rpc.HandleFunc("signsharerequest", rpc.SignShareRequest)
 	rpc.HandleFunc("whitelistoperate", rpc.WhiteListOperate)
 	rpc.HandleFunc("getfilewhitelist", rpc.GetFileWhiteList)
+	rpc.HandleFunc("setwhitelistgroup", rpc.SetWhitelistGroup)
+	rpc.HandleFunc("getwhitelistgroups", rpc.GetWhitelistGroups)
+	rpc.HandleFunc("deletewhitelistgroup", rpc.DeleteWhitelistGroup)
+	rpc.HandleFunc("applywhitelistgroup", rpc.ApplyWhitelistGroup)
 	rpc.HandleFunc("getprovedetail", rpc.GetUploadFileProveDetail)
 	rpc.HandleFunc("getuserspace", rpc.GetUserSpace)
 	rpc.HandleFunc("setuserspace", rpc.SetUserSpace)
 	rpc.HandleFunc("getuserspacerecords", rpc.GetUserSpaceRecords)
+	rpc.HandleFunc("addfilerevision", rpc.AddFileRevision)
+	rpc.HandleFunc("getfilerevisions", rpc.GetFileRevisions)
+	rpc.HandleFunc("rollbackfilerevision", rpc.RollbackFileRevision)
+	rpc.HandleFunc("prunefilerevisions", rpc.PruneFileRevisions)
 
 	rpc.HandleFunc("registernode", rpc.RegisterNode)
 	rpc.HandleFunc("unregisternode", rpc.UnregisterNode)
@@ -117,6 +174,9 @@ func StartRPCServer() error {
 	rpc.HandleFunc("deletesector", rpc.DeleteSector)
 	rpc.HandleFunc("getsectorinfo", rpc.GetSectorInfo)
 	rpc.HandleFunc("getsectorinfosfornode", rpc.GetSectorInfosForNode)
+	rpc.HandleFunc("getsectorssummary", rpc.GetSectorsSummary)
+	rpc.HandleFunc("plansectorrebalance", rpc.PlanSectorRebalance)
+	rpc.HandleFunc("rebalancesector", rpc.RebalanceSector)
 
 	rpc.HandleFunc("generateplotfile", rpc.GeneratePlotFile)
 	rpc.HandleFunc("getallplotfiles", rpc.GetAllPlotFiles)
@@ -124,6 +184,12 @@ func StartRPCServer() error {
 	rpc.HandleFunc("addplotfiles", rpc.AddPlotFiles)
 	rpc.HandleFunc("getallprovedplotfile", rpc.GetAllProvedPlotFile)
 	rpc.HandleFunc("getallpoctasks", rpc.GetAllPocTasks)
+	rpc.HandleFunc("getplotjobs", rpc.GetPlotJobs)
+	rpc.HandleFunc("cancelplotjob", rpc.CancelPlotJob)
+	rpc.HandleFunc("setplotjoblimits", rpc.SetPlotJobLimits)
+	rpc.HandleFunc("verifyplotfiles", rpc.VerifyPlotFiles)
+	rpc.HandleFunc("planplotfiles", rpc.PlanPlotFiles)
+	rpc.HandleFunc("startplotplan", rpc.StartPlotPlan)
 	log.Debugf("start json rpc at port base %v, offset %v",
 		config.Parameters.BaseConfig.PortBase, config.Parameters.BaseConfig.JsonRpcPortOffset)
 
@@ -0,0 +1,258 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/themis/common/log"
+)
+
+func GetTags(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	tags, err := dsp.DspService.GetTags()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = tags
+	return resp
+}
+
+func TagFiles(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("TagFiles cmd:%v", cmd)
+	return updateFileTags(cmd, false)
+}
+
+func UntagFiles(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("UntagFiles cmd:%v", cmd)
+	return updateFileTags(cmd, true)
+}
+
+func RenameTag(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	newName, ok := cmd["NewName"].(string)
+	if !ok || len(newName) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.RenameTag(name, newName)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func DeleteTag(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.DeleteTag(name)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetCollections(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, _ := cmd["Name"].(string)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if len(name) > 0 {
+		collection, err := dsp.DspService.GetCollection(name)
+		if err != nil {
+			return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+		}
+		resp["Result"] = collection
+		return resp
+	}
+	collections, err := dsp.DspService.GetCollections()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = collections
+	return resp
+}
+
+func SetCollection(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SetCollection cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	description, _ := cmd["Description"].(string)
+	fileHashes, ok := stringList(cmd["FileHashes"])
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.SetCollection(name, description, fileHashes)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func DeleteCollection(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if err := dsp.DspService.DeleteCollection(name); err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	return resp
+}
+
+func AddCollectionFiles(cmd map[string]interface{}) map[string]interface{} {
+	return updateCollectionFiles(cmd, false)
+}
+
+func RemoveCollectionFiles(cmd map[string]interface{}) map[string]interface{} {
+	return updateCollectionFiles(cmd, true)
+}
+
+func CollectionAction(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("CollectionAction cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	action, ok := cmd["Action"].(string)
+	if !ok || len(action) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	pwd, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	params := &dsp.CollectionActionParams{}
+	params.WhitelistGroup, _ = cmd["Group"].(string)
+	params.Operation, _ = cmd["Operation"].(string)
+	params.Recipient, _ = cmd["Recipient"].(string)
+	for key, value := range map[string]*uint64{
+		"Duration": &params.Duration,
+		"GasLimit": &params.GasLimit,
+	} {
+		if cmd[key] == nil {
+			continue
+		}
+		v, err := dsp.ToUint64(cmd[key])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		*value = v
+	}
+	if cmd["DownloadLimit"] != nil {
+		limit, err := dsp.ToUint64(cmd["DownloadLimit"])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		params.DownloadLimit = uint32(limit)
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(pwd); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	ret, err := dsp.DspService.CollectionAction(name, action, params)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func updateFileTags(cmd map[string]interface{}, remove bool) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	fileHashes, ok := stringList(cmd["FileHashes"])
+	if !ok || len(fileHashes) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	tags, ok := stringList(cmd["Tags"])
+	if !ok || len(tags) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	update := dsp.DspService.TagFiles
+	if remove {
+		update = dsp.DspService.UntagFiles
+	}
+	ret, err := update(fileHashes, tags)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func updateCollectionFiles(cmd map[string]interface{}, remove bool) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	fileHashes, ok := stringList(cmd["FileHashes"])
+	if !ok || len(fileHashes) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	update := dsp.DspService.AddCollectionFiles
+	if remove {
+		update = dsp.DspService.RemoveCollectionFiles
+	}
+	ret, err := update(name, fileHashes)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+// stringList. convert the optional string list param
+func stringList(value interface{}) ([]string, bool) {
+	if value == nil {
+		return nil, true
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	strs := make([]string, 0, len(list))
+	for _, item := range list {
+		str, ok := item.(string)
+		if !ok || len(str) == 0 {
+			return nil, false
+		}
+		strs = append(strs, str)
+	}
+	return strs, true
+}
@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/themis/common/log"
+)
+
+func UploadFileErasure(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	path, ok := cmd["Path"].(string)
+	if !ok || len(path) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	desc, _ := cmd["Desc"].(string)
+	if strings.TrimSpace(desc) == "" {
+		desc = filepath.Base(path)
+	}
+	dataShards, err := dsp.ToUint64(cmd["DataShards"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	parityShards, err := dsp.ToUint64(cmd["ParityShards"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	whitelist := make([]string, 0)
+	wh, _ := cmd["WhiteList"].([]interface{})
+	for _, w := range wh {
+		if _, ok := w.(string); !ok {
+			continue
+		}
+		whitelist = append(whitelist, w.(string))
+	}
+	pwd, _ := cmd["EncryptPassword"].(string)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	manifest, derr := dsp.DspService.UploadFileErasure(path, desc, int(dataShards), int(parityShards),
+		cmd["Duration"], cmd["ProveLevel"], cmd["Privilege"], cmd["StoreType"], pwd, whitelist)
+	if derr != nil {
+		log.Errorf("upload erasure file failed, err %v", derr.Error)
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = manifest
+	return resp
+}
+
+func GetErasureManifests(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	id, _ := cmd["Id"].(string)
+	if len(id) == 0 {
+		manifests, derr := dsp.DspService.GetErasureManifests()
+		if derr != nil {
+			return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+		}
+		resp["Result"] = manifests
+		return resp
+	}
+	manifest, derr := dsp.DspService.GetErasureManifest(id)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = manifest
+	return resp
+}
+
+func DownloadFileErasure(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	id, ok := cmd["Id"].(string)
+	if !ok || len(id) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	decryptPwd, _ := cmd["DecryptPassword"].(string)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	manifest, derr := dsp.DspService.DownloadFileErasure(id, decryptPwd)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = manifest
+	return resp
+}
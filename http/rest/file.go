@@ -52,13 +52,46 @@ func UploadFile(cmd map[string]interface{}) map[string]interface{} {
 	ena, _ := cmd["EncryptNodeAddr"].(string)
 	url, _ := cmd["Url"].(string)
 	share, _ := cmd["Share"].(bool)
+	checkDuplicate, _ := cmd["CheckDuplicate"].(bool)
+	rejectDuplicate, _ := cmd["RejectDuplicate"].(bool)
+	encryptType, _ := cmd["EncryptType"].(string)
+	if encryptType == dsp.ENCRYPT_TYPE_GCM && len(pwd) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
 	if dsp.DspService == nil {
 		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
 	}
-	opt, err := dsp.DspService.UploadFile(taskId, path, desc, cmd["Duration"], cmd["ProveLevel"],
+	var duplicate *dsp.DuplicateUpload
+	var derr *dsp.DspErr
+	if checkDuplicate || rejectDuplicate {
+		duplicate, derr = dsp.DspService.FindDuplicateUpload(path)
+		if derr != nil {
+			log.Errorf("find duplicate upload of %s failed, err %v", path, derr.Error)
+		}
+	}
+	if duplicate != nil && rejectDuplicate {
+		resp = ResponsePackWithErrMsg(dsp.DSP_UPLOAD_FILE_DUPLICATED, dsp.ErrMaps[dsp.DSP_UPLOAD_FILE_DUPLICATED].Error())
+		resp["Result"] = map[string]interface{}{
+			"Duplicate": duplicate,
+		}
+		return resp
+	}
+	uploadPath := path
+	if encryptType == dsp.ENCRYPT_TYPE_GCM {
+		// upload the encrypted stream instead, the sdk doesn't encrypt it again
+		uploadPath, derr = dsp.DspService.SealFileForUpload(path, pwd)
+		if derr != nil {
+			return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+		}
+		pwd = ""
+	}
+	opt, err := dsp.DspService.UploadFile(taskId, uploadPath, desc, cmd["Duration"], cmd["ProveLevel"],
 		cmd["Privilege"], cmd["CopyNum"], cmd["StoreType"], cmd["RealFileSize"], pwd, ena, url, whitelist, share)
 	if err != nil {
 		log.Errorf("upload file failed, err %v", err)
+		if uploadPath != path {
+			os.Remove(uploadPath)
+		}
 		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
 	}
 	uploadOption := make(map[string]interface{})
@@ -69,12 +102,16 @@ func UploadFile(cmd map[string]interface{}) map[string]interface{} {
 	uploadOption["ExpiredHeight"] = opt.ExpiredHeight
 	uploadOption["Privilege"] = opt.Privilege
 	uploadOption["CopyNum"] = opt.CopyNum
-	uploadOption["Encrypt"] = opt.Encrypt
+	uploadOption["Encrypt"] = opt.Encrypt || encryptType == dsp.ENCRYPT_TYPE_GCM
+	uploadOption["EncryptType"] = encryptType
 	uploadOption["EncryptPassword"] = string(opt.EncryptPassword)
 	uploadOption["Url"] = string(opt.DnsURL)
 	uploadOption["WhiteList"] = whitelist
 	uploadOption["Share"] = opt.Share
 	uploadOption["StorageType"] = opt.StorageType
+	if duplicate != nil {
+		uploadOption["Duplicate"] = duplicate
+	}
 	resp["Result"] = uploadOption
 	return resp
 }
@@ -193,6 +230,8 @@ func DownloadFile(cmd map[string]interface{}) map[string]interface{} {
 	}
 	setFileName, _ := cmd["SetFileName"].(bool)
 	inOrder, _ := cmd["InOrder"].(bool)
+	encryptType, _ := cmd["EncryptType"].(string)
+	shareLink, _ := cmd["ShareLink"].(string)
 	if dsp.DspService == nil {
 		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
 	}
@@ -203,7 +242,14 @@ func DownloadFile(cmd map[string]interface{}) map[string]interface{} {
 	// if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
 	// 	return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
 	// }
-	err := dsp.DspService.DownloadFile(taskId, fileHash, url, link, decryptedPassword, uint64(max), setFileName, inOrder)
+	var err *dsp.DspErr
+	if encryptType == dsp.ENCRYPT_TYPE_GCM {
+		err = dsp.DspService.DownloadFileStream(taskId, fileHash, url, link, decryptedPassword, uint64(max),
+			setFileName, inOrder, shareLink)
+	} else {
+		err = dsp.DspService.DownloadFile(taskId, fileHash, url, link, decryptedPassword, uint64(max), setFileName,
+			inOrder, shareLink)
+	}
 	if err != nil {
 		log.Errorf("download file failed, err %v", err)
 		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
@@ -245,13 +291,14 @@ func GetUploadFiles(cmd map[string]interface{}) map[string]interface{} {
 	createdAtEnd := edgeUtils.StringToUint64(cmd["CreatedAtEnd"])
 	updatedAt := edgeUtils.StringToUint64(cmd["UpdatedAt"])
 	updatedAtEnd := edgeUtils.StringToUint64(cmd["UpdatedAtEnd"])
+	group, _ := cmd["Group"].(string)
 
 	if dsp.DspService == nil {
 		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
 	}
 	log.Debugf("cmd :%v, type %d, offset %d limit %d", cmd, fileType, offset, limit)
 	files, totalCount, err := dsp.DspService.GetUploadFiles(dsp.DspFileListType(fileType),
-		offset, limit, createdAt, createdAtEnd, updatedAt, updatedAtEnd, dsp.UploadFileFilterType(filter))
+		offset, limit, createdAt, createdAtEnd, updatedAt, updatedAtEnd, dsp.UploadFileFilterType(filter), group)
 	if err != nil {
 		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
 	}
@@ -355,10 +402,68 @@ func CalculateUploadFee(cmd map[string]interface{}) map[string]interface{} {
 	if derr != nil {
 		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
 	}
+	if checkDuplicate, _ := cmd["CheckDuplicate"].(string); checkDuplicate == "true" || checkDuplicate == "1" {
+		res.Duplicate, derr = dsp.DspService.FindDuplicateUpload(string(path))
+		if derr != nil {
+			log.Errorf("find duplicate upload of %s failed, err %v", path, derr.Error)
+		}
+	}
+	dataShards, _ := dsp.ToUint64(cmd["DataShards"])
+	parityShards, _ := dsp.ToUint64(cmd["ParityShards"])
+	if dataShards > 0 && parityShards > 0 {
+		res.Erasure, derr = dsp.DspService.CalculateErasureUploadFee(string(path), res, cmd["Duration"],
+			cmd["ProveLevel"], cmd["CopyNum"], cmd["WhiteList"], cmd["StoreType"], int(dataShards), int(parityShards))
+		if derr != nil {
+			return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+		}
+	}
 	resp["Result"] = res
 	return resp
 }
 
+func ReuseDuplicateUpload(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	path, ok := cmd["Path"].(string)
+	if !ok || len(path) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	action, ok := cmd["Action"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	url, _ := cmd["Url"].(string)
+	duration, _ := dsp.ToUint64(cmd["Duration"])
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	ret, derr := dsp.DspService.ReuseDuplicateUpload(path, action, url, duration)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetResumableUploads(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, derr := dsp.DspService.GetResumableUploads()
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
 func GetDownloadFileInfo(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("GetDownloadFileInfo cmd:%v", cmd)
 	resp := ResponsePack(dsp.SUCCESS)
@@ -399,9 +504,12 @@ func EncryptFile(cmd map[string]interface{}) map[string]interface{} {
 	if pErr != nil {
 		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, pErr.Error())
 	}
+	encryptType, _ := cmd["EncryptType"].(string)
 	var dErr *dsp.DspErr
 	if stat.IsDir() {
 		dErr = dsp.DspService.EncryptFileInDir(path, password)
+	} else if encryptType == dsp.ENCRYPT_TYPE_GCM {
+		_, dErr = dsp.DspService.EncryptFileStream(path, password)
 	} else {
 		dErr = dsp.DspService.EncryptFile(path, password)
 	}
@@ -467,8 +575,15 @@ func EncryptFileA(cmd map[string]interface{}) map[string]interface{} {
 	if !ok || len(path) == 0 {
 		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
 	}
-	address, ok := cmd["Address"].(string)
-	if !ok || len(address) == 0 {
+	address, _ := cmd["Address"].(string)
+	addresses := make([]string, 0)
+	v, _ := cmd["Addresses"].([]interface{})
+	for _, a := range v {
+		if str, ok := a.(string); ok && len(str) > 0 {
+			addresses = append(addresses, str)
+		}
+	}
+	if len(address) == 0 && len(addresses) == 0 {
 		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
 	}
 	if dsp.DspService == nil {
@@ -478,6 +593,21 @@ func EncryptFileA(cmd map[string]interface{}) map[string]interface{} {
 	if pErr != nil {
 		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, pErr.Error())
 	}
+	if len(addresses) > 0 {
+		// multi recipients, the content key is wrapped for each address
+		if stat.IsDir() {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		if len(address) > 0 {
+			addresses = append(addresses, address)
+		}
+		ret, dErr := dsp.DspService.EncryptFileForRecipients(path, addresses)
+		if dErr != nil {
+			return ResponsePackWithErrMsg(dErr.Code, dErr.Error.Error())
+		}
+		resp["Result"] = ret
+		return resp
+	}
 	var dErr *dsp.DspErr
 	if stat.IsDir() {
 		dErr = dsp.DspService.EncryptFileAInDIr(path, address)
@@ -497,10 +627,7 @@ func DecryptFileA(cmd map[string]interface{}) map[string]interface{} {
 	if !ok || len(path) == 0 {
 		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
 	}
-	privKey, ok := cmd["PrivateKey"].(string)
-	if !ok || len(privKey) == 0 {
-		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
-	}
+	privKey, _ := cmd["PrivateKey"].(string)
 	fileName, _ := cmd["FileName"].(string)
 	if dsp.DspService == nil {
 		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
@@ -509,6 +636,16 @@ func DecryptFileA(cmd map[string]interface{}) map[string]interface{} {
 	if pErr != nil {
 		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, pErr.Error())
 	}
+	// the local account key is used for multi-recipient file if private key is empty
+	if len(privKey) == 0 {
+		if stat.IsDir() || !dsp.DspService.IsEnvelopeEncrypted(path) {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		password, _ := cmd["Password"].(string)
+		if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+			return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+		}
+	}
 	outPath := ""
 	var dErr *dsp.DspErr
 	if stat.IsDir() {
@@ -527,6 +664,59 @@ func DecryptFileA(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+func AddFileRecipients(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("AddFileRecipients cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	path, ok := cmd["Path"].(string)
+	if !ok || len(path) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	v, _ := cmd["Addresses"].([]interface{})
+	addresses := make([]string, 0, len(v))
+	for _, a := range v {
+		if str, ok := a.(string); ok && len(str) > 0 {
+			addresses = append(addresses, str)
+		}
+	}
+	if len(addresses) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	// the content key is unwrapped with the key of local account
+	if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	ret, dErr := dsp.DspService.AddFileRecipients(path, addresses)
+	if dErr != nil {
+		return ResponsePackWithErrMsg(dErr.Code, dErr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetFileRecipients(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	path, ok := cmd["Path"].(string)
+	if !ok || len(path) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, dErr := dsp.DspService.GetFileRecipients(path)
+	if dErr != nil {
+		return ResponsePackWithErrMsg(dErr.Code, dErr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
 func GetFileShareIncome(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("GetFileShareIncome cmd:%v", cmd)
 	resp := ResponsePack(dsp.SUCCESS)
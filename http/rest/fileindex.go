@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"strconv"
+
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/utils/fileindex"
+	"github.com/saveio/themis/common/log"
+)
+
+func SearchFiles(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SearchFiles cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	query := &fileindex.Query{}
+	query.Kind, _ = cmd["Kind"].(string)
+	query.Mime, _ = cmd["Mime"].(string)
+	query.Tag, _ = cmd["Tag"].(string)
+	query.Owner, _ = cmd["Owner"].(string)
+	query.Name, _ = cmd["Name"].(string)
+	query.Text, _ = cmd["Text"].(string)
+	query.SortBy, _ = cmd["Sort"].(string)
+	query.Cursor, _ = cmd["Cursor"].(string)
+	if desc, ok := cmd["Desc"].(string); ok && len(desc) > 0 {
+		var err error
+		if query.Desc, err = strconv.ParseBool(desc); err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+	}
+	category, err := dsp.OptionStrToUint64(cmd["Type"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	query.Category = int(category)
+	for key, value := range map[string]*uint64{
+		"MinSize":     &query.MinSize,
+		"MaxSize":     &query.MaxSize,
+		"CreatedFrom": &query.CreatedFrom,
+		"CreatedTo":   &query.CreatedTo,
+	} {
+		v, err := dsp.OptionStrToUint64(cmd[key])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		*value = v
+	}
+	limit, err := dsp.OptionStrToUint64(cmd["Limit"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	query.Limit = int(limit)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	result, derr := dsp.DspService.SearchFiles(query)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = result
+	return resp
+}
+
+func SetFileMetadata(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	hash, ok := cmd["Hash"].(string)
+	if !ok || len(hash) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	kind, _ := cmd["Kind"].(string)
+	description, _ := cmd["Description"].(string)
+	tags := make([]string, 0)
+	if cmd["Tags"] != nil {
+		list, ok := cmd["Tags"].([]interface{})
+		if !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		for _, item := range list {
+			tag, ok := item.(string)
+			if !ok {
+				return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+			}
+			tags = append(tags, tag)
+		}
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	entries, err := dsp.DspService.SetFileMetadata(kind, hash, description, tags)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = entries
+	return resp
+}
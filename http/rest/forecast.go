@@ -0,0 +1,26 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+)
+
+func GetCostForecast(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	months, err := dsp.OptionStrToUint64(cmd["Months"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	userspacePeriod, err := dsp.OptionStrToUint64(cmd["UserspacePeriod"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	forecast, derr := dsp.DspService.ForecastCost(months, userspacePeriod)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = forecast
+	return resp
+}
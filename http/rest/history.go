@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/themis/common/log"
+)
+
+func ExportTransferHistory(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("ExportTransferHistory cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	path, ok := cmd["Path"].(string)
+	if !ok || len(path) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.ExportTransferHistory(path)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func ImportTransferHistory(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("ImportTransferHistory cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	path, ok := cmd["Path"].(string)
+	if !ok || len(path) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.ImportTransferHistory(path)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/themis/common/log"
+)
+
+func GetNodeExit(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	exit, err := dsp.DspService.GetNodeExit()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = exit
+	return resp
+}
+
+func StartNodeExit(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("StartNodeExit cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	minReplicas := uint64(0)
+	if cmd["MinReplicas"] != nil {
+		v, err := dsp.ToUint64(cmd["MinReplicas"])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		minReplicas = v
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if derr := dsp.DspService.CheckPassword(password); derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	exit, derr := dsp.DspService.StartNodeExit(int(minReplicas))
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = exit
+	return resp
+}
+
+func CancelNodeExit(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("CancelNodeExit cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if derr := dsp.DspService.CheckPassword(password); derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	exit, derr := dsp.DspService.CancelNodeExit()
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = exit
+	return resp
+}
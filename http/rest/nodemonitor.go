@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/utils/nodemonitor"
+	"github.com/saveio/themis/common/log"
+)
+
+func SetNodeMonitorPolicy(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SetNodeMonitorPolicy cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	policy := &nodemonitor.Policy{}
+	var ok bool
+	if policy.ServiceTimeThresholds, ok = thresholdList(cmd["ServiceTimeThresholds"]); !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if cmd["AutoExtend"] != nil {
+		if policy.AutoExtend, ok = cmd["AutoExtend"].(bool); !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+	}
+	for key, value := range map[string]*uint64{
+		"AutoExtendBefore": &policy.AutoExtendBefore,
+		"ExtendPeriod":     &policy.ExtendPeriod,
+	} {
+		if cmd[key] == nil {
+			continue
+		}
+		v, err := dsp.ToUint64(cmd[key])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		*value = v
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	// auto extend sends node update txs without asking, the same as updating node manually
+	if policy.AutoExtend {
+		password, ok := cmd["Password"].(string)
+		if !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+			return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+		}
+	}
+	ret, err := dsp.DspService.SetNodeMonitorPolicy(policy)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetNodeMonitorPolicy(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	policy, err := dsp.DspService.GetNodeMonitorPolicy()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = policy
+	return resp
+}
+
+func GetNodeMonitor(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	days, err := dsp.OptionStrToUint64(cmd["Days"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, derr := dsp.DspService.GetNodeMonitor(days)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
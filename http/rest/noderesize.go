@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"strings"
+
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/themis/common/log"
+)
+
+func CheckVolumeResize(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	volume, dataDirs, err := volumeResizeParams(cmd)
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	plan, derr := dsp.DspService.CheckVolumeResize(volume, dataDirs)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = plan
+	return resp
+}
+
+func ResizeVolume(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("ResizeVolume cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	volume, dataDirs, err := volumeResizeParams(cmd)
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if derr := dsp.DspService.CheckPassword(password); derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	ret, derr := dsp.DspService.ResizeVolume(volume, dataDirs)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+// volumeResizeParams. the new volume, and the data directories in a list or a string separated by comma
+func volumeResizeParams(cmd map[string]interface{}) (uint64, []string, error) {
+	volume, err := dsp.ToUint64(cmd["Volume"])
+	if err != nil {
+		return 0, nil, err
+	}
+	dataDirs := make([]string, 0)
+	switch dirs := cmd["DataDirs"].(type) {
+	case string:
+		for _, dir := range strings.Split(dirs, ",") {
+			if dir = strings.TrimSpace(dir); len(dir) > 0 {
+				dataDirs = append(dataDirs, dir)
+			}
+		}
+	case []interface{}:
+		for _, dir := range dirs {
+			if d, ok := dir.(string); ok && len(d) > 0 {
+				dataDirs = append(dataDirs, d)
+			}
+		}
+	}
+	return volume, dataDirs, nil
+}
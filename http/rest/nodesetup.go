@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"time"
+
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/utils/nodesetup"
+	"github.com/saveio/themis/common/log"
+)
+
+func CheckNodeSetup(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	opts, err := nodeSetupOptions(cmd)
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	plan, derr := dsp.DspService.CheckNodeSetup(opts)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = plan
+	return resp
+}
+
+func SetupNode(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SetupNode cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	opts, err := nodeSetupOptions(cmd)
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if derr := dsp.DspService.CheckPassword(password); derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	ret, derr := dsp.DspService.SetupNode(opts)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+// nodeSetupOptions. options of node setup, the service time is ServicePeriod seconds from now
+func nodeSetupOptions(cmd map[string]interface{}) (*nodesetup.Options, error) {
+	opts := &nodesetup.Options{}
+	opts.NodeAddr, _ = cmd["NodeAddr"].(string)
+	servicePeriod := uint64(0)
+	for key, value := range map[string]*uint64{
+		"Volume":        &opts.Volume,
+		"ServicePeriod": &servicePeriod,
+		"SectorSize":    &opts.SectorSize,
+		"ProveLevel":    &opts.ProveLevel,
+		"ChannelAmount": &opts.ChannelAmount,
+	} {
+		if cmd[key] == nil || cmd[key] == "" {
+			continue
+		}
+		v, err := dsp.ToUint64(cmd[key])
+		if err != nil {
+			return nil, err
+		}
+		*value = v
+	}
+	if servicePeriod > 0 {
+		opts.ServiceTime = uint64(time.Now().Unix()) + servicePeriod
+	}
+	return opts, nil
+}
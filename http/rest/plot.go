@@ -368,3 +368,134 @@ func DeletePlotFiles(cmd map[string]interface{}) map[string]interface{} {
 	resp["Result"] = result
 	return resp
 }
+
+func GetPlotJobs(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	resp["Result"] = dsp.DspService.GetPlotJobs()
+	return resp
+}
+
+func CancelPlotJob(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	taskId, ok := cmd["TaskId"].(string)
+	if !ok || len(taskId) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	job, err := dsp.DspService.CancelPlotJob(taskId)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = job
+	return resp
+}
+
+func VerifyPlotFiles(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	path, _ := cmd["Path"].(string)
+	samples := uint64(plot.VERIFY_SAMPLE_NONCES)
+	if str, _ := cmd["Samples"].(string); len(str) > 0 {
+		var err error
+		samples, err = strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	report, derr := dsp.DspService.VerifyPlotFiles(path, samples)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = report
+	return resp
+}
+
+func PlanPlotFiles(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	capacity, fileSize, err := plotPlanParams(cmd)
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	plan, derr := dsp.DspService.PlanPlotFiles(capacity, fileSize)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = plan
+	return resp
+}
+
+func StartPlotPlan(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	capacity, fileSize, err := plotPlanParams(cmd)
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	createSector, _ := cmd["CreateSector"].(bool)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	plan, derr := dsp.DspService.StartPlotPlan(capacity, fileSize, createSector)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = plan
+	return resp
+}
+
+// plotPlanParams. parse optional capacity in MB and file size in KB of plot plan
+func plotPlanParams(cmd map[string]interface{}) (uint64, uint64, error) {
+	values := make([]uint64, 0, 2)
+	for _, key := range []string{"Capacity", "FileSize"} {
+		if str, ok := cmd[key].(string); cmd[key] == nil || (ok && len(str) == 0) {
+			values = append(values, 0)
+			continue
+		}
+		value, err := dsp.ToUint64(cmd[key])
+		if err != nil {
+			return 0, 0, err
+		}
+		values = append(values, value)
+	}
+	if values[1] > 0 && (values[1]/plot.DEFAULT_PLOT_SIZEKB == 0 || values[1]/plot.DEFAULT_PLOT_SIZEKB%8 != 0) {
+		return 0, 0, fmt.Errorf("invalid params file size, size should be an integer multiple of 2048")
+	}
+	return values[0], values[1], nil
+}
+
+func SetPlotJobLimits(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	maxJobs, err := dsp.ToUint64(cmd["MaxJobs"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	threads, minFreeSpace := uint64(0), uint64(0)
+	if cmd["Threads"] != nil {
+		if threads, err = dsp.ToUint64(cmd["Threads"]); err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+	}
+	if cmd["MinFreeSpace"] != nil {
+		if minFreeSpace, err = dsp.ToUint64(cmd["MinFreeSpace"]); err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	limits, derr := dsp.DspService.SetPlotJobLimits(int(maxJobs), threads, minFreeSpace)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = limits
+	return resp
+}
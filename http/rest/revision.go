@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"strconv"
+
+	"github.com/saveio/edge/dsp"
+)
+
+func AddFileRevision(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	hash, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	pwd, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	comment, _ := cmd["Comment"].(string)
+	url, _ := cmd["Url"].(string)
+	if checkErr := dsp.DspService.CheckPassword(pwd); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	file, err := dsp.DspService.AddFileRevision(name, hash, comment, url)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = file
+	return resp
+}
+
+func GetFileRevisions(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, _ := cmd["Name"].(string)
+	if len(name) == 0 {
+		files, err := dsp.DspService.GetVersionedFiles()
+		if err != nil {
+			return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+		}
+		resp["Result"] = files
+		return resp
+	}
+	file, err := dsp.DspService.GetFileRevisions(name)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = file
+	return resp
+}
+
+func RollbackFileRevision(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	version, ok := cmd["Version"].(float64)
+	if !ok || version <= 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	pwd, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(pwd); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	file, err := dsp.DspService.RollbackFileRevision(name, uint32(version))
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = file
+	return resp
+}
+
+func PruneFileRevisions(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	keep, ok := cmd["Keep"].(float64)
+	if !ok || keep < 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	pwd, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	gl, _ := cmd["GasLimit"].(string)
+	gasLimit := uint64(0)
+	if len(gl) > 0 {
+		var err error
+		gasLimit, err = strconv.ParseUint(gl, 10, 64)
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+	}
+	if checkErr := dsp.DspService.CheckPassword(pwd); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	ret, err := dsp.DspService.PruneFileRevisions(name, uint32(keep), gasLimit)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
@@ -90,6 +90,77 @@ func GetSectorInfosForNode(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+func GetSectorsSummary(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	summary, derr := dsp.DspService.GetSectorsSummary()
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = summary
+	return resp
+}
+
+func PlanSectorRebalance(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	sectorId, err := parseSectorId(cmd["SectorId"])
+	if err != nil || sectorId == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	createSector := parseBoolParam(cmd["CreateSector"])
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	plan, derr := dsp.DspService.PlanSectorRebalance(uint64(sectorId), createSector)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = plan
+	return resp
+}
+
+func RebalanceSector(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	sectorId, err := parseSectorId(cmd["SectorId"])
+	if err != nil || sectorId == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	createSector := parseBoolParam(cmd["CreateSector"])
+	password, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	gasLimit, err := dsp.OptionStrToUint64(cmd["GasLimit"])
+	if err != nil {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	result, derr := dsp.DspService.RebalanceSector(uint64(sectorId), createSector, gasLimit)
+	if derr != nil {
+		return ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	resp["Result"] = result
+	return resp
+}
+
+// parseBoolParam. bool param from json or "true" string from url
+func parseBoolParam(param interface{}) bool {
+	switch v := param.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "1"
+	}
+	return false
+}
+
 func parseSectorId(param interface{}) (int, error) {
 	sectorIdStr, ok := param.(string)
 	if !ok {
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/utils/seedpolicy"
+	"github.com/saveio/themis/common/log"
+)
+
+func SetSeedingPolicy(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SetSeedingPolicy cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	policy := &seedpolicy.Policy{}
+	var ok bool
+	if policy.AllowList, ok = seedingAddrList(cmd["AllowList"]); !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if policy.DenyList, ok = seedingAddrList(cmd["DenyList"]); !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	for key, value := range map[string]*uint64{
+		"MinPricePerBlock": &policy.MinPricePerBlock,
+		"MaxDuration":      &policy.MaxDuration,
+		"MinProfit":        &policy.MinProfit,
+		"ProfitPeriod":     &policy.ProfitPeriod,
+	} {
+		if cmd[key] == nil {
+			continue
+		}
+		v, err := dsp.ToUint64(cmd[key])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		*value = v
+	}
+	if cmd["MaxRatio"] != nil {
+		if policy.MaxRatio, ok = cmd["MaxRatio"].(float64); !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+	}
+	if cmd["FileLimits"] != nil {
+		limits, ok := cmd["FileLimits"].(map[string]interface{})
+		if !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		policy.FileLimits = make(map[string]*seedpolicy.FileLimit, len(limits))
+		for fileHash, item := range limits {
+			l, ok := item.(map[string]interface{})
+			if !ok {
+				return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+			}
+			limit := &seedpolicy.FileLimit{}
+			if l["MaxRatio"] != nil {
+				if limit.MaxRatio, ok = l["MaxRatio"].(float64); !ok {
+					return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+				}
+			}
+			if l["MaxDuration"] != nil {
+				duration, err := dsp.ToUint64(l["MaxDuration"])
+				if err != nil {
+					return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+				}
+				limit.MaxDuration = duration
+			}
+			policy.FileLimits[fileHash] = limit
+		}
+	}
+	if cmd["Windows"] != nil {
+		windows, ok := cmd["Windows"].([]interface{})
+		if !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		for _, item := range windows {
+			w, ok := item.(map[string]interface{})
+			if !ok {
+				return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+			}
+			start, _ := w["Start"].(string)
+			end, _ := w["End"].(string)
+			policy.Windows = append(policy.Windows, &seedpolicy.Window{Start: start, End: end})
+		}
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.SetSeedingPolicy(policy)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetSeedingPolicy(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	policy, err := dsp.DspService.GetSeedingPolicy()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = policy
+	return resp
+}
+
+func GetSeedStates(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	states, err := dsp.DspService.GetSeedStates()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = states
+	return resp
+}
+
+func ResumeSeeding(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	fileHash, ok := cmd["FileHash"].(string)
+	if !ok || len(fileHash) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	state, err := dsp.DspService.ResumeSeeding(fileHash)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = state
+	return resp
+}
+
+// seedingAddrList. convert the optional address list param
+func seedingAddrList(value interface{}) ([]string, bool) {
+	if value == nil {
+		return nil, true
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	addrs := make([]string, 0, len(list))
+	for _, item := range list {
+		addr, ok := item.(string)
+		if !ok || len(addr) == 0 {
+			return nil, false
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, true
+}
@@ -111,6 +111,9 @@ const (
 	DSP_FILE_UPLOAD_RESUME         = "/api/v1/dsp/file/upload/resume"
 	DSP_FILE_UPLOAD_RETRY          = "/api/v1/dsp/file/upload/retry"
 	DSP_FILE_UPLOAD_CANCEL         = "/api/v1/dsp/file/upload/cancel"
+	DSP_FILE_UPLOAD_REUSE          = "/api/v1/dsp/file/upload/reuse"
+	DSP_FILE_UPLOAD_RESUMABLE      = "/api/v1/dsp/file/upload/resumable"
+	DSP_FILE_UPLOAD_ERASURE        = "/api/v1/dsp/file/upload/erasure"
 	DSP_FILE_UPLOAD_FEE            = "/api/v1/dsp/file/uploadfee/:file"
 	DSP_FILE_UPLOAD_DELETE         = "/api/v1/dsp/file/delete"
 	DSP_FILES_UPLOAD_DELETE        = "/api/v1/dsp/files/delete"
@@ -122,17 +125,75 @@ const (
 	DSP_FILE_DOWNLOAD_CANCEL       = "/api/v1/dsp/file/download/cancel"
 	DSP_FILE_DOWNLOAD_INFO         = "/api/v1/dsp/file/downloadinfo/:url"
 	DSP_FILE_DOWNLOAD_DELETE       = "/api/v1/dsp/file/download/delete"
+	DSP_FILE_DOWNLOAD_ERASURE      = "/api/v1/dsp/file/download/erasure"
 	DSP_FILE_ENCRYPT               = "/api/v1/dsp/file/encrypt"
 	DSP_FILE_DECRYPT               = "/api/v1/dsp/file/decrypt"
 	DSP_FILE_ENCRYPT_A             = "/api/v1/dsp/file/encrypta"
 	DSP_FILE_DECRYPT_A             = "/api/v1/dsp/file/decrypta"
+	DSP_FILE_RECIPIENTS            = "/api/v1/dsp/file/recipients"
+	DSP_FILE_RECIPIENTS_ADD        = "/api/v1/dsp/file/recipients/add"
 	DSP_FILE_SHARE_INCOME          = "/api/v1/dsp/file/share/income/:begin/:end/:offset/:limit"
 	DSP_FILE_SHARE_REVENUE         = "/api/v1/dsp/file/share/revenue"
+	DSP_FILE_SHARE_LINKS           = "/api/v1/dsp/file/sharelinks"
+	DSP_FILE_SHARE_LINK_CREATE     = "/api/v1/dsp/file/sharelink/create"
+	DSP_FILE_SHARE_LINK_REVOKE     = "/api/v1/dsp/file/sharelink/revoke"
+	DSP_FILE_SHARE_LINK_VERIFY     = "/api/v1/dsp/file/sharelink/verify"
+	DSP_FILE_SHARE_LINK_REQUEST    = "/api/v1/dsp/file/sharelink/request"
+	DSP_WHITELIST_GROUPS           = "/api/v1/dsp/whitelist/groups"
+	DSP_WHITELIST_GROUP_SET        = "/api/v1/dsp/whitelist/group/set"
+	DSP_WHITELIST_GROUP_DELETE     = "/api/v1/dsp/whitelist/group/delete"
+	DSP_WHITELIST_GROUP_APPLY      = "/api/v1/dsp/whitelist/group/apply"
+	DSP_SHARE_STATS                = "/api/v1/dsp/share/stats"
+	DSP_SHARE_STATS_FILES          = "/api/v1/dsp/share/stats/files"
+	DSP_SHARE_STATS_DOWNLOADERS    = "/api/v1/dsp/share/stats/downloaders"
+	DSP_SHARE_STATS_PERIODS        = "/api/v1/dsp/share/stats/periods"
+	DSP_SHARE_STATS_TOP            = "/api/v1/dsp/share/stats/top"
+	DSP_SEEDING_POLICY             = "/api/v1/dsp/seeding/policy"
+	DSP_SEEDING_POLICY_SET         = "/api/v1/dsp/seeding/policy/set"
+	DSP_SEEDING_FILES              = "/api/v1/dsp/seeding/files"
+	DSP_SEEDING_FILE_RESUME        = "/api/v1/dsp/seeding/file/resume"
+	DSP_FILE_SEARCH                = "/api/v1/dsp/file/search"
+	DSP_FILE_METADATA_SET          = "/api/v1/dsp/file/metadata/set"
+	DSP_TAGS                       = "/api/v1/dsp/tags"
+	DSP_TAGS_ADD                   = "/api/v1/dsp/tags/add"
+	DSP_TAGS_REMOVE                = "/api/v1/dsp/tags/remove"
+	DSP_TAG_RENAME                 = "/api/v1/dsp/tag/rename"
+	DSP_TAG_DELETE                 = "/api/v1/dsp/tag/delete"
+	DSP_COLLECTIONS                = "/api/v1/dsp/collections"
+	DSP_COLLECTION_SET             = "/api/v1/dsp/collection/set"
+	DSP_COLLECTION_DELETE          = "/api/v1/dsp/collection/delete"
+	DSP_COLLECTION_FILES_ADD       = "/api/v1/dsp/collection/files/add"
+	DSP_COLLECTION_FILES_REMOVE    = "/api/v1/dsp/collection/files/remove"
+	DSP_COLLECTION_ACTION          = "/api/v1/dsp/collection/action"
+	DSP_TRASH                      = "/api/v1/dsp/trash"
+	DSP_TRASH_RESTORE              = "/api/v1/dsp/trash/restore"
+	DSP_TRASH_EMPTY                = "/api/v1/dsp/trash/empty"
+	DSP_TRANSFER_HISTORY_EXPORT    = "/api/v1/dsp/file/transfer/history/export"
+	DSP_TRANSFER_HISTORY_IMPORT    = "/api/v1/dsp/file/transfer/history/import"
+	DSP_COST_FORECAST              = "/api/v1/dsp/cost/forecast"
+	DSP_SPACE_GUARD_POLICY         = "/api/v1/dsp/userspace/guard/policy"
+	DSP_SPACE_GUARD_POLICY_SET     = "/api/v1/dsp/userspace/guard/policy/set"
+	DSP_SPACE_GUARD_ACTIONS        = "/api/v1/dsp/userspace/guard/actions"
+	DSP_NODE_MONITOR               = "/api/v1/dsp/node/monitor"
+	DSP_NODE_MONITOR_POLICY        = "/api/v1/dsp/node/monitor/policy"
+	DSP_NODE_MONITOR_POLICY_SET    = "/api/v1/dsp/node/monitor/policy/set"
+	DSP_NODE_SETUP_CHECK           = "/api/v1/dsp/node/setup/check"
+	DSP_NODE_SETUP                 = "/api/v1/dsp/node/setup"
+	DSP_NODE_EXIT                  = "/api/v1/dsp/node/exit"
+	DSP_NODE_EXIT_START            = "/api/v1/dsp/node/exit/start"
+	DSP_NODE_EXIT_CANCEL           = "/api/v1/dsp/node/exit/cancel"
+	DSP_NODE_VOLUME_CHECK          = "/api/v1/dsp/node/volume/check"
+	DSP_NODE_VOLUME_RESIZE         = "/api/v1/dsp/node/volume/resize"
 	DSP_GET_FILE_WHITELIST         = "/api/v1/dsp/file/whitelist/:hash"
 	DSP_UPDATE_FILE_WHITELIST      = "/api/v1/dsp/file/updatewhitelist"
 	DSP_FILE_UPLOAD_INFO           = "/api/v1/dsp/file/upload/info/:hash"
 	DSP_FILE_PROVE_DETAIL          = "/api/v1/dsp/file/prove/detail/:hash"
 	DSP_FILE_PEER_COUNT            = "/api/v1/dsp/file/peers/count/:hash"
+	DSP_FILE_REVISIONS             = "/api/v1/dsp/file/revisions"
+	DSP_FILE_REVISION_ADD          = "/api/v1/dsp/file/revision/add"
+	DSP_FILE_REVISION_ROLLBACK     = "/api/v1/dsp/file/revision/rollback"
+	DSP_FILE_REVISION_PRUNE        = "/api/v1/dsp/file/revision/prune"
+	DSP_FILE_ERASURE_MANIFESTS     = "/api/v1/dsp/file/erasure"
 
 	GET_CHANNEL_INIT_PROGRESS = "/api/v1/channel/init/progress"
 	GET_ALL_CHANNEL           = "/api/v1/channel"
@@ -184,6 +245,16 @@ const (
 	ALL_PLOT_TASK           = "/api/v1/plottasks"
 	DELETE_PLOT_TASK        = "/api/v1/plottask/delete"
 	DELETE_PLOT_TASKS       = "/api/v1/plottask/batchdelete"
+	PLOT_JOBS               = "/api/v1/plotjobs"
+	CANCEL_PLOT_JOB         = "/api/v1/plotjob/cancel"
+	SET_PLOT_JOB_LIMITS     = "/api/v1/plotjob/limits"
+	VERIFY_PLOT_FILES       = "/api/v1/plotverify"
+	PLAN_PLOT_FILES         = "/api/v1/plotplan"
+	START_PLOT_PLAN         = "/api/v1/plotplan/start"
+
+	SECTORS_SUMMARY       = "/api/v1/sectors/summary"
+	SECTOR_REBALANCE_PLAN = "/api/v1/sector/rebalance/plan"
+	SECTOR_REBALANCE      = "/api/v1/sector/rebalance"
 )
 
 //init restful server
@@ -285,15 +356,41 @@ func (this *restServer) registryMethod() {
 		DSP_GET_FILE_TRANSFERLIST_FULL: {name: "gettransferlist", handler: GetTransferList},
 		DSP_GET_FILE_TRANSFER_DETAIL:   {name: "gettransferdetail", handler: GetTransferDetail},
 
-		DSP_FILE_UPLOAD_FEE:    {name: "uploadfilefee", handler: CalculateUploadFee},
-		DSP_FILE_DOWNLOAD_INFO: {name: "getdownloadinfo", handler: GetDownloadFileInfo},
-		DSP_FILE_SHARE_INCOME:  {name: "getfileshareincome", handler: GetFileShareIncome},
-		DSP_FILE_SHARE_REVENUE: {name: "getfilesharerevenue", handler: GetFileShareRevenue},
-		DSP_GET_FILE_WHITELIST: {name: "getwhitelist", handler: GetFileWhiteList},
-		DSP_FILE_UPLOAD_INFO:   {name: "getuploadfileinfo", handler: GetUploadFileInfo},
-		DSP_FILE_PROVE_DETAIL:  {name: "getuploadfileprovedetail", handler: GetUploadFileProveDetail},
-		DSP_FILE_PEER_COUNT:    {name: "getfilepeercount", handler: GetPeerCountOfHash},
-		DSP_FILES_DELETE_FEE:   {name: "deletefilesfee", handler: CalculateDeleteFilesFee},
+		DSP_FILE_UPLOAD_FEE:        {name: "uploadfilefee", handler: CalculateUploadFee},
+		DSP_FILE_DOWNLOAD_INFO:     {name: "getdownloadinfo", handler: GetDownloadFileInfo},
+		DSP_FILE_SHARE_INCOME:      {name: "getfileshareincome", handler: GetFileShareIncome},
+		DSP_FILE_SHARE_REVENUE:     {name: "getfilesharerevenue", handler: GetFileShareRevenue},
+		DSP_GET_FILE_WHITELIST:     {name: "getwhitelist", handler: GetFileWhiteList},
+		DSP_FILE_UPLOAD_INFO:       {name: "getuploadfileinfo", handler: GetUploadFileInfo},
+		DSP_FILE_PROVE_DETAIL:      {name: "getuploadfileprovedetail", handler: GetUploadFileProveDetail},
+		DSP_FILE_PEER_COUNT:        {name: "getfilepeercount", handler: GetPeerCountOfHash},
+		DSP_FILES_DELETE_FEE:       {name: "deletefilesfee", handler: CalculateDeleteFilesFee},
+		DSP_FILE_REVISIONS:         {name: "getfilerevisions", handler: GetFileRevisions},
+		DSP_FILE_UPLOAD_RESUMABLE:  {name: "getresumableuploads", handler: GetResumableUploads},
+		DSP_FILE_ERASURE_MANIFESTS: {name: "geterasuremanifests", handler: GetErasureManifests},
+		DSP_FILE_RECIPIENTS:        {name: "getfilerecipients", handler: GetFileRecipients},
+		DSP_FILE_SHARE_LINKS:       {name: "getsharelinks", handler: GetShareLinks},
+		DSP_WHITELIST_GROUPS:       {name: "getwhitelistgroups", handler: GetWhitelistGroups},
+
+		DSP_SHARE_STATS:             {name: "getsharestats", handler: GetShareStats},
+		DSP_SHARE_STATS_FILES:       {name: "getsharefilestats", handler: GetShareFileStats},
+		DSP_SHARE_STATS_DOWNLOADERS: {name: "getsharedownloaderstats", handler: GetShareDownloaderStats},
+		DSP_SHARE_STATS_PERIODS:     {name: "getshareperiodstats", handler: GetSharePeriodStats},
+		DSP_SHARE_STATS_TOP:         {name: "getsharetopfiles", handler: GetShareTopFiles},
+		DSP_SEEDING_POLICY:          {name: "getseedingpolicy", handler: GetSeedingPolicy},
+		DSP_SEEDING_FILES:           {name: "getseedstates", handler: GetSeedStates},
+		DSP_FILE_SEARCH:             {name: "searchfiles", handler: SearchFiles},
+		DSP_TAGS:                    {name: "gettags", handler: GetTags},
+		DSP_COLLECTIONS:             {name: "getcollections", handler: GetCollections},
+		DSP_TRASH:                   {name: "gettrash", handler: GetTrash},
+		DSP_COST_FORECAST:           {name: "getcostforecast", handler: GetCostForecast},
+		DSP_SPACE_GUARD_POLICY:      {name: "getspaceguardpolicy", handler: GetSpaceGuardPolicy},
+		DSP_SPACE_GUARD_ACTIONS:     {name: "getspaceguardactions", handler: GetSpaceGuardActions},
+		DSP_NODE_MONITOR:            {name: "getnodemonitor", handler: GetNodeMonitor},
+		DSP_NODE_MONITOR_POLICY:     {name: "getnodemonitorpolicy", handler: GetNodeMonitorPolicy},
+		DSP_NODE_SETUP_CHECK:        {name: "checknodesetup", handler: CheckNodeSetup},
+		DSP_NODE_EXIT:               {name: "getnodeexit", handler: GetNodeExit},
+		DSP_NODE_VOLUME_CHECK:       {name: "checkvolumeresize", handler: CheckVolumeResize},
 
 		GET_CHANNEL_INIT_PROGRESS: {name: "channelinitprogress", handler: GetChannelInitProgress},
 		GET_ALL_CHANNEL:           {name: "getallchannels", handler: GetAllChannels},
@@ -326,6 +423,12 @@ func (this *restServer) registryMethod() {
 		GET_ALL_PLOT_FILES: {name: "getallplotfiles", handler: GetAllPlotFiles},
 		ALL_PROVED_PLOTS:   {name: "getallprovedplotfiles", handler: GetAllProvedPlotFile},
 		ALL_PLOT_TASK:      {name: "getallpolttasks", handler: GetAllPocTasks},
+		PLOT_JOBS:          {name: "getplotjobs", handler: GetPlotJobs},
+		VERIFY_PLOT_FILES:  {name: "verifyplotfiles", handler: VerifyPlotFiles},
+		PLAN_PLOT_FILES:    {name: "planplotfiles", handler: PlanPlotFiles},
+
+		SECTORS_SUMMARY:       {name: "getsectorssummary", handler: GetSectorsSummary},
+		SECTOR_REBALANCE_PLAN: {name: "plansectorrebalance", handler: PlanSectorRebalance},
 	}
 	this.getMap = getMethodMap
 
@@ -353,6 +456,8 @@ func (this *restServer) registryMethod() {
 		DSP_FILE_UPLOAD_PAUSE:          {name: "pauseuploadfile", handler: PauseUploadFile},
 		DSP_FILE_UPLOAD_RETRY:          {name: "retryuploadfile", handler: RetryUploadFile},
 		DSP_FILE_UPLOAD_CANCEL:         {name: "canceluploadfile", handler: CancelUploadFile},
+		DSP_FILE_UPLOAD_REUSE:          {name: "reuseduplicateupload", handler: ReuseDuplicateUpload},
+		DSP_FILE_UPLOAD_ERASURE:        {name: "uploadfileerasure", handler: UploadFileErasure},
 		DSP_FILE_UPLOAD_DELETE:         {name: "deleteuploadfile", handler: DeleteUploadFile},
 		DSP_FILES_UPLOAD_DELETE:        {name: "deletefiles", handler: DeleteUploadFiles},
 		DSP_FILE_DOWNLOAD:              {name: "downloadfile", handler: DownloadFile},
@@ -361,12 +466,46 @@ func (this *restServer) registryMethod() {
 		DSP_FILE_DOWNLOAD_RETRY:        {name: "retrydownloadfile", handler: RetryDownloadFile},
 		DSP_FILE_DOWNLOAD_CANCEL:       {name: "canceldownloadfile", handler: CancelDownloadFile},
 		DSP_FILE_DOWNLOAD_DELETE:       {name: "deletedownloadfile", handler: DeleteDownloadFile},
+		DSP_FILE_DOWNLOAD_ERASURE:      {name: "downloadfileerasure", handler: DownloadFileErasure},
 		DSP_FILE_ENCRYPT:               {name: "encryptfile", handler: EncryptFile},
 		DSP_FILE_DECRYPT:               {name: "decryptfile", handler: DecryptFile},
 		DSP_FILE_ENCRYPT_A:             {name: "encryptfile", handler: EncryptFileA},
 		DSP_FILE_DECRYPT_A:             {name: "decryptfile", handler: DecryptFileA},
+		DSP_FILE_RECIPIENTS_ADD:        {name: "addfilerecipients", handler: AddFileRecipients},
+		DSP_FILE_SHARE_LINK_CREATE:     {name: "createsharelink", handler: CreateShareLink},
+		DSP_FILE_SHARE_LINK_REVOKE:     {name: "revokesharelink", handler: RevokeShareLink},
+		DSP_FILE_SHARE_LINK_VERIFY:     {name: "verifysharelink", handler: VerifyShareLink},
+		DSP_FILE_SHARE_LINK_REQUEST:    {name: "signsharerequest", handler: SignShareRequest},
+		DSP_WHITELIST_GROUP_SET:        {name: "setwhitelistgroup", handler: SetWhitelistGroup},
+		DSP_WHITELIST_GROUP_DELETE:     {name: "deletewhitelistgroup", handler: DeleteWhitelistGroup},
+		DSP_WHITELIST_GROUP_APPLY:      {name: "applywhitelistgroup", handler: ApplyWhitelistGroup},
+		DSP_SEEDING_POLICY_SET:         {name: "setseedingpolicy", handler: SetSeedingPolicy},
+		DSP_SEEDING_FILE_RESUME:        {name: "resumeseeding", handler: ResumeSeeding},
+		DSP_FILE_METADATA_SET:          {name: "setfilemetadata", handler: SetFileMetadata},
+		DSP_TAGS_ADD:                   {name: "tagfiles", handler: TagFiles},
+		DSP_TAGS_REMOVE:                {name: "untagfiles", handler: UntagFiles},
+		DSP_TAG_RENAME:                 {name: "renametag", handler: RenameTag},
+		DSP_TAG_DELETE:                 {name: "deletetag", handler: DeleteTag},
+		DSP_COLLECTION_SET:             {name: "setcollection", handler: SetCollection},
+		DSP_COLLECTION_DELETE:          {name: "deletecollection", handler: DeleteCollection},
+		DSP_COLLECTION_FILES_ADD:       {name: "addcollectionfiles", handler: AddCollectionFiles},
+		DSP_COLLECTION_FILES_REMOVE:    {name: "removecollectionfiles", handler: RemoveCollectionFiles},
+		DSP_COLLECTION_ACTION:          {name: "collectionaction", handler: CollectionAction},
+		DSP_TRASH_RESTORE:              {name: "restoretrashfiles", handler: RestoreTrashFiles},
+		DSP_TRASH_EMPTY:                {name: "emptytrash", handler: EmptyTrash},
+		DSP_TRANSFER_HISTORY_EXPORT:    {name: "exporttransferhistory", handler: ExportTransferHistory},
+		DSP_TRANSFER_HISTORY_IMPORT:    {name: "importtransferhistory", handler: ImportTransferHistory},
+		DSP_SPACE_GUARD_POLICY_SET:     {name: "setspaceguardpolicy", handler: SetSpaceGuardPolicy},
+		DSP_NODE_MONITOR_POLICY_SET:    {name: "setnodemonitorpolicy", handler: SetNodeMonitorPolicy},
+		DSP_NODE_SETUP:                 {name: "setupnode", handler: SetupNode},
+		DSP_NODE_EXIT_START:            {name: "startnodeexit", handler: StartNodeExit},
+		DSP_NODE_EXIT_CANCEL:           {name: "cancelnodeexit", handler: CancelNodeExit},
+		DSP_NODE_VOLUME_RESIZE:         {name: "resizevolume", handler: ResizeVolume},
 		DSP_UPDATE_FILE_WHITELIST:      {name: "updatewhitelist", handler: WhiteListOperate},
 		DSP_DELETE_TRANSFER_RECORD:     {name: "deletetransnferlist", handler: DeleteTransferRecord},
+		DSP_FILE_REVISION_ADD:          {name: "addfilerevision", handler: AddFileRevision},
+		DSP_FILE_REVISION_ROLLBACK:     {name: "rollbackfilerevision", handler: RollbackFileRevision},
+		DSP_FILE_REVISION_PRUNE:        {name: "prunefilerevisions", handler: PruneFileRevisions},
 
 		TRANSFER_BY_CHANNEL: {name: "transferbychannel", handler: TransferByChannel},
 		DEPOSIT_CHANNEL:     {name: "depositchannel", handler: DepositChannel},
@@ -399,6 +538,11 @@ func (this *restServer) registryMethod() {
 		ADD_PLOT_FOLDER_TO_MINE: {name: "addplotfoldertomine", handler: AddPlotFolderToMine},
 		DELETE_PLOT_TASK:        {name: "deleteplotfile", handler: DeletePlotFile},
 		DELETE_PLOT_TASKS:       {name: "deleteplotfiles", handler: DeletePlotFiles},
+		CANCEL_PLOT_JOB:         {name: "cancelplotjob", handler: CancelPlotJob},
+		SET_PLOT_JOB_LIMITS:     {name: "setplotjoblimits", handler: SetPlotJobLimits},
+		START_PLOT_PLAN:         {name: "startplotplan", handler: StartPlotPlan},
+
+		SECTOR_REBALANCE: {name: "rebalancesector", handler: RebalanceSector},
 	}
 	this.postMap = postMethodMap
 }
@@ -612,6 +756,7 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Type"], req["Offset"], req["Limit"], req["Filter"], req["CreatedAt"], req["CreatedAtEnd"], req["UpdatedAt"], req["UpdatedAtEnd"] =
 			getParam(r, "type"), getParam(r, "offset"), getParam(r, "limit"), getParam(r, "filter"),
 			getParam(r, "createdAt"), getParam(r, "createdAtEnd"), getParam(r, "updatedAt"), getParam(r, "updatedAtEnd")
+		req["Group"] = r.FormValue("group")
 	case DSP_GET_DOWNLOAD_FILELIST:
 		req["Type"], req["Offset"], req["Limit"] = getParam(r, "type"), getParam(r, "offset"), getParam(r, "limit")
 	case DSP_GET_FILE_TRANSFERLIST:
@@ -624,6 +769,8 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 			r.FormValue("copyNum"), r.FormValue("whitelistCount")
 		req["StoreType"] = r.FormValue("storeType")
 		req["ProveLevel"] = r.FormValue("proveLevel")
+		req["DataShards"], req["ParityShards"] = r.FormValue("dataShards"), r.FormValue("parityShards")
+		req["CheckDuplicate"] = r.FormValue("checkDuplicate")
 	case DSP_FILES_DELETE_FEE:
 		hashList := r.URL.Query()["hash"]
 		var hashListInterface []interface{} = make([]interface{}, len(hashList))
@@ -650,6 +797,38 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Addr"], req["Offset"], req["Limit"] = getParam(r, "addr"), getParam(r, "offset"), getParam(r, "limit")
 	case DSP_GET_FILE_TRANSFER_DETAIL:
 		req["Type"], req["Id"] = getParam(r, "type"), getParam(r, "id")
+	case DSP_FILE_REVISIONS:
+		req["Name"] = r.FormValue("name")
+	case DSP_FILE_ERASURE_MANIFESTS:
+		req["Id"] = r.FormValue("id")
+	case DSP_FILE_RECIPIENTS:
+		req["Path"] = r.FormValue("path")
+	case DSP_FILE_SHARE_LINKS:
+		req["Hash"] = r.FormValue("hash")
+	case DSP_WHITELIST_GROUPS:
+		req["Name"] = r.FormValue("name")
+	case DSP_SHARE_STATS, DSP_SHARE_STATS_FILES, DSP_SHARE_STATS_DOWNLOADERS, DSP_SHARE_STATS_PERIODS, DSP_SHARE_STATS_TOP:
+		req["Begin"], req["End"] = r.FormValue("begin"), r.FormValue("end")
+		req["Period"], req["Top"] = r.FormValue("period"), r.FormValue("top")
+	case DSP_FILE_SEARCH:
+		req["Kind"], req["Type"], req["Mime"] = r.FormValue("kind"), r.FormValue("type"), r.FormValue("mime")
+		req["Tag"], req["Owner"], req["Name"] = r.FormValue("tag"), r.FormValue("owner"), r.FormValue("name")
+		req["MinSize"], req["MaxSize"] = r.FormValue("minSize"), r.FormValue("maxSize")
+		req["CreatedFrom"], req["CreatedTo"] = r.FormValue("createdFrom"), r.FormValue("createdTo")
+		req["Text"], req["Sort"], req["Desc"] = r.FormValue("text"), r.FormValue("sort"), r.FormValue("desc")
+		req["Cursor"], req["Limit"] = r.FormValue("cursor"), r.FormValue("limit")
+	case DSP_COLLECTIONS:
+		req["Name"] = r.FormValue("name")
+	case DSP_COST_FORECAST:
+		req["Months"], req["UserspacePeriod"] = r.FormValue("months"), r.FormValue("userspacePeriod")
+	case DSP_NODE_MONITOR:
+		req["Days"] = r.FormValue("days")
+	case DSP_NODE_SETUP_CHECK:
+		req["NodeAddr"], req["Volume"] = r.FormValue("nodeAddr"), r.FormValue("volume")
+		req["ServicePeriod"], req["SectorSize"] = r.FormValue("servicePeriod"), r.FormValue("sectorSize")
+		req["ProveLevel"], req["ChannelAmount"] = r.FormValue("proveLevel"), r.FormValue("channelAmount")
+	case DSP_NODE_VOLUME_CHECK:
+		req["Volume"], req["DataDirs"] = r.FormValue("volume"), r.FormValue("dataDirs")
 	default:
 	}
 
@@ -685,6 +864,12 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	switch url {
 	case GET_ALL_PLOT_FILES:
 		req["Path"] = getParam(r, "path")
+	case VERIFY_PLOT_FILES:
+		req["Path"], req["Samples"] = r.FormValue("path"), r.FormValue("samples")
+	case PLAN_PLOT_FILES:
+		req["Capacity"], req["FileSize"] = r.FormValue("capacity"), r.FormValue("fileSize")
+	case SECTOR_REBALANCE_PLAN:
+		req["SectorId"], req["CreateSector"] = r.FormValue("sectorId"), r.FormValue("createSector")
 	default:
 	}
 
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/utils/sharestats"
+	"github.com/saveio/themis/common/log"
+)
+
+// GetShareStats. revenue of shared files with all breakdowns
+func GetShareStats(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	stats, errResp := getShareStats(cmd)
+	if errResp != nil {
+		return errResp
+	}
+	resp["Result"] = stats
+	return resp
+}
+
+// GetShareFileStats. revenue per file
+func GetShareFileStats(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	stats, errResp := getShareStats(cmd)
+	if errResp != nil {
+		return errResp
+	}
+	resp["Result"] = stats.Files
+	return resp
+}
+
+// GetShareDownloaderStats. revenue per downloader address
+func GetShareDownloaderStats(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	stats, errResp := getShareStats(cmd)
+	if errResp != nil {
+		return errResp
+	}
+	resp["Result"] = stats.Downloaders
+	return resp
+}
+
+// GetSharePeriodStats. revenue per day, week or month
+func GetSharePeriodStats(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	stats, errResp := getShareStats(cmd)
+	if errResp != nil {
+		return errResp
+	}
+	resp["Result"] = stats.Periods
+	return resp
+}
+
+// GetShareTopFiles. top N files by profit
+func GetShareTopFiles(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	stats, errResp := getShareStats(cmd)
+	if errResp != nil {
+		return errResp
+	}
+	resp["Result"] = stats.TopFiles
+	return resp
+}
+
+func getShareStats(cmd map[string]interface{}) (*sharestats.Stats, map[string]interface{}) {
+	log.Debugf("GetShareStats cmd:%v", cmd)
+	begin, err := dsp.OptionStrToUint64(cmd["Begin"])
+	if err != nil {
+		return nil, ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	end, err := dsp.OptionStrToUint64(cmd["End"])
+	if err != nil {
+		return nil, ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	top, err := dsp.OptionStrToUint64(cmd["Top"])
+	if err != nil {
+		return nil, ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	period, _ := cmd["Period"].(string)
+	if dsp.DspService == nil {
+		return nil, ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	stats, derr := dsp.DspService.GetShareStats(begin, end, period, int(top))
+	if derr != nil {
+		return nil, ResponsePackWithErrMsg(derr.Code, derr.Error.Error())
+	}
+	return stats, nil
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/utils/spaceguard"
+	"github.com/saveio/themis/common/log"
+)
+
+func SetSpaceGuardPolicy(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SetSpaceGuardPolicy cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	policy := &spaceguard.Policy{}
+	var ok bool
+	if policy.UsageThresholds, ok = thresholdList(cmd["UsageThresholds"]); !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if policy.ExpiryThresholds, ok = thresholdList(cmd["ExpiryThresholds"]); !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if cmd["AutoExtend"] != nil {
+		if policy.AutoExtend, ok = cmd["AutoExtend"].(bool); !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+	}
+	for key, value := range map[string]*uint64{
+		"AutoUsage":    &policy.AutoUsage,
+		"AddSize":      &policy.AddSize,
+		"AutoExpiry":   &policy.AutoExpiry,
+		"AddPeriod":    &policy.AddPeriod,
+		"Budget":       &policy.Budget,
+		"BudgetPeriod": &policy.BudgetPeriod,
+	} {
+		if cmd[key] == nil {
+			continue
+		}
+		v, err := dsp.ToUint64(cmd[key])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		*value = v
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	// auto extend spends the balance on userspace without asking, the same as setting userspace manually
+	if policy.AutoExtend {
+		password, ok := cmd["Password"].(string)
+		if !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		if checkErr := dsp.DspService.CheckPassword(password); checkErr != nil {
+			return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+		}
+	}
+	ret, err := dsp.DspService.SetSpaceGuardPolicy(policy)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetSpaceGuardPolicy(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	policy, err := dsp.DspService.GetSpaceGuardPolicy()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = policy
+	return resp
+}
+
+func GetSpaceGuardActions(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	actions, err := dsp.DspService.GetSpaceGuardActions()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = actions
+	return resp
+}
+
+// thresholdList. convert the optional threshold list param
+func thresholdList(value interface{}) ([]uint64, bool) {
+	if value == nil {
+		return nil, true
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	thresholds := make([]uint64, 0, len(list))
+	for _, item := range list {
+		v, err := dsp.ToUint64(item)
+		if err != nil {
+			return nil, false
+		}
+		thresholds = append(thresholds, v)
+	}
+	return thresholds, true
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"github.com/saveio/edge/dsp"
+	"github.com/saveio/edge/dsp/db"
+	"github.com/saveio/themis/common/log"
+)
+
+func SetWhitelistGroup(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("SetWhitelistGroup cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	list, ok := cmd["Members"].([]interface{})
+	if !ok || len(list) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	group := &db.WhitelistGroup{
+		Name:    name,
+		Members: make([]*db.WhitelistMember, 0, len(list)),
+	}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		addr, ok := m["Addr"].(string)
+		if !ok || len(addr) == 0 {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		member := &db.WhitelistMember{Addr: addr}
+		if m["StartHeight"] != nil {
+			height, err := dsp.ToUint64(m["StartHeight"])
+			if err != nil {
+				return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+			}
+			member.StartHeight = height
+		}
+		if m["ExpiredHeight"] != nil {
+			height, err := dsp.ToUint64(m["ExpiredHeight"])
+			if err != nil {
+				return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+			}
+			member.ExpiredHeight = height
+		}
+		group.Members = append(group.Members, member)
+	}
+	if cmd["StartHeight"] != nil {
+		height, err := dsp.ToUint64(cmd["StartHeight"])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		group.StartHeight = height
+	}
+	if cmd["ExpiredHeight"] != nil {
+		height, err := dsp.ToUint64(cmd["ExpiredHeight"])
+		if err != nil {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, err.Error())
+		}
+		group.ExpiredHeight = height
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	ret, err := dsp.DspService.SetWhitelistGroup(group)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
+
+func GetWhitelistGroups(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, _ := cmd["Name"].(string)
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if len(name) > 0 {
+		group, err := dsp.DspService.GetWhitelistGroup(name)
+		if err != nil {
+			return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+		}
+		resp["Result"] = []*db.WhitelistGroup{group}
+		return resp
+	}
+	groups, err := dsp.DspService.GetWhitelistGroups()
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = groups
+	return resp
+}
+
+func DeleteWhitelistGroup(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if err := dsp.DspService.DeleteWhitelistGroup(name); err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	return resp
+}
+
+func ApplyWhitelistGroup(cmd map[string]interface{}) map[string]interface{} {
+	log.Debugf("ApplyWhitelistGroup cmd:%v", cmd)
+	resp := ResponsePack(dsp.SUCCESS)
+	name, ok := cmd["Name"].(string)
+	if !ok || len(name) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	operation, ok := cmd["Operation"].(string)
+	if !ok || len(operation) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	list, ok := cmd["FileHashes"].([]interface{})
+	if !ok || len(list) == 0 {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	fileHashes := make([]string, 0, len(list))
+	for _, item := range list {
+		hash, ok := item.(string)
+		if !ok || len(hash) == 0 {
+			return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+		}
+		fileHashes = append(fileHashes, hash)
+	}
+	pwd, ok := cmd["Password"].(string)
+	if !ok {
+		return ResponsePackWithErrMsg(dsp.INVALID_PARAMS, dsp.ErrMaps[dsp.INVALID_PARAMS].Error())
+	}
+	if dsp.DspService == nil {
+		return ResponsePackWithErrMsg(dsp.NO_ACCOUNT, dsp.ErrMaps[dsp.NO_ACCOUNT].Error())
+	}
+	if checkErr := dsp.DspService.CheckPassword(pwd); checkErr != nil {
+		return ResponsePackWithErrMsg(checkErr.Code, checkErr.Error.Error())
+	}
+	ret, err := dsp.DspService.ApplyWhitelistGroup(name, fileHashes, operation)
+	if err != nil {
+		return ResponsePackWithErrMsg(err.Code, err.Error.Error())
+	}
+	resp["Result"] = ret
+	return resp
+}
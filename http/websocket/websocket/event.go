@@ -6,6 +6,10 @@ import (
 
 	"github.com/saveio/edge/dsp"
 	"github.com/saveio/edge/http/rest"
+	"github.com/saveio/edge/utils/nodemonitor"
+	"github.com/saveio/edge/utils/plotjob"
+	"github.com/saveio/edge/utils/seedpolicy"
+	"github.com/saveio/edge/utils/spaceguard"
 	sdkCom "github.com/saveio/themis-go-sdk/common"
 	"github.com/saveio/themis/common/log"
 	"github.com/saveio/themis/smartcontract/service/native/utils"
@@ -89,6 +93,55 @@ func (self *WsServer) PushRevenue() {
 	self.Broadcast(WS_TOPIC_EVENT, resp)
 }
 
+// PushSeedingDecision. push the seeding policy decision of share request
+func (self *WsServer) PushSeedingDecision(decision *seedpolicy.Decision) {
+	resp := rest.ResponsePack(dsp.SUCCESS)
+	resp["Result"] = decision
+	resp["Action"] = "seedingdecision"
+	self.Broadcast(WS_TOPIC_EVENT, resp)
+}
+
+// PushUserspaceAlert. push the alert when userspace crossed a threshold of guardian policy
+func (self *WsServer) PushUserspaceAlert(alert *spaceguard.Alert) {
+	resp := rest.ResponsePack(dsp.SUCCESS)
+	resp["Result"] = alert
+	resp["Action"] = "userspacealert"
+	self.Broadcast(WS_TOPIC_EVENT, resp)
+}
+
+// PushUserspaceAction. push the automatic action of userspace guardian, and the current userspace
+func (self *WsServer) PushUserspaceAction(action *spaceguard.ActionLog) {
+	resp := rest.ResponsePack(dsp.SUCCESS)
+	resp["Result"] = action
+	resp["Action"] = "userspaceaction"
+	self.Broadcast(WS_TOPIC_EVENT, resp)
+	self.PushCurrentUserSpace()
+}
+
+// PushPlotJob. push the state and progress of plot job
+func (self *WsServer) PushPlotJob(job *plotjob.Job) {
+	resp := rest.ResponsePack(dsp.SUCCESS)
+	resp["Result"] = job
+	resp["Action"] = "plotjob"
+	self.Broadcast(WS_TOPIC_EVENT, resp)
+}
+
+// PushNodeAlert. push the alert of node monitor, missed proofs or service time running out
+func (self *WsServer) PushNodeAlert(alert *nodemonitor.Alert) {
+	resp := rest.ResponsePack(dsp.SUCCESS)
+	resp["Result"] = alert
+	resp["Action"] = "nodealert"
+	self.Broadcast(WS_TOPIC_EVENT, resp)
+}
+
+// PushNodeAction. push the automatic extension of node service time
+func (self *WsServer) PushNodeAction(action *nodemonitor.ActionLog) {
+	resp := rest.ResponsePack(dsp.SUCCESS)
+	resp["Result"] = action
+	resp["Action"] = "nodeaction"
+	self.Broadcast(WS_TOPIC_EVENT, resp)
+}
+
 // PushNetworkState. push networkstate for some required host
 func (self *WsServer) PushNetworkState() {
 	resp := rest.GetNetworkState(nil)
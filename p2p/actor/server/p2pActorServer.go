@@ -27,6 +27,7 @@ type P2PActor struct {
 	msgHandlers         map[string]MessageHandler
 	localPID            *actor.PID
 	dnsHostAddrCallBack func(string) string
+	dspSendHook         func(proto.Message, string)
 }
 
 func NewP2PActor() (*P2PActor, error) {
@@ -57,6 +58,11 @@ func (this *P2PActor) SetDNSHostAddrCallback(cb func(string) string) {
 	this.dnsHostAddrCallBack = cb
 }
 
+// SetDspSendHook. the hook is called with each dsp message and the wallet address after it's sent
+func (this *P2PActor) SetDspSendHook(hook func(proto.Message, string)) {
+	this.dspSendHook = hook
+}
+
 func (this *P2PActor) Start() (*actor.PID, error) {
 	this.props = actor.FromProducer(func() actor.Actor { return this })
 	localPid, err := actor.SpawnNamed(this.props, "net_server")
@@ -131,6 +137,9 @@ func (this *P2PActor) Receive(ctx actor.Context) {
 	case *dspAct.SendReq:
 		go func() {
 			err := this.dspNet.Send(msg.Data, msg.SessionId, msg.MsgId, msg.Address, msg.SendTimeout)
+			if err == nil && this.dspSendHook != nil {
+				this.dspSendHook(msg.Data, msg.Address)
+			}
 			msg.Response <- &dspAct.P2PResp{Error: err}
 		}()
 	case *dspAct.BroadcastReq:
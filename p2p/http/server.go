@@ -424,6 +424,17 @@ func CreateDownloadTask(w http.ResponseWriter, r *http.Request) {
 		w.Write(res)
 		return
 	}
+	// the requester signs the request with the share link if it's not allowed by the file privilege
+	requester, derr := dsp.DspService.AuthorizeShareDownload(fileHash, r.URL.Query().Get("request"), peerAddr)
+	if derr == nil && len(requester) > 0 && requester != peerAddr {
+		derr = &dsp.DspErr{Code: dsp.DSP_SHARE_LINK_INVALID, Error: fmt.Errorf("request is not signed by %s", peerAddr)}
+	}
+	if derr != nil {
+		m["err"] = derr.Error.Error()
+		res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+		w.Write(res)
+		return
+	}
 	downloadTaskId, err := task.CreateDownloadTask(peerAddr, fileHash)
 	if err != nil {
 		m["err"] = err.Error()
@@ -589,6 +600,75 @@ func FileDownload(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+type ShareLinkRevocationData struct {
+	Revocation string `json:"revocation"`
+}
+
+func ShareLinkRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(405)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error(err)
+	}
+	var req ShareLinkRevocationData
+	json.Unmarshal(body, &req)
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	m := make(map[string]string)
+	if req.Revocation == "" {
+		m["error"] = "parameter error"
+		res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+		w.Write(res)
+		return
+	}
+	if derr := dsp.DspService.AcceptShareLinkRevocation(req.Revocation); derr != nil {
+		m["error"] = derr.Error.Error()
+		res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+		w.Write(res)
+		return
+	}
+	m["revoked"] = "1"
+	res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+	w.Write(res)
+}
+
+type ShareLinkPresentData struct {
+	Request string `json:"request"`
+}
+
+// ShareLinkPresent. accept the share link presented by a requester before it asks for the file over p2p
+func ShareLinkPresent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(405)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Error(err)
+	}
+	var req ShareLinkPresentData
+	json.Unmarshal(body, &req)
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	m := make(map[string]string)
+	if req.Request == "" {
+		m["error"] = "parameter error"
+		res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+		w.Write(res)
+		return
+	}
+	if derr := dsp.DspService.AcceptShareLinkPresent(req.Request); derr != nil {
+		m["error"] = derr.Error.Error()
+		res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+		w.Write(res)
+		return
+	}
+	m["accepted"] = "1"
+	res, _ := json.Marshal(JsonResult{Code: 200, Msg: "success", Data: m})
+	w.Write(res)
+}
+
 func DownloadCompleted(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		w.WriteHeader(405)
@@ -756,6 +836,9 @@ func (hs *HttpServer) initPostHandler() {
 	hs.router.Post("/api/v1/p2p/http/file/download", FileDownload)
 
 	hs.router.Post("/api/v1/p2p/http/file/deleteTask", DeleteTask)
+
+	hs.router.Post("/api/v1/p2p/http/share/revoke", ShareLinkRevoke)
+	hs.router.Post("/api/v1/p2p/http/share/present", ShareLinkPresent)
 }
 func (hs *HttpServer) Start() error {
 
@@ -0,0 +1,148 @@
+// Package costforecast projects the renewal costs of storage commitments over a horizon.
+// A commitment is renewed when it expires, and then every period after that,
+// each renewal costs the same as the current price of one period.
+package costforecast
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	KIND_FILE           = "file"           // file stored with its own duration
+	KIND_USERSPACE      = "userspace"      // userspace of account
+	KIND_USERSPACE_FILE = "userspace_file" // file stored in userspace, renewed with userspace
+)
+
+const UNTAGGED = "untagged"
+
+var ErrInvalidHorizon = errors.New("invalid forecast horizon")
+
+// Commitment. a storage commitment which needs to be renewed to keep
+type Commitment struct {
+	Id        string
+	Name      string
+	Kind      string
+	Tags      []string
+	ExpiresAt uint64 // unix seconds
+	Period    uint64 // seconds extended by one renewal, zero means renewed once
+	Cost      uint64 // cost of one renewal
+}
+
+// Renewal. a projected renewal of commitment
+type Renewal struct {
+	At   uint64
+	Cost uint64
+}
+
+type CommitmentForecast struct {
+	*Commitment
+	Renewals []*Renewal
+	Total    uint64
+}
+
+type TagForecast struct {
+	Tag      string
+	Files    int
+	Renewals int
+	Total    uint64
+}
+
+type MonthForecast struct {
+	Month    string
+	Renewals int
+	Total    uint64
+}
+
+// Forecast. projected costs of commitments in [From, To]
+type Forecast struct {
+	From        uint64
+	To          uint64
+	Renewals    int
+	Total       uint64
+	Commitments []*CommitmentForecast
+	Tags        []*TagForecast
+	Months      []*MonthForecast
+}
+
+// Project. project the renewals of commitments in [from, to], months are keyed as 2006-01 in the location.
+// The commitments already expired before from are not projected because they can't be renewed.
+// The renewals without cost, such as the files renewed with userspace, are listed but not counted.
+// Only the files are broken down by tag, the untagged files are grouped as UNTAGGED.
+func Project(commitments []*Commitment, from, to uint64, loc *time.Location) (*Forecast, error) {
+	if to <= from {
+		return nil, ErrInvalidHorizon
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	forecast := &Forecast{
+		From:        from,
+		To:          to,
+		Commitments: make([]*CommitmentForecast, 0, len(commitments)),
+		Tags:        make([]*TagForecast, 0),
+		Months:      make([]*MonthForecast, 0),
+	}
+	months := make(map[string]*MonthForecast)
+	for t := time.Unix(int64(from), 0).In(loc); ; t = t.AddDate(0, 1, 0) {
+		first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		if uint64(first.Unix()) > to {
+			break
+		}
+		m := &MonthForecast{Month: first.Format("2006-01")}
+		months[m.Month] = m
+		forecast.Months = append(forecast.Months, m)
+		t = first
+	}
+	tags := make(map[string]*TagForecast)
+	for _, c := range commitments {
+		cf := &CommitmentForecast{Commitment: c, Renewals: make([]*Renewal, 0)}
+		for at := c.ExpiresAt; at >= from && at <= to; at += c.Period {
+			cf.Renewals = append(cf.Renewals, &Renewal{At: at, Cost: c.Cost})
+			cf.Total += c.Cost
+			if m := months[time.Unix(int64(at), 0).In(loc).Format("2006-01")]; m != nil && c.Cost > 0 {
+				m.Renewals++
+				m.Total += c.Cost
+			}
+			if c.Period == 0 {
+				break
+			}
+		}
+		renewals := len(cf.Renewals)
+		if c.Cost == 0 {
+			renewals = 0
+		}
+		forecast.Renewals += renewals
+		forecast.Total += cf.Total
+		forecast.Commitments = append(forecast.Commitments, cf)
+		if c.Kind == KIND_USERSPACE {
+			continue
+		}
+		fileTags := c.Tags
+		if len(fileTags) == 0 {
+			fileTags = []string{UNTAGGED}
+		}
+		for _, tag := range fileTags {
+			tf, ok := tags[tag]
+			if !ok {
+				tf = &TagForecast{Tag: tag}
+				tags[tag] = tf
+				forecast.Tags = append(forecast.Tags, tf)
+			}
+			tf.Files++
+			tf.Renewals += renewals
+			tf.Total += cf.Total
+		}
+	}
+	sort.SliceStable(forecast.Commitments, func(i, j int) bool {
+		if forecast.Commitments[i].Total != forecast.Commitments[j].Total {
+			return forecast.Commitments[i].Total > forecast.Commitments[j].Total
+		}
+		return forecast.Commitments[i].Id < forecast.Commitments[j].Id
+	})
+	sort.Slice(forecast.Tags, func(i, j int) bool {
+		return forecast.Tags[i].Tag < forecast.Tags[j].Tag
+	})
+	return forecast, nil
+}
@@ -0,0 +1,64 @@
+package costforecast
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProject(t *testing.T) {
+	from := uint64(time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC).Unix())
+	to := uint64(time.Date(2021, 4, 15, 0, 0, 0, 0, time.UTC).Unix())
+	day := uint64(24 * 3600)
+	commitments := []*Commitment{
+		{Id: "a", Kind: KIND_FILE, Tags: []string{"work"}, ExpiresAt: from + day, Period: 30 * day, Cost: 10},
+		{Id: "b", Kind: KIND_FILE, ExpiresAt: to + day, Period: 30 * day, Cost: 100},
+		{Id: "c", Kind: KIND_FILE, Tags: []string{"work", "photo"}, ExpiresAt: from - day, Period: 30 * day, Cost: 5},
+		{Id: "u", Kind: KIND_USERSPACE, ExpiresAt: from + 40*day, Cost: 7},
+		// renewed with userspace, listed without counting
+		{Id: "f", Kind: KIND_USERSPACE_FILE, Tags: []string{"photo"}, ExpiresAt: from + 40*day},
+	}
+	forecast, err := Project(commitments, from, to, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a renews at day 1, 31, 61 in the horizon of 90 days
+	if forecast.Renewals != 4 || forecast.Total != 37 {
+		t.Fatalf("wrong total renewals %d cost %d", forecast.Renewals, forecast.Total)
+	}
+	if forecast.Commitments[0].Id != "a" || len(forecast.Commitments[0].Renewals) != 3 {
+		t.Fatalf("wrong commitment %+v", forecast.Commitments[0])
+	}
+	for _, cf := range forecast.Commitments {
+		if cf.Id == "f" && (len(cf.Renewals) != 1 || cf.Total != 0) {
+			t.Fatalf("wrong userspace file %+v", cf)
+		}
+	}
+	if len(forecast.Months) != 4 || forecast.Months[0].Month != "2021-01" || forecast.Months[3].Month != "2021-04" {
+		t.Fatalf("wrong months %+v", forecast.Months)
+	}
+	if forecast.Months[1].Total != 17 || forecast.Months[1].Renewals != 2 {
+		t.Fatalf("wrong month %+v", forecast.Months[1])
+	}
+	if len(forecast.Tags) != 3 {
+		t.Fatalf("wrong tags %+v", forecast.Tags)
+	}
+	for _, tag := range forecast.Tags {
+		switch tag.Tag {
+		case "work":
+			if tag.Files != 2 || tag.Total != 30 {
+				t.Fatalf("wrong work tag %+v", tag)
+			}
+		case "photo":
+			if tag.Files != 2 || tag.Renewals != 0 || tag.Total != 0 {
+				t.Fatalf("wrong photo tag %+v", tag)
+			}
+		case UNTAGGED:
+			if tag.Files != 1 || tag.Total != 0 {
+				t.Fatalf("wrong untagged %+v", tag)
+			}
+		}
+	}
+	if _, err := Project(commitments, to, from, nil); err != ErrInvalidHorizon {
+		t.Fatalf("expect invalid horizon, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package envelope implements the multi-recipient header of a encrypted file.
+// The content is encrypted once with a random content key, and the content key is wrapped
+// with ECIES for the public key of each recipient.
+//
+// Header layout:
+//
+//	magic(4) | version(1) | capacity(4) | used(4) | json recipients(used) | zero padding(capacity - used)
+//
+// The header has reserved capacity, so recipients can be added without moving the content.
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+)
+
+const (
+	VERSION          = 1
+	FIXED_HEADER_LEN = 4 + 1 + 4 + 4
+	DEFAULT_CAPACITY = 4 * 1024
+	MAX_CAPACITY     = 16 * 1024 * 1024
+	CONTENT_KEY_LEN  = 32
+	KEY_ID_LEN       = 8
+)
+
+var MAGIC = []byte("SENV")
+
+var (
+	ErrInvalidHeader  = errors.New("invalid envelope header")
+	ErrVersion        = errors.New("unsupported envelope version")
+	ErrHeaderFull     = errors.New("envelope header capacity is not enough")
+	ErrNoRecipient    = errors.New("no envelope for the key")
+	ErrUnwrapFailed   = errors.New("unwrap content key failed")
+	ErrUnsupportedKey = errors.New("unsupported key")
+)
+
+// Recipient. the content key wrapped for one public key
+type Recipient struct {
+	Address    string
+	KeyId      string
+	WrappedKey []byte
+	AddedAt    uint64
+}
+
+type Header struct {
+	Version    byte
+	Capacity   uint32
+	Recipients []*Recipient
+}
+
+// IsEnvelope. check whether the data starts with a envelope header
+func IsEnvelope(head []byte) bool {
+	return len(head) >= len(MAGIC) && bytes.Equal(head[:len(MAGIC)], MAGIC)
+}
+
+// Size. total bytes of the header in file
+func (this *Header) Size() int64 {
+	return int64(FIXED_HEADER_LEN) + int64(this.Capacity)
+}
+
+// GetRecipient. get the recipient by key id
+func (this *Header) GetRecipient(keyId string) *Recipient {
+	for _, r := range this.Recipients {
+		if r.KeyId == keyId {
+			return r
+		}
+	}
+	return nil
+}
+
+// Serialize. serialize the header with zero padding to its capacity
+func (this *Header) Serialize() ([]byte, error) {
+	body, err := json.Marshal(this.Recipients)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(body)) > this.Capacity {
+		return nil, ErrHeaderFull
+	}
+	buf := make([]byte, this.Size())
+	copy(buf, MAGIC)
+	buf[4] = this.Version
+	binary.BigEndian.PutUint32(buf[5:9], this.Capacity)
+	binary.BigEndian.PutUint32(buf[9:13], uint32(len(body)))
+	copy(buf[FIXED_HEADER_LEN:], body)
+	return buf, nil
+}
+
+// RequiredCapacity. the capacity needed by current recipients
+func (this *Header) RequiredCapacity() (uint32, error) {
+	body, err := json.Marshal(this.Recipients)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(len(body)), nil
+}
+
+// ReadHeader. read the header from r, r is positioned at the content after read
+func ReadHeader(r io.Reader) (*Header, error) {
+	fixed := make([]byte, FIXED_HEADER_LEN)
+	if _, err := io.ReadFull(r, fixed); err != nil || !IsEnvelope(fixed) {
+		return nil, ErrInvalidHeader
+	}
+	h := &Header{Version: fixed[4]}
+	if h.Version != VERSION {
+		return nil, ErrVersion
+	}
+	h.Capacity = binary.BigEndian.Uint32(fixed[5:9])
+	used := binary.BigEndian.Uint32(fixed[9:13])
+	if h.Capacity > MAX_CAPACITY || used > h.Capacity {
+		return nil, ErrInvalidHeader
+	}
+	body := make([]byte, h.Capacity)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if err := json.Unmarshal(body[:used], &h.Recipients); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	return h, nil
+}
+
+// NewContentKey. generate a random content key
+func NewContentKey() ([]byte, error) {
+	key := make([]byte, CONTENT_KEY_LEN)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// KeyId. short id of a public key, used to find the envelope of a key
+func KeyId(pub *ecdsa.PublicKey) string {
+	h := sha256.Sum256(elliptic.Marshal(pub.Curve, pub.X, pub.Y))
+	return hex.EncodeToString(h[:KEY_ID_LEN])
+}
+
+// WrapKey. encrypt the content key with ECIES on the curve of the public key:
+// ephemeral public key | aes-gcm(sha256(shared secret | ephemeral public key), key)
+func WrapKey(pub *ecdsa.PublicKey, key []byte) ([]byte, error) {
+	if pub == nil || pub.Curve == nil {
+		return nil, ErrUnsupportedKey
+	}
+	curve := pub.Curve
+	ephemeral, x, y, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPub := elliptic.Marshal(curve, x, y)
+	sx, _ := curve.ScalarMult(pub.X, pub.Y, ephemeral)
+	aead, err := newKeyCipher(curve, sx, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nil, nonce, key, ephemeralPub)
+	return append(ephemeralPub, sealed...), nil
+}
+
+// UnwrapKey. decrypt the content key with the private key
+func UnwrapKey(priv *ecdsa.PrivateKey, wrapped []byte) ([]byte, error) {
+	if priv == nil || priv.Curve == nil {
+		return nil, ErrUnsupportedKey
+	}
+	curve := priv.Curve
+	pubLen := 1 + 2*((curve.Params().BitSize+7)/8)
+	if len(wrapped) <= pubLen {
+		return nil, ErrUnwrapFailed
+	}
+	ephemeralPub := wrapped[:pubLen]
+	x, y := elliptic.Unmarshal(curve, ephemeralPub)
+	if x == nil {
+		return nil, ErrUnwrapFailed
+	}
+	sx, _ := curve.ScalarMult(x, y, priv.D.Bytes())
+	aead, err := newKeyCipher(curve, sx, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	key, err := aead.Open(nil, nonce, wrapped[pubLen:], ephemeralPub)
+	if err != nil {
+		return nil, ErrUnwrapFailed
+	}
+	return key, nil
+}
+
+// newKeyCipher. the key encryption key is used only once, so a zero nonce is safe
+func newKeyCipher(curve elliptic.Curve, sx *big.Int, ephemeralPub []byte) (cipher.AEAD, error) {
+	secret := make([]byte, (curve.Params().BitSize+7)/8)
+	sx.FillBytes(secret)
+	kek := sha256.Sum256(append(secret, ephemeralPub...))
+	block, err := aes.NewCipher(kek[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
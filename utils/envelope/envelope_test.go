@@ -0,0 +1,79 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestWrapUnwrapKey(t *testing.T) {
+	alice, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewContentKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapped, err := WrapKey(&alice.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnwrapKey(alice, wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Fatal("unwrapped key mismatch")
+	}
+	if _, err := UnwrapKey(bob, wrapped); err != ErrUnwrapFailed {
+		t.Fatalf("expect unwrap failed with other key, got %v", err)
+	}
+	wrapped[len(wrapped)-1] ^= 1
+	if _, err := UnwrapKey(alice, wrapped); err != ErrUnwrapFailed {
+		t.Fatalf("expect unwrap failed with modified key, got %v", err)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Header{Version: VERSION, Capacity: 256}
+	h.Recipients = append(h.Recipients, &Recipient{
+		Address:    "AKuNsvkZ8LbR8ixEnQ7ZjXVLFpDBeLQFbG",
+		KeyId:      KeyId(&priv.PublicKey),
+		WrappedKey: []byte{1, 2, 3},
+	})
+	buf, err := h.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(buf)) != h.Size() {
+		t.Fatalf("header size %d, expect %d", len(buf), h.Size())
+	}
+	content := []byte("content")
+	r := bytes.NewReader(append(buf, content...))
+	got, err := ReadHeader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Capacity != h.Capacity || len(got.Recipients) != 1 || got.GetRecipient(KeyId(&priv.PublicKey)) == nil {
+		t.Fatalf("header mismatch %+v", got)
+	}
+	rest := make([]byte, len(content))
+	if _, err := r.Read(rest); err != nil || !bytes.Equal(rest, content) {
+		t.Fatal("reader is not positioned at the content")
+	}
+	h.Capacity = 8
+	if _, err := h.Serialize(); err != ErrHeaderFull {
+		t.Fatalf("expect header full, got %v", err)
+	}
+}
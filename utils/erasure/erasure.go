@@ -0,0 +1,363 @@
+// Package erasure implements a systematic Reed-Solomon code over GF(2^8).
+// A file is split into k data shards and m parity shards,
+// and it can be reconstructed from any k of them.
+package erasure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	MAX_TOTAL_SHARDS   = 256
+	DEFAULT_STRIPE_LEN = 256 * 1024 // bytes of each shard in one stripe
+)
+
+var (
+	ErrInvalidShardNum   = errors.New("invalid shard number")
+	ErrShardSize         = errors.New("shards have different size")
+	ErrTooFewShards      = errors.New("too few shards to reconstruct")
+	ErrInvalidStripeSize = errors.New("invalid stripe size")
+)
+
+// Codec. reed-solomon codec with k data shards and m parity shards
+type Codec struct {
+	dataShards   int
+	parityShards int
+	matrix       matrix // (k+m) x k encoding matrix, the top k rows is identity
+	parity       matrix // the bottom m rows of encoding matrix
+}
+
+func NewCodec(dataShards, parityShards int) (*Codec, error) {
+	if dataShards <= 0 || parityShards <= 0 || dataShards+parityShards > MAX_TOTAL_SHARDS {
+		return nil, ErrInvalidShardNum
+	}
+	total := dataShards + parityShards
+	vm := vandermonde(total, dataShards)
+	top, err := vm.subMatrix(0, 0, dataShards, dataShards).invert()
+	if err != nil {
+		return nil, err
+	}
+	m := vm.multiply(top)
+	return &Codec{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		matrix:       m,
+		parity:       m.subMatrix(dataShards, 0, total, dataShards),
+	}, nil
+}
+
+func (this *Codec) DataShards() int {
+	return this.dataShards
+}
+
+func (this *Codec) ParityShards() int {
+	return this.parityShards
+}
+
+func (this *Codec) TotalShards() int {
+	return this.dataShards + this.parityShards
+}
+
+// Encode. compute the parity shards from the data shards.
+// All shards must be allocated with the same size.
+func (this *Codec) Encode(shards [][]byte) error {
+	if len(shards) != this.TotalShards() {
+		return ErrInvalidShardNum
+	}
+	size, err := shardSize(shards, false)
+	if err != nil {
+		return err
+	}
+	for i, row := range this.parity {
+		out := shards[this.dataShards+i][:size]
+		for j := range out {
+			out[j] = 0
+		}
+		for c, coef := range row {
+			mulSliceXor(coef, shards[c][:size], out)
+		}
+	}
+	return nil
+}
+
+// Verify. check whether the parity shards match the data shards
+func (this *Codec) Verify(shards [][]byte) (bool, error) {
+	if len(shards) != this.TotalShards() {
+		return false, ErrInvalidShardNum
+	}
+	size, err := shardSize(shards, false)
+	if err != nil {
+		return false, err
+	}
+	buf := make([]byte, size)
+	for i, row := range this.parity {
+		for j := range buf {
+			buf[j] = 0
+		}
+		for c, coef := range row {
+			mulSliceXor(coef, shards[c], buf)
+		}
+		for j, v := range shards[this.dataShards+i] {
+			if buf[j] != v {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// Reconstruct. rebuild the missing shards, the missing shard is nil or empty.
+// At least k shards are needed.
+func (this *Codec) Reconstruct(shards [][]byte) error {
+	if len(shards) != this.TotalShards() {
+		return ErrInvalidShardNum
+	}
+	size, err := shardSize(shards, true)
+	if err != nil {
+		return err
+	}
+	present := make([]int, 0, this.dataShards)
+	for i, s := range shards {
+		if len(s) == 0 {
+			continue
+		}
+		present = append(present, i)
+	}
+	if len(present) == this.TotalShards() {
+		return nil
+	}
+	if len(present) < this.dataShards {
+		return ErrTooFewShards
+	}
+	present = present[:this.dataShards]
+	sub := newMatrix(this.dataShards, this.dataShards)
+	for r, idx := range present {
+		copy(sub[r], this.matrix[idx])
+	}
+	decode, err := sub.invert()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < this.dataShards; i++ {
+		if len(shards[i]) != 0 {
+			continue
+		}
+		out := make([]byte, size)
+		for c, idx := range present {
+			mulSliceXor(decode[i][c], shards[idx], out)
+		}
+		shards[i] = out
+	}
+	for i := this.dataShards; i < this.TotalShards(); i++ {
+		if len(shards[i]) != 0 {
+			continue
+		}
+		out := make([]byte, size)
+		for c, coef := range this.matrix[i] {
+			mulSliceXor(coef, shards[c], out)
+		}
+		shards[i] = out
+	}
+	return nil
+}
+
+// Split. split data into k data shards and allocate m parity shards, the last data shard is padded with zero
+func (this *Codec) Split(data []byte) [][]byte {
+	perShard := (len(data) + this.dataShards - 1) / this.dataShards
+	if perShard == 0 {
+		perShard = 1
+	}
+	shards := make([][]byte, this.TotalShards())
+	for i := range shards {
+		shards[i] = make([]byte, perShard)
+		if i < this.dataShards && i*perShard < len(data) {
+			copy(shards[i], data[i*perShard:])
+		}
+	}
+	return shards
+}
+
+// Join. write the first size bytes of the data shards to w
+func (this *Codec) Join(w io.Writer, shards [][]byte, size int) error {
+	if len(shards) < this.dataShards {
+		return ErrTooFewShards
+	}
+	for i := 0; i < this.dataShards && size > 0; i++ {
+		s := shards[i]
+		if len(s) == 0 {
+			return ErrTooFewShards
+		}
+		if len(s) > size {
+			s = s[:size]
+		}
+		if _, err := w.Write(s); err != nil {
+			return err
+		}
+		size -= len(s)
+	}
+	return nil
+}
+
+// ShardFileSize. size of each shard file for a file with the stripe length
+func ShardFileSize(fileSize int64, dataShards, stripeLen int) int64 {
+	stripeData := int64(dataShards * stripeLen)
+	stripes := (fileSize + stripeData - 1) / stripeData
+	if stripes == 0 {
+		stripes = 1
+	}
+	return stripes * int64(stripeLen)
+}
+
+// EncodeFile. encode the file stripe by stripe and write each shard to shardPaths.
+// Each stripe reads k * stripeLen bytes from the file, so the memory usage is independent of the file size.
+func (this *Codec) EncodeFile(filePath string, shardPaths []string, stripeLen int) (int64, error) {
+	if len(shardPaths) != this.TotalShards() {
+		return 0, ErrInvalidShardNum
+	}
+	if stripeLen <= 0 {
+		return 0, ErrInvalidStripeSize
+	}
+	src, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	outs := make([]*os.File, len(shardPaths))
+	defer func() {
+		for _, f := range outs {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}()
+	for i, p := range shardPaths {
+		outs[i], err = os.Create(p)
+		if err != nil {
+			return 0, err
+		}
+	}
+	shards := make([][]byte, this.TotalShards())
+	for i := range shards {
+		shards[i] = make([]byte, stripeLen)
+	}
+	buf := make([]byte, this.dataShards*stripeLen)
+	var total int64
+	for first := true; ; first = false {
+		n, err := io.ReadFull(src, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return 0, err
+		}
+		if n == 0 && !first {
+			break
+		}
+		total += int64(n)
+		for i := n; i < len(buf); i++ {
+			buf[i] = 0
+		}
+		for i := 0; i < this.dataShards; i++ {
+			copy(shards[i], buf[i*stripeLen:(i+1)*stripeLen])
+		}
+		if err := this.Encode(shards); err != nil {
+			return 0, err
+		}
+		for i, f := range outs {
+			if _, err := f.Write(shards[i]); err != nil {
+				return 0, err
+			}
+		}
+		if n < len(buf) {
+			break
+		}
+	}
+	return total, nil
+}
+
+// DecodeFile. rebuild the original file of fileSize bytes from the shard files,
+// the path of missing shard should be empty.
+func (this *Codec) DecodeFile(shardPaths []string, filePath string, fileSize int64, stripeLen int) error {
+	if len(shardPaths) != this.TotalShards() {
+		return ErrInvalidShardNum
+	}
+	if stripeLen <= 0 {
+		return ErrInvalidStripeSize
+	}
+	ins := make([]*os.File, len(shardPaths))
+	defer func() {
+		for _, f := range ins {
+			if f != nil {
+				f.Close()
+			}
+		}
+	}()
+	available := 0
+	for i, p := range shardPaths {
+		if len(p) == 0 {
+			continue
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		ins[i] = f
+		available++
+	}
+	if available < this.dataShards {
+		return ErrTooFewShards
+	}
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	remain := fileSize
+	shards := make([][]byte, this.TotalShards())
+	for remain > 0 {
+		for i, f := range ins {
+			shards[i] = nil
+			if f == nil {
+				continue
+			}
+			s := make([]byte, stripeLen)
+			if _, err := io.ReadFull(f, s); err != nil {
+				return fmt.Errorf("read shard %d failed: %s", i, err)
+			}
+			shards[i] = s
+		}
+		if err := this.Reconstruct(shards); err != nil {
+			return err
+		}
+		size := this.dataShards * stripeLen
+		if int64(size) > remain {
+			size = int(remain)
+		}
+		if err := this.Join(dst, shards, size); err != nil {
+			return err
+		}
+		remain -= int64(size)
+	}
+	return nil
+}
+
+func shardSize(shards [][]byte, allowMissing bool) (int, error) {
+	size := 0
+	for _, s := range shards {
+		if len(s) == 0 {
+			if !allowMissing {
+				return 0, ErrShardSize
+			}
+			continue
+		}
+		if size == 0 {
+			size = len(s)
+		} else if len(s) != size {
+			return 0, ErrShardSize
+		}
+	}
+	if size == 0 {
+		return 0, ErrShardSize
+	}
+	return size, nil
+}
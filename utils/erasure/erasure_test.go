@@ -0,0 +1,91 @@
+package erasure
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReconstruct(t *testing.T) {
+	codec, err := NewCodec(4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 1000)
+	rand.Read(data)
+	shards := codec.Split(data)
+	if err := codec.Encode(shards); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := codec.Verify(shards); err != nil || !ok {
+		t.Fatalf("verify failed %v %v", ok, err)
+	}
+	// lose one data shard and one parity shard
+	shards[1], shards[5] = nil, nil
+	if err := codec.Reconstruct(shards); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := codec.Join(buf, shards, len(data)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatal("reconstructed data mismatch")
+	}
+	shards[0], shards[2], shards[3] = nil, nil, nil
+	if err := codec.Reconstruct(shards); err != ErrTooFewShards {
+		t.Fatalf("expect too few shards, got %v", err)
+	}
+}
+
+func TestEncodeDecodeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "erasure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data := make([]byte, 10*1024+7)
+	rand.Read(data)
+	src := filepath.Join(dir, "src")
+	if err := ioutil.WriteFile(src, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	codec, err := NewCodec(3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripeLen := 1024
+	shardPaths := make([]string, codec.TotalShards())
+	for i := range shardPaths {
+		shardPaths[i] = filepath.Join(dir, "shard"+string(rune('0'+i)))
+	}
+	size, err := codec.EncodeFile(src, shardPaths, stripeLen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(data)) {
+		t.Fatalf("encode size %d, expect %d", size, len(data))
+	}
+	stat, err := os.Stat(shardPaths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Size() != ShardFileSize(size, 3, stripeLen) {
+		t.Fatalf("shard size %d, expect %d", stat.Size(), ShardFileSize(size, 3, stripeLen))
+	}
+	shardPaths[0], shardPaths[3] = "", ""
+	dst := filepath.Join(dir, "dst")
+	if err := codec.DecodeFile(shardPaths, dst, size, stripeLen); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("decoded file mismatch")
+	}
+}
@@ -0,0 +1,166 @@
+package erasure
+
+import "errors"
+
+// arithmetic over GF(2^8) with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1
+const gfPolynomial = 0x11d
+
+var (
+	gfExp [512]byte
+	gfLog [256]byte
+)
+
+var errSingularMatrix = errors.New("matrix is singular")
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	if b == 0 {
+		panic("erasure: divide by zero")
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// gfPow. a^n
+func gfPow(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(int(gfLog[a])*n)%255]
+}
+
+// mulSliceXor. out ^= c * in
+func mulSliceXor(c byte, in, out []byte) {
+	if c == 0 {
+		return
+	}
+	if c == 1 {
+		for i := range in {
+			out[i] ^= in[i]
+		}
+		return
+	}
+	logC := int(gfLog[c])
+	for i, v := range in {
+		if v == 0 {
+			continue
+		}
+		out[i] ^= gfExp[logC+int(gfLog[v])]
+	}
+}
+
+type matrix [][]byte
+
+func newMatrix(rows, cols int) matrix {
+	m := make(matrix, rows)
+	for i := range m {
+		m[i] = make([]byte, cols)
+	}
+	return m
+}
+
+func identityMatrix(size int) matrix {
+	m := newMatrix(size, size)
+	for i := range m {
+		m[i][i] = 1
+	}
+	return m
+}
+
+// vandermonde. rows x cols matrix with m[r][c] = r^c
+func vandermonde(rows, cols int) matrix {
+	m := newMatrix(rows, cols)
+	for r := range m {
+		for c := range m[r] {
+			m[r][c] = gfPow(byte(r), c)
+		}
+	}
+	return m
+}
+
+func (m matrix) multiply(right matrix) matrix {
+	out := newMatrix(len(m), len(right[0]))
+	for r := range out {
+		for c := range out[r] {
+			var v byte
+			for i := range right {
+				v ^= gfMul(m[r][i], right[i][c])
+			}
+			out[r][c] = v
+		}
+	}
+	return out
+}
+
+func (m matrix) subMatrix(rmin, cmin, rmax, cmax int) matrix {
+	out := newMatrix(rmax-rmin, cmax-cmin)
+	for r := rmin; r < rmax; r++ {
+		copy(out[r-rmin], m[r][cmin:cmax])
+	}
+	return out
+}
+
+// invert. invert a square matrix with gaussian elimination
+func (m matrix) invert() (matrix, error) {
+	size := len(m)
+	work := newMatrix(size, size*2)
+	for r := range m {
+		copy(work[r], m[r])
+		work[r][size+r] = 1
+	}
+	for r := 0; r < size; r++ {
+		if work[r][r] == 0 {
+			for below := r + 1; below < size; below++ {
+				if work[below][r] != 0 {
+					work[r], work[below] = work[below], work[r]
+					break
+				}
+			}
+		}
+		if work[r][r] == 0 {
+			return nil, errSingularMatrix
+		}
+		if work[r][r] != 1 {
+			scale := gfDiv(1, work[r][r])
+			for c := range work[r] {
+				work[r][c] = gfMul(work[r][c], scale)
+			}
+		}
+		for other := 0; other < size; other++ {
+			if other == r || work[other][r] == 0 {
+				continue
+			}
+			scale := work[other][r]
+			for c := range work[other] {
+				work[other][c] ^= gfMul(scale, work[r][c])
+			}
+		}
+	}
+	return work.subMatrix(0, size, size, size*2), nil
+}
@@ -0,0 +1,355 @@
+// Package fileindex keeps searchable metadata of local uploaded and downloaded files.
+// Files are categorized by the MIME type sniffed from content, falling back to the exact file extension,
+// and searched with filters, sorting and cursor pagination.
+package fileindex
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// file categories, the values are the same as the file list types of dsp
+const (
+	CATEGORY_ALL   = 0
+	CATEGORY_IMAGE = 1
+	CATEGORY_DOC   = 2
+	CATEGORY_VIDEO = 3
+	CATEGORY_MUSIC = 4
+	CATEGORY_OTHER = 5
+)
+
+const (
+	KIND_UPLOAD   = "upload"
+	KIND_DOWNLOAD = "download"
+)
+
+const (
+	SORT_BY_NAME       = "name"
+	SORT_BY_SIZE       = "size"
+	SORT_BY_CREATED_AT = "createdAt"
+	SORT_BY_UPDATED_AT = "updatedAt"
+)
+
+const (
+	DEFAULT_LIMIT = 50
+	MAX_LIMIT     = 1000
+	SNIFF_LEN     = 512
+)
+
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+var extCategories = map[string]int{
+	".jpg": CATEGORY_IMAGE, ".jpeg": CATEGORY_IMAGE, ".png": CATEGORY_IMAGE, ".gif": CATEGORY_IMAGE,
+	".svg": CATEGORY_IMAGE, ".bmp": CATEGORY_IMAGE, ".webp": CATEGORY_IMAGE,
+	".doc": CATEGORY_DOC, ".docx": CATEGORY_DOC, ".txt": CATEGORY_DOC, ".dat": CATEGORY_DOC,
+	".md": CATEGORY_DOC, ".pdf": CATEGORY_DOC, ".xls": CATEGORY_DOC, ".xlsx": CATEGORY_DOC,
+	".xlx": CATEGORY_DOC, ".ppt": CATEGORY_DOC, ".pptx": CATEGORY_DOC,
+	".mp4": CATEGORY_VIDEO, ".mov": CATEGORY_VIDEO, ".rmvb": CATEGORY_VIDEO, ".avi": CATEGORY_VIDEO,
+	".rm": CATEGORY_VIDEO, ".mkv": CATEGORY_VIDEO, ".webm": CATEGORY_VIDEO,
+	".mp3": CATEGORY_MUSIC, ".wav": CATEGORY_MUSIC, ".flac": CATEGORY_MUSIC, ".aac": CATEGORY_MUSIC,
+	".ogg": CATEGORY_MUSIC, ".m4a": CATEGORY_MUSIC,
+}
+
+var docMimes = map[string]struct{}{
+	"application/pdf":    {},
+	"application/msword": {},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": {},
+	"application/vnd.ms-excel": {},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         {},
+	"application/vnd.ms-powerpoint":                                             {},
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": {},
+}
+
+// Entry. metadata of a local file
+type Entry struct {
+	FileHash    string
+	Kind        string
+	Name        string
+	Path        string
+	Mime        string
+	Category    int
+	Size        uint64 // bytes
+	Owner       string
+	Description string
+	Tags        []string
+	CreatedAt   uint64
+	UpdatedAt   uint64
+}
+
+// Query. search conditions, the zero value of a field matches all
+type Query struct {
+	Kind        string
+	Category    int
+	Mime        string // prefix of mime type, e.g. "image/"
+	Tag         string
+	MinSize     uint64
+	MaxSize     uint64
+	CreatedFrom uint64
+	CreatedTo   uint64
+	Owner       string
+	Name        string // case insensitive name substring
+	Text        string // case insensitive substring of name, description or tags
+	SortBy      string
+	Desc        bool
+	Cursor      string
+	Limit       int
+}
+
+// Result. a page of search result
+type Result struct {
+	Total      int
+	Entries    []*Entry
+	NextCursor string `json:",omitempty"`
+}
+
+// CategoryOfName. category by the exact extension of file name
+func CategoryOfName(name string) int {
+	if c, ok := extCategories[strings.ToLower(filepath.Ext(name))]; ok {
+		return c
+	}
+	return CATEGORY_OTHER
+}
+
+// CategoryOfMime. category by mime type, return CATEGORY_OTHER if the mime is not specific
+func CategoryOfMime(mimeType string) int {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return CATEGORY_IMAGE
+	case strings.HasPrefix(mimeType, "video/"):
+		return CATEGORY_VIDEO
+	case strings.HasPrefix(mimeType, "audio/"):
+		return CATEGORY_MUSIC
+	case strings.HasPrefix(mimeType, "text/"):
+		return CATEGORY_DOC
+	}
+	if _, ok := docMimes[mimeType]; ok {
+		return CATEGORY_DOC
+	}
+	return CATEGORY_OTHER
+}
+
+// Categorize. category of the file, the content mime is preferred and the extension is the fallback.
+// The generic mimes such as text/plain and application/octet-stream are decided by extension first.
+func Categorize(name, mimeType string) int {
+	byName := CategoryOfName(name)
+	byMime := CategoryOfMime(mimeType)
+	if byMime == CATEGORY_OTHER || (byMime == CATEGORY_DOC && strings.HasPrefix(mimeType, "text/plain")) {
+		if byName != CATEGORY_OTHER {
+			return byName
+		}
+	}
+	return byMime
+}
+
+// SniffMime. detect mime type from the file content, fallback to the extension of name
+func SniffMime(path, name string) string {
+	byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	f, err := os.Open(path)
+	if err != nil {
+		return byExt
+	}
+	defer f.Close()
+	buf := make([]byte, SNIFF_LEN)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return byExt
+	}
+	detected := http.DetectContentType(buf[:n])
+	if len(byExt) > 0 && (detected == "application/octet-stream" || strings.HasPrefix(detected, "text/plain")) {
+		return byExt
+	}
+	return detected
+}
+
+// HasTag. the entry has the tag, case insensitive
+func (this *Entry) HasTag(tag string) bool {
+	for _, t := range this.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match. the entry matches the query filters
+func (this *Query) Match(e *Entry) bool {
+	if len(this.Kind) > 0 && e.Kind != this.Kind {
+		return false
+	}
+	if this.Category != CATEGORY_ALL && e.Category != this.Category {
+		return false
+	}
+	if len(this.Mime) > 0 && !strings.HasPrefix(e.Mime, this.Mime) {
+		return false
+	}
+	if len(this.Tag) > 0 && !e.HasTag(this.Tag) {
+		return false
+	}
+	if this.MinSize > 0 && e.Size < this.MinSize {
+		return false
+	}
+	if this.MaxSize > 0 && e.Size > this.MaxSize {
+		return false
+	}
+	if this.CreatedFrom > 0 && e.CreatedAt < this.CreatedFrom {
+		return false
+	}
+	if this.CreatedTo > 0 && e.CreatedAt > this.CreatedTo {
+		return false
+	}
+	if len(this.Owner) > 0 && e.Owner != this.Owner {
+		return false
+	}
+	if len(this.Name) > 0 && !containsFold(e.Name, this.Name) {
+		return false
+	}
+	if len(this.Text) > 0 && !containsFold(e.Name, this.Text) && !containsFold(e.Description, this.Text) {
+		matched := false
+		for _, t := range e.Tags {
+			if containsFold(t, this.Text) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Search. filter, sort and page the entries
+func Search(entries []*Entry, q *Query) (*Result, error) {
+	less, err := q.less()
+	if err != nil {
+		return nil, err
+	}
+	var after *Entry
+	if len(q.Cursor) > 0 {
+		if after, err = decodeCursor(q.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	matched := make([]*Entry, 0)
+	for _, e := range entries {
+		if e != nil && q.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[i], matched[j])
+	})
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return less(after, matched[i])
+		})
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+	if limit > MAX_LIMIT {
+		limit = MAX_LIMIT
+	}
+	end := start + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+	result := &Result{Total: len(matched), Entries: matched[start:end]}
+	if end < len(matched) {
+		result.NextCursor = encodeCursor(matched[end-1])
+	}
+	return result, nil
+}
+
+// less. the order of query, entries are ordered by file hash and kind when the sort fields equal
+func (this *Query) less() (func(a, b *Entry) bool, error) {
+	var cmp func(a, b *Entry) int
+	switch this.SortBy {
+	case "", SORT_BY_CREATED_AT:
+		cmp = func(a, b *Entry) int { return compareUint(a.CreatedAt, b.CreatedAt) }
+	case SORT_BY_UPDATED_AT:
+		cmp = func(a, b *Entry) int { return compareUint(a.UpdatedAt, b.UpdatedAt) }
+	case SORT_BY_SIZE:
+		cmp = func(a, b *Entry) int { return compareUint(a.Size, b.Size) }
+	case SORT_BY_NAME:
+		cmp = func(a, b *Entry) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) }
+	default:
+		return nil, errors.New("invalid sort field " + this.SortBy)
+	}
+	desc := this.Desc
+	return func(a, b *Entry) bool {
+		if c := cmp(a, b); c != 0 {
+			return (c < 0) != desc
+		}
+		if a.FileHash != b.FileHash {
+			return a.FileHash < b.FileHash
+		}
+		return a.Kind < b.Kind
+	}, nil
+}
+
+// cursor. the sort fields of the last entry in a page
+type cursor struct {
+	FileHash  string
+	Kind      string
+	Name      string
+	Size      uint64
+	CreatedAt uint64
+	UpdatedAt uint64
+}
+
+func encodeCursor(e *Entry) string {
+	buf, _ := json.Marshal(&cursor{
+		FileHash:  e.FileHash,
+		Kind:      e.Kind,
+		Name:      e.Name,
+		Size:      e.Size,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	})
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func decodeCursor(s string) (*Entry, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(buf, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Entry{
+		FileHash:  c.FileHash,
+		Kind:      c.Kind,
+		Name:      c.Name,
+		Size:      c.Size,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}, nil
+}
+
+func compareUint(a, b uint64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,101 @@
+package fileindex
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCategoryOfName(t *testing.T) {
+	cases := map[string]int{
+		"my.mp3.txt": CATEGORY_DOC,
+		"movie.rmvb": CATEGORY_VIDEO,
+		"movie.rm":   CATEGORY_VIDEO,
+		"a.RMX":      CATEGORY_OTHER,
+		"PHOTO.JPG":  CATEGORY_IMAGE,
+		"song.mp3":   CATEGORY_MUSIC,
+		"noext":      CATEGORY_OTHER,
+	}
+	for name, expect := range cases {
+		if c := CategoryOfName(name); c != expect {
+			t.Fatalf("%s category %d, expect %d", name, c, expect)
+		}
+	}
+}
+
+func TestSniffMime(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// png content with a misleading name
+	path := filepath.Join(dir, "image.mp3.txt")
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	if err := ioutil.WriteFile(path, png, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := SniffMime(path, "image.mp3.txt")
+	if m != "image/png" || Categorize("image.mp3.txt", m) != CATEGORY_IMAGE {
+		t.Fatalf("wrong mime %s", m)
+	}
+	if c := Categorize("a.mp4", "application/octet-stream"); c != CATEGORY_VIDEO {
+		t.Fatalf("generic mime should fallback to extension, got %d", c)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	entries := make([]*Entry, 0)
+	for i := 0; i < 10; i++ {
+		entries = append(entries, &Entry{
+			FileHash:  fmt.Sprintf("hash%d", i),
+			Kind:      KIND_UPLOAD,
+			Name:      fmt.Sprintf("File%d.mp4", i),
+			Category:  CATEGORY_VIDEO,
+			Size:      uint64(i * 100),
+			Owner:     "alice",
+			CreatedAt: uint64(1000 + i%3),
+		})
+	}
+	entries[3].Tags = []string{"Holiday"}
+	entries[4].Category = CATEGORY_DOC
+
+	r, err := Search(entries, &Query{Tag: "holiday"})
+	if err != nil || r.Total != 1 || r.Entries[0].FileHash != "hash3" {
+		t.Fatalf("tag search failed %v %+v", err, r)
+	}
+	r, _ = Search(entries, &Query{Category: CATEGORY_VIDEO, MinSize: 200, MaxSize: 700, Name: "file"})
+	if r.Total != 5 {
+		t.Fatalf("filter search total %d", r.Total)
+	}
+	// page through with cursor sorted by created time which has duplicated values
+	seen := make(map[string]bool)
+	q := &Query{SortBy: SORT_BY_CREATED_AT, Desc: true, Limit: 3}
+	for pages := 0; ; pages++ {
+		r, err := Search(entries, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range r.Entries {
+			if seen[e.FileHash] {
+				t.Fatalf("duplicated entry %s", e.FileHash)
+			}
+			seen[e.FileHash] = true
+		}
+		if len(r.NextCursor) == 0 {
+			break
+		}
+		if pages > 10 {
+			t.Fatal("too many pages")
+		}
+		q.Cursor = r.NextCursor
+	}
+	if len(seen) != len(entries) {
+		t.Fatalf("paged %d entries, expect %d", len(seen), len(entries))
+	}
+	if _, err := Search(entries, &Query{Cursor: "!"}); err != ErrInvalidCursor {
+		t.Fatalf("expect invalid cursor, got %v", err)
+	}
+}
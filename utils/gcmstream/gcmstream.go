@@ -0,0 +1,320 @@
+// Package gcmstream implements a chunked AES-GCM stream.
+// The plaintext is split into fixed size chunks and each chunk is sealed with its own nonce,
+// so a file can be encrypted or decrypted in one pass with constant memory.
+//
+// Stream layout:
+//
+//	magic(4) | version(1) | chunk size(4) | salt(16) | nonce prefix(4) | sealed chunk...
+//
+// The nonce of chunk i is nonce prefix | i (big endian uint64).
+// The header and a final chunk flag are authenticated as additional data of every chunk,
+// so reordered, truncated or extended streams are rejected.
+package gcmstream
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+)
+
+const (
+	VERSION            = 1
+	DEFAULT_CHUNK_SIZE = 64 * 1024
+	MAX_CHUNK_SIZE     = 16 * 1024 * 1024
+	HEADER_LEN         = 4 + 1 + 4 + SALT_LEN + NONCE_PREFIX_LEN
+	SALT_LEN           = 16
+	NONCE_PREFIX_LEN   = 4
+	KEY_LEN            = 32
+	KDF_ITERATIONS     = 100000
+)
+
+var MAGIC = []byte("SGCM")
+
+var (
+	ErrInvalidHeader  = errors.New("invalid gcm stream header")
+	ErrVersion        = errors.New("unsupported gcm stream version")
+	ErrAuthentication = errors.New("gcm stream authentication failed")
+	ErrClosed         = errors.New("gcm stream writer closed")
+)
+
+// IsStream. check whether the data starts with a gcm stream header
+func IsStream(head []byte) bool {
+	return len(head) >= len(MAGIC) && bytes.Equal(head[:len(MAGIC)], MAGIC)
+}
+
+type header struct {
+	version     byte
+	chunkSize   uint32
+	salt        []byte
+	noncePrefix []byte
+}
+
+func (this *header) serialize() []byte {
+	buf := make([]byte, 0, HEADER_LEN)
+	buf = append(buf, MAGIC...)
+	buf = append(buf, this.version)
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, this.chunkSize)
+	buf = append(buf, size...)
+	buf = append(buf, this.salt...)
+	buf = append(buf, this.noncePrefix...)
+	return buf
+}
+
+func parseHeader(buf []byte) (*header, error) {
+	if len(buf) != HEADER_LEN || !IsStream(buf) {
+		return nil, ErrInvalidHeader
+	}
+	h := &header{version: buf[4]}
+	if h.version != VERSION {
+		return nil, ErrVersion
+	}
+	h.chunkSize = binary.BigEndian.Uint32(buf[5:9])
+	if h.chunkSize == 0 || h.chunkSize > MAX_CHUNK_SIZE {
+		return nil, ErrInvalidHeader
+	}
+	h.salt = buf[9 : 9+SALT_LEN]
+	h.noncePrefix = buf[9+SALT_LEN:]
+	return h, nil
+}
+
+type sealer struct {
+	aead   cipher.AEAD
+	header []byte
+	prefix []byte
+	index  uint64
+}
+
+func newSealer(password []byte, h *header) (*sealer, error) {
+	key := deriveKey(password, h.salt)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead, header: h.serialize(), prefix: h.noncePrefix}, nil
+}
+
+func (this *sealer) nonce() []byte {
+	nonce := make([]byte, this.aead.NonceSize())
+	copy(nonce, this.prefix)
+	binary.BigEndian.PutUint64(nonce[len(this.prefix):], this.index)
+	return nonce
+}
+
+func (this *sealer) additional(final bool) []byte {
+	ad := make([]byte, len(this.header)+1)
+	copy(ad, this.header)
+	if final {
+		ad[len(ad)-1] = 1
+	}
+	return ad
+}
+
+type writer struct {
+	w       io.Writer
+	sealer  *sealer
+	chunk   int
+	buf     []byte
+	closed  bool
+	written bool
+}
+
+// NewWriter. return a writer which encrypts the data written to it and writes the stream to w.
+// Close must be called to seal the final chunk, it doesn't close w.
+func NewWriter(w io.Writer, password []byte, chunkSize int) (io.WriteCloser, error) {
+	if chunkSize <= 0 || chunkSize > MAX_CHUNK_SIZE {
+		chunkSize = DEFAULT_CHUNK_SIZE
+	}
+	h := &header{
+		version:     VERSION,
+		chunkSize:   uint32(chunkSize),
+		salt:        make([]byte, SALT_LEN),
+		noncePrefix: make([]byte, NONCE_PREFIX_LEN),
+	}
+	if _, err := rand.Read(h.salt); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(h.noncePrefix); err != nil {
+		return nil, err
+	}
+	s, err := newSealer(password, h)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{
+		w:      w,
+		sealer: s,
+		chunk:  chunkSize,
+		buf:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (this *writer) Write(p []byte) (int, error) {
+	if this.closed {
+		return 0, ErrClosed
+	}
+	if err := this.writeHeader(); err != nil {
+		return 0, err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		// the full chunk is sealed only if more data comes, so the last chunk can be marked as final
+		if len(this.buf) == this.chunk {
+			if err := this.seal(false); err != nil {
+				return n - len(p), err
+			}
+		}
+		m := this.chunk - len(this.buf)
+		if m > len(p) {
+			m = len(p)
+		}
+		this.buf = append(this.buf, p[:m]...)
+		p = p[m:]
+	}
+	return n, nil
+}
+
+func (this *writer) Close() error {
+	if this.closed {
+		return nil
+	}
+	if err := this.writeHeader(); err != nil {
+		return err
+	}
+	this.closed = true
+	return this.seal(true)
+}
+
+func (this *writer) writeHeader() error {
+	if this.written {
+		return nil
+	}
+	this.written = true
+	_, err := this.w.Write(this.sealer.header)
+	return err
+}
+
+func (this *writer) seal(final bool) error {
+	out := this.sealer.aead.Seal(nil, this.sealer.nonce(), this.buf, this.sealer.additional(final))
+	this.sealer.index++
+	this.buf = this.buf[:0]
+	_, err := this.w.Write(out)
+	return err
+}
+
+type reader struct {
+	r      *bufio.Reader
+	sealer *sealer
+	chunk  int
+	plain  []byte
+	done   bool
+	err    error
+}
+
+// NewReader. return a reader which reads the stream from r and returns the decrypted data.
+// The error ErrAuthentication is returned if the stream is modified or the password is wrong.
+func NewReader(r io.Reader, password []byte) (io.Reader, error) {
+	buf := make([]byte, HEADER_LEN)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	h, err := parseHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSealer(password, h)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{
+		r:      bufio.NewReaderSize(r, int(h.chunkSize)+s.aead.Overhead()+1),
+		sealer: s,
+		chunk:  int(h.chunkSize),
+	}, nil
+}
+
+func (this *reader) Read(p []byte) (int, error) {
+	for len(this.plain) == 0 {
+		if this.err != nil {
+			return 0, this.err
+		}
+		if this.done {
+			return 0, io.EOF
+		}
+		this.err = this.open()
+	}
+	n := copy(p, this.plain)
+	this.plain = this.plain[n:]
+	return n, nil
+}
+
+func (this *reader) open() error {
+	sealed := make([]byte, this.chunk+this.sealer.aead.Overhead())
+	n, err := io.ReadFull(this.r, sealed)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			// the final chunk is missing
+			return ErrAuthentication
+		}
+		return err
+	}
+	sealed = sealed[:n]
+	final := err == io.ErrUnexpectedEOF
+	if !final {
+		if _, err := this.r.Peek(1); err == io.EOF {
+			final = true
+		}
+	}
+	plain, err := this.sealer.aead.Open(nil, this.sealer.nonce(), sealed, this.sealer.additional(final))
+	if err != nil {
+		return ErrAuthentication
+	}
+	this.sealer.index++
+	this.plain = plain
+	this.done = final
+	return nil
+}
+
+// deriveKey. PBKDF2-HMAC-SHA256 of the password
+func deriveKey(password, salt []byte) []byte {
+	prf := hmac.New(sha256.New, password)
+	return pbkdf2(prf, salt, KDF_ITERATIONS, KEY_LEN)
+}
+
+func pbkdf2(prf hash.Hash, salt []byte, iter, keyLen int) []byte {
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+	key := make([]byte, 0, blocks*hashLen)
+	counter := make([]byte, 4)
+	for block := 1; block <= blocks; block++ {
+		binary.BigEndian.PutUint32(counter, uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter)
+		u := prf.Sum(nil)
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iter; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
@@ -0,0 +1,71 @@
+package gcmstream
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+func seal(t *testing.T, data, password []byte, chunkSize int) []byte {
+	out := new(bytes.Buffer)
+	w, err := NewWriter(out, password, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func open(sealed, password []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), password)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	password := []byte("123456")
+	chunkSize := 16
+	for _, size := range []int{0, 1, chunkSize, chunkSize + 1, 3*chunkSize + 5} {
+		data := make([]byte, size)
+		rand.Read(data)
+		sealed := seal(t, data, password, chunkSize)
+		if !IsStream(sealed) {
+			t.Fatalf("size %d: missing stream header", size)
+		}
+		got, err := open(sealed, password)
+		if err != nil {
+			t.Fatalf("size %d: %s", size, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("size %d: data mismatch", size)
+		}
+	}
+}
+
+func TestRejectModifiedStream(t *testing.T) {
+	chunkSize := 16
+	data := make([]byte, 3*chunkSize+5)
+	rand.Read(data)
+	sealed := seal(t, data, []byte("123456"), chunkSize)
+	if _, err := open(sealed, []byte("654321")); err != ErrAuthentication {
+		t.Fatalf("wrong password: expect %v, got %v", ErrAuthentication, err)
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[HEADER_LEN+3] ^= 0xff
+	if _, err := open(tampered, []byte("123456")); err != ErrAuthentication {
+		t.Fatalf("tampered: expect %v, got %v", ErrAuthentication, err)
+	}
+	// drop the final chunk
+	truncated := sealed[:HEADER_LEN+3*(chunkSize+16)]
+	if _, err := open(truncated, []byte("123456")); err != ErrAuthentication {
+		t.Fatalf("truncated: expect %v, got %v", ErrAuthentication, err)
+	}
+}
@@ -0,0 +1,158 @@
+// Package nodeexit tracks the planned exit of a storage node.
+// The node keeps proving the files it stores until each file is proved by enough other nodes or expired.
+// The other nodes proving a file before the exit are its copy holders, so a file with CopyNum copies
+// is handed off when CopyNum+1 other nodes prove it, at least one of them holds the copy of this node.
+// the exit is drained then and the node can be unregistered without leaving files to repair.
+// The node can't add copies of files it doesn't own, a file gaining no replica for long is reported as stalled
+// with its owner, who should add copies of it.
+package nodeexit
+
+import "sort"
+
+const (
+	STATE_DRAINING  = "draining"  // waiting for files handed off
+	STATE_DONE      = "done"      // node is unregistered
+	STATE_CANCELLED = "cancelled" // exit is cancelled, node accepts new files again
+)
+
+const (
+	FILE_PENDING    = "pending"    // the node still needs to prove the file
+	FILE_REPLICATED = "replicated" // the file is proved by enough other nodes
+	FILE_EXPIRED    = "expired"    // the file is expired
+	FILE_REMOVED    = "removed"    // the file is not stored by the node any more
+	FILE_OWNED      = "owned"      // plot file of the node, it needs no handoff
+)
+
+// DEFAULT_MIN_REPLICAS. other nodes proved a file before it's handed off, at least CopyNum+1 of the file
+const DEFAULT_MIN_REPLICAS = 1
+
+// FileStatus. a file stored by the node at current height
+type FileStatus struct {
+	FileHash      string
+	Owner         string
+	IsPlot        bool
+	ExpiredHeight uint64
+	CopyNum       uint64 // copies of file besides the primary, proved by other nodes before the exit
+	Replicas      int    // other nodes proved the file
+}
+
+// File. handoff progress of a file
+type File struct {
+	FileHash       string
+	Owner          string `json:",omitempty"`
+	Status         string
+	ExpiredHeight  uint64
+	Replicas       int
+	Required       int // other nodes needed to prove the file
+	UpdatedHeight  uint64
+	ProgressHeight uint64 // height when the file is tracked or gains a replica
+	Stalled        bool   // pending file gaining no replica for StallBlocks
+}
+
+// Sector. an empty sector deleted by the exit, created again if the exit is cancelled
+type Sector struct {
+	SectorId   uint64
+	Size       uint64
+	ProveLevel uint64
+	IsPlots    bool
+}
+
+// Exit. a planned exit of node
+type Exit struct {
+	State        string
+	MinReplicas  int
+	StallBlocks  uint64 // blocks a pending file gains no replica before it's stalled, 0 for never
+	OldVolume    uint64 // volume before exit, restored if cancelled
+	Volume       uint64 // volume during exit, no space left for new files
+	VolumeTx     string
+	Sectors      []*Sector // empty sectors deleted before the volume is reduced
+	Height       uint64
+	Files        []*File
+	Pending      int
+	Stalled      int
+	WithdrawTx   string `json:",omitempty"`
+	UnregisterTx string `json:",omitempty"`
+	Error        string `json:",omitempty"`
+	StartedAt    uint64
+	UpdatedAt    uint64
+}
+
+// Volume. volume of node during exit, the used space not less than the sectors of node
+func Volume(used, sectorsSize uint64) uint64 {
+	if sectorsSize > used {
+		return sectorsSize
+	}
+	return used
+}
+
+// Update. update handoff progress of files with the files stored at height,
+// a file not stored any more is removed, a handed off file is not pending again.
+func (this *Exit) Update(files []*FileStatus, height uint64) {
+	if this.MinReplicas <= 0 {
+		this.MinReplicas = DEFAULT_MIN_REPLICAS
+	}
+	stored := make(map[string]*FileStatus)
+	for _, f := range files {
+		stored[f.FileHash] = f
+	}
+	tracked := make(map[string]*File)
+	for _, f := range this.Files {
+		tracked[f.FileHash] = f
+	}
+	for _, f := range files {
+		if _, ok := tracked[f.FileHash]; ok {
+			continue
+		}
+		file := &File{FileHash: f.FileHash, Status: FILE_PENDING, ProgressHeight: height}
+		tracked[f.FileHash] = file
+		this.Files = append(this.Files, file)
+	}
+	this.Pending, this.Stalled = 0, 0
+	for _, file := range this.Files {
+		f, ok := stored[file.FileHash]
+		switch {
+		case file.Status != FILE_PENDING:
+		case !ok:
+			file.Status = FILE_REMOVED
+		case f.IsPlot:
+			file.Status = FILE_OWNED
+		case f.ExpiredHeight > 0 && height >= f.ExpiredHeight:
+			file.Status = FILE_EXPIRED
+		case f.Replicas >= this.required(f):
+			file.Status = FILE_REPLICATED
+		}
+		if ok {
+			file.Required = this.required(f)
+			if f.Replicas > file.Replicas {
+				file.ProgressHeight = height
+			}
+			file.Owner, file.ExpiredHeight, file.Replicas, file.UpdatedHeight = f.Owner, f.ExpiredHeight,
+				f.Replicas, height
+		}
+		file.Stalled = file.Status == FILE_PENDING && this.StallBlocks > 0 &&
+			height > file.ProgressHeight+this.StallBlocks
+		if file.Status == FILE_PENDING {
+			this.Pending++
+		}
+		if file.Stalled {
+			this.Stalled++
+		}
+	}
+	sort.SliceStable(this.Files, func(i, j int) bool {
+		return this.Files[i].Status == FILE_PENDING && this.Files[j].Status != FILE_PENDING
+	})
+	this.Height = height
+}
+
+// required. the other nodes needed to prove a file, the copy holders before the exit and one more
+func (this *Exit) required(f *FileStatus) int {
+	if int(f.CopyNum)+1 > this.MinReplicas {
+		return int(f.CopyNum) + 1
+	}
+	return this.MinReplicas
+}
+
+// Drained. all files are handed off
+func (this *Exit) Drained() bool {
+	return this.State == STATE_DRAINING && this.Pending == 0
+}
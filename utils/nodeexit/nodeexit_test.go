@@ -0,0 +1,92 @@
+package nodeexit
+
+import "testing"
+
+func TestUpdate(t *testing.T) {
+	exit := &Exit{State: STATE_DRAINING}
+	exit.Update([]*FileStatus{
+		{FileHash: "a", ExpiredHeight: 1000},
+		{FileHash: "b", ExpiredHeight: 200},
+		{FileHash: "c", ExpiredHeight: 1000, Replicas: 1},
+		{FileHash: "d", IsPlot: true},
+		{FileHash: "e", ExpiredHeight: 1000},
+	}, 300)
+	if exit.MinReplicas != DEFAULT_MIN_REPLICAS || exit.Pending != 2 || exit.Drained() {
+		t.Fatalf("unexpected exit %+v", exit)
+	}
+	if exit.Files[0].FileHash != "a" || exit.Files[1].FileHash != "e" {
+		t.Fatalf("pending files should be first, %+v %+v", exit.Files[0], exit.Files[1])
+	}
+	status := make(map[string]string)
+	for _, f := range exit.Files {
+		status[f.FileHash] = f.Status
+	}
+	if status["b"] != FILE_EXPIRED || status["c"] != FILE_REPLICATED || status["d"] != FILE_OWNED {
+		t.Fatalf("unexpected status %v", status)
+	}
+
+	// a replicated file stays done, a new file is tracked, a deleted file is removed
+	exit.Update([]*FileStatus{
+		{FileHash: "a", ExpiredHeight: 1000, Replicas: 2},
+		{FileHash: "c", ExpiredHeight: 1000},
+		{FileHash: "f", ExpiredHeight: 1000},
+	}, 400)
+	if exit.Pending != 1 || exit.Files[0].FileHash != "f" || len(exit.Files) != 6 {
+		t.Fatalf("unexpected exit %+v", exit)
+	}
+	for _, f := range exit.Files {
+		status[f.FileHash] = f.Status
+	}
+	if status["a"] != FILE_REPLICATED || status["c"] != FILE_REPLICATED || status["e"] != FILE_REMOVED {
+		t.Fatalf("unexpected status %v", status)
+	}
+	exit.Update([]*FileStatus{{FileHash: "f", ExpiredHeight: 1000}}, 1000)
+	if !exit.Drained() {
+		t.Fatalf("exit should be drained %+v", exit)
+	}
+}
+
+func TestCopyHolders(t *testing.T) {
+	exit := &Exit{State: STATE_DRAINING}
+	// the 2 copy holders proved the file before the exit
+	exit.Update([]*FileStatus{{FileHash: "a", ExpiredHeight: 1000, CopyNum: 2, Replicas: 2}}, 100)
+	if exit.Pending != 1 || exit.Files[0].Required != 3 {
+		t.Fatalf("file proved by copy holders only should be pending %+v", exit.Files[0])
+	}
+	exit.Update([]*FileStatus{{FileHash: "a", ExpiredHeight: 1000, CopyNum: 2, Replicas: 3}}, 200)
+	if exit.Files[0].Status != FILE_REPLICATED || !exit.Drained() {
+		t.Fatalf("file proved by a new node should be replicated %+v", exit.Files[0])
+	}
+	// min replicas is used if more than the copies
+	exit = &Exit{State: STATE_DRAINING, MinReplicas: 3}
+	exit.Update([]*FileStatus{{FileHash: "b", ExpiredHeight: 1000, CopyNum: 1, Replicas: 2}}, 100)
+	if exit.Pending != 1 {
+		t.Fatalf("unexpected exit %+v", exit.Files[0])
+	}
+}
+
+func TestStalled(t *testing.T) {
+	exit := &Exit{State: STATE_DRAINING, MinReplicas: 2, StallBlocks: 100}
+	exit.Update([]*FileStatus{
+		{FileHash: "a", Owner: "x", ExpiredHeight: 1000},
+		{FileHash: "b", Owner: "y", ExpiredHeight: 1000},
+	}, 100)
+	exit.Update([]*FileStatus{
+		{FileHash: "a", Owner: "x", ExpiredHeight: 1000},
+		{FileHash: "b", Owner: "y", ExpiredHeight: 1000, Replicas: 1},
+	}, 150)
+	exit.Update([]*FileStatus{
+		{FileHash: "a", Owner: "x", ExpiredHeight: 1000},
+		{FileHash: "b", Owner: "y", ExpiredHeight: 1000, Replicas: 1},
+	}, 201)
+	if exit.Pending != 2 || exit.Stalled != 1 || !exit.Files[0].Stalled || exit.Files[0].Owner != "x" ||
+		exit.Files[1].Stalled {
+		t.Fatalf("unexpected exit %+v %+v", exit.Files[0], exit.Files[1])
+	}
+}
+
+func TestVolume(t *testing.T) {
+	if Volume(100, 300) != 300 || Volume(300, 100) != 300 {
+		t.Fatal("volume should keep the used space and sectors")
+	}
+}
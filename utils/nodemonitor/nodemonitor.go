@@ -0,0 +1,291 @@
+// Package nodemonitor watches a storage node over time.
+// Samples of the node info on chain are recorded periodically to compute the earnings per GB per day,
+// a policy raises alerts once when sectors miss their proofs, files stored are not proved in time,
+// or the service time of node is running out, and decides automatic extensions of the service time.
+// Volumes are in KB as the node info on chain. The zero value of a policy alerts missed proofs only.
+package nodemonitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	ALERT_PROOF_MISSED = "proofmissed" // a sector passed its next prove height without a proof
+	ALERT_PDP_FAILED   = "pdpfailed"   // prove times of a file stored have not grown in its prove interval
+	ALERT_SERVICE_TIME = "servicetime" // the service time of node is running out
+)
+
+// PROOF_DELAY_BLOCKS. blocks after the prove interval before the proof of file is regarded as failed
+const PROOF_DELAY_BLOCKS = 100
+
+// ACTION_RETRY_INTERVAL. min seconds between two automatic extensions, avoid retrying a failed one too often
+const ACTION_RETRY_INTERVAL = 3600
+
+const (
+	KB_PER_GB      = 1024 * 1024
+	SECONDS_OF_DAY = 86400
+)
+
+var ErrExtendPeriodRequired = errors.New("extend period is required with auto extend")
+
+// Policy. node monitor policy
+type Policy struct {
+	ServiceTimeThresholds []uint64 // seconds before service time runs out to alert, e.g. 7 days
+	AutoExtend            bool
+	AutoExtendBefore      uint64 // seconds before service time runs out to extend
+	ExtendPeriod          uint64 // seconds added to service time
+	UpdatedAt             uint64
+}
+
+// Sample. node info on chain at a time
+type Sample struct {
+	Height      uint64
+	Pledge      uint64
+	Profit      uint64
+	Volume      uint64
+	RestVol     uint64
+	ServiceTime uint64 // timestamp when the node stops service
+	FileCount   int
+	ProveTimes  uint64 // total prove times of files stored
+	CreatedAt   uint64
+}
+
+// FileProof. proof progress of a file stored by the node
+type FileProof struct {
+	FileHash      string
+	ProveTimes    uint64
+	ProveInterval uint64 // blocks
+	ExpiredHeight uint64
+}
+
+// Snapshot. what the node looks like at current height
+type Snapshot struct {
+	Sample        *Sample
+	MissedSectors []uint64
+	Files         []*FileProof
+}
+
+// FileState. the height when prove times of a file last changed
+type FileState struct {
+	ProveTimes    uint64
+	ChangedHeight uint64
+}
+
+// State. alerted items and last action time of monitor
+type State struct {
+	Files          map[string]*FileState
+	SectorsAlerted []uint64
+	FilesAlerted   []string
+	ServiceAlerted []uint64
+	LastActionAt   uint64
+}
+
+// Alert. a problem found by the monitor
+type Alert struct {
+	Kind        string
+	SectorId    uint64 `json:",omitempty"`
+	FileHash    string `json:",omitempty"`
+	Threshold   uint64 `json:",omitempty"`
+	Value       uint64 // blocks since last proof, or seconds to the end of service time
+	Height      uint64
+	ServiceTime uint64
+	CreatedAt   uint64
+}
+
+// Action. a automatic extension of service time
+type Action struct {
+	ServiceTime uint64 // new service time
+	AddPeriod   uint64
+}
+
+// ActionLog. a automatic extension which has been tried
+type ActionLog struct {
+	Id          string
+	ServiceTime uint64
+	AddPeriod   uint64
+	Tx          string `json:",omitempty"`
+	Error       string `json:",omitempty"`
+	CreatedAt   uint64
+}
+
+// Earnings. profit earned by the node in a period
+type Earnings struct {
+	From       uint64
+	To         uint64
+	Profit     uint64
+	AvgUsed    uint64 // KB
+	PerGBDay   float64
+	SampleSize int
+}
+
+// Validate. check the thresholds and auto extension of the policy
+func (this *Policy) Validate() error {
+	for _, t := range this.ServiceTimeThresholds {
+		if t == 0 {
+			return fmt.Errorf("invalid service time threshold %d", t)
+		}
+	}
+	if !this.AutoExtend {
+		return nil
+	}
+	if this.AutoExtendBefore == 0 {
+		return errors.New("auto extend needs the time before service time runs out")
+	}
+	if this.ExtendPeriod == 0 {
+		return ErrExtendPeriodRequired
+	}
+	return nil
+}
+
+// Check. check the snapshot with the policy, return the new alerts and the automatic action.
+// An alert is raised once until the problem goes away. The state is updated in place.
+func (this *Policy) Check(snapshot *Snapshot, state *State, now time.Time) ([]*Alert, *Action) {
+	if snapshot == nil || snapshot.Sample == nil {
+		return nil, nil
+	}
+	sample := snapshot.Sample
+	newAlert := func(kind string) *Alert {
+		return &Alert{
+			Kind:        kind,
+			Height:      sample.Height,
+			ServiceTime: sample.ServiceTime,
+			CreatedAt:   uint64(now.Unix()),
+		}
+	}
+	alerts := make([]*Alert, 0)
+
+	sectors := make([]uint64, 0)
+	for _, sectorId := range snapshot.MissedSectors {
+		if !containsUint64(state.SectorsAlerted, sectorId) {
+			alert := newAlert(ALERT_PROOF_MISSED)
+			alert.SectorId = sectorId
+			alerts = append(alerts, alert)
+		}
+		sectors = append(sectors, sectorId)
+	}
+	state.SectorsAlerted = sectors
+
+	files := make(map[string]*FileState)
+	failed := make([]string, 0)
+	for _, file := range snapshot.Files {
+		fileState := state.Files[file.FileHash]
+		if fileState == nil || fileState.ProveTimes != file.ProveTimes {
+			fileState = &FileState{ProveTimes: file.ProveTimes, ChangedHeight: sample.Height}
+		}
+		files[file.FileHash] = fileState
+		if file.ExpiredHeight > 0 && sample.Height >= file.ExpiredHeight {
+			continue
+		}
+		if sample.Height <= fileState.ChangedHeight+file.ProveInterval+PROOF_DELAY_BLOCKS {
+			continue
+		}
+		if !containsString(state.FilesAlerted, file.FileHash) {
+			alert := newAlert(ALERT_PDP_FAILED)
+			alert.FileHash = file.FileHash
+			alert.Value = sample.Height - fileState.ChangedHeight
+			alerts = append(alerts, alert)
+		}
+		failed = append(failed, file.FileHash)
+	}
+	state.Files = files
+	state.FilesAlerted = failed
+
+	left := uint64(0)
+	if sample.ServiceTime > uint64(now.Unix()) {
+		left = sample.ServiceTime - uint64(now.Unix())
+	}
+	thresholds := append([]uint64{}, this.ServiceTimeThresholds...)
+	sort.Slice(thresholds, func(i, j int) bool {
+		return thresholds[i] > thresholds[j]
+	})
+	alerted := make([]uint64, 0)
+	for _, t := range thresholds {
+		if left > t {
+			continue
+		}
+		if !containsUint64(state.ServiceAlerted, t) {
+			alert := newAlert(ALERT_SERVICE_TIME)
+			alert.Threshold = t
+			alert.Value = left
+			alerts = append(alerts, alert)
+		}
+		alerted = append(alerted, t)
+	}
+	state.ServiceAlerted = alerted
+	return alerts, this.action(sample, left, state, now)
+}
+
+func (this *Policy) action(sample *Sample, left uint64, state *State, now time.Time) *Action {
+	if !this.AutoExtend || this.ExtendPeriod == 0 || left > this.AutoExtendBefore {
+		return nil
+	}
+	if state.LastActionAt+ACTION_RETRY_INTERVAL > uint64(now.Unix()) {
+		return nil
+	}
+	serviceTime := sample.ServiceTime
+	if serviceTime < uint64(now.Unix()) {
+		serviceTime = uint64(now.Unix())
+	}
+	return &Action{ServiceTime: serviceTime + this.ExtendPeriod, AddPeriod: this.ExtendPeriod}
+}
+
+// ComputeEarnings. profit earned by the samples after from, the profit on chain is reset when withdrawn,
+// so a decrease of profit is regarded as a withdrawal and the new profit is earned after it.
+func ComputeEarnings(samples []*Sample, from uint64) *Earnings {
+	sorted := make([]*Sample, 0, len(samples))
+	for _, sample := range samples {
+		if sample.CreatedAt >= from {
+			sorted = append(sorted, sample)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt < sorted[j].CreatedAt
+	})
+	earnings := &Earnings{SampleSize: len(sorted)}
+	if len(sorted) == 0 {
+		return earnings
+	}
+	earnings.From, earnings.To = sorted[0].CreatedAt, sorted[len(sorted)-1].CreatedAt
+	used := uint64(0)
+	for i, sample := range sorted {
+		if sample.Volume > sample.RestVol {
+			used += sample.Volume - sample.RestVol
+		}
+		if i == 0 {
+			continue
+		}
+		if prev := sorted[i-1]; sample.Profit >= prev.Profit {
+			earnings.Profit += sample.Profit - prev.Profit
+		} else {
+			earnings.Profit += sample.Profit
+		}
+	}
+	earnings.AvgUsed = used / uint64(len(sorted))
+	days := float64(earnings.To-earnings.From) / SECONDS_OF_DAY
+	if earnings.AvgUsed == 0 || days == 0 {
+		return earnings
+	}
+	earnings.PerGBDay = float64(earnings.Profit) / (float64(earnings.AvgUsed) / KB_PER_GB) / days
+	return earnings
+}
+
+func containsUint64(list []uint64, v uint64) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package nodemonitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckAlerts(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.Local)
+	day := uint64(24 * 3600)
+	policy := &Policy{ServiceTimeThresholds: []uint64{day, 7 * day}}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	state := &State{}
+	snapshot := &Snapshot{
+		Sample:        &Sample{Height: 1000, ServiceTime: uint64(now.Unix()) + 3*day},
+		MissedSectors: []uint64{2},
+		Files: []*FileProof{
+			{FileHash: "a", ProveTimes: 1, ProveInterval: 200, ExpiredHeight: 5000},
+			{FileHash: "b", ProveTimes: 1, ProveInterval: 200, ExpiredHeight: 1200},
+		},
+	}
+	alerts, action := policy.Check(snapshot, state, now)
+	if action != nil {
+		t.Fatalf("unexpected action %+v", action)
+	}
+	if len(alerts) != 2 || alerts[0].Kind != ALERT_PROOF_MISSED || alerts[0].SectorId != 2 ||
+		alerts[1].Kind != ALERT_SERVICE_TIME || alerts[1].Threshold != 7*day {
+		t.Fatalf("unexpected alerts %+v", alerts)
+	}
+	if alerts, _ = policy.Check(snapshot, state, now); len(alerts) != 0 {
+		t.Fatalf("alerts raised again %+v", alerts)
+	}
+
+	// file a proved, file b not proved in its interval but expired
+	snapshot.MissedSectors = nil
+	snapshot.Sample.Height = 1400
+	snapshot.Files[0].ProveTimes = 2
+	if alerts, _ = policy.Check(snapshot, state, now); len(alerts) != 0 || len(state.SectorsAlerted) != 0 {
+		t.Fatalf("unexpected alerts %+v, state %+v", alerts, state)
+	}
+	snapshot.Sample.Height = 1800
+	alerts, _ = policy.Check(snapshot, state, now)
+	if len(alerts) != 1 || alerts[0].Kind != ALERT_PDP_FAILED || alerts[0].FileHash != "a" || alerts[0].Value != 400 {
+		t.Fatalf("unexpected alerts %+v", alerts)
+	}
+	snapshot.Files[0].ProveTimes = 3
+	if alerts, _ = policy.Check(snapshot, state, now); len(alerts) != 0 || len(state.FilesAlerted) != 0 {
+		t.Fatalf("unexpected alerts %+v, state %+v", alerts, state)
+	}
+}
+
+func TestAutoExtend(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.Local)
+	day := uint64(24 * 3600)
+	policy := &Policy{AutoExtend: true, AutoExtendBefore: 2 * day}
+	if err := policy.Validate(); err != ErrExtendPeriodRequired {
+		t.Fatalf("expect extend period required, got %v", err)
+	}
+	policy.ExtendPeriod = 30 * day
+	state := &State{}
+	snapshot := &Snapshot{Sample: &Sample{ServiceTime: uint64(now.Unix()) + 3*day}}
+	if _, action := policy.Check(snapshot, state, now); action != nil {
+		t.Fatalf("unexpected action %+v", action)
+	}
+	snapshot.Sample.ServiceTime = uint64(now.Unix()) + day
+	_, action := policy.Check(snapshot, state, now)
+	if action == nil || action.ServiceTime != uint64(now.Unix())+31*day {
+		t.Fatalf("unexpected action %+v", action)
+	}
+	state.LastActionAt = uint64(now.Unix())
+	if _, action = policy.Check(snapshot, state, now.Add(time.Minute)); action != nil {
+		t.Fatalf("action retried too soon %+v", action)
+	}
+}
+
+func TestComputeEarnings(t *testing.T) {
+	day := uint64(24 * 3600)
+	samples := []*Sample{
+		{Profit: 300, Volume: 3 * KB_PER_GB, RestVol: KB_PER_GB, CreatedAt: 2 * day},
+		{Profit: 100, Volume: 3 * KB_PER_GB, RestVol: KB_PER_GB, CreatedAt: day},
+		{Profit: 50, Volume: 3 * KB_PER_GB, RestVol: KB_PER_GB, CreatedAt: 3 * day},
+		{Profit: 999, CreatedAt: 0},
+	}
+	earnings := ComputeEarnings(samples, day)
+	// 200 earned, then withdrawn and 50 earned
+	if earnings.Profit != 250 || earnings.AvgUsed != 2*KB_PER_GB || earnings.SampleSize != 3 {
+		t.Fatalf("unexpected earnings %+v", earnings)
+	}
+	if earnings.PerGBDay != 62.5 {
+		t.Fatalf("unexpected earnings per GB per day %v", earnings.PerGBDay)
+	}
+}
@@ -0,0 +1,83 @@
+// Package noderesize checks a new volume of a registered storage node before it's sent to chain.
+// A shrink must keep the used space and the sectors, the unused part of a grown volume must fit in the free disk
+// of FsRepoRoot and FsMaxStorage, which can't be raised without restarting the node.
+// Growing onto new data directories is rejected, the fs store of node only writes to FsRepoRoot
+// and can't add directories while the node is running.
+// Volumes and sizes are in KB as the node info on chain.
+package noderesize
+
+import (
+	"fmt"
+	"strings"
+)
+
+const KB_PER_GB = 1024 * 1024
+
+// ERR_DATA_DIRS. the error of growing onto new data directories
+const ERR_DATA_DIRS = "growing onto new data directories is not supported, the fs store only writes to FsRepoRoot"
+
+// Node. what the node looks like before resize
+type Node struct {
+	Volume      uint64
+	RestVol     uint64
+	SectorsSize uint64 // total size of sectors of node
+	MinVolume   uint64
+	MaxStorage  uint64   // KB of FsMaxStorage, 0 if not set
+	DiskFree    uint64   // KB of free disk of FsRepoRoot
+	DataDirs    []string // new data directories asked to grow onto
+}
+
+// Plan. what the resize does
+type Plan struct {
+	OldVolume   uint64
+	Volume      uint64
+	Used        uint64
+	SectorsSize uint64
+	DiskFree    uint64
+	DataDirs    []string `json:",omitempty"`
+	Warnings    []string
+	Errors      []string // problems blocking the resize
+}
+
+// MakePlan. check the new volume of node
+func MakePlan(node *Node, volume uint64) *Plan {
+	plan := &Plan{
+		OldVolume:   node.Volume,
+		Volume:      volume,
+		SectorsSize: node.SectorsSize,
+		DiskFree:    node.DiskFree,
+		DataDirs:    node.DataDirs,
+		Warnings:    make([]string, 0),
+		Errors:      make([]string, 0),
+	}
+	if node.Volume > node.RestVol {
+		plan.Used = node.Volume - node.RestVol
+	}
+	switch {
+	case volume == node.Volume:
+		plan.Errors = append(plan.Errors, "volume is not changed")
+	case volume < node.MinVolume:
+		plan.Errors = append(plan.Errors, fmt.Sprintf("volume %d is less than min volume %d", volume,
+			node.MinVolume))
+	}
+	if volume < plan.Used {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("volume %d is less than used space %d", volume, plan.Used))
+	}
+	if volume < node.SectorsSize {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("volume %d is less than total size %d of sectors", volume,
+			node.SectorsSize))
+	}
+	// the used space has been written to disk, the rest of volume may be written
+	if volume > node.Volume && volume > plan.Used && volume-plan.Used > plan.DiskFree {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("unused volume %d is more than free disk %d",
+			volume-plan.Used, plan.DiskFree))
+	}
+	if len(node.DataDirs) > 0 {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("%s: %s", ERR_DATA_DIRS, strings.Join(node.DataDirs, ", ")))
+	}
+	if volume > node.Volume && node.MaxStorage > 0 && volume > node.MaxStorage {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("volume %d is more than FsMaxStorage %d, "+
+			"raise FsMaxStorage and restart the node first", volume, node.MaxStorage))
+	}
+	return plan
+}
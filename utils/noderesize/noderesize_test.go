@@ -0,0 +1,45 @@
+package noderesize
+
+import "testing"
+
+func TestMakePlan(t *testing.T) {
+	node := &Node{
+		Volume:      100 * KB_PER_GB,
+		RestVol:     40 * KB_PER_GB,
+		SectorsSize: 80 * KB_PER_GB,
+		MinVolume:   KB_PER_GB,
+		MaxStorage:  200 * KB_PER_GB,
+		DiskFree:    50 * KB_PER_GB,
+	}
+
+	plan := MakePlan(node, 90*KB_PER_GB)
+	if len(plan.Errors) != 0 || plan.Used != 60*KB_PER_GB {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	// shrink below sectors and used space
+	if plan = MakePlan(node, 50*KB_PER_GB); len(plan.Errors) != 2 {
+		t.Fatalf("unexpected errors %v", plan.Errors)
+	}
+	// the unused volume 110-60 fits in the free disk
+	plan = MakePlan(node, 110*KB_PER_GB)
+	if len(plan.Errors) != 0 || plan.DiskFree != 50*KB_PER_GB {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	// the growth 50 fits, but the unused volume 150-60 doesn't
+	if plan = MakePlan(node, 150*KB_PER_GB); len(plan.Errors) != 1 {
+		t.Fatalf("unexpected errors %v", plan.Errors)
+	}
+	if plan = MakePlan(node, 100*KB_PER_GB); len(plan.Errors) != 1 {
+		t.Fatalf("unexpected errors %v", plan.Errors)
+	}
+	// growth past max storage needs a restart
+	node.MaxStorage, node.DiskFree = 120*KB_PER_GB, 100*KB_PER_GB
+	if plan = MakePlan(node, 150*KB_PER_GB); len(plan.Errors) != 1 {
+		t.Fatalf("unexpected errors %v", plan.Errors)
+	}
+	// new data directories are rejected
+	node.DataDirs = []string{"/data2"}
+	if plan = MakePlan(node, 110*KB_PER_GB); len(plan.Errors) != 1 || len(plan.DataDirs) != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+}
@@ -0,0 +1,227 @@
+// Package nodesetup plans the onboarding of a storage node, and runs its steps in order.
+// A plan suggests the volume and sectors from the free disk, roughly estimates the pledge from the fs setting,
+// and lists the problems blocking the setup. Steps are rolled back in reverse order when one fails.
+// Volumes and sizes are in KB as the node info on chain.
+package nodesetup
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	KB_PER_GB             = 1024 * 1024
+	MIN_SECTOR_SIZE       = KB_PER_GB        // 1GB
+	DEFAULT_SECTOR_SIZE   = 1024 * KB_PER_GB // 1TB
+	DISK_RESERVED_PERCENT = 10               // percent of free disk not suggested as volume
+)
+
+const (
+	STEP_REGISTER_NODE = "registernode"
+	STEP_CREATE_SECTOR = "createsector"
+	STEP_OPEN_CHANNEL  = "openchannel"
+)
+
+var ErrNoVolume = errors.New("no disk space for volume")
+
+// Machine. what the node looks like before setup
+type Machine struct {
+	DiskFree     uint64 // KB
+	MaxStorage   uint64 // KB of FsMaxStorage, 0 if not set
+	PublicAddr   string // listen address of node
+	ExternalAddr string // host address of node known by dns, empty if unreachable
+	Balance      uint64
+	Registered   bool
+	HasChannel   bool
+	SectorIds    []uint64 // sectors of node
+}
+
+// Setting. fs contract setting used for setup
+type Setting struct {
+	MinVolume        uint64
+	FsGasPrice       uint64
+	GasPerGBPerBlock uint64
+}
+
+// Options. options of setup, zero values are suggested
+type Options struct {
+	NodeAddr      string
+	Volume        uint64
+	ServiceTime   uint64 // timestamp when the node stops service
+	SectorSize    uint64
+	ProveLevel    uint64
+	ChannelAmount uint64 // deposit of channel opened to dns, 0 for no channel
+}
+
+// SectorPlan. a sector to create
+type SectorPlan struct {
+	SectorId   uint64
+	Size       uint64
+	ProveLevel uint64
+}
+
+// Plan. what the setup does
+type Plan struct {
+	NodeAddr        string
+	Volume          uint64
+	ServiceTime     uint64
+	EstimatedPledge uint64 // rough estimate, the pledge is computed by the fs contract when registering
+	Sectors         []*SectorPlan
+	ChannelAmount   uint64
+	Machine         *Machine
+	Warnings        []string
+	Errors          []string // problems blocking the setup
+}
+
+// Step. a step of setup, Rollback is nil if the step can't be undone
+type Step struct {
+	Name     string
+	Run      func() (string, error)
+	Rollback func() (string, error)
+}
+
+// StepResult. result of a step run, and its rollback if any
+type StepResult struct {
+	Name          string
+	Tx            string `json:",omitempty"`
+	Error         string `json:",omitempty"`
+	RolledBack    bool
+	RollbackTx    string `json:",omitempty"`
+	RollbackError string `json:",omitempty"`
+}
+
+// SuggestVolume. the free disk without the reserved part, limited by FsMaxStorage
+func SuggestVolume(diskFree, maxStorage uint64) uint64 {
+	volume := diskFree / 100 * (100 - DISK_RESERVED_PERCENT)
+	if maxStorage > 0 && volume > maxStorage {
+		volume = maxStorage
+	}
+	return volume / KB_PER_GB * KB_PER_GB
+}
+
+// EstimatePledge. rough estimate of the pledge of volume as FsGasPrice * GasPerGBPerBlock per GB,
+// it's the gas of storing the volume for one block, not the pledge formula of fs contract
+func EstimatePledge(volume uint64, setting *Setting) uint64 {
+	return setting.FsGasPrice * setting.GasPerGBPerBlock * volume / KB_PER_GB
+}
+
+// PlanSectors. split the volume to sectors of sectorSize with ids after the existing ones,
+// the last sector takes the rest if it's no less than MIN_SECTOR_SIZE.
+func PlanSectors(volume, sectorSize, proveLevel uint64, existIds []uint64) []*SectorPlan {
+	if sectorSize < MIN_SECTOR_SIZE {
+		sectorSize = MIN_SECTOR_SIZE
+	}
+	nextId := uint64(1)
+	for _, id := range existIds {
+		if id >= nextId {
+			nextId = id + 1
+		}
+	}
+	sectors := make([]*SectorPlan, 0)
+	for volume >= MIN_SECTOR_SIZE {
+		size := sectorSize
+		if volume < size {
+			size = volume
+		}
+		sectors = append(sectors, &SectorPlan{SectorId: nextId, Size: size, ProveLevel: proveLevel})
+		volume -= size
+		nextId++
+	}
+	return sectors
+}
+
+// MakePlan. plan the setup with the machine, fs setting and options
+func MakePlan(machine *Machine, setting *Setting, opts *Options) *Plan {
+	plan := &Plan{
+		NodeAddr:      opts.NodeAddr,
+		Volume:        opts.Volume,
+		ServiceTime:   opts.ServiceTime,
+		ChannelAmount: opts.ChannelAmount,
+		Machine:       machine,
+		Sectors:       make([]*SectorPlan, 0),
+		Warnings:      make([]string, 0),
+		Errors:        make([]string, 0),
+	}
+	if len(plan.NodeAddr) == 0 {
+		plan.NodeAddr = machine.PublicAddr
+	}
+	if len(plan.NodeAddr) == 0 {
+		plan.Errors = append(plan.Errors, "node address is unknown")
+	}
+	if machine.Registered {
+		plan.Errors = append(plan.Errors, "node has been registered")
+	}
+	if len(machine.ExternalAddr) == 0 {
+		plan.Warnings = append(plan.Warnings, "node is not reachable by dns")
+	} else if len(plan.NodeAddr) > 0 && machine.ExternalAddr != plan.NodeAddr {
+		plan.Warnings = append(plan.Warnings,
+			fmt.Sprintf("node address %s is different from %s known by dns", plan.NodeAddr, machine.ExternalAddr))
+	}
+	if plan.Volume == 0 {
+		plan.Volume = SuggestVolume(machine.DiskFree, machine.MaxStorage)
+	}
+	if plan.Volume == 0 {
+		plan.Errors = append(plan.Errors, ErrNoVolume.Error())
+	} else if plan.Volume < setting.MinVolume {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("volume %d is less than min volume %d", plan.Volume,
+			setting.MinVolume))
+	}
+	if plan.Volume > machine.DiskFree {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("volume %d is more than free disk %d", plan.Volume,
+			machine.DiskFree))
+	}
+	// FsMaxStorage takes effect after restart, so it isn't changed by setup
+	if machine.MaxStorage > 0 && plan.Volume > machine.MaxStorage {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("volume %d is more than FsMaxStorage %d, "+
+			"raise FsMaxStorage and restart the node first", plan.Volume, machine.MaxStorage))
+	}
+	sectorSize := opts.SectorSize
+	if sectorSize == 0 {
+		sectorSize = DEFAULT_SECTOR_SIZE
+	}
+	plan.Sectors = PlanSectors(plan.Volume, sectorSize, opts.ProveLevel, machine.SectorIds)
+	if machine.HasChannel {
+		plan.ChannelAmount = 0
+	}
+	plan.EstimatedPledge = EstimatePledge(plan.Volume, setting)
+	if plan.ChannelAmount > machine.Balance {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("balance %d is not enough for channel %d",
+			machine.Balance, plan.ChannelAmount))
+	} else if cost := plan.EstimatedPledge + plan.ChannelAmount; cost > machine.Balance {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("balance %d may not be enough for estimated pledge "+
+			"and channel %d", machine.Balance, cost))
+	}
+	return plan
+}
+
+// Run. run the steps in order, the finished steps are rolled back in reverse order if a step fails
+func Run(steps []*Step) ([]*StepResult, bool) {
+	results := make([]*StepResult, 0, len(steps))
+	for i, step := range steps {
+		result := &StepResult{Name: step.Name}
+		results = append(results, result)
+		tx, err := step.Run()
+		result.Tx = tx
+		if err == nil {
+			continue
+		}
+		result.Error = err.Error()
+		rollback(steps[:i], results[:i])
+		return results, false
+	}
+	return results, true
+}
+
+func rollback(steps []*Step, results []*StepResult) {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Rollback == nil {
+			continue
+		}
+		tx, err := steps[i].Rollback()
+		results[i].RolledBack = err == nil
+		results[i].RollbackTx = tx
+		if err != nil {
+			results[i].RollbackError = err.Error()
+		}
+	}
+}
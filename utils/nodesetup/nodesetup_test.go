@@ -0,0 +1,86 @@
+package nodesetup
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMakePlan(t *testing.T) {
+	machine := &Machine{
+		DiskFree:     2500 * KB_PER_GB,
+		MaxStorage:   4096 * KB_PER_GB,
+		PublicAddr:   "tcp://1.2.3.4:10338",
+		ExternalAddr: "tcp://1.2.3.4:10338",
+		Balance:      10000,
+		SectorIds:    []uint64{1, 3},
+	}
+	setting := &Setting{MinVolume: KB_PER_GB, FsGasPrice: 1, GasPerGBPerBlock: 2}
+	plan := MakePlan(machine, setting, &Options{Volume: 2049 * KB_PER_GB, ChannelAmount: 100})
+	if len(plan.Errors) != 0 || len(plan.Warnings) != 0 {
+		t.Fatalf("unexpected problems %v %v", plan.Errors, plan.Warnings)
+	}
+	if plan.NodeAddr != machine.PublicAddr || plan.EstimatedPledge != 4098 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if len(plan.Sectors) != 3 || plan.Sectors[0].SectorId != 4 || plan.Sectors[2].Size != KB_PER_GB {
+		t.Fatalf("unexpected sectors %+v", plan.Sectors)
+	}
+
+	// suggested volume is limited by max storage, the estimated pledge only warns
+	machine.Balance, machine.ExternalAddr, machine.MaxStorage = 100, "", 1024*KB_PER_GB
+	plan = MakePlan(machine, setting, &Options{ChannelAmount: 100})
+	if plan.Volume != 1024*KB_PER_GB || len(plan.Sectors) != 1 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+	if len(plan.Errors) != 0 || len(plan.Warnings) != 2 {
+		t.Fatalf("unexpected problems %v %v", plan.Errors, plan.Warnings)
+	}
+
+	// volume more than max storage and channel more than balance are blocked
+	plan = MakePlan(machine, setting, &Options{Volume: 2049 * KB_PER_GB, ChannelAmount: 200})
+	if len(plan.Errors) != 2 {
+		t.Fatalf("unexpected problems %v", plan.Errors)
+	}
+}
+
+func TestSuggestVolume(t *testing.T) {
+	if v := SuggestVolume(100*KB_PER_GB+5, 0); v != 90*KB_PER_GB {
+		t.Fatalf("unexpected volume %d", v)
+	}
+	if v := SuggestVolume(KB_PER_GB, 0); v != 0 {
+		t.Fatalf("unexpected volume %d", v)
+	}
+}
+
+func TestRun(t *testing.T) {
+	undone := make([]string, 0)
+	step := func(name string, fail, canRollback bool) *Step {
+		s := &Step{Name: name, Run: func() (string, error) {
+			if fail {
+				return "", errors.New("failed")
+			}
+			return name + "tx", nil
+		}}
+		if canRollback {
+			s.Rollback = func() (string, error) {
+				undone = append(undone, name)
+				return "", nil
+			}
+		}
+		return s
+	}
+	results, ok := Run([]*Step{step("a", false, true), step("b", false, false), step("c", false, true),
+		step("d", true, true), step("e", false, true)})
+	if ok || len(results) != 4 || results[3].Error != "failed" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if len(undone) != 2 || undone[0] != "c" || undone[1] != "a" {
+		t.Fatalf("unexpected rollback %v", undone)
+	}
+	if !results[0].RolledBack || results[1].RolledBack || results[0].Tx != "atx" {
+		t.Fatalf("unexpected results %+v %+v", results[0], results[1])
+	}
+	if _, ok := Run([]*Step{step("a", false, true)}); !ok {
+		t.Fatal("steps should succeed")
+	}
+}
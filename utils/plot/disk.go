@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package plot
+
+import (
+	"syscall"
+)
+
+// FreeSpace. free bytes of the disk where the path is, available to current user
+func FreeSpace(path string) (uint64, error) {
+	stat := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,155 @@
+package plot
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+const (
+	HASH_SIZE   = 32
+	HASH_CAP    = 4096
+	SCOOP_SIZE  = 64
+	SCOOP_COUNT = 4096
+	NONCE_SIZE  = SCOOP_SIZE * SCOOP_COUNT // 256KB
+
+	NATIVE_BATCH_NONCES = 64 // nonces generated in memory before written to plot file
+	NATIVE_TEMP_SUFFIX  = ".tmp"
+)
+
+// GenerateNonce. generate the PoC2 data of a nonce for the numeric account id, data should be NONCE_SIZE.
+func GenerateNonce(numericID, nonce uint64, data []byte) {
+	gen := make([]byte, NONCE_SIZE+16)
+	binary.BigEndian.PutUint64(gen[NONCE_SIZE:], numericID)
+	binary.BigEndian.PutUint64(gen[NONCE_SIZE+8:], nonce)
+	for i := NONCE_SIZE; i > 0; i -= HASH_SIZE {
+		end := i + HASH_CAP
+		if end > len(gen) {
+			end = len(gen)
+		}
+		shabal256Sum(gen[i:end], gen[i-HASH_SIZE:i])
+	}
+	final := make([]byte, HASH_SIZE)
+	shabal256Sum(gen, final)
+	for i := 0; i < NONCE_SIZE; i++ {
+		data[i] = gen[i] ^ final[i%HASH_SIZE]
+	}
+	// PoC2, swap the second hash of scoop with the mirror scoop
+	buf := make([]byte, HASH_SIZE)
+	for low, high := HASH_SIZE, NONCE_SIZE-HASH_SIZE; low < NONCE_SIZE/2; low, high = low+SCOOP_SIZE, high-SCOOP_SIZE {
+		copy(buf, data[low:low+HASH_SIZE])
+		copy(data[low:low+HASH_SIZE], data[high:high+HASH_SIZE])
+		copy(data[high:high+HASH_SIZE], buf)
+	}
+}
+
+// plotNative. generate the plot file in go, the file has the same layout with plot tool,
+// scoops of all nonces are stored together by the scoop index.
+// The file is written to a temp file and renamed when completed.
+func plotNative(ctx context.Context, cfg *PlotConfig, onProgress func(Progress)) error {
+	numericID, err := strconv.ParseUint(cfg.NumericID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid numeric id %s", cfg.NumericID)
+	}
+	nonces := cfg.Nonces - cfg.Nonces%8
+	if nonces == 0 {
+		return fmt.Errorf("invalid nonces %d", cfg.Nonces)
+	}
+	fullPath := GetPlotFileFullPath(cfg)
+	tempPath := fullPath + NATIVE_TEMP_SUFFIX
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	err = writeNativePlot(ctx, file, numericID, cfg.StartNonce, nonces, threadsOf(cfg), onProgress)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if err := os.Rename(tempPath, fullPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if onProgress != nil {
+		onProgress(Progress{Hashing: 100, Writing: 100, Done: true})
+	}
+	return nil
+}
+
+func writeNativePlot(ctx context.Context, file *os.File, numericID, startNonce, nonces uint64, threads int,
+	onProgress func(Progress)) error {
+	if err := file.Truncate(int64(nonces * NONCE_SIZE)); err != nil {
+		return err
+	}
+	batch := make([]byte, NATIVE_BATCH_NONCES*NONCE_SIZE)
+	scoops := make([]byte, NATIVE_BATCH_NONCES*SCOOP_SIZE)
+	for offset := uint64(0); offset < nonces; offset += NATIVE_BATCH_NONCES {
+		count := nonces - offset
+		if count > NATIVE_BATCH_NONCES {
+			count = NATIVE_BATCH_NONCES
+		}
+		if err := generateNonces(ctx, numericID, startNonce+offset, count, threads, batch); err != nil {
+			return err
+		}
+		if onProgress != nil {
+			onProgress(Progress{
+				Hashing: float64(offset+count) * 100 / float64(nonces),
+				Writing: float64(offset) * 100 / float64(nonces),
+			})
+		}
+		for scoop := uint64(0); scoop < SCOOP_COUNT; scoop++ {
+			for n := uint64(0); n < count; n++ {
+				copy(scoops[n*SCOOP_SIZE:(n+1)*SCOOP_SIZE], batch[n*NONCE_SIZE+scoop*SCOOP_SIZE:])
+			}
+			pos := int64((scoop*nonces + offset) * SCOOP_SIZE)
+			if _, err := file.WriteAt(scoops[:count*SCOOP_SIZE], pos); err != nil {
+				return err
+			}
+		}
+		if onProgress != nil {
+			onProgress(Progress{
+				Hashing: float64(offset+count) * 100 / float64(nonces),
+				Writing: float64(offset+count) * 100 / float64(nonces),
+			})
+		}
+	}
+	return file.Sync()
+}
+
+// generateNonces. generate count nonces to data with threads in parallel
+func generateNonces(ctx context.Context, numericID, startNonce, count uint64, threads int, data []byte) error {
+	next := make(chan uint64, count)
+	for i := uint64(0); i < count; i++ {
+		next <- i
+	}
+	close(next)
+	wg := new(sync.WaitGroup)
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					return
+				}
+				GenerateNonce(numericID, startNonce+i, data[i*NONCE_SIZE:(i+1)*NONCE_SIZE])
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func threadsOf(cfg *PlotConfig) int {
+	if cfg.Threads > 0 {
+		return int(cfg.Threads)
+	}
+	return runtime.NumCPU()
+}
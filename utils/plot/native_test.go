@@ -0,0 +1,80 @@
+package plot
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShabal256(t *testing.T) {
+	cases := map[string]string{
+		"": "aec750d11feee9f16271922fbaf5a9be142f62019ef8d720f858940070889014",
+		"abcdefghijklmnopqrstuvwxyz-0123456789-ABCDEFGHIJKLMNOPQRSTUVWXYZ-0123456789-abcdefghijklmnopqrstuvwxyz": "b49f34bf51864c30533cc46cc2542bdec2f96fd06f5c539aff6ead5883f7327a",
+	}
+	out := make([]byte, HASH_SIZE)
+	for msg, expect := range cases {
+		shabal256Sum([]byte(msg), out)
+		if hex.EncodeToString(out) != expect {
+			t.Fatalf("shabal256(%q) = %x, expect %s", msg, out, expect)
+		}
+	}
+}
+
+func TestGenerateNonce(t *testing.T) {
+	data, other := make([]byte, NONCE_SIZE), make([]byte, NONCE_SIZE)
+	GenerateNonce(10282355196851764065, 0, data)
+	GenerateNonce(10282355196851764065, 0, other)
+	if !bytes.Equal(data, other) {
+		t.Fatal("nonce generation is not deterministic")
+	}
+	GenerateNonce(10282355196851764065, 1, other)
+	if bytes.Equal(data[:SCOOP_SIZE], other[:SCOOP_SIZE]) {
+		t.Fatal("different nonces have same scoop")
+	}
+}
+
+func TestPlotNative(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := &PlotConfig{NumericID: "3988", StartNonce: 16, Nonces: 12, Path: dir, Threads: 2, Native: true}
+	var last Progress
+	if err := plotNative(context.Background(), cfg, func(p Progress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+	if !last.Done {
+		t.Fatalf("unexpected progress %+v", last)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "3988_16_8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uint64(len(data)) != GetPlotFileSize(cfg) {
+		t.Fatalf("plot file size %d, expect %d", len(data), GetPlotFileSize(cfg))
+	}
+	nonce := make([]byte, NONCE_SIZE)
+	for _, n := range []uint64{0, 7} {
+		GenerateNonce(3988, cfg.StartNonce+n, nonce)
+		for _, scoop := range []uint64{0, 1, 2047, 4095} {
+			pos := (scoop*8 + n) * SCOOP_SIZE
+			if !bytes.Equal(data[pos:pos+SCOOP_SIZE], nonce[scoop*SCOOP_SIZE:(scoop+1)*SCOOP_SIZE]) {
+				t.Fatalf("scoop %d of nonce %d mismatch", scoop, n)
+			}
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg.StartNonce = 32
+	if err := plotNative(ctx, cfg, nil); err != context.Canceled {
+		t.Fatalf("expect canceled, got %v", err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("partial plot file is not removed, files %d", len(files))
+	}
+}
@@ -0,0 +1,152 @@
+package plot
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+var ErrNoCapacity = errors.New("not enough capacity in plot directories")
+
+// PlotDir. a directory to store plot files, sizes are in bytes
+type PlotDir struct {
+	Path     string
+	Capacity uint64 // max size of plot files in the directory, 0 for no limit
+	Used     uint64 // size of plot files and unfinished plot jobs in the directory
+	Free     uint64 // free space of the disk, not including the space reserved by unfinished plot jobs
+}
+
+// Available. size of plot files can be added to the directory with minFree bytes kept free on disk
+func (this PlotDir) Available(minFree uint64) uint64 {
+	avail := uint64(0)
+	if this.Free > minFree {
+		avail = this.Free - minFree
+	}
+	if this.Capacity == 0 {
+		return avail
+	}
+	if this.Used >= this.Capacity {
+		return 0
+	}
+	if left := this.Capacity - this.Used; left < avail {
+		return left
+	}
+	return avail
+}
+
+// PlannedFile. a plot file planned in the directory
+type PlannedFile struct {
+	Path       string
+	Name       string
+	StartNonce uint64
+	Nonces     uint64
+	Size       uint64
+	TaskId     string `json:",omitempty"`
+}
+
+// PlotPlan. plot files planned in directories with continuous nonces
+type PlotPlan struct {
+	StartNonce uint64
+	EndNonce   uint64
+	Size       uint64 // total size of planned files
+	Dirs       []PlotDir
+	Files      []*PlannedFile
+}
+
+// MakePlotPlan. plan plot files of size target from startNonce in dirs by order, each file has at most fileSize bytes,
+// minFree bytes are kept free on each disk. Use all capacity of dirs if target is 0.
+func MakePlotPlan(numericID string, dirs []PlotDir, startNonce, target, fileSize, minFree uint64) (*PlotPlan, error) {
+	fileNonces := fileSize / NONCE_SIZE
+	fileNonces -= fileNonces % 8
+	if fileNonces == 0 {
+		return nil, errors.New("plot file size should be at least 8 nonces")
+	}
+	plan := &PlotPlan{
+		StartNonce: startNonce,
+		EndNonce:   startNonce,
+		Dirs:       dirs,
+		Files:      make([]*PlannedFile, 0),
+	}
+	remain := target / NONCE_SIZE
+	remain -= remain % 8
+	for _, dir := range dirs {
+		if target > 0 && remain == 0 {
+			break
+		}
+		avail := dir.Available(minFree) / NONCE_SIZE
+		avail -= avail % 8
+		for avail > 0 && (target == 0 || remain > 0) {
+			nonces := fileNonces
+			if nonces > avail {
+				nonces = avail
+			}
+			if target > 0 && nonces > remain {
+				nonces = remain
+			}
+			cfg := &PlotConfig{NumericID: numericID, StartNonce: plan.EndNonce, Nonces: nonces, Path: dir.Path}
+			plan.Files = append(plan.Files, &PlannedFile{
+				Path:       dir.Path,
+				Name:       GetPlotFileName(cfg),
+				StartNonce: plan.EndNonce,
+				Nonces:     nonces,
+				Size:       GetPlotFileSize(cfg),
+			})
+			plan.EndNonce += nonces
+			plan.Size += GetPlotFileSize(cfg)
+			avail -= nonces
+			if target > 0 {
+				remain -= nonces
+			}
+		}
+	}
+	if len(plan.Files) == 0 || (target > 0 && remain > 0) {
+		return plan, ErrNoCapacity
+	}
+	return plan, nil
+}
+
+// GetMinStartNonceOfPaths. get the min start nonce after all plot files of numericId in paths,
+// the paths not exist are ignored.
+func GetMinStartNonceOfPaths(numericId string, paths []string) (uint64, error) {
+	startNonce := uint64(0)
+	for _, path := range paths {
+		start, err := GetMinStartNonce(numericId, path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if start > startNonce {
+			startNonce = start
+		}
+	}
+	return startNonce, nil
+}
+
+// GetPlotFilesSize. total size of plot files of numericId in path
+func GetPlotFilesSize(numericId, path string) (uint64, error) {
+	infos, err := ioutil.ReadDir(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	size := uint64(0)
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasPrefix(info.Name(), numericId) {
+			continue
+		}
+		if strings.HasSuffix(info.Name(), NATIVE_TEMP_SUFFIX) {
+			continue
+		}
+		file, err := ParsePlotFileName(info.Name())
+		if err != nil || file.NumericID != numericId {
+			continue
+		}
+		size += uint64(info.Size())
+	}
+	return size, nil
+}
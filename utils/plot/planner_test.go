@@ -0,0 +1,67 @@
+package plot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMakePlotPlan(t *testing.T) {
+	dirs := []PlotDir{
+		{Path: "/disk1", Capacity: 20 * NONCE_SIZE, Used: 8 * NONCE_SIZE, Free: 100 * NONCE_SIZE},
+		{Path: "/disk2", Free: 34 * NONCE_SIZE},
+		{Path: "/disk3", Free: 100 * NONCE_SIZE},
+	}
+	plan, err := MakePlotPlan("3988", dirs, 16, 40*NONCE_SIZE, 16*NONCE_SIZE, 2*NONCE_SIZE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []PlannedFile{
+		{Path: "/disk1", Name: "3988_16_8", StartNonce: 16, Nonces: 8},
+		{Path: "/disk2", Name: "3988_24_16", StartNonce: 24, Nonces: 16},
+		{Path: "/disk2", Name: "3988_40_16", StartNonce: 40, Nonces: 16},
+	}
+	if len(plan.Files) != len(expect) {
+		t.Fatalf("unexpected plan files %d", len(plan.Files))
+	}
+	for i, file := range plan.Files {
+		if file.Path != expect[i].Path || file.Name != expect[i].Name || file.Nonces != expect[i].Nonces {
+			t.Fatalf("unexpected plan file %d %+v", i, file)
+		}
+	}
+	if plan.EndNonce != 56 || plan.Size != 40*NONCE_SIZE {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	if _, err := MakePlotPlan("3988", dirs[:2], 0, 80*NONCE_SIZE, 16*NONCE_SIZE, 0); err != ErrNoCapacity {
+		t.Fatalf("expect no capacity, got %v", err)
+	}
+	plan, err = MakePlotPlan("3988", dirs[:2], 0, 0, 16*NONCE_SIZE, 0)
+	if err != nil || plan.Size != 40*NONCE_SIZE {
+		t.Fatalf("unexpected plan of all capacity %+v, err %v", plan, err)
+	}
+}
+
+func TestGetMinStartNonceOfPaths(t *testing.T) {
+	dir1, err := ioutil.TempDir("", "plot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir1)
+	dir2, err := ioutil.TempDir("", "plot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir2)
+	ioutil.WriteFile(filepath.Join(dir1, "3988_0_8"), nil, 0666)
+	ioutil.WriteFile(filepath.Join(dir2, "3988_8_16"), nil, 0666)
+	ioutil.WriteFile(filepath.Join(dir2, "39881_0_64"), nil, 0666)
+	start, err := GetMinStartNonceOfPaths("3988", []string{dir1, dir2, filepath.Join(dir1, "none")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 24 {
+		t.Fatalf("start nonce %d, expect 24", start)
+	}
+}
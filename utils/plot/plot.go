@@ -1,12 +1,14 @@
 package plot
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -26,6 +28,7 @@ const (
 	FLAG_START_NONCE = "-s"
 	FLAG_NONCES      = "-n"
 	FLAG_PATH        = "-p"
+	FLAG_THREADS     = "-c"
 
 	DEFAULT_PLOT_TOOL_NAME = "engraver_cpu"
 
@@ -38,15 +41,36 @@ type PlotConfig struct {
 	StartNonce uint64 // start nonce
 	Nonces     uint64 // num of nonce
 	Path       string // path to store plot file
+	Threads    uint64 `json:",omitempty"` // max cpu threads, 0 for all
+	Native     bool   `json:",omitempty"` // generate plot file in go instead of plot tool
 }
 
 // do the file plotting with cfg, if cfg not exist, use saved cfg
 func Plot(cfg *PlotConfig) error {
+	return PlotWithContext(context.Background(), cfg, nil)
+}
+
+// PlotWithContext. do the file plotting with cfg, report the progress parsed from the output of plot tool.
+// The plot tool is killed and the partial plot file is removed when ctx is done.
+// The plot file is generated in go if it's configured, or no plot tool is bundled for the platform.
+func PlotWithContext(ctx context.Context, cfg *PlotConfig, onProgress func(Progress)) error {
 
 	if err := checkPlotConfig(cfg); err != nil {
 		return err
 	}
 
+	if useNativePlot(cfg) {
+		if err := dspOS.CreateDirIfNeed(cfg.Path); err != nil {
+			return fmt.Errorf("create dir in path %s errror %s", cfg.Path, err)
+		}
+		if err := plotNative(ctx, cfg, onProgress); err != nil {
+			log.Errorf("plotNative error %s", err)
+			return fmt.Errorf("plotNative error %s", err)
+		}
+		log.Debugf("native plot ok with config: %+v", cfg)
+		return nil
+	}
+
 	toolName := getPlotToolName(cfg)
 	toolPath := "./" + toolName
 
@@ -60,7 +84,7 @@ func Plot(cfg *PlotConfig) error {
 		return fmt.Errorf("create dir in path %s errror %s", cfg.Path, err)
 	}
 
-	if err := runPlotCmd(cfg, toolPath); err != nil {
+	if err := runPlotCmd(ctx, cfg, toolPath, onProgress); err != nil {
 		log.Errorf("runPlotCmd error %s", err)
 		return fmt.Errorf("runPlotCmd error %s", err)
 	}
@@ -82,11 +106,11 @@ func checkPlotConfig(cfg *PlotConfig) error {
 		return fmt.Errorf("cfg is nil")
 	}
 
-	if cfg.Sys != SYS_LINUX && cfg.Sys != SYS_WIN {
+	if !useNativePlot(cfg) && cfg.Sys != SYS_LINUX && cfg.Sys != SYS_WIN {
 		return fmt.Errorf("wrong sys %s", cfg.Sys)
 	}
 
-	_, err := strconv.Atoi(cfg.NumericID)
+	_, err := strconv.ParseUint(cfg.NumericID, 10, 64)
 	if err != nil {
 		return fmt.Errorf("invalid numeric id")
 	}
@@ -94,23 +118,42 @@ func checkPlotConfig(cfg *PlotConfig) error {
 	return nil
 }
 
-// the config should have been checked already
-func runPlotCmd(cfg *PlotConfig, cmdPath string) error {
-	var cmd *exec.Cmd
+// useNativePlot. the bundled plot tools are built for linux and windows on amd64 only
+func useNativePlot(cfg *PlotConfig) bool {
+	if cfg.Native || runtime.GOARCH != "amd64" {
+		return true
+	}
+	return runtime.GOOS != "linux" && runtime.GOOS != "windows"
+}
 
-	if cfg.Path == "" {
-		cmd = exec.Command(cmdPath, FLAG_NUMERIC_ID, cfg.NumericID, FLAG_START_NONCE, strconv.Itoa(int(cfg.StartNonce)),
-			FLAG_NONCES, strconv.Itoa(int(cfg.Nonces)))
-	} else {
-		cmd = exec.Command(cmdPath, FLAG_NUMERIC_ID, cfg.NumericID, FLAG_START_NONCE, strconv.Itoa(int(cfg.StartNonce)),
-			FLAG_NONCES, strconv.Itoa(int(cfg.Nonces)), FLAG_PATH, cfg.Path)
+// the config should have been checked already
+func runPlotCmd(ctx context.Context, cfg *PlotConfig, cmdPath string, onProgress func(Progress)) error {
+	args := []string{FLAG_NUMERIC_ID, cfg.NumericID, FLAG_START_NONCE, strconv.Itoa(int(cfg.StartNonce)),
+		FLAG_NONCES, strconv.Itoa(int(cfg.Nonces))}
+	if cfg.Path != "" {
+		args = append(args, FLAG_PATH, cfg.Path)
+	}
+	if cfg.Threads > 0 {
+		args = append(args, FLAG_THREADS, strconv.FormatUint(cfg.Threads, 10))
 	}
-	// dont check error here, it returns error even run successfully
-	cmd.Run()
+	cmd := exec.CommandContext(ctx, cmdPath, args...)
+	writer := NewProgressWriter(onProgress)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	runErr := cmd.Run()
 
 	fullPath := GetPlotFileFullPath(cfg)
+	if ctx.Err() != nil {
+		os.Remove(fullPath)
+		return ctx.Err()
+	}
 	if !fileExists(fullPath) {
-		return fmt.Errorf("plot file %s not found", fullPath)
+		return fmt.Errorf("plot file %s not found, exit with %v", fullPath, runErr)
+	}
+	// the tool may exit with error even run successfully, trust the summary it printed
+	if runErr != nil && !writer.Progress().Done {
+		os.Remove(fullPath)
+		return fmt.Errorf("plot file %s is incomplete, exit with %s", fullPath, runErr)
 	}
 	return nil
 }
@@ -139,6 +182,11 @@ func GetPlotFileName(cfg *PlotConfig) string {
 	return strings.Join([]string{cfg.NumericID, startStr, noncesStr}, "_")
 }
 
+// GetPlotFileSize. size of plot file in bytes
+func GetPlotFileSize(cfg *PlotConfig) uint64 {
+	return (cfg.Nonces - cfg.Nonces%8) * DEFAULT_PLOT_SIZEKB * 1024
+}
+
 func GetPlotFileFullPath(cfg *PlotConfig) string {
 	fileName := GetPlotFileName(cfg)
 	return filepath.Join(cfg.Path, fileName)
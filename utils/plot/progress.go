@@ -0,0 +1,101 @@
+package plot
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	STAGE_HASHING = "Hashing:"
+	STAGE_WRITING = "Writing:"
+	STAGE_DONE    = "Generated"
+)
+
+var (
+	percentRegexp = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*%`)
+	ansiRegexp    = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+)
+
+// Progress. plotting progress parsed from the output of plot tool,
+// the tool hashes and writes nonces in parallel, each stage is 0-100 percent.
+type Progress struct {
+	Hashing float64
+	Writing float64
+	Done    bool
+}
+
+// Percent. overall percent of plotting
+func (this *Progress) Percent() float64 {
+	if this.Done {
+		return 100
+	}
+	return (this.Hashing + this.Writing) / 2
+}
+
+// ParseProgress. update the progress with a output line of plot tool, return true if it's changed
+func ParseProgress(line string, p *Progress) bool {
+	line = strings.TrimSpace(ansiRegexp.ReplaceAllString(line, ""))
+	if strings.HasPrefix(line, STAGE_DONE) {
+		p.Done = true
+		return true
+	}
+	var stage *float64
+	switch {
+	case strings.HasPrefix(line, STAGE_HASHING):
+		stage = &p.Hashing
+	case strings.HasPrefix(line, STAGE_WRITING):
+		stage = &p.Writing
+	default:
+		return false
+	}
+	matches := percentRegexp.FindAllStringSubmatch(line, -1)
+	if len(matches) == 0 {
+		return false
+	}
+	percent, err := strconv.ParseFloat(matches[len(matches)-1][1], 64)
+	if err != nil || percent < 0 || percent > 100 || percent == *stage {
+		return false
+	}
+	*stage = percent
+	return true
+}
+
+// ProgressWriter. split the output of plot tool to lines by CR or LF, and parse the progress of them
+type ProgressWriter struct {
+	lock     sync.Mutex
+	buf      []byte
+	progress Progress
+	onChange func(Progress)
+}
+
+func NewProgressWriter(onChange func(Progress)) *ProgressWriter {
+	return &ProgressWriter{onChange: onChange}
+}
+
+func (this *ProgressWriter) Write(data []byte) (int, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.buf = append(this.buf, data...)
+	for {
+		idx := bytes.IndexAny(this.buf, "\r\n")
+		if idx < 0 {
+			break
+		}
+		line := string(this.buf[:idx])
+		this.buf = this.buf[idx+1:]
+		if ParseProgress(line, &this.progress) && this.onChange != nil {
+			this.onChange(this.progress)
+		}
+	}
+	return len(data), nil
+}
+
+// Progress. the latest progress
+func (this *ProgressWriter) Progress() Progress {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.progress
+}
@@ -0,0 +1,42 @@
+package plot
+
+import (
+	"testing"
+)
+
+func TestProgressWriter(t *testing.T) {
+	changes := 0
+	w := NewProgressWriter(func(Progress) { changes++ })
+	output := "Numeric ID:  3988\nStart Nonce: 0\nNonces: 8\nStarting plotting...\n" +
+		"Hashing: 1.00 MiB / 2.00 MiB [=====>-----] 50.00 % 1.00 MiB/s 1s\r" +
+		"\x1b[1AWriting: 512 KiB / 2.00 MiB [==>--------] 25.00 % 1.00 MiB/s 2s\r" +
+		"Hashing: 2.00 MiB / 2.00 MiB [===========] 100.00 % 1.00 MiB/s 0s\r"
+	// write in pieces to split the lines
+	for i := 0; i < len(output); i += 7 {
+		end := i + 7
+		if end > len(output) {
+			end = len(output)
+		}
+		w.Write([]byte(output[i:end]))
+	}
+	p := w.Progress()
+	if p.Hashing != 100 || p.Writing != 25 || p.Percent() != 62.5 || changes != 3 {
+		t.Fatalf("unexpected progress %+v, changes %d", p, changes)
+	}
+	w.Write([]byte("Generated 8 nonces in 0h 0m 2s, 240.00 nonces/m.\n"))
+	if p = w.Progress(); !p.Done || p.Percent() != 100 {
+		t.Fatalf("unexpected progress %+v", p)
+	}
+}
+
+func TestParseProgress(t *testing.T) {
+	p := &Progress{}
+	for _, line := range []string{"", "Fast file pre-allocation...", "Hashing: [=>---]", "Writing: 120.00 %"} {
+		if ParseProgress(line, p) {
+			t.Fatalf("line %q changed progress %+v", line, p)
+		}
+	}
+	if !ParseProgress("Writing: 5 % 50 %", p) || p.Writing != 50 {
+		t.Fatalf("unexpected progress %+v", p)
+	}
+}
@@ -0,0 +1,98 @@
+package plot
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// shabal256. Shabal-256 hash used by plot generation.
+// The initial value is derived by processing the two prefix blocks of the output size,
+// so no constant table is needed.
+type shabal256 struct {
+	a    [12]uint32
+	b, c [16]uint32
+	w    uint64
+}
+
+var shabal256IV = newShabal256IV()
+
+// shabalA. index of A words, i mod 12
+var shabalA = func() (idx [60]int) {
+	for i := range idx {
+		idx[i] = i % 12
+	}
+	return
+}()
+
+func newShabal256IV() shabal256 {
+	s := shabal256{w: ^uint64(0)}
+	var m [16]uint32
+	for k := 0; k < 2; k++ {
+		for i := range m {
+			m[i] = uint32(256 + 16*k + i)
+		}
+		s.block(&m)
+	}
+	return s
+}
+
+// block. process a full message block
+func (this *shabal256) block(m *[16]uint32) {
+	for i := range this.b {
+		this.b[i] += m[i]
+	}
+	this.permute(m)
+	for i := range this.c {
+		this.c[i] -= m[i]
+	}
+	this.b, this.c = this.c, this.b
+	this.w++
+}
+
+func (this *shabal256) permute(m *[16]uint32) {
+	this.a[0] ^= uint32(this.w)
+	this.a[1] ^= uint32(this.w >> 32)
+	for i := range this.b {
+		this.b[i] = bits.RotateLeft32(this.b[i], 17)
+	}
+	a, b, c := &this.a, &this.b, &this.c
+	for j := 0; j < 48; j++ {
+		i := j & 15
+		k, prev := shabalA[j], shabalA[j+11]
+		a[k] = 3*(a[k]^(5*bits.RotateLeft32(a[prev], 15))^c[(24-i)&15]) ^
+			b[(i+13)&15] ^ (b[(i+9)&15] &^ b[(i+6)&15]) ^ m[i]
+		b[i] = ^(bits.RotateLeft32(b[i], 1) ^ a[k])
+	}
+	for j := 0; j < 36; j++ {
+		a[shabalA[j]] += c[(j+3)&15]
+	}
+}
+
+// shabal256Sum. Shabal-256 digest of data
+func shabal256Sum(data []byte, out []byte) {
+	s := shabal256IV
+	var m [16]uint32
+	for ; len(data) >= 64; data = data[64:] {
+		for i := range m {
+			m[i] = binary.LittleEndian.Uint32(data[4*i:])
+		}
+		s.block(&m)
+	}
+	var last [64]byte
+	copy(last[:], data)
+	last[len(data)] = 0x80
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint32(last[4*i:])
+	}
+	for i := range s.b {
+		s.b[i] += m[i]
+	}
+	s.permute(&m)
+	for i := 0; i < 3; i++ {
+		s.b, s.c = s.c, s.b
+		s.permute(&m)
+	}
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(out[4*i:], s.b[8+i])
+	}
+}
@@ -27,7 +27,7 @@ func GetMinStartNonce(numericId, path string) (uint64, error) {
 		}
 		parts := strings.Split(info.Name(), "_")
 
-		if len(parts) != 3 {
+		if len(parts) != 3 || parts[0] != numericId {
 			continue
 		}
 
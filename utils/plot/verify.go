@@ -0,0 +1,277 @@
+package plot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	VERIFY_SAMPLE_NONCES = 2 // nonces sampled of each plot file by default
+	VERIFY_SAMPLE_SCOOPS = 8 // scoops checked of each sampled nonce
+)
+
+// PlotFileInfo. plot file info parsed from the name "numericID_startNonce_nonces"
+type PlotFileInfo struct {
+	Name       string
+	Path       string
+	NumericID  string
+	StartNonce uint64
+	Nonces     uint64
+}
+
+// FileReport. verify result of a plot file
+type FileReport struct {
+	Name          string
+	Path          string
+	StartNonce    uint64
+	Nonces        uint64
+	Size          uint64
+	ExpectedSize  uint64
+	SampledNonces []uint64
+	SampledScoops uint64
+	BadScoops     uint64
+	Errors        []string
+	Valid         bool
+}
+
+// NonceRange. nonces in [StartNonce, EndNonce)
+type NonceRange struct {
+	StartNonce uint64
+	EndNonce   uint64
+	Files      []string `json:",omitempty"`
+}
+
+// VerifyReport. verify result of all plot files of an account in directories
+type VerifyReport struct {
+	Paths      []string
+	NumericID  string
+	Files      []*FileReport
+	Overlaps   []NonceRange
+	Gaps       []NonceRange // nonces not plotted, they don't affect mining so the report is still valid
+	Incomplete []string     // temp files left by unfinished plotting
+	Valid      bool
+}
+
+// ParsePlotFileName. parse the plot file name, return error if it's not a plot file
+func ParsePlotFileName(name string) (*PlotFileInfo, error) {
+	parts := strings.Split(filepath.Base(name), "_")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid plot file name %s", name)
+	}
+	if _, err := strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid numeric id of plot file %s", name)
+	}
+	startNonce, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start nonce of plot file %s", name)
+	}
+	nonces, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || nonces == 0 {
+		return nil, fmt.Errorf("invalid nonces of plot file %s", name)
+	}
+	return &PlotFileInfo{
+		Name:       filepath.Base(name),
+		Path:       filepath.Dir(name),
+		NumericID:  parts[0],
+		StartNonce: startNonce,
+		Nonces:     nonces,
+	}, nil
+}
+
+// VerifyPlots. verify all plot files of numericID in paths, check the file sizes, sample scoops of each file
+// and find the overlapping or missing nonce ranges between files. The missing ranges are only reported.
+func VerifyPlots(ctx context.Context, numericID string, paths []string, samples int) (*VerifyReport, error) {
+	report := &VerifyReport{
+		Paths:      paths,
+		NumericID:  numericID,
+		Files:      make([]*FileReport, 0),
+		Incomplete: make([]string, 0),
+	}
+	files := make([]*PlotFileInfo, 0)
+	for _, path := range paths {
+		infos, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if info.IsDir() || !strings.HasPrefix(info.Name(), numericID) {
+				continue
+			}
+			if strings.HasSuffix(info.Name(), NATIVE_TEMP_SUFFIX) {
+				report.Incomplete = append(report.Incomplete, filepath.Join(path, info.Name()))
+				continue
+			}
+			file, err := ParsePlotFileName(filepath.Join(path, info.Name()))
+			if err != nil || file.NumericID != numericID {
+				continue
+			}
+			files = append(files, file)
+		}
+	}
+
+	report.Files = make([]*FileReport, len(files))
+	next := make(chan int, len(files))
+	for i := range files {
+		next <- i
+	}
+	close(next)
+	wg := new(sync.WaitGroup)
+	for t := 0; t < runtime.NumCPU(); t++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				report.Files[i] = VerifyFile(ctx, filepath.Join(files[i].Path, files[i].Name), samples)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report.Overlaps, report.Gaps = FindNonceRangeIssues(files)
+	report.Valid = len(report.Overlaps) == 0 && len(report.Incomplete) == 0
+	for _, file := range report.Files {
+		report.Valid = report.Valid && file.Valid
+	}
+	return report, nil
+}
+
+// VerifyFile. verify a plot file, check the file size matches the nonces of its name,
+// and regenerate samples nonces to compare with the scoops in file. Only check size if samples is 0.
+func VerifyFile(ctx context.Context, fullPath string, samples int) *FileReport {
+	report := &FileReport{
+		Name:          filepath.Base(fullPath),
+		Path:          filepath.Dir(fullPath),
+		SampledNonces: make([]uint64, 0),
+		Errors:        make([]string, 0),
+	}
+	file, err := ParsePlotFileName(fullPath)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+	report.StartNonce = file.StartNonce
+	report.Nonces = file.Nonces
+	report.ExpectedSize = file.Nonces * NONCE_SIZE
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+	report.Size = uint64(info.Size())
+	if report.Size != report.ExpectedSize {
+		report.Errors = append(report.Errors,
+			fmt.Sprintf("file size %d not match %d nonces, expect %d", report.Size, file.Nonces, report.ExpectedSize))
+		return report
+	}
+	if samples > 0 {
+		if err := sampleScoops(ctx, fullPath, file, samples, report); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	report.Valid = len(report.Errors) == 0
+	return report
+}
+
+// sampleScoops. regenerate the first, the last and random nonces of file, compare random scoops of them
+func sampleScoops(ctx context.Context, fullPath string, file *PlotFileInfo, samples int, report *FileReport) error {
+	numericID, err := strconv.ParseUint(file.NumericID, 10, 64)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	if uint64(samples) > file.Nonces {
+		samples = int(file.Nonces)
+	}
+	nonces := []uint64{0, file.Nonces - 1}
+	for len(nonces) < samples {
+		nonces = append(nonces, uint64(r.Int63n(int64(file.Nonces))))
+	}
+	nonces = nonces[:samples]
+
+	data := make([]byte, NONCE_SIZE)
+	scoop := make([]byte, SCOOP_SIZE)
+	for _, n := range nonces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		GenerateNonce(numericID, file.StartNonce+n, data)
+		report.SampledNonces = append(report.SampledNonces, file.StartNonce+n)
+		for i := 0; i < VERIFY_SAMPLE_SCOOPS; i++ {
+			s := uint64(r.Intn(SCOOP_COUNT))
+			if _, err := f.ReadAt(scoop, int64((s*file.Nonces+n)*SCOOP_SIZE)); err != nil {
+				return err
+			}
+			report.SampledScoops++
+			if !bytes.Equal(scoop, data[s*SCOOP_SIZE:(s+1)*SCOOP_SIZE]) {
+				report.BadScoops++
+			}
+		}
+	}
+	if report.BadScoops > 0 {
+		return fmt.Errorf("%d of %d sampled scoops are wrong", report.BadScoops, report.SampledScoops)
+	}
+	return nil
+}
+
+// FindNonceRangeIssues. find the overlapping nonce ranges and the missing nonce ranges between files
+func FindNonceRangeIssues(files []*PlotFileInfo) ([]NonceRange, []NonceRange) {
+	sorted := make([]*PlotFileInfo, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].StartNonce == sorted[j].StartNonce {
+			return sorted[i].Nonces < sorted[j].Nonces
+		}
+		return sorted[i].StartNonce < sorted[j].StartNonce
+	})
+
+	overlaps, gaps := make([]NonceRange, 0), make([]NonceRange, 0)
+	for i, file := range sorted {
+		end := file.StartNonce + file.Nonces
+		for _, next := range sorted[i+1:] {
+			if next.StartNonce >= end {
+				break
+			}
+			nextEnd := next.StartNonce + next.Nonces
+			if nextEnd > end {
+				nextEnd = end
+			}
+			overlaps = append(overlaps, NonceRange{
+				StartNonce: next.StartNonce,
+				EndNonce:   nextEnd,
+				Files:      []string{filepath.Join(file.Path, file.Name), filepath.Join(next.Path, next.Name)},
+			})
+		}
+	}
+
+	var maxEnd uint64
+	for i, file := range sorted {
+		if i > 0 && file.StartNonce > maxEnd {
+			gaps = append(gaps, NonceRange{StartNonce: maxEnd, EndNonce: file.StartNonce})
+		}
+		if end := file.StartNonce + file.Nonces; end > maxEnd {
+			maxEnd = end
+		}
+	}
+	return overlaps, gaps
+}
@@ -0,0 +1,82 @@
+package plot
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindNonceRangeIssues(t *testing.T) {
+	files := []*PlotFileInfo{
+		{Name: "3988_0_8", StartNonce: 0, Nonces: 8},
+		{Name: "3988_32_8", StartNonce: 32, Nonces: 8},
+		{Name: "3988_8_16", StartNonce: 8, Nonces: 16},
+		{Name: "3988_16_8", StartNonce: 16, Nonces: 8},
+	}
+	overlaps, gaps := FindNonceRangeIssues(files)
+	if len(overlaps) != 1 || overlaps[0].StartNonce != 16 || overlaps[0].EndNonce != 24 {
+		t.Fatalf("unexpected overlaps %+v", overlaps)
+	}
+	if len(gaps) != 1 || gaps[0].StartNonce != 24 || gaps[0].EndNonce != 32 {
+		t.Fatalf("unexpected gaps %+v", gaps)
+	}
+}
+
+func TestVerifyPlots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := &PlotConfig{NumericID: "3988", StartNonce: 0, Nonces: 8, Path: dir, Native: true}
+	if err := plotNative(context.Background(), cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "3988_16_8"), make([]byte, 10), 0666); err != nil {
+		t.Fatal(err)
+	}
+	report, err := VerifyPlots(context.Background(), "3988", []string{dir}, VERIFY_SAMPLE_NONCES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Valid || len(report.Files) != 2 || len(report.Gaps) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for _, file := range report.Files {
+		if file.Name == "3988_0_8" && (!file.Valid || file.SampledScoops == 0) {
+			t.Fatalf("unexpected report of complete file %+v", file)
+		}
+		if file.Name == "3988_16_8" && file.Valid {
+			t.Fatalf("truncated file is valid %+v", file)
+		}
+	}
+	// the gap between complete files is reported without failing the report
+	if err := os.Remove(filepath.Join(dir, "3988_16_8")); err != nil {
+		t.Fatal(err)
+	}
+	if err := plotNative(context.Background(), &PlotConfig{NumericID: "3988", StartNonce: 16, Nonces: 8, Path: dir,
+		Native: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = VerifyPlots(context.Background(), "3988", []string{dir}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid || len(report.Gaps) != 1 {
+		t.Fatalf("unexpected report with gap %+v", report)
+	}
+
+	// corrupt all scoops of the first nonce
+	f, err := os.OpenFile(GetPlotFileFullPath(cfg), os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s := uint64(0); s < SCOOP_COUNT; s++ {
+		f.WriteAt(make([]byte, SCOOP_SIZE), int64(s*8*SCOOP_SIZE))
+	}
+	f.Close()
+	if file := VerifyFile(context.Background(), GetPlotFileFullPath(cfg), 1); file.Valid || file.BadScoops == 0 {
+		t.Fatalf("corrupted file is valid %+v", file)
+	}
+}
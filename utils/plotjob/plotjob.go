@@ -0,0 +1,326 @@
+// Package plotjob schedules plot jobs with limits of parallel jobs, cpu threads and free disk space.
+// Jobs are started in submitted order, a job waits while the running jobs reach the limit,
+// or the free space of its disk is not enough for the plot file and the reserved space.
+package plotjob
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	STATE_PENDING  = "pending"
+	STATE_RUNNING  = "running"
+	STATE_DONE     = "done"
+	STATE_FAILED   = "failed"
+	STATE_CANCELED = "canceled"
+)
+
+// MAX_FINISHED_JOBS. max finished jobs kept in manager
+const MAX_FINISHED_JOBS = 100
+
+var (
+	ErrJobExists   = errors.New("plot job exists")
+	ErrJobNotFound = errors.New("plot job not found")
+	ErrJobFinished = errors.New("plot job has finished")
+	ErrNoSpace     = errors.New("no enough disk space for plot file")
+)
+
+// Limits. limits of plot jobs
+type Limits struct {
+	MaxJobs      int    // max running jobs, at least 1
+	Threads      uint64 // max cpu threads of a job, 0 for all
+	MinFreeSpace uint64 // bytes kept free on disk after plotting
+}
+
+// RunFunc. run the job with the threads limit, report the progress in percent
+type RunFunc func(ctx context.Context, threads uint64, progress func(percent float64)) error
+
+// FreeSpaceFunc. get the free bytes of disk where the path is
+type FreeSpaceFunc func(path string) (uint64, error)
+
+// Job. a plot job
+type Job struct {
+	Id         string
+	Path       string
+	Name       string // plot file name
+	Size       uint64 // bytes of plot file
+	State      string
+	Percent    float64
+	Error      string `json:",omitempty"`
+	CreatedAt  uint64
+	StartedAt  uint64 `json:",omitempty"`
+	FinishedAt uint64 `json:",omitempty"`
+	run        RunFunc
+	cancel     context.CancelFunc
+}
+
+// Finished. the job is done, failed or canceled
+func (this *Job) Finished() bool {
+	return this.State == STATE_DONE || this.State == STATE_FAILED || this.State == STATE_CANCELED
+}
+
+type Manager struct {
+	lock      sync.Mutex
+	limits    Limits
+	jobs      map[string]*Job
+	queue     []string // pending job ids
+	running   int
+	freeSpace FreeSpaceFunc
+	notify    func(Job)
+}
+
+func NewManager(limits Limits, freeSpace FreeSpaceFunc, notify func(Job)) *Manager {
+	return &Manager{
+		limits:    limits,
+		jobs:      make(map[string]*Job),
+		freeSpace: freeSpace,
+		notify:    notify,
+	}
+}
+
+func (this *Manager) Limits() Limits {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.limits
+}
+
+// SetLimits. update the limits, the running jobs are not affected
+func (this *Manager) SetLimits(limits Limits) {
+	this.lock.Lock()
+	this.limits = limits
+	changed := this.schedule()
+	this.lock.Unlock()
+	this.notifyJobs(changed)
+}
+
+// Submit. add a pending job to plot file name in path, a finished job with the same id is replaced
+func (this *Manager) Submit(id, path, name string, size uint64, run RunFunc) (Job, error) {
+	this.lock.Lock()
+	if old, ok := this.jobs[id]; ok && !old.Finished() {
+		this.lock.Unlock()
+		return Job{}, ErrJobExists
+	}
+	job := &Job{
+		Id:        id,
+		Path:      path,
+		Name:      name,
+		Size:      size,
+		State:     STATE_PENDING,
+		CreatedAt: uint64(time.Now().Unix()),
+		run:       run,
+	}
+	this.jobs[id] = job
+	this.queue = append(this.queue, id)
+	changed := append([]Job{*job}, this.schedule()...)
+	ret := *job
+	this.lock.Unlock()
+	this.notifyJobs(changed)
+	return ret, nil
+}
+
+// Cancel. cancel a pending or running job, the running job is canceled by its context
+func (this *Manager) Cancel(id string) (Job, error) {
+	this.lock.Lock()
+	job, ok := this.jobs[id]
+	if !ok {
+		this.lock.Unlock()
+		return Job{}, ErrJobNotFound
+	}
+	if job.Finished() {
+		this.lock.Unlock()
+		return *job, ErrJobFinished
+	}
+	changed := make([]Job, 0)
+	if job.State == STATE_PENDING {
+		this.removeFromQueue(id)
+		this.finish(job, context.Canceled)
+		changed = append(changed, *job)
+	} else if job.cancel != nil {
+		job.cancel()
+	}
+	ret := *job
+	this.lock.Unlock()
+	this.notifyJobs(changed)
+	return ret, nil
+}
+
+func (this *Manager) Get(id string) (Job, bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	job, ok := this.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Jobs. all jobs ordered by created time
+func (this *Manager) Jobs() []Job {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	jobs := make([]Job, 0, len(this.jobs))
+	for _, job := range this.jobs {
+		jobs = append(jobs, *job)
+	}
+	sortJobs(jobs)
+	return jobs
+}
+
+// schedule. start pending jobs within the limits, return the changed jobs. It should be called with lock.
+func (this *Manager) schedule() []Job {
+	changed := make([]Job, 0)
+	maxJobs := this.limits.MaxJobs
+	if maxJobs <= 0 {
+		maxJobs = 1
+	}
+	for len(this.queue) > 0 && this.running < maxJobs {
+		job := this.jobs[this.queue[0]]
+		if this.freeSpace != nil {
+			free, err := this.freeSpace(job.Path)
+			if err != nil {
+				this.queue = this.queue[1:]
+				this.finish(job, err)
+				changed = append(changed, *job)
+				continue
+			}
+			if free < this.reserved()+job.Size+this.limits.MinFreeSpace {
+				if this.running > 0 {
+					// wait for the running jobs
+					break
+				}
+				this.queue = this.queue[1:]
+				this.finish(job, ErrNoSpace)
+				changed = append(changed, *job)
+				continue
+			}
+		}
+		this.queue = this.queue[1:]
+		this.start(job)
+		changed = append(changed, *job)
+	}
+	return changed
+}
+
+// reserved. the space not written yet by running jobs, Size*(100-Percent)/100 of each job,
+// the written part has been taken from the free space of disk
+func (this *Manager) reserved() uint64 {
+	reserved := uint64(0)
+	for _, job := range this.jobs {
+		if job.State != STATE_RUNNING || job.Percent >= 100 {
+			continue
+		}
+		percent := job.Percent
+		if percent < 0 {
+			percent = 0
+		}
+		reserved += uint64(float64(job.Size) * (100 - percent) / 100)
+	}
+	return reserved
+}
+
+func (this *Manager) start(job *Job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job.State = STATE_RUNNING
+	job.StartedAt = uint64(time.Now().Unix())
+	job.cancel = cancel
+	this.running++
+	threads := this.limits.Threads
+	go func() {
+		err := job.run(ctx, threads, func(percent float64) {
+			this.updateProgress(job, percent)
+		})
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		cancel()
+		this.lock.Lock()
+		this.running--
+		this.finish(job, err)
+		changed := append([]Job{*job}, this.schedule()...)
+		this.prune()
+		this.lock.Unlock()
+		this.notifyJobs(changed)
+	}()
+}
+
+// updateProgress. update the percent of job, notify it when the integer percent changed
+func (this *Manager) updateProgress(job *Job, percent float64) {
+	this.lock.Lock()
+	if job.State != STATE_RUNNING || percent == job.Percent {
+		this.lock.Unlock()
+		return
+	}
+	changed := int(percent) != int(job.Percent)
+	job.Percent = percent
+	ret := *job
+	this.lock.Unlock()
+	if changed {
+		this.notifyJobs([]Job{ret})
+	}
+}
+
+// finish. set the finished state of job with the error. It should be called with lock.
+func (this *Manager) finish(job *Job, err error) {
+	job.FinishedAt = uint64(time.Now().Unix())
+	job.cancel = nil
+	switch {
+	case err == nil:
+		job.State = STATE_DONE
+		job.Percent = 100
+	case errors.Is(err, context.Canceled):
+		job.State = STATE_CANCELED
+	default:
+		job.State = STATE_FAILED
+		job.Error = err.Error()
+	}
+}
+
+// prune. remove the oldest finished jobs. It should be called with lock.
+func (this *Manager) prune() {
+	finished := make([]Job, 0)
+	for _, job := range this.jobs {
+		if job.Finished() {
+			finished = append(finished, *job)
+		}
+	}
+	if len(finished) <= MAX_FINISHED_JOBS {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].FinishedAt > finished[j].FinishedAt
+	})
+	for _, job := range finished[MAX_FINISHED_JOBS:] {
+		delete(this.jobs, job.Id)
+	}
+}
+
+func (this *Manager) removeFromQueue(id string) {
+	for i, queued := range this.queue {
+		if queued == id {
+			this.queue = append(this.queue[:i], this.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+func (this *Manager) notifyJobs(jobs []Job) {
+	if this.notify == nil {
+		return
+	}
+	for _, job := range jobs {
+		this.notify(job)
+	}
+}
+
+func sortJobs(jobs []Job) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].CreatedAt != jobs[j].CreatedAt {
+			return jobs[i].CreatedAt < jobs[j].CreatedAt
+		}
+		return jobs[i].Id < jobs[j].Id
+	})
+}
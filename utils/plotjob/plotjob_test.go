@@ -0,0 +1,157 @@
+package plotjob
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockRun. a run func blocked until released or canceled
+func blockRun(release chan struct{}, started chan string, id string) RunFunc {
+	return func(ctx context.Context, threads uint64, progress func(float64)) error {
+		started <- id
+		progress(50)
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func waitState(t *testing.T, m *Manager, id, state string) Job {
+	for i := 0; i < 200; i++ {
+		if job, ok := m.Get(id); ok && job.State == state {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := m.Get(id)
+	t.Fatalf("job %s state %s, expect %s", id, job.State, state)
+	return job
+}
+
+func TestParallelLimit(t *testing.T) {
+	var lock sync.Mutex
+	notified := 0
+	m := NewManager(Limits{MaxJobs: 2}, nil, func(Job) {
+		lock.Lock()
+		notified++
+		lock.Unlock()
+	})
+	release, started := make(chan struct{}), make(chan string, 3)
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := m.Submit(id, "/plots", "", 1, blockRun(release, started, id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Submit("a", "/plots", "", 1, nil); err != ErrJobExists {
+		t.Fatalf("expect job exists, got %v", err)
+	}
+	<-started
+	<-started
+	waitState(t, m, "c", STATE_PENDING)
+	if job := waitState(t, m, "a", STATE_RUNNING); job.Percent != 50 {
+		t.Fatalf("unexpected percent %v", job.Percent)
+	}
+	release <- struct{}{}
+	if id := <-started; id != "c" {
+		t.Fatalf("unexpected started job %s", id)
+	}
+	close(release)
+	for _, id := range []string{"a", "b", "c"} {
+		if job := waitState(t, m, id, STATE_DONE); job.Percent != 100 {
+			t.Fatalf("unexpected percent %v", job.Percent)
+		}
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if notified == 0 {
+		t.Fatal("jobs not notified")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	m := NewManager(Limits{MaxJobs: 1}, nil, nil)
+	release, started := make(chan struct{}), make(chan string, 2)
+	m.Submit("a", "/plots", "", 1, blockRun(release, started, "a"))
+	m.Submit("b", "/plots", "", 1, blockRun(release, started, "b"))
+	<-started
+	if job, err := m.Cancel("b"); err != nil || job.State != STATE_CANCELED {
+		t.Fatalf("cancel pending job %+v err %v", job, err)
+	}
+	if _, err := m.Cancel("a"); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, m, "a", STATE_CANCELED)
+	if _, err := m.Cancel("a"); err != ErrJobFinished {
+		t.Fatalf("expect job finished, got %v", err)
+	}
+	if _, err := m.Cancel("x"); err != ErrJobNotFound {
+		t.Fatalf("expect job not found, got %v", err)
+	}
+	failed := errors.New("failed")
+	m.Submit("b", "/plots", "", 1, func(context.Context, uint64, func(float64)) error { return failed })
+	if job := waitState(t, m, "b", STATE_FAILED); job.Error != failed.Error() {
+		t.Fatalf("unexpected error %s", job.Error)
+	}
+	if jobs := m.Jobs(); len(jobs) != 2 {
+		t.Fatalf("unexpected jobs %+v", jobs)
+	}
+}
+
+func TestFreeSpace(t *testing.T) {
+	free := uint64(100)
+	m := NewManager(Limits{MaxJobs: 3, MinFreeSpace: 10}, func(string) (uint64, error) { return free, nil }, nil)
+	release, started := make(chan struct{}), make(chan string, 3)
+	noProgress := func(ctx context.Context, threads uint64, progress func(float64)) error {
+		started <- "a"
+		<-release
+		return nil
+	}
+	m.Submit("a", "/plots", "", 50, noProgress)
+	<-started
+	// a is not allocated yet, b waits for it
+	m.Submit("b", "/plots", "", 50, blockRun(release, started, "b"))
+	waitState(t, m, "b", STATE_PENDING)
+	free = 40
+	release <- struct{}{}
+	// no job is running, b can never be started
+	job := waitState(t, m, "b", STATE_FAILED)
+	if job.Error != ErrNoSpace.Error() {
+		t.Fatalf("unexpected error %s", job.Error)
+	}
+	free = 100
+	m.Submit("c", "/plots", "", 50, blockRun(release, started, "c"))
+	<-started
+	close(release)
+	waitState(t, m, "c", STATE_DONE)
+}
+
+func TestReservedWithProgress(t *testing.T) {
+	m := NewManager(Limits{MaxJobs: 3, MinFreeSpace: 10}, func(string) (uint64, error) { return 90, nil }, nil)
+	release, started := make(chan struct{}), make(chan string, 2)
+	m.Submit("a", "/plots", "", 80, blockRun(release, started, "a"))
+	<-started
+	for i := 0; i < 200; i++ {
+		if job, _ := m.Get("a"); job.Percent == 50 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// a reported 50%, the left 40 bytes are still reserved, 40+50+10 > 90
+	m.Submit("b", "/plots", "", 50, blockRun(release, started, "b"))
+	waitState(t, m, "b", STATE_PENDING)
+	m.lock.Lock()
+	reserved := m.reserved()
+	m.lock.Unlock()
+	if reserved != 40 {
+		t.Fatalf("unexpected reserved %d", reserved)
+	}
+	close(release)
+	waitState(t, m, "a", STATE_DONE)
+	waitState(t, m, "b", STATE_DONE)
+}
@@ -0,0 +1,188 @@
+// Package sectorview aggregates sectors of a node with their usage, files and proof health,
+// and plans moving files out of a sector before it is deleted.
+// Sizes are in KB as the sectors on chain.
+package sectorview
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	PROOF_NONE    = "none"    // first prove height is not reached
+	PROOF_SUCCESS = "success" // the last proof of sector is recorded on chain and the next one isn't due
+	PROOF_MISSED  = "missed"  // no proof is recorded since the prove height is passed
+	PROOF_EMPTY   = "empty"   // no file in the sector to prove
+)
+
+// PROOF_DELAY_BLOCKS. blocks after the next prove height before the proof is regarded as missed
+const PROOF_DELAY_BLOCKS = 100
+
+var ErrNoSpace = errors.New("no enough free space in other sectors")
+
+// File. a file stored in sector
+type File struct {
+	FileHash    string
+	Size        uint64
+	IsPlot      bool
+	Name        string `json:",omitempty"`
+	ProveHeight uint64 // height of the last proof of file by the node recorded on chain, 0 if never proved
+}
+
+// Sector. aggregated view of a sector
+type Sector struct {
+	SectorId         uint64
+	Size             uint64
+	Used             uint64
+	Free             uint64
+	UsedPercent      float64
+	ProveLevel       uint64
+	IsPlots          bool
+	FirstProveHeight uint64
+	NextProveHeight  uint64
+	LastProveHeight  uint64
+	LastProof        string
+	FileCount        int
+	PlotCount        int
+	Files            []*File
+}
+
+// Summary. sectors of a node
+type Summary struct {
+	Height      uint64
+	Size        uint64
+	Used        uint64
+	Free        uint64
+	MissedCount int
+	Sectors     []*Sector
+}
+
+// Fill. compute the used size, free size, counts and proof outcome of sector at current height
+func (this *Sector) Fill(currentHeight uint64) {
+	this.Used, this.FileCount, this.PlotCount, this.LastProveHeight = 0, len(this.Files), 0, 0
+	for _, file := range this.Files {
+		this.Used += file.Size
+		if file.IsPlot {
+			this.PlotCount++
+		}
+		if file.ProveHeight > this.LastProveHeight {
+			this.LastProveHeight = file.ProveHeight
+		}
+	}
+	this.Free = 0
+	if this.Size > this.Used {
+		this.Free = this.Size - this.Used
+	}
+	this.UsedPercent = 0
+	if this.Size > 0 {
+		this.UsedPercent = float64(this.Used) * 100 / float64(this.Size)
+	}
+	this.LastProof = LastProof(len(this.Files) > 0, this.LastProveHeight, this.FirstProveHeight, this.NextProveHeight,
+		currentHeight)
+}
+
+// LastProof. outcome of the last proof of sector from the height of last proof recorded on chain,
+// the proof is missed if no proof is recorded at or after the prove height once the delay is passed
+func LastProof(hasFile bool, lastProveHeight, firstProveHeight, nextProveHeight, currentHeight uint64) string {
+	if !hasFile {
+		return PROOF_EMPTY
+	}
+	dueHeight := nextProveHeight
+	if lastProveHeight == 0 {
+		dueHeight = firstProveHeight
+	}
+	if currentHeight > dueHeight+PROOF_DELAY_BLOCKS && lastProveHeight < dueHeight {
+		return PROOF_MISSED
+	}
+	if lastProveHeight > 0 {
+		return PROOF_SUCCESS
+	}
+	return PROOF_NONE
+}
+
+// Summarize. fill sectors and sum them up, sectors are sorted by id
+func Summarize(sectors []*Sector, currentHeight uint64) *Summary {
+	sort.Slice(sectors, func(i, j int) bool {
+		return sectors[i].SectorId < sectors[j].SectorId
+	})
+	summary := &Summary{Height: currentHeight, Sectors: sectors}
+	for _, sector := range sectors {
+		sector.Fill(currentHeight)
+		summary.Size += sector.Size
+		summary.Used += sector.Used
+		summary.Free += sector.Free
+		if sector.LastProof == PROOF_MISSED {
+			summary.MissedCount++
+		}
+	}
+	return summary
+}
+
+// Move. move a file out of sector, To is 0 if a new sector is needed.
+// To is a hint of the placement, it's not enforced when the file is added again
+type Move struct {
+	FileHash string
+	Name     string `json:",omitempty"`
+	Size     uint64
+	To       uint64
+}
+
+// RebalancePlan. moves of files out of a sector
+type RebalancePlan struct {
+	SectorId  uint64
+	Moves     []*Move
+	Unmovable []string // files can't be moved by the node, only plot files of the node can be moved
+	NewSize   uint64   // size of files need new sectors
+}
+
+// PlanRebalance. plan moving the files of source to the other sectors of the same kind,
+// the biggest file is placed first into the sector with the least free space it fits.
+// Files not fitting any sector need new sectors if createSector, otherwise ErrNoSpace is returned.
+func PlanRebalance(source *Sector, sectors []*Sector, createSector bool) (*RebalancePlan, error) {
+	plan := &RebalancePlan{
+		SectorId:  source.SectorId,
+		Moves:     make([]*Move, 0),
+		Unmovable: make([]string, 0),
+	}
+	free := make(map[uint64]uint64)
+	targets := make([]*Sector, 0)
+	for _, sector := range sectors {
+		if sector.SectorId == source.SectorId || sector.IsPlots != source.IsPlots {
+			continue
+		}
+		free[sector.SectorId] = sector.Free
+		targets = append(targets, sector)
+	}
+
+	files := make([]*File, 0, len(source.Files))
+	for _, file := range source.Files {
+		if !file.IsPlot {
+			plan.Unmovable = append(plan.Unmovable, file.FileHash)
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	for _, file := range files {
+		move := &Move{FileHash: file.FileHash, Name: file.Name, Size: file.Size}
+		for _, target := range targets {
+			if free[target.SectorId] < file.Size {
+				continue
+			}
+			if move.To == 0 || free[target.SectorId] < free[move.To] {
+				move.To = target.SectorId
+			}
+		}
+		if move.To != 0 {
+			free[move.To] -= file.Size
+		} else if createSector {
+			plan.NewSize += file.Size
+		} else {
+			return plan, ErrNoSpace
+		}
+		plan.Moves = append(plan.Moves, move)
+	}
+	return plan, nil
+}
@@ -0,0 +1,72 @@
+package sectorview
+
+import "testing"
+
+func TestSummarize(t *testing.T) {
+	sectors := []*Sector{
+		{SectorId: 2, Size: 1000, FirstProveHeight: 100, NextProveHeight: 100},
+		{SectorId: 1, Size: 1000, FirstProveHeight: 100, NextProveHeight: 400, Files: []*File{
+			{FileHash: "a", Size: 300, IsPlot: true, ProveHeight: 300},
+			{FileHash: "b", Size: 200, ProveHeight: 310},
+		}},
+		{SectorId: 3, Size: 100, FirstProveHeight: 100, NextProveHeight: 200, Files: []*File{
+			{FileHash: "c", Size: 150, ProveHeight: 150},
+		}},
+	}
+	summary := Summarize(sectors, 350)
+	if summary.Sectors[0].SectorId != 1 || summary.Size != 2100 || summary.Used != 650 || summary.Free != 1500 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	expect := map[uint64]string{1: PROOF_SUCCESS, 2: PROOF_EMPTY, 3: PROOF_MISSED}
+	for _, sector := range summary.Sectors {
+		if sector.LastProof != expect[sector.SectorId] {
+			t.Fatalf("sector %d last proof %s, expect %s", sector.SectorId, sector.LastProof, expect[sector.SectorId])
+		}
+	}
+	if summary.MissedCount != 1 || sectors[0].PlotCount != 1 || sectors[0].UsedPercent != 50 ||
+		sectors[0].LastProveHeight != 310 {
+		t.Fatalf("unexpected summary %+v, sector %+v", summary, sectors[0])
+	}
+	if LastProof(true, 0, 100, 100, 150) != PROOF_NONE {
+		t.Fatal("sector before first prove height should have no proof")
+	}
+	if LastProof(true, 0, 100, 100, 250) != PROOF_MISSED {
+		t.Fatal("sector never proved after first prove height should miss the proof")
+	}
+	if LastProof(true, 420, 100, 400, 600) != PROOF_SUCCESS {
+		t.Fatal("sector proved at its prove height shouldn't miss the proof")
+	}
+}
+
+func TestPlanRebalance(t *testing.T) {
+	source := &Sector{SectorId: 1, IsPlots: true, Files: []*File{
+		{FileHash: "a", Size: 100, IsPlot: true},
+		{FileHash: "b", Size: 300, IsPlot: true},
+		{FileHash: "c", Size: 50},
+	}}
+	sectors := []*Sector{
+		source,
+		{SectorId: 2, IsPlots: true, Free: 500},
+		{SectorId: 3, IsPlots: true, Free: 320},
+		{SectorId: 4, Free: 1000},
+	}
+	plan, err := PlanRebalance(source, sectors, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Moves) != 2 || plan.Moves[0].FileHash != "b" || plan.Moves[0].To != 3 || plan.Moves[1].To != 2 {
+		t.Fatalf("unexpected moves %+v %+v", plan.Moves[0], plan.Moves[1])
+	}
+	if len(plan.Unmovable) != 1 || plan.Unmovable[0] != "c" {
+		t.Fatalf("unexpected unmovable %v", plan.Unmovable)
+	}
+
+	sectors[1].Free = 50
+	if _, err := PlanRebalance(source, sectors, false); err != ErrNoSpace {
+		t.Fatalf("expect no space, got %v", err)
+	}
+	plan, err = PlanRebalance(source, sectors, true)
+	if err != nil || plan.NewSize != 100 || plan.Moves[1].To != 0 {
+		t.Fatalf("unexpected plan with new sector %+v, err %v", plan, err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package seedpolicy decides whether a share node keeps seeding a file to a downloader.
+// A policy combines allow/deny lists of downloader addresses, a minimum price per block,
+// seeding ratio and time limits of files, seeding windows of a day and a profitability threshold.
+// The zero value of a policy allows everything.
+package seedpolicy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	REASON_DENY_LIST    = "downloader is in deny list"
+	REASON_NOT_ALLOWED  = "downloader is not in allow list"
+	REASON_LOW_PRICE    = "price per block is lower than minimum"
+	REASON_OUT_OF_TIME  = "out of seeding windows"
+	REASON_RATIO_LIMIT  = "seeding ratio limit reached"
+	REASON_TIME_LIMIT   = "seeding time limit reached"
+	REASON_UNPROFITABLE = "seeding stopped for low profit"
+)
+
+var ErrInvalidWindow = errors.New("invalid seeding window")
+
+// Window. seeding window of a day in local time, formatted as 15:04.
+// The window crosses midnight if End is before Start.
+type Window struct {
+	Start string
+	End   string
+}
+
+// FileLimit. seeding limits of a file, 0 is unlimited
+type FileLimit struct {
+	MaxRatio    float64 // max served bytes / file size
+	MaxDuration uint64  // max seconds since seeding started
+}
+
+// Policy. seeding policy of a share node
+type Policy struct {
+	AllowList        []string
+	DenyList         []string
+	MinPricePerBlock uint64
+	MaxRatio         float64               // default max seeding ratio of files, 0 is unlimited
+	MaxDuration      uint64                // default max seeding seconds of files, 0 is unlimited
+	FileLimits       map[string]*FileLimit // limits overriding the default of files
+	Windows          []*Window             // empty for seeding all day
+	MinProfit        uint64                // min profit of a file in ProfitPeriod, 0 is disabled
+	ProfitPeriod     uint64                // seconds
+	UpdatedAt        uint64
+}
+
+// FileState. seeding state of a file
+type FileState struct {
+	FileHash    string
+	FileSize    uint64 // bytes
+	SeedStartAt uint64
+	ServedBytes uint64 // bytes of the blocks sent to downloaders
+	Requests    uint64
+	Denied      uint64
+	Stopped     bool
+	StopReason  string
+	UpdatedAt   uint64
+}
+
+// Request. a share request of downloader
+type Request struct {
+	Downloader string
+	FileHash   string
+	UnitPrice  uint64
+	Time       time.Time
+}
+
+// Decision. the check result of a share request
+type Decision struct {
+	FileHash   string
+	Downloader string
+	UnitPrice  uint64
+	Allowed    bool
+	Reason     string `json:",omitempty"`
+	CheckedAt  uint64
+}
+
+// Validate. check the windows and limits of the policy
+func (this *Policy) Validate() error {
+	for _, w := range this.Windows {
+		if w == nil {
+			return ErrInvalidWindow
+		}
+		if _, err := parseClock(w.Start); err != nil {
+			return err
+		}
+		if _, err := parseClock(w.End); err != nil {
+			return err
+		}
+	}
+	if this.MaxRatio < 0 {
+		return fmt.Errorf("invalid max ratio %v", this.MaxRatio)
+	}
+	for hash, l := range this.FileLimits {
+		if l == nil || l.MaxRatio < 0 {
+			return fmt.Errorf("invalid limit of file %s", hash)
+		}
+	}
+	if this.MinProfit > 0 && this.ProfitPeriod == 0 {
+		return errors.New("profit period is required with min profit")
+	}
+	return nil
+}
+
+// InWindow. the time is in one of the seeding windows
+func (this *Policy) InWindow(t time.Time) bool {
+	if len(this.Windows) == 0 {
+		return true
+	}
+	now := t.Hour()*60 + t.Minute()
+	for _, w := range this.Windows {
+		start, err := parseClock(w.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			continue
+		}
+		if start <= end && now >= start && now < end {
+			return true
+		}
+		if start > end && (now >= start || now < end) {
+			return true
+		}
+	}
+	return false
+}
+
+// Limit. the seeding limit of the file
+func (this *Policy) Limit(fileHash string) *FileLimit {
+	if l, ok := this.FileLimits[fileHash]; ok && l != nil {
+		return l
+	}
+	return &FileLimit{MaxRatio: this.MaxRatio, MaxDuration: this.MaxDuration}
+}
+
+// Check. check the request with the policy and the seeding state of file, state can be nil
+func (this *Policy) Check(req *Request, state *FileState) *Decision {
+	d := &Decision{
+		FileHash:   req.FileHash,
+		Downloader: req.Downloader,
+		UnitPrice:  req.UnitPrice,
+		CheckedAt:  uint64(req.Time.Unix()),
+	}
+	d.Reason = this.denyReason(req, state)
+	d.Allowed = len(d.Reason) == 0
+	return d
+}
+
+// Unprofitable. the file has seeded for a whole profit period and the profit is lower than threshold
+func (this *Policy) Unprofitable(state *FileState, profit uint64, now time.Time) bool {
+	if this.MinProfit == 0 || state == nil || state.SeedStartAt == 0 {
+		return false
+	}
+	if uint64(now.Unix()) < state.SeedStartAt+this.ProfitPeriod {
+		return false
+	}
+	return profit < this.MinProfit
+}
+
+func (this *Policy) denyReason(req *Request, state *FileState) string {
+	if contains(this.DenyList, req.Downloader) {
+		return REASON_DENY_LIST
+	}
+	if len(this.AllowList) > 0 && !contains(this.AllowList, req.Downloader) {
+		return REASON_NOT_ALLOWED
+	}
+	if req.UnitPrice < this.MinPricePerBlock {
+		return REASON_LOW_PRICE
+	}
+	if !this.InWindow(req.Time) {
+		return REASON_OUT_OF_TIME
+	}
+	if state == nil {
+		return ""
+	}
+	if state.Stopped {
+		return state.StopReason
+	}
+	limit := this.Limit(req.FileHash)
+	if limit.MaxRatio > 0 && state.FileSize > 0 &&
+		float64(state.ServedBytes)/float64(state.FileSize) >= limit.MaxRatio {
+		return REASON_RATIO_LIMIT
+	}
+	if limit.MaxDuration > 0 && state.SeedStartAt > 0 &&
+		uint64(req.Time.Unix()) >= state.SeedStartAt+limit.MaxDuration {
+		return REASON_TIME_LIMIT
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock. parse 15:04 to minutes of the day
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidWindow
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
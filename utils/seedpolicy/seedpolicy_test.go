@@ -0,0 +1,83 @@
+package seedpolicy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.Local)
+	policy := &Policy{
+		AllowList:        []string{"alice", "bob"},
+		DenyList:         []string{"bob"},
+		MinPricePerBlock: 2,
+		MaxRatio:         2,
+		FileLimits:       map[string]*FileLimit{"big": {MaxDuration: 60}},
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		req    *Request
+		state  *FileState
+		reason string
+	}{
+		{&Request{Downloader: "alice", FileHash: "a", UnitPrice: 2, Time: now}, nil, ""},
+		{&Request{Downloader: "bob", FileHash: "a", UnitPrice: 2, Time: now}, nil, REASON_DENY_LIST},
+		{&Request{Downloader: "carol", FileHash: "a", UnitPrice: 2, Time: now}, nil, REASON_NOT_ALLOWED},
+		{&Request{Downloader: "alice", FileHash: "a", UnitPrice: 1, Time: now}, nil, REASON_LOW_PRICE},
+		{&Request{Downloader: "alice", FileHash: "a", UnitPrice: 2, Time: now},
+			&FileState{FileSize: 10, ServedBytes: 20}, REASON_RATIO_LIMIT},
+		{&Request{Downloader: "alice", FileHash: "big", UnitPrice: 2, Time: now},
+			&FileState{FileSize: 10, ServedBytes: 20, SeedStartAt: uint64(now.Unix()) - 30}, ""},
+		{&Request{Downloader: "alice", FileHash: "big", UnitPrice: 2, Time: now},
+			&FileState{SeedStartAt: uint64(now.Unix()) - 60}, REASON_TIME_LIMIT},
+		{&Request{Downloader: "alice", FileHash: "a", UnitPrice: 2, Time: now},
+			&FileState{Stopped: true, StopReason: REASON_UNPROFITABLE}, REASON_UNPROFITABLE},
+	}
+	for i, c := range cases {
+		d := policy.Check(c.req, c.state)
+		if d.Reason != c.reason || d.Allowed != (len(c.reason) == 0) {
+			t.Fatalf("case %d: got %+v, expect reason %q", i, d, c.reason)
+		}
+	}
+	if d := (&Policy{}).Check(&Request{Downloader: "any", Time: now}, nil); !d.Allowed {
+		t.Fatalf("empty policy denied %+v", d)
+	}
+}
+
+func TestInWindow(t *testing.T) {
+	policy := &Policy{Windows: []*Window{{Start: "22:00", End: "06:00"}, {Start: "12:00", End: "13:30"}}}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	at := func(h, m int) time.Time {
+		return time.Date(2021, 3, 10, h, m, 0, 0, time.Local)
+	}
+	for _, tm := range []time.Time{at(23, 0), at(2, 0), at(12, 0), at(13, 29)} {
+		if !policy.InWindow(tm) {
+			t.Fatalf("%v should be in window", tm)
+		}
+	}
+	for _, tm := range []time.Time{at(6, 0), at(13, 30), at(21, 59)} {
+		if policy.InWindow(tm) {
+			t.Fatalf("%v should be out of window", tm)
+		}
+	}
+	if err := (&Policy{Windows: []*Window{{Start: "25:00", End: "01:00"}}}).Validate(); err != ErrInvalidWindow {
+		t.Fatalf("expect invalid window, got %v", err)
+	}
+}
+
+func TestUnprofitable(t *testing.T) {
+	now := time.Now()
+	policy := &Policy{MinProfit: 10, ProfitPeriod: 3600}
+	state := &FileState{SeedStartAt: uint64(now.Unix()) - 1800}
+	if policy.Unprofitable(state, 0, now) {
+		t.Fatal("file seeded less than a period should not be unprofitable")
+	}
+	state.SeedStartAt = uint64(now.Unix()) - 3600
+	if !policy.Unprofitable(state, 9, now) || policy.Unprofitable(state, 10, now) {
+		t.Fatal("wrong profitability")
+	}
+}
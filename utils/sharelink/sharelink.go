@@ -0,0 +1,173 @@
+// Package sharelink implements signed share links of a file.
+// A link carries an access grant signed by the file owner:
+//
+//	saveshare://base64url(grant json).base64url(signature)
+//
+// A grant is bound to one recipient address, or to anyone who has the link if the recipient is empty.
+// Signing and verification are done by the caller with the owner account key.
+//
+// A download request carries the link and is signed by the requester, so the serving node knows the
+// requester owns the address which the link is granted to:
+//
+//	base64url(request json).base64url(signature)
+package sharelink
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const (
+	VERSION         = 1
+	LINK_PREFIX     = "saveshare://"
+	REQUEST_MAX_AGE = 300 // seconds, a signed request is rejected after that
+)
+
+const (
+	ACTION_GRANT  = "grant"
+	ACTION_REVOKE = "revoke"
+)
+
+var (
+	ErrInvalidLink      = errors.New("invalid share link")
+	ErrVersion          = errors.New("unsupported share link version")
+	ErrFileMismatch     = errors.New("share link is not for the file")
+	ErrRecipientInvalid = errors.New("share link is not granted to the address")
+	ErrExpired          = errors.New("share link is expired")
+	ErrInvalidRequest   = errors.New("invalid share request")
+	ErrRequestExpired   = errors.New("share request is expired")
+)
+
+// Grant. access grant of a file
+type Grant struct {
+	Version       byte
+	Action        string
+	Id            string
+	FileHash      string
+	Owner         string
+	OwnerPubKey   string // hex of the serialized owner public key
+	Recipient     string // empty for bearer grant
+	ExpiredHeight uint64
+	DownloadLimit uint32 // 0 is unlimited
+	CreatedAt     uint64
+}
+
+// IsBearer. anyone who has the link can use it
+func (this *Grant) IsBearer() bool {
+	return len(this.Recipient) == 0
+}
+
+// Payload. the signed bytes of the grant
+func (this *Grant) Payload() ([]byte, error) {
+	return json.Marshal(this)
+}
+
+// Check. check the grant is usable by the requester at the block height, the signature is not checked
+func (this *Grant) Check(fileHash, requester string, height uint64) error {
+	if this.Action != ACTION_GRANT {
+		return ErrInvalidLink
+	}
+	if len(fileHash) > 0 && this.FileHash != fileHash {
+		return ErrFileMismatch
+	}
+	if !this.IsBearer() && this.Recipient != requester {
+		return ErrRecipientInvalid
+	}
+	if this.ExpiredHeight > 0 && height > this.ExpiredHeight {
+		return ErrExpired
+	}
+	return nil
+}
+
+// Encode. encode the payload and signature to a link
+func Encode(payload, sig []byte) string {
+	return LINK_PREFIX + base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// Decode. decode the link, return the grant, signed payload and signature
+func Decode(link string) (*Grant, []byte, []byte, error) {
+	if !strings.HasPrefix(link, LINK_PREFIX) {
+		return nil, nil, nil, ErrInvalidLink
+	}
+	parts := strings.Split(strings.TrimPrefix(link, LINK_PREFIX), ".")
+	if len(parts) != 2 {
+		return nil, nil, nil, ErrInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, nil, ErrInvalidLink
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(sig) == 0 {
+		return nil, nil, nil, ErrInvalidLink
+	}
+	grant := &Grant{}
+	if err := json.Unmarshal(payload, grant); err != nil {
+		return nil, nil, nil, ErrInvalidLink
+	}
+	if grant.Version != VERSION {
+		return nil, nil, nil, ErrVersion
+	}
+	if len(grant.Id) == 0 || len(grant.FileHash) == 0 || len(grant.Owner) == 0 || len(grant.OwnerPubKey) == 0 {
+		return nil, nil, nil, ErrInvalidLink
+	}
+	return grant, payload, sig, nil
+}
+
+// Request. download request of a file signed by the requester
+type Request struct {
+	Version   byte
+	FileHash  string
+	Link      string // empty if the requester is allowed by the file privilege
+	Requester string
+	PubKey    string // hex of the serialized requester public key
+	Timestamp uint64
+}
+
+// Payload. the signed bytes of the request
+func (this *Request) Payload() ([]byte, error) {
+	return json.Marshal(this)
+}
+
+// CheckTime. the request must be signed within REQUEST_MAX_AGE seconds before or after now
+func (this *Request) CheckTime(now uint64) error {
+	if this.Timestamp+REQUEST_MAX_AGE < now || now+REQUEST_MAX_AGE < this.Timestamp {
+		return ErrRequestExpired
+	}
+	return nil
+}
+
+// EncodeRequest. encode the payload and signature of request
+func EncodeRequest(payload, sig []byte) string {
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// DecodeRequest. decode the request, return the request, signed payload and signature
+func DecodeRequest(str string) (*Request, []byte, []byte, error) {
+	parts := strings.Split(str, ".")
+	if len(parts) != 2 {
+		return nil, nil, nil, ErrInvalidRequest
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, nil, ErrInvalidRequest
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(sig) == 0 {
+		return nil, nil, nil, ErrInvalidRequest
+	}
+	req := &Request{}
+	if err := json.Unmarshal(payload, req); err != nil {
+		return nil, nil, nil, ErrInvalidRequest
+	}
+	if req.Version != VERSION {
+		return nil, nil, nil, ErrVersion
+	}
+	if len(req.FileHash) == 0 || len(req.Requester) == 0 || len(req.PubKey) == 0 {
+		return nil, nil, nil, ErrInvalidRequest
+	}
+	return req, payload, sig, nil
+}
@@ -0,0 +1,99 @@
+package sharelink
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	grant := &Grant{
+		Version:       VERSION,
+		Action:        ACTION_GRANT,
+		Id:            "1",
+		FileHash:      "zb2rhbZsvkDsD3ZsKhDjfMaNN1Fv3RgBRfa5ob6M5CMSHLcPN",
+		Owner:         "AKuNsvkZ8LbR8ixEnQ7ZjXVLFpDBeLQFbG",
+		OwnerPubKey:   "02ab",
+		Recipient:     "AQs3bRkqgs7KhRTDjbMqBqEL1pHWA2vYSy",
+		ExpiredHeight: 100,
+		DownloadLimit: 3,
+	}
+	payload, err := grant.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := []byte{1, 2, 3}
+	got, gotPayload, gotSig, err := Decode(Encode(payload, sig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *grant || !bytes.Equal(gotPayload, payload) || !bytes.Equal(gotSig, sig) {
+		t.Fatalf("decoded grant mismatch %+v", got)
+	}
+	if _, _, _, err := Decode("saveshare://abc"); err != ErrInvalidLink {
+		t.Fatalf("expect invalid link, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	grant := &Grant{
+		Version:       VERSION,
+		Action:        ACTION_GRANT,
+		FileHash:      "hash",
+		Recipient:     "alice",
+		ExpiredHeight: 100,
+	}
+	if err := grant.Check("hash", "alice", 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := grant.Check("other", "alice", 100); err != ErrFileMismatch {
+		t.Fatalf("expect file mismatch, got %v", err)
+	}
+	if err := grant.Check("hash", "bob", 100); err != ErrRecipientInvalid {
+		t.Fatalf("expect recipient invalid, got %v", err)
+	}
+	if err := grant.Check("hash", "alice", 101); err != ErrExpired {
+		t.Fatalf("expect expired, got %v", err)
+	}
+	grant.Recipient = ""
+	if err := grant.Check("hash", "bob", 50); err != nil {
+		t.Fatalf("bearer grant check failed %v", err)
+	}
+	grant.Action = ACTION_REVOKE
+	if err := grant.Check("hash", "bob", 50); err != ErrInvalidLink {
+		t.Fatalf("expect invalid link for revocation, got %v", err)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	req := &Request{
+		Version:   VERSION,
+		FileHash:  "hash",
+		Link:      "saveshare://abc.def",
+		Requester: "alice",
+		PubKey:    "02ab",
+		Timestamp: 1000,
+	}
+	payload, err := req.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, gotPayload, gotSig, err := DecodeRequest(EncodeRequest(payload, []byte{1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *req || !bytes.Equal(gotPayload, payload) || !bytes.Equal(gotSig, []byte{1}) {
+		t.Fatalf("decoded request mismatch %+v", got)
+	}
+	if _, _, _, err := DecodeRequest(EncodeRequest(payload, nil)); err != ErrInvalidRequest {
+		t.Fatalf("expect invalid request, got %v", err)
+	}
+	if err := req.CheckTime(1000 + REQUEST_MAX_AGE); err != nil {
+		t.Fatal(err)
+	}
+	if err := req.CheckTime(1001 + REQUEST_MAX_AGE); err != ErrRequestExpired {
+		t.Fatalf("expect expired request, got %v", err)
+	}
+	if err := req.CheckTime(999 - REQUEST_MAX_AGE); err != ErrRequestExpired {
+		t.Fatalf("expect request from future rejected, got %v", err)
+	}
+}
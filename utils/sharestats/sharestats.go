@@ -0,0 +1,155 @@
+// Package sharestats aggregates share records to revenue analytics.
+// Records are grouped by file, by downloader address and by day, week or month,
+// and the bandwidth served per unit of revenue is computed for each group.
+package sharestats
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	PERIOD_DAY   = "day"
+	PERIOD_WEEK  = "week"
+	PERIOD_MONTH = "month"
+)
+
+const DEFAULT_TOP_N = 10
+
+var ErrInvalidPeriod = errors.New("invalid stats period")
+
+// Record. one share record of a file served to a downloader.
+// A record without profit only carries the bytes served, it's not counted as a share record.
+type Record struct {
+	FileHash    string
+	FileName    string
+	Downloader  string
+	Profit      uint64
+	ServedBytes uint64
+	CreatedAt   uint64 // unix seconds
+}
+
+// Stat. aggregated revenue of a group
+type Stat struct {
+	Key             string
+	Name            string `json:",omitempty"`
+	Records         int
+	Profit          uint64
+	ProfitFormat    string `json:",omitempty"` // filled by caller with its unit
+	ServedBytes     uint64
+	ServedPerProfit float64 // bytes served per unit of profit
+}
+
+// Stats. revenue analytics of share records
+type Stats struct {
+	Begin           uint64
+	End             uint64
+	Period          string
+	Records         int
+	Profit          uint64
+	ProfitFormat    string `json:",omitempty"`
+	ServedBytes     uint64
+	ServedPerProfit float64
+	Files           []*Stat
+	Downloaders     []*Stat
+	Periods         []*Stat
+	TopFiles        []*Stat
+}
+
+// PeriodKey. key of the period which the time belongs to.
+// Days are formatted as 2006-01-02, weeks as the date of its monday and months as 2006-01.
+func PeriodKey(t time.Time, period string) (string, error) {
+	switch period {
+	case PERIOD_DAY:
+		return t.Format("2006-01-02"), nil
+	case PERIOD_WEEK:
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format("2006-01-02"), nil
+	case PERIOD_MONTH:
+		return t.Format("2006-01"), nil
+	}
+	return "", ErrInvalidPeriod
+}
+
+// Aggregate. aggregate the records in the location, topN files with most profit are picked
+func Aggregate(records []*Record, period string, topN int, loc *time.Location) (*Stats, error) {
+	if len(period) == 0 {
+		period = PERIOD_DAY
+	}
+	if _, err := PeriodKey(time.Time{}, period); err != nil {
+		return nil, err
+	}
+	if topN <= 0 {
+		topN = DEFAULT_TOP_N
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	stats := &Stats{Period: period}
+	files := make(map[string]*Stat)
+	downloaders := make(map[string]*Stat)
+	periods := make(map[string]*Stat)
+	for _, r := range records {
+		if r.Profit > 0 {
+			stats.Records++
+		}
+		stats.Profit += r.Profit
+		stats.ServedBytes += r.ServedBytes
+		key, _ := PeriodKey(time.Unix(int64(r.CreatedAt), 0).In(loc), period)
+		addRecord(files, r.FileHash, r.FileName, r)
+		addRecord(downloaders, r.Downloader, "", r)
+		addRecord(periods, key, "", r)
+	}
+	stats.ServedPerProfit = servedPerProfit(stats.ServedBytes, stats.Profit)
+	stats.Files = sortedStats(files, byProfit)
+	stats.Downloaders = sortedStats(downloaders, byProfit)
+	stats.Periods = sortedStats(periods, func(a, b *Stat) bool { return a.Key < b.Key })
+	if topN > len(stats.Files) {
+		topN = len(stats.Files)
+	}
+	stats.TopFiles = stats.Files[:topN]
+	return stats, nil
+}
+
+func addRecord(m map[string]*Stat, key, name string, r *Record) {
+	s, ok := m[key]
+	if !ok {
+		s = &Stat{Key: key, Name: name}
+		m[key] = s
+	}
+	if len(s.Name) == 0 {
+		s.Name = name
+	}
+	if r.Profit > 0 {
+		s.Records++
+	}
+	s.Profit += r.Profit
+	s.ServedBytes += r.ServedBytes
+}
+
+func sortedStats(m map[string]*Stat, less func(a, b *Stat) bool) []*Stat {
+	list := make([]*Stat, 0, len(m))
+	for _, s := range m {
+		s.ServedPerProfit = servedPerProfit(s.ServedBytes, s.Profit)
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return less(list[i], list[j])
+	})
+	return list
+}
+
+func byProfit(a, b *Stat) bool {
+	if a.Profit != b.Profit {
+		return a.Profit > b.Profit
+	}
+	return a.Key < b.Key
+}
+
+func servedPerProfit(served, profit uint64) float64 {
+	if profit == 0 {
+		return 0
+	}
+	return float64(served) / float64(profit)
+}
@@ -0,0 +1,67 @@
+package sharestats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodKey(t *testing.T) {
+	// 2021-03-10 is a wednesday
+	tm := time.Date(2021, 3, 10, 23, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		PERIOD_DAY:   "2021-03-10",
+		PERIOD_WEEK:  "2021-03-08",
+		PERIOD_MONTH: "2021-03",
+	}
+	for period, expect := range cases {
+		key, err := PeriodKey(tm, period)
+		if err != nil || key != expect {
+			t.Fatalf("period %s key %s, expect %s, err %v", period, key, expect, err)
+		}
+	}
+	sunday := time.Date(2021, 3, 14, 0, 0, 0, 0, time.UTC)
+	if key, _ := PeriodKey(sunday, PERIOD_WEEK); key != "2021-03-08" {
+		t.Fatalf("sunday week key %s", key)
+	}
+	if _, err := PeriodKey(tm, "year"); err != ErrInvalidPeriod {
+		t.Fatalf("expect invalid period, got %v", err)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	day := uint64(time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC).Unix())
+	records := []*Record{
+		{FileHash: "a", FileName: "a.txt", Downloader: "alice", Profit: 10, CreatedAt: day},
+		{FileHash: "b", FileName: "b.txt", Downloader: "alice", Profit: 30, CreatedAt: day},
+		{FileHash: "a", FileName: "a.txt", Downloader: "bob", Profit: 5, CreatedAt: day + 86400},
+		{FileHash: "a", Downloader: "alice", ServedBytes: 100, CreatedAt: day},
+		{FileHash: "b", Downloader: "alice", ServedBytes: 600, CreatedAt: day},
+		{FileHash: "a", Downloader: "bob", ServedBytes: 100, CreatedAt: day + 86400},
+	}
+	stats, err := Aggregate(records, PERIOD_DAY, 1, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Records != 3 || stats.Profit != 45 || stats.ServedBytes != 800 {
+		t.Fatalf("wrong total %+v", stats)
+	}
+	if len(stats.Files) != 2 || stats.Files[0].Key != "b" || stats.Files[1].Profit != 15 ||
+		stats.Files[1].Records != 2 || stats.Files[1].Name != "a.txt" {
+		t.Fatalf("wrong files %+v %+v", stats.Files[0], stats.Files[1])
+	}
+	if stats.Files[0].ServedPerProfit != 20 {
+		t.Fatalf("wrong served per profit %v", stats.Files[0].ServedPerProfit)
+	}
+	if len(stats.Downloaders) != 2 || stats.Downloaders[0].Key != "alice" || stats.Downloaders[0].Profit != 40 {
+		t.Fatalf("wrong downloaders %+v", stats.Downloaders[0])
+	}
+	if len(stats.Periods) != 2 || stats.Periods[0].Key != "2021-03-10" || stats.Periods[1].Profit != 5 {
+		t.Fatalf("wrong periods %+v", stats.Periods)
+	}
+	if len(stats.TopFiles) != 1 || stats.TopFiles[0].Key != "b" {
+		t.Fatalf("wrong top files %+v", stats.TopFiles)
+	}
+	if _, err := Aggregate(records, "year", 0, nil); err != ErrInvalidPeriod {
+		t.Fatalf("expect invalid period, got %v", err)
+	}
+}
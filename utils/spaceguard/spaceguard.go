@@ -0,0 +1,236 @@
+// Package spaceguard watches the usage and expiry of a userspace.
+// A policy raises alerts once when the used percent or the time to expiry crosses its thresholds,
+// and decides automatic extensions of space or time, limited by a budget of a period.
+// The zero value of a policy watches nothing.
+package spaceguard
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	ALERT_USAGE  = "usage"
+	ALERT_EXPIRY = "expiry"
+)
+
+const (
+	REASON_USAGE  = "used percent reached auto extend threshold"
+	REASON_EXPIRY = "expiry is within auto extend threshold"
+)
+
+// ACTION_RETRY_INTERVAL. min seconds between two automatic actions, avoid retrying a failed action too often
+const ACTION_RETRY_INTERVAL = 3600
+
+var ErrBudgetRequired = errors.New("budget is required with auto extend")
+
+// Policy. userspace guardian policy
+type Policy struct {
+	UsageThresholds  []uint64 // used percents to alert, e.g. 80, 95
+	ExpiryThresholds []uint64 // seconds before expiry to alert, e.g. 7 days
+	AutoExtend       bool
+	AutoUsage        uint64 // used percent to add AddSize, 0 is disabled
+	AddSize          uint64 // KB
+	AutoExpiry       uint64 // seconds before expiry to add AddPeriod, 0 is disabled
+	AddPeriod        uint64 // seconds
+	Budget           uint64 // max cost of automatic actions in BudgetPeriod
+	BudgetPeriod     uint64 // seconds, 0 for all actions
+	UpdatedAt        uint64
+}
+
+// Space. current userspace of the wallet
+type Space struct {
+	Used      uint64 // KB
+	Remain    uint64 // KB
+	ExpiredAt uint64
+}
+
+// State. alerted thresholds and last action time of guardian
+type State struct {
+	UsageAlerted  []uint64
+	ExpiryAlerted []uint64
+	LastActionAt  uint64
+}
+
+// Alert. a threshold crossed by the userspace
+type Alert struct {
+	Kind      string
+	Threshold uint64
+	Value     uint64 // used percent, or seconds to expiry
+	Used      uint64
+	Remain    uint64
+	ExpiredAt uint64
+	CreatedAt uint64
+}
+
+// Action. a automatic extension of userspace
+type Action struct {
+	AddSize   uint64
+	AddPeriod uint64
+	Reasons   []string
+}
+
+// ActionLog. a automatic action which has been tried
+type ActionLog struct {
+	Id        string
+	AddSize   uint64
+	AddPeriod uint64
+	Reasons   []string
+	Cost      uint64
+	Tx        string `json:",omitempty"`
+	Skipped   bool
+	Error     string `json:",omitempty"`
+	CreatedAt uint64
+}
+
+// Validate. check the thresholds and auto extension of the policy
+func (this *Policy) Validate() error {
+	for _, t := range this.UsageThresholds {
+		if t == 0 || t > 100 {
+			return fmt.Errorf("invalid usage threshold %d", t)
+		}
+	}
+	for _, t := range this.ExpiryThresholds {
+		if t == 0 {
+			return fmt.Errorf("invalid expiry threshold %d", t)
+		}
+	}
+	if !this.AutoExtend {
+		return nil
+	}
+	if this.AutoUsage > 100 {
+		return fmt.Errorf("invalid auto usage %d", this.AutoUsage)
+	}
+	if (this.AutoUsage == 0 || this.AddSize == 0) && (this.AutoExpiry == 0 || this.AddPeriod == 0) {
+		return errors.New("auto extend needs size or period to add")
+	}
+	if this.Budget == 0 {
+		return ErrBudgetRequired
+	}
+	return nil
+}
+
+// UsedPercent. used percent of the space, 0 if no space
+func (this *Space) UsedPercent() uint64 {
+	total := this.Used + this.Remain
+	if total == 0 {
+		return 0
+	}
+	return this.Used * 100 / total
+}
+
+// Check. check the space with the policy, return the new alerts and the automatic action.
+// An alert is raised once until the space goes back below its threshold. The state is updated in place.
+func (this *Policy) Check(space *Space, state *State, now time.Time) ([]*Alert, *Action) {
+	if space == nil || space.Used+space.Remain == 0 {
+		// no userspace to guard
+		state.UsageAlerted, state.ExpiryAlerted = nil, nil
+		return nil, nil
+	}
+	percent := space.UsedPercent()
+	left := uint64(0)
+	if space.ExpiredAt > uint64(now.Unix()) {
+		left = space.ExpiredAt - uint64(now.Unix())
+	}
+	alerts := make([]*Alert, 0)
+	newAlert := func(kind string, threshold, value uint64) *Alert {
+		return &Alert{
+			Kind:      kind,
+			Threshold: threshold,
+			Value:     value,
+			Used:      space.Used,
+			Remain:    space.Remain,
+			ExpiredAt: space.ExpiredAt,
+			CreatedAt: uint64(now.Unix()),
+		}
+	}
+	alerted := make([]uint64, 0)
+	for _, t := range sortedThresholds(this.UsageThresholds, true) {
+		if percent < t {
+			continue
+		}
+		if !contains(state.UsageAlerted, t) {
+			alerts = append(alerts, newAlert(ALERT_USAGE, t, percent))
+		}
+		alerted = append(alerted, t)
+	}
+	state.UsageAlerted = alerted
+	alerted = make([]uint64, 0)
+	for _, t := range sortedThresholds(this.ExpiryThresholds, false) {
+		if left > t {
+			continue
+		}
+		if !contains(state.ExpiryAlerted, t) {
+			alerts = append(alerts, newAlert(ALERT_EXPIRY, t, left))
+		}
+		alerted = append(alerted, t)
+	}
+	state.ExpiryAlerted = alerted
+	return alerts, this.action(percent, left, state, now)
+}
+
+func (this *Policy) action(percent, left uint64, state *State, now time.Time) *Action {
+	if !this.AutoExtend {
+		return nil
+	}
+	if state.LastActionAt+ACTION_RETRY_INTERVAL > uint64(now.Unix()) {
+		return nil
+	}
+	action := &Action{}
+	if this.AutoUsage > 0 && this.AddSize > 0 && percent >= this.AutoUsage {
+		action.AddSize = this.AddSize
+		action.Reasons = append(action.Reasons, REASON_USAGE)
+	}
+	if this.AutoExpiry > 0 && this.AddPeriod > 0 && left <= this.AutoExpiry {
+		action.AddPeriod = this.AddPeriod
+		action.Reasons = append(action.Reasons, REASON_EXPIRY)
+	}
+	if action.AddSize == 0 && action.AddPeriod == 0 {
+		return nil
+	}
+	return action
+}
+
+// Spent. cost of the automatic actions in the budget period before now
+func (this *Policy) Spent(logs []*ActionLog, now time.Time) uint64 {
+	spent := uint64(0)
+	for _, l := range logs {
+		if l.Skipped || len(l.Error) > 0 {
+			continue
+		}
+		if this.BudgetPeriod > 0 && l.CreatedAt+this.BudgetPeriod <= uint64(now.Unix()) {
+			continue
+		}
+		spent += l.Cost
+	}
+	return spent
+}
+
+// Affordable. the cost is within the remaining budget
+func (this *Policy) Affordable(cost uint64, logs []*ActionLog, now time.Time) bool {
+	spent := this.Spent(logs, now)
+	return spent <= this.Budget && cost <= this.Budget-spent
+}
+
+// sortedThresholds. copy and sort thresholds, ascending for percents, descending for seconds
+func sortedThresholds(thresholds []uint64, asc bool) []uint64 {
+	sorted := append([]uint64{}, thresholds...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if asc {
+			return sorted[i] < sorted[j]
+		}
+		return sorted[i] > sorted[j]
+	})
+	return sorted
+}
+
+func contains(list []uint64, v uint64) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package spaceguard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckAlerts(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.Local)
+	day := uint64(24 * 3600)
+	policy := &Policy{UsageThresholds: []uint64{95, 80}, ExpiryThresholds: []uint64{day, 7 * day}}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	state := &State{}
+	space := &Space{Used: 85, Remain: 15, ExpiredAt: uint64(now.Unix()) + 3*day}
+	alerts, action := policy.Check(space, state, now)
+	if action != nil {
+		t.Fatalf("unexpected action %+v", action)
+	}
+	if len(alerts) != 2 || alerts[0].Kind != ALERT_USAGE || alerts[0].Threshold != 80 ||
+		alerts[1].Kind != ALERT_EXPIRY || alerts[1].Threshold != 7*day {
+		t.Fatalf("unexpected alerts %+v", alerts)
+	}
+	// alerted thresholds are not raised again
+	if alerts, _ = policy.Check(space, state, now); len(alerts) != 0 {
+		t.Fatalf("alerts raised again %+v", alerts)
+	}
+	space.Used, space.Remain = 96, 4
+	if alerts, _ = policy.Check(space, state, now); len(alerts) != 1 || alerts[0].Threshold != 95 {
+		t.Fatalf("unexpected alerts %+v", alerts)
+	}
+	// back below threshold resets it
+	space.Used, space.Remain = 10, 90
+	space.ExpiredAt = uint64(now.Unix()) + 30*day
+	if alerts, _ = policy.Check(space, state, now); len(alerts) != 0 || len(state.UsageAlerted) != 0 {
+		t.Fatalf("unexpected alerts %+v, state %+v", alerts, state)
+	}
+	space.Used, space.Remain = 81, 19
+	if alerts, _ = policy.Check(space, state, now); len(alerts) != 1 || alerts[0].Threshold != 80 {
+		t.Fatalf("unexpected alerts %+v", alerts)
+	}
+	if alerts, _ = policy.Check(&Space{}, state, now); len(alerts) != 0 {
+		t.Fatalf("alerts of empty space %+v", alerts)
+	}
+}
+
+func TestCheckAction(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.Local)
+	day := uint64(24 * 3600)
+	policy := &Policy{AutoExtend: true, AutoUsage: 90, AddSize: 1024, AutoExpiry: 2 * day, AddPeriod: 30 * day}
+	if err := policy.Validate(); err != ErrBudgetRequired {
+		t.Fatalf("expect budget required, got %v", err)
+	}
+	policy.Budget = 100
+	policy.BudgetPeriod = 30 * day
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	state := &State{}
+	space := &Space{Used: 95, Remain: 5, ExpiredAt: uint64(now.Unix()) + day}
+	_, action := policy.Check(space, state, now)
+	if action == nil || action.AddSize != 1024 || action.AddPeriod != 30*day || len(action.Reasons) != 2 {
+		t.Fatalf("unexpected action %+v", action)
+	}
+	state.LastActionAt = uint64(now.Unix())
+	if _, action = policy.Check(space, state, now.Add(time.Minute)); action != nil {
+		t.Fatalf("action retried too early %+v", action)
+	}
+	space.ExpiredAt = uint64(now.Unix()) + 10*day
+	_, action = policy.Check(space, state, now.Add(2*time.Hour))
+	if action == nil || action.AddSize != 1024 || action.AddPeriod != 0 {
+		t.Fatalf("unexpected action %+v", action)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	now := time.Date(2021, 3, 10, 12, 0, 0, 0, time.Local)
+	policy := &Policy{Budget: 100, BudgetPeriod: 3600}
+	logs := []*ActionLog{
+		{Cost: 50, CreatedAt: uint64(now.Unix()) - 7200},
+		{Cost: 30, CreatedAt: uint64(now.Unix()) - 60},
+		{Cost: 40, CreatedAt: uint64(now.Unix()) - 60, Error: "failed"},
+		{Cost: 40, CreatedAt: uint64(now.Unix()) - 60, Skipped: true},
+	}
+	if spent := policy.Spent(logs, now); spent != 30 {
+		t.Fatalf("spent %d, expect 30", spent)
+	}
+	if !policy.Affordable(70, logs, now) || policy.Affordable(71, logs, now) {
+		t.Fatal("unexpected affordable result")
+	}
+	policy.BudgetPeriod = 0
+	if policy.Affordable(30, logs, now) {
+		t.Fatal("unexpected affordable without period")
+	}
+}
@@ -0,0 +1,145 @@
+// Package transferhistory reads and writes the transfer history archive in JSON Lines,
+// and merges the imported records into the pages of local transfer list.
+package transferhistory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidPath   = errors.New("archive path should be a relative path under the history directory")
+	ErrInvalidRecord = errors.New("invalid transfer history record")
+)
+
+// Record. the fields of each archive record used to check and deduplicate it
+type Record struct {
+	Version int
+	TaskId  string
+}
+
+// ArchivePath. the path of archive in dir, the name can't be absolute or go out of dir
+func ArchivePath(dir, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || filepath.IsAbs(name) {
+		return "", ErrInvalidPath
+	}
+	name = filepath.Clean(name)
+	if name == "." || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidPath
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Writer. write the records line by line, the task written before is skipped
+type Writer struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+	written map[string]struct{}
+	Total   int
+}
+
+func NewWriter(w io.Writer) *Writer {
+	bw := bufio.NewWriter(w)
+	return &Writer{
+		w:       bw,
+		encoder: json.NewEncoder(bw),
+		written: make(map[string]struct{}),
+	}
+}
+
+// Written. whether the task has been written
+func (this *Writer) Written(taskId string) bool {
+	_, ok := this.written[taskId]
+	return ok
+}
+
+// Write. write the record of task, return false if the task has been written
+func (this *Writer) Write(taskId string, record interface{}) (bool, error) {
+	if this.Written(taskId) {
+		return false, nil
+	}
+	if err := this.encoder.Encode(record); err != nil {
+		return false, err
+	}
+	this.written[taskId] = struct{}{}
+	this.Total++
+	return true, nil
+}
+
+func (this *Writer) Flush() error {
+	return this.w.Flush()
+}
+
+// Read. call handle with each line of archive, empty lines are skipped
+func Read(r io.Reader, handle func(line []byte) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			if herr := handle(line); herr != nil {
+				return herr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// Decode. decode the line into record, return ErrInvalidRecord if it's not a record of task,
+// or its version is newer than maxVersion
+func Decode(line []byte, maxVersion int, record interface{}) error {
+	header := &Record{}
+	if err := json.Unmarshal(line, header); err != nil || len(header.TaskId) == 0 || header.Version > maxVersion {
+		return ErrInvalidRecord
+	}
+	if err := json.Unmarshal(line, record); err != nil {
+		return ErrInvalidRecord
+	}
+	return nil
+}
+
+// Page. the ranges of local and imported records in a page, the imported records are listed after local ones.
+// The local records are queried from 0 with limit offset+limit, so the local count is known.
+type Page struct {
+	LocalStart    int
+	LocalEnd      int
+	ImportedStart int
+	ImportedEnd   int
+}
+
+// MergePage. compute the page of offset and limit from local and imported records, zero limit means no limit
+func MergePage(local, imported int, offset, limit uint32) *Page {
+	page := &Page{}
+	start := int(offset)
+	if start < local {
+		page.LocalStart, page.LocalEnd = start, local
+	} else {
+		page.LocalStart, page.LocalEnd = local, local
+		page.ImportedStart = start - local
+	}
+	if page.ImportedStart > imported {
+		page.ImportedStart = imported
+	}
+	page.ImportedEnd = imported
+	if limit == 0 {
+		return page
+	}
+	if page.LocalEnd-page.LocalStart > int(limit) {
+		page.LocalEnd = page.LocalStart + int(limit)
+	}
+	remain := int(limit) - (page.LocalEnd - page.LocalStart)
+	if page.ImportedEnd-page.ImportedStart > remain {
+		page.ImportedEnd = page.ImportedStart + remain
+	}
+	return page
+}
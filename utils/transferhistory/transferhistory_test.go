@@ -0,0 +1,104 @@
+package transferhistory
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+type testRecord struct {
+	Version int
+	TaskId  string
+	Name    string
+}
+
+func TestArchivePath(t *testing.T) {
+	dir := filepath.Join("data", "history")
+	path, err := ArchivePath(dir, "backup/a.jsonl")
+	if err != nil || path != filepath.Join(dir, "backup", "a.jsonl") {
+		t.Fatalf("unexpected path %s, err %v", path, err)
+	}
+	if path, err = ArchivePath(dir, "backup/../a.jsonl"); err != nil || path != filepath.Join(dir, "a.jsonl") {
+		t.Fatalf("unexpected path %s, err %v", path, err)
+	}
+	for _, name := range []string{"", "/etc/passwd", "..", "../a.jsonl", "a/../../b.jsonl", "."} {
+		if _, err := ArchivePath(dir, name); err != ErrInvalidPath {
+			t.Fatalf("expect invalid path of %q, got %v", name, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewWriter(buf)
+	records := []*testRecord{
+		{Version: 1, TaskId: "a", Name: "a.txt"},
+		{Version: 1, TaskId: "b", Name: "b.txt"},
+		{Version: 1, TaskId: "a", Name: "dup.txt"},
+	}
+	for _, r := range records {
+		if _, err := w.Write(r.TaskId, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Total != 2 || !w.Written("a") || w.Written("c") {
+		t.Fatalf("unexpected writer total %d", w.Total)
+	}
+	// a empty line, invalid records, a record of newer version and a task read before
+	buf.WriteString("\n{\"Name\":\"no id\"}\n{\"Version\":2,\"TaskId\":\"c\"}\nnot json\n")
+	buf.WriteString("{\"Version\":1,\"TaskId\":\"b\",\"Name\":\"b2.txt\"}")
+
+	read := make([]*testRecord, 0)
+	invalid := 0
+	seen := make(map[string]struct{})
+	skipped := 0
+	err := Read(buf, func(line []byte) error {
+		r := &testRecord{}
+		if err := Decode(line, 1, r); err != nil {
+			invalid++
+			return nil
+		}
+		if _, ok := seen[r.TaskId]; ok {
+			skipped++
+			return nil
+		}
+		seen[r.TaskId] = struct{}{}
+		read = append(read, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 || *read[0] != *records[0] || *read[1] != *records[1] || invalid != 3 || skipped != 1 {
+		t.Fatalf("unexpected records %v, invalid %d, skipped %d", read, invalid, skipped)
+	}
+}
+
+func TestMergePage(t *testing.T) {
+	cases := []struct {
+		local, imported int
+		offset, limit   uint32
+		expect          Page
+	}{
+		// local only
+		{5, 3, 0, 2, Page{0, 2, 0, 0}},
+		// across local and imported
+		{5, 3, 4, 3, Page{4, 5, 0, 2}},
+		// imported only
+		{5, 3, 6, 10, Page{5, 5, 1, 3}},
+		// out of range
+		{5, 3, 9, 2, Page{5, 5, 3, 3}},
+		// no limit
+		{5, 3, 2, 0, Page{2, 5, 0, 3}},
+		{0, 3, 0, 2, Page{0, 0, 0, 2}},
+	}
+	for i, c := range cases {
+		page := MergePage(c.local, c.imported, c.offset, c.limit)
+		if *page != c.expect {
+			t.Fatalf("case %d: unexpected page %+v, expect %+v", i, *page, c.expect)
+		}
+	}
+}
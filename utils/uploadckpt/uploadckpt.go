@@ -0,0 +1,118 @@
+// Package uploadckpt keeps the blocks of an upload acknowledged by each storage node,
+// so an interrupted upload is resumed from the first block a node hasn't acknowledged.
+// The blocks are kept as sorted ranges of block indexes.
+package uploadckpt
+
+import "sort"
+
+// Range. block indexes from Start to End, End is excluded
+type Range struct {
+	Start uint64
+	End   uint64
+}
+
+// Blocks. sorted ranges of block indexes acknowledged by a node, the ranges don't overlap or touch
+type Blocks []*Range
+
+// Add. add the blocks from start to end, end is excluded
+func (this Blocks) Add(start, end uint64) Blocks {
+	if end <= start {
+		return this
+	}
+	ranges := append(this, &Range{Start: start, End: end})
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+	merged := make(Blocks, 0, len(ranges))
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && r.Start <= merged[last].End {
+			if r.End > merged[last].End {
+				merged[last].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, &Range{Start: r.Start, End: r.End})
+	}
+	return merged
+}
+
+// Count. count of blocks
+func (this Blocks) Count() uint64 {
+	count := uint64(0)
+	for _, r := range this {
+		count += r.End - r.Start
+	}
+	return count
+}
+
+// Contains. whether the block of index is acknowledged
+func (this Blocks) Contains(index uint64) bool {
+	for _, r := range this {
+		if index >= r.Start && index < r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Next. the first block not acknowledged
+func (this Blocks) Next() uint64 {
+	if len(this) == 0 || this[0].Start > 0 {
+		return 0
+	}
+	return this[0].End
+}
+
+// Checkpoint. blocks acknowledged by each node of an upload
+type Checkpoint struct {
+	Total uint64            // block count of the file
+	Nodes map[string]Blocks // acknowledged blocks of each node by wallet address
+}
+
+// Ack. a node acknowledged count blocks, the blocks are sent to each node in index order,
+// so they are the blocks from 0 to count. The acknowledged blocks are kept if the count goes back.
+func (this *Checkpoint) Ack(node string, count uint64) {
+	if this.Nodes == nil {
+		this.Nodes = make(map[string]Blocks)
+	}
+	if count > this.Total && this.Total > 0 {
+		count = this.Total
+	}
+	this.Nodes[node] = this.Nodes[node].Add(0, count)
+}
+
+// ResumeFrom. the first block not acknowledged of each node
+func (this *Checkpoint) ResumeFrom() map[string]uint64 {
+	from := make(map[string]uint64, len(this.Nodes))
+	for node, blocks := range this.Nodes {
+		from[node] = blocks.Next()
+	}
+	return from
+}
+
+// Sent. all nodes acknowledged all blocks, only the confirmation of the upload is left
+func (this *Checkpoint) Sent() bool {
+	if this.Total == 0 || len(this.Nodes) == 0 {
+		return false
+	}
+	for _, blocks := range this.Nodes {
+		if blocks.Next() < this.Total {
+			return false
+		}
+	}
+	return true
+}
+
+// Regressed. nodes whose acknowledged count reported by the task is less than the checkpoint,
+// the task is sending the blocks to them again
+func (this *Checkpoint) Regressed(counts map[string]uint64) []string {
+	nodes := make([]string, 0)
+	for node, count := range counts {
+		if count < this.Nodes[node].Next() {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
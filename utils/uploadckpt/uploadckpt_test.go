@@ -0,0 +1,35 @@
+package uploadckpt
+
+import "testing"
+
+func TestBlocks(t *testing.T) {
+	var blocks Blocks
+	blocks = blocks.Add(5, 8).Add(0, 3).Add(3, 4).Add(7, 10).Add(2, 2)
+	if len(blocks) != 2 || blocks[0].End != 4 || blocks[1].Start != 5 || blocks[1].End != 10 {
+		t.Fatalf("unexpected blocks %+v %+v", blocks[0], blocks[1])
+	}
+	if blocks.Count() != 9 || blocks.Next() != 4 || !blocks.Contains(9) || blocks.Contains(4) {
+		t.Fatalf("unexpected blocks count %d next %d", blocks.Count(), blocks.Next())
+	}
+	if (Blocks{}).Add(1, 3).Next() != 0 {
+		t.Fatal("next should be 0 if block 0 is missing")
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	cp := &Checkpoint{Total: 10}
+	cp.Ack("a", 4)
+	cp.Ack("b", 10)
+	cp.Ack("a", 2)
+	from := cp.ResumeFrom()
+	if from["a"] != 4 || from["b"] != 10 || cp.Sent() {
+		t.Fatalf("unexpected resume from %v", from)
+	}
+	if nodes := cp.Regressed(map[string]uint64{"a": 1, "b": 10, "c": 0}); len(nodes) != 1 || nodes[0] != "a" {
+		t.Fatalf("unexpected regressed nodes %v", nodes)
+	}
+	cp.Ack("a", 12)
+	if !cp.Sent() || cp.Nodes["a"].Count() != 10 {
+		t.Fatalf("all blocks should be sent %+v", cp.Nodes["a"])
+	}
+}